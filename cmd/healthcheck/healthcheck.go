@@ -1,8 +1,10 @@
 package healthcheck
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -75,5 +77,16 @@ func Do(staticConfiguration static.Configuration) (*http.Response, error) {
 
 	path := "/"
 
-	return client.Head(protocol + "://" + pingEntryPoint.GetAddress() + path + "ping")
+	address := pingEntryPoint.GetAddress()
+	if epProtocol, _ := pingEntryPoint.GetProtocol(); epProtocol == "unix" {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", address)
+			},
+		}
+		address = "localhost"
+	}
+
+	return client.Head(protocol + "://" + address + path + "ping")
 }
@@ -4,18 +4,19 @@ import (
 	"fmt"
 
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/plugins"
 )
 
 const outputDir = "./plugins-storage/"
 
-func createPluginBuilder(staticConfiguration *static.Configuration) (*plugins.Builder, error) {
+func createPluginBuilder(staticConfiguration *static.Configuration, metricsRegistry metrics.Registry) (*plugins.Builder, error) {
 	client, plgs, localPlgs, err := initPlugins(staticConfiguration)
 	if err != nil {
 		return nil, err
 	}
 
-	return plugins.NewBuilder(client, plgs, localPlgs)
+	return plugins.NewBuilder(client, plgs, localPlgs, metricsRegistry)
 }
 
 func initPlugins(staticCfg *static.Configuration) (*plugins.Client, map[string]plugins.Descriptor, map[string]plugins.LocalDescriptor, error) {
@@ -29,7 +30,8 @@ func initPlugins(staticCfg *static.Configuration) (*plugins.Client, map[string]p
 
 	if hasPlugins(staticCfg) {
 		opts := plugins.ClientOptions{
-			Output: outputDir,
+			Output:   outputDir,
+			Registry: staticCfg.Experimental.PluginRegistry,
 		}
 
 		var err error
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	stdlog "log"
@@ -18,6 +19,7 @@ import (
 	"github.com/coreos/go-systemd/v22/daemon"
 	"github.com/go-acme/lego/v4/challenge"
 	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/kvtools/valkeyrie/store"
 	"github.com/rs/zerolog/log"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
@@ -30,9 +32,11 @@ import (
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/logs"
 	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares/accesslog"
+	"github.com/apache4/apache4/v3/pkg/plugins"
 	"github.com/apache4/apache4/v3/pkg/provider/acme"
 	"github.com/apache4/apache4/v3/pkg/provider/aggregator"
 	"github.com/apache4/apache4/v3/pkg/provider/tailscale"
@@ -185,6 +189,16 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	tlsManager := apache4tls.NewManager(staticConfiguration.OCSP)
 	routinesPool.GoCtx(tlsManager.Run)
 
+	if staticConfiguration.SessionTickets != nil {
+		sessionTicketKeys, err := initSessionTicketKeys(staticConfiguration)
+		if err != nil {
+			return nil, fmt.Errorf("initializing session ticket keys: %w", err)
+		}
+
+		routinesPool.GoCtx(sessionTicketKeys.Run)
+		tlsManager.SetSessionTicketKeys(sessionTicketKeys)
+	}
+
 	httpChallengeProvider := acme.NewChallengeHTTP()
 
 	tlsChallengeProvider := acme.NewChallengeTLSALPN()
@@ -210,18 +224,29 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	}
 	metricsRegistry := metrics.NewMultiRegistry(metricRegistries)
+	tlsManager.SetRevocationFailuresCounter(metricsRegistry.TLSClientCertRevocationFailuresCounter())
 	accessLog := setupAccessLog(ctx, staticConfiguration.AccessLog)
 	tracer, tracerCloser := setupTracing(ctx, staticConfiguration.Tracing)
 	observabilityMgr := middleware.NewObservabilityMgr(*staticConfiguration, metricsRegistry, semConvMetricRegistry, accessLog, tracer, tracerCloser)
 
+	// GeoIP
+
+	var geoIPDatabase *geoip.Database
+	if staticConfiguration.GeoIP != nil {
+		geoIPDatabase, err = geoip.NewDatabase(ctx, staticConfiguration.GeoIP.Database, staticConfiguration.GeoIP.ASNDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("loading GeoIP databases: %w", err)
+		}
+	}
+
 	// Entrypoints
 
-	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints, staticConfiguration.HostResolver, metricsRegistry)
+	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints, staticConfiguration.HostResolver, geoIPDatabase, metricsRegistry, tracer)
 	if err != nil {
 		return nil, err
 	}
 
-	serverEntryPointsUDP, err := server.NewUDPEntryPoints(staticConfiguration.EntryPoints)
+	serverEntryPointsUDP, err := server.NewUDPEntryPoints(staticConfiguration.EntryPoints, tracer)
 	if err != nil {
 		return nil, err
 	}
@@ -241,8 +266,9 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		pluginLogger.Info().Msg("Loading plugins...")
 	}
 
-	pluginBuilder, err := createPluginBuilder(staticConfiguration)
-	if err != nil && staticConfiguration.Experimental != nil && staticConfiguration.Experimental.AbortOnPluginFailure {
+	pluginBuilder, err := createPluginBuilder(staticConfiguration, metricsRegistry)
+	// The integrity check failures always prevent apache4 from starting.
+	if err != nil && (errors.Is(err, plugins.ErrIntegrityCheck) || staticConfiguration.Experimental != nil && staticConfiguration.Experimental.AbortOnPluginFailure) {
 		return nil, fmt.Errorf("plugin: failed to create plugin builder: %w", err)
 	}
 	if err != nil {
@@ -299,7 +325,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 
 	dialerManager := tcp.NewDialerManager(spiffeX509Source)
 	acmeHTTPHandler := getHTTPChallengeHandler(acmeProviders, httpChallengeProvider)
-	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, observabilityMgr, transportManager, proxyBuilder, acmeHTTPHandler)
+	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, observabilityMgr, transportManager, proxyBuilder, acmeHTTPHandler, tlsManager)
 
 	// Router factory
 
@@ -388,6 +414,14 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	})
 
+	// Local plugins in watch mode
+	if pluginBuilder != nil {
+		err = pluginBuilder.WatchLocalPlugins(routinesPool, watcher.Refresh)
+		if err != nil {
+			pluginLogger.Err(err).Msg("Unable to watch the local plugins")
+		}
+	}
+
 	return server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, watcher, observabilityMgr), nil
 }
 
@@ -505,6 +539,42 @@ func initTailscaleProviders(cfg *static.Configuration, providerAggregator *aggre
 	return providers
 }
 
+// initSessionTicketKeys creates the session ticket keys shared by several instances,
+// the KV store holding the keys being the one of the designated KV provider.
+func initSessionTicketKeys(cfg *static.Configuration) (*apache4tls.SessionTicketKeys, error) {
+	if cfg.SessionTickets.KV == nil {
+		return apache4tls.NewSessionTicketKeys(cfg.SessionTickets, nil)
+	}
+
+	var pvd interface {
+		Init() error
+		KVClient() store.Store
+	}
+
+	// The providers are copied, so that the store client is not shared with the provider instances.
+	switch cfg.SessionTickets.KV.Provider {
+	case "consul":
+		pvd = cfg.Providers.Consul.BuildProviders()[0]
+	case "etcd":
+		etcdProvider := *cfg.Providers.Etcd
+		pvd = &etcdProvider
+	case "redis":
+		redisProvider := *cfg.Providers.Redis
+		pvd = &redisProvider
+	case "zooKeeper":
+		zkProvider := *cfg.Providers.ZooKeeper
+		pvd = &zkProvider
+	default:
+		return nil, fmt.Errorf("unsupported KV provider %q", cfg.SessionTickets.KV.Provider)
+	}
+
+	if err := pvd.Init(); err != nil {
+		return nil, fmt.Errorf("initializing KV provider %q: %w", cfg.SessionTickets.KV.Provider, err)
+	}
+
+	return apache4tls.NewSessionTicketKeys(cfg.SessionTickets, pvd.KVClient())
+}
+
 func registerMetricClients(metricsConfig *types.Metrics) []metrics.Registry {
 	if metricsConfig == nil {
 		return nil
@@ -11,6 +11,7 @@ import (
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/tls"
 	"github.com/apache4/apache4/v3/pkg/version"
 )
 
@@ -51,12 +52,18 @@ type Handler struct {
 
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *runtime.Configuration
+
+	// tlsManager provides the TLS certificates, options and stores exposed by the API.
+	tlsManager *tls.Manager
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration.
-func NewBuilder(staticConfig static.Configuration) func(*runtime.Configuration) http.Handler {
+func NewBuilder(staticConfig static.Configuration, tlsManager *tls.Manager) func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).createRouter()
+		handler := New(staticConfig, configuration)
+		handler.tlsManager = tlsManager
+
+		return handler.createRouter()
 	}
 }
 
@@ -113,6 +120,10 @@ func (h Handler) createRouter() *mux.Router {
 	apiRouter.Methods(http.MethodGet).Path("/api/udp/services").HandlerFunc(h.getUDPServices)
 	apiRouter.Methods(http.MethodGet).Path("/api/udp/services/{serviceID}").HandlerFunc(h.getUDPService)
 
+	apiRouter.Methods(http.MethodGet).Path("/api/tls/certificates").HandlerFunc(h.getTLSCertificates)
+	apiRouter.Methods(http.MethodGet).Path("/api/tls/options").HandlerFunc(h.getTLSOptions)
+	apiRouter.Methods(http.MethodGet).Path("/api/tls/stores").HandlerFunc(h.getTLSStores)
+
 	version.Handler{}.Append(apiRouter)
 
 	return router
@@ -52,17 +52,23 @@ func newServiceRepresentation(name string, si *runtime.ServiceInfo) serviceRepre
 
 type middlewareRepresentation struct {
 	*runtime.MiddlewareInfo
-	Name     string `json:"name,omitempty"`
-	Provider string `json:"provider,omitempty"`
-	Type     string `json:"type,omitempty"`
+	CircuitBreakers []runtime.CircuitBreakerStatus `json:"circuitBreakers,omitempty"`
+	Err             []string                       `json:"error,omitempty"`
+	Status          string                         `json:"status,omitempty"`
+	Name            string                         `json:"name,omitempty"`
+	Provider        string                         `json:"provider,omitempty"`
+	Type            string                         `json:"type,omitempty"`
 }
 
 func newMiddlewareRepresentation(name string, mi *runtime.MiddlewareInfo) middlewareRepresentation {
 	return middlewareRepresentation{
-		MiddlewareInfo: mi,
-		Name:           name,
-		Provider:       getProviderName(name),
-		Type:           strings.ToLower(extractType(mi.Middleware)),
+		MiddlewareInfo:  mi,
+		CircuitBreakers: mi.GetCircuitBreakersStatus(),
+		Err:             mi.GetErrors(),
+		Status:          mi.GetStatus(),
+		Name:            name,
+		Provider:        getProviderName(name),
+		Type:            strings.ToLower(extractType(mi.Middleware)),
 	}
 }
 
@@ -261,5 +267,5 @@ func keepMiddleware(name string, item *runtime.MiddlewareInfo, criterion *search
 		return true
 	}
 
-	return criterion.withStatus(item.Status) && criterion.searchIn(name)
+	return criterion.withStatus(item.GetStatus()) && criterion.searchIn(name)
 }
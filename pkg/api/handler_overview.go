@@ -108,7 +108,7 @@ func getHTTPMiddlewareSection(middlewares map[string]*runtime.MiddlewareInfo) *s
 	var countErrors int
 	var countWarnings int
 	for _, mid := range middlewares {
-		switch mid.Status {
+		switch mid.GetStatus() {
 		case runtime.StatusDisabled:
 			countErrors++
 		case runtime.StatusWarning:
@@ -165,7 +165,7 @@ func getTCPMiddlewareSection(middlewares map[string]*runtime.TCPMiddlewareInfo)
 	var countErrors int
 	var countWarnings int
 	for _, mid := range middlewares {
-		switch mid.Status {
+		switch mid.GetStatus() {
 		case runtime.StatusDisabled:
 			countErrors++
 		case runtime.StatusWarning:
@@ -45,14 +45,18 @@ func newTCPServiceRepresentation(name string, si *runtime.TCPServiceInfo) tcpSer
 
 type tcpMiddlewareRepresentation struct {
 	*runtime.TCPMiddlewareInfo
-	Name     string `json:"name,omitempty"`
-	Provider string `json:"provider,omitempty"`
-	Type     string `json:"type,omitempty"`
+	Err      []string `json:"error,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	Name     string   `json:"name,omitempty"`
+	Provider string   `json:"provider,omitempty"`
+	Type     string   `json:"type,omitempty"`
 }
 
 func newTCPMiddlewareRepresentation(name string, mi *runtime.TCPMiddlewareInfo) tcpMiddlewareRepresentation {
 	return tcpMiddlewareRepresentation{
 		TCPMiddlewareInfo: mi,
+		Err:               mi.GetErrors(),
+		Status:            mi.GetStatus(),
 		Name:              name,
 		Provider:          getProviderName(name),
 		Type:              strings.ToLower(extractType(mi.TCPMiddleware)),
@@ -254,5 +258,5 @@ func keepTCPMiddleware(name string, item *runtime.TCPMiddlewareInfo, criterion *
 		return true
 	}
 
-	return criterion.withStatus(item.Status) && criterion.searchIn(name)
+	return criterion.withStatus(item.GetStatus()) && criterion.searchIn(name)
 }
@@ -0,0 +1,306 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	httpmuxer "github.com/apache4/apache4/v3/pkg/muxer/http"
+	tcpmuxer "github.com/apache4/apache4/v3/pkg/muxer/tcp"
+	"github.com/apache4/apache4/v3/pkg/tls"
+)
+
+const acmeProviderSuffix = ".acme"
+
+type tlsCertificateRepresentation struct {
+	Fingerprint  string    `json:"fingerprint"`
+	Store        string    `json:"store"`
+	Source       string    `json:"source,omitempty"`
+	Resolver     string    `json:"resolver,omitempty"`
+	Default      bool      `json:"default,omitempty"`
+	CommonName   string    `json:"commonName,omitempty"`
+	SANs         []string  `json:"sans,omitempty"`
+	Issuer       string    `json:"issuer,omitempty"`
+	SerialNumber string    `json:"serialNumber,omitempty"`
+	NotBefore    time.Time `json:"notBefore"`
+	NotAfter     time.Time `json:"notAfter"`
+	OCSPStaple   string    `json:"ocspStaple,omitempty"`
+	Routers      []string  `json:"routers,omitempty"`
+	TCPRouters   []string  `json:"tcpRouters,omitempty"`
+}
+
+func newTLSCertificateRepresentation(info tls.CertificateInfo) tlsCertificateRepresentation {
+	cert := info.Certificate
+
+	repr := tlsCertificateRepresentation{
+		Fingerprint:  fingerprint(cert.Raw),
+		Store:        info.Store,
+		Source:       info.Source,
+		Default:      info.Default,
+		CommonName:   cert.Subject.CommonName,
+		SANs:         cert.DNSNames,
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: cert.SerialNumber.String(),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		OCSPStaple:   info.OCSPStaple,
+	}
+
+	for _, ip := range cert.IPAddresses {
+		repr.SANs = append(repr.SANs, ip.String())
+	}
+
+	if resolver, ok := strings.CutSuffix(info.Source, acmeProviderSuffix); ok {
+		repr.Resolver = resolver
+	}
+
+	return repr
+}
+
+type tlsOptionsRepresentation struct {
+	tls.Options
+	Name     string `json:"name,omitempty"`
+	Provider string `json:"provider,omitempty"`
+}
+
+type tlsStoreRepresentation struct {
+	Name                 string             `json:"name,omitempty"`
+	Provider             string             `json:"provider,omitempty"`
+	DefaultCertificate   string             `json:"defaultCertificate,omitempty"`
+	DefaultGeneratedCert *tls.GeneratedCert `json:"defaultGeneratedCert,omitempty"`
+	Certificates         int                `json:"certificates"`
+}
+
+func (h Handler) getTLSCertificates(rw http.ResponseWriter, request *http.Request) {
+	var infos []tls.CertificateInfo
+	if h.tlsManager != nil {
+		infos = h.tlsManager.GetCertificatesInfo()
+	}
+
+	criterion := newSearchCriterion(request.URL.Query())
+
+	usages := h.getCertificateUsages()
+
+	results := make([]tlsCertificateRepresentation, 0, len(infos))
+	for _, info := range infos {
+		repr := newTLSCertificateRepresentation(info)
+		if criterion != nil && !criterion.searchIn(append([]string{repr.CommonName, repr.Store, repr.Source, repr.Issuer}, repr.SANs...)...) {
+			continue
+		}
+
+		if repr.Store == tls.DefaultTLSStoreName {
+			usage := usages[repr.Fingerprint]
+			repr.Routers = usage.routers
+			repr.TCPRouters = usage.tcpRouters
+		}
+
+		results = append(results, repr)
+	}
+
+	// The certificates expiring first come first.
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].NotAfter.Equal(results[j].NotAfter) {
+			return results[i].NotAfter.Before(results[j].NotAfter)
+		}
+		if results[i].Store != results[j].Store {
+			return results[i].Store < results[j].Store
+		}
+		return results[i].Fingerprint < results[j].Fingerprint
+	})
+
+	writePaginatedResults(rw, request, results)
+}
+
+func (h Handler) getTLSOptions(rw http.ResponseWriter, request *http.Request) {
+	var options map[string]tls.Options
+	if h.tlsManager != nil {
+		options = h.tlsManager.GetOptions()
+	}
+
+	criterion := newSearchCriterion(request.URL.Query())
+
+	results := make([]tlsOptionsRepresentation, 0, len(options))
+	for name, option := range options {
+		if criterion != nil && !criterion.searchIn(name) {
+			continue
+		}
+
+		results = append(results, tlsOptionsRepresentation{
+			Options:  option,
+			Name:     name,
+			Provider: getTLSProviderName(name),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	writePaginatedResults(rw, request, results)
+}
+
+func (h Handler) getTLSStores(rw http.ResponseWriter, request *http.Request) {
+	var stores map[string]tls.Store
+	var infos []tls.CertificateInfo
+	if h.tlsManager != nil {
+		stores = h.tlsManager.GetStores()
+		infos = h.tlsManager.GetCertificatesInfo()
+	}
+
+	criterion := newSearchCriterion(request.URL.Query())
+
+	results := make([]tlsStoreRepresentation, 0, len(stores))
+	for name, store := range stores {
+		if criterion != nil && !criterion.searchIn(name) {
+			continue
+		}
+
+		repr := tlsStoreRepresentation{
+			Name:                 name,
+			Provider:             getTLSProviderName(name),
+			DefaultGeneratedCert: store.DefaultGeneratedCert,
+		}
+
+		for _, info := range infos {
+			if info.Store != name {
+				continue
+			}
+
+			if info.Default {
+				repr.DefaultCertificate = fingerprint(info.Certificate.Raw)
+				continue
+			}
+
+			repr.Certificates++
+		}
+
+		results = append(results, repr)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	writePaginatedResults(rw, request, results)
+}
+
+type certificateUsage struct {
+	routers    []string
+	tcpRouters []string
+}
+
+// getCertificateUsages returns, by certificate fingerprint, the routers served with the certificate of the default TLS store.
+func (h Handler) getCertificateUsages() map[string]certificateUsage {
+	usages := make(map[string]certificateUsage)
+	if h.tlsManager == nil {
+		return usages
+	}
+
+	servedCertificates := func(domains []string) []string {
+		var fingerprints []string
+		for _, domain := range domains {
+			cert := h.tlsManager.MatchCertificate(tls.DefaultTLSStoreName, domain)
+			if cert == nil {
+				continue
+			}
+
+			if fp := fingerprint(cert.Raw); !slices.Contains(fingerprints, fp) {
+				fingerprints = append(fingerprints, fp)
+			}
+		}
+
+		return fingerprints
+	}
+
+	for name, rt := range h.runtimeConfiguration.Routers {
+		if rt.TLS == nil {
+			continue
+		}
+
+		domains, err := httpmuxer.ParseDomains(rt.Rule)
+		if err != nil {
+			log.Debug().Err(err).Str("routerName", name).Msg("Unable to parse router domains")
+		}
+
+		for _, domain := range rt.TLS.Domains {
+			domains = append(domains, domain.ToStrArray()...)
+		}
+
+		for _, fp := range servedCertificates(domains) {
+			usage := usages[fp]
+			usage.routers = append(usage.routers, name)
+			usages[fp] = usage
+		}
+	}
+
+	for name, rt := range h.runtimeConfiguration.TCPRouters {
+		if rt.TLS == nil || rt.TLS.Passthrough {
+			continue
+		}
+
+		domains, err := tcpmuxer.ParseHostSNI(rt.Rule)
+		if err != nil {
+			log.Debug().Err(err).Str("routerName", name).Msg("Unable to parse router domains")
+		}
+
+		for _, domain := range rt.TLS.Domains {
+			domains = append(domains, domain.ToStrArray()...)
+		}
+
+		domains = slices.DeleteFunc(domains, func(domain string) bool { return domain == "*" })
+
+		for _, fp := range servedCertificates(domains) {
+			usage := usages[fp]
+			usage.tcpRouters = append(usage.tcpRouters, name)
+			usages[fp] = usage
+		}
+	}
+
+	for fp, usage := range usages {
+		slices.Sort(usage.routers)
+		slices.Sort(usage.tcpRouters)
+		usages[fp] = usage
+	}
+
+	return usages
+}
+
+func writePaginatedResults[T any](rw http.ResponseWriter, request *http.Request, results []T) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// getTLSProviderName returns the provider of the given TLS options or store,
+// the default ones not being qualified with a provider name.
+func getTLSProviderName(name string) string {
+	if !strings.Contains(name, "@") {
+		return ""
+	}
+
+	return getProviderName(name)
+}
+
+func fingerprint(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/tls"
+	"github.com/apache4/apache4/v3/pkg/tls/generate"
+	"github.com/apache4/apache4/v3/pkg/types"
+)
+
+func TestHandler_TLS(t *testing.T) {
+	certPEM, keyPEM, err := generate.KeyPair("foo.example.com", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	tlsManager := tls.NewManager(nil)
+	tlsManager.UpdateConfigs(context.Background(),
+		map[string]tls.Store{
+			"bar@file": {},
+		},
+		map[string]tls.Options{
+			"default":  {},
+			"foo@file": {MinVersion: "VersionTLS12"},
+		},
+		[]*tls.CertAndStores{{
+			Certificate: tls.Certificate{CertFile: types.FileOrContent(certPEM), KeyFile: types.FileOrContent(keyPEM)},
+			Source:      "myresolver.acme",
+		}},
+	)
+
+	rtConf := &runtime.Configuration{
+		Routers: map[string]*runtime.RouterInfo{
+			"foo@file": {Router: &dynamic.Router{Rule: "Host(`foo.example.com`)", TLS: &dynamic.RouterTLSConfig{}}},
+			"bar@file": {Router: &dynamic.Router{Rule: "Host(`bar.example.com`)", TLS: &dynamic.RouterTLSConfig{}}},
+			"baz@file": {Router: &dynamic.Router{Rule: "Host(`foo.example.com`)"}},
+		},
+		TCPRouters: map[string]*runtime.TCPRouterInfo{
+			"foo@file": {TCPRouter: &dynamic.TCPRouter{Rule: "HostSNI(`foo.example.com`)", TLS: &dynamic.RouterTCPTLSConfig{}}},
+			"bar@file": {TCPRouter: &dynamic.TCPRouter{Rule: "HostSNI(`foo.example.com`)", TLS: &dynamic.RouterTCPTLSConfig{Passthrough: true}}},
+		},
+	}
+
+	handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, rtConf)
+	handler.tlsManager = tlsManager
+
+	server := httptest.NewServer(handler.createRouter())
+	t.Cleanup(server.Close)
+
+	var certificates []tlsCertificateRepresentation
+	getJSON(t, server.URL+"/api/tls/certificates", &certificates)
+
+	// The ACME challenge store has no default certificate,
+	// hence the certificate and the default certificates of the default and bar@file stores.
+	require.Len(t, certificates, 3)
+
+	assert.Equal(t, "default", certificates[0].Store)
+	assert.Equal(t, "myresolver.acme", certificates[0].Source)
+	assert.Equal(t, "myresolver", certificates[0].Resolver)
+	assert.Equal(t, "foo.example.com", certificates[0].CommonName)
+	assert.False(t, certificates[0].Default)
+	assert.Equal(t, []string{"foo@file"}, certificates[0].Routers)
+	assert.Equal(t, []string{"foo@file"}, certificates[0].TCPRouters)
+
+	for _, certificate := range certificates[1:] {
+		assert.True(t, certificate.Default)
+		assert.Equal(t, tls.CertificateSourceGenerated, certificate.Source)
+
+		if certificate.Store == "default" {
+			assert.Equal(t, []string{"bar@file"}, certificate.Routers)
+		} else {
+			assert.Equal(t, "bar@file", certificate.Store)
+			assert.Empty(t, certificate.Routers)
+		}
+	}
+
+	var options []tlsOptionsRepresentation
+	getJSON(t, server.URL+"/api/tls/options", &options)
+
+	require.Len(t, options, 2)
+	assert.Equal(t, "default", options[0].Name)
+	assert.Empty(t, options[0].Provider)
+	assert.Equal(t, "foo@file", options[1].Name)
+	assert.Equal(t, "file", options[1].Provider)
+	assert.Equal(t, "VersionTLS12", options[1].MinVersion)
+
+	var stores []tlsStoreRepresentation
+	getJSON(t, server.URL+"/api/tls/stores", &stores)
+
+	require.Len(t, stores, 3)
+	assert.Equal(t, "acme-tls/1", stores[0].Name)
+	assert.Empty(t, stores[0].DefaultCertificate)
+	assert.Equal(t, "bar@file", stores[1].Name)
+	assert.Equal(t, "file", stores[1].Provider)
+	assert.Zero(t, stores[1].Certificates)
+	assert.Equal(t, "default", stores[2].Name)
+	assert.Equal(t, 1, stores[2].Certificates)
+	assert.NotEmpty(t, stores[2].DefaultCertificate)
+}
+
+func TestHandler_TLS_withoutManager(t *testing.T) {
+	handler := New(static.Configuration{API: &static.API{}, Global: &static.Global{}}, &runtime.Configuration{})
+
+	server := httptest.NewServer(handler.createRouter())
+	t.Cleanup(server.Close)
+
+	for _, path := range []string{"/api/tls/certificates", "/api/tls/options", "/api/tls/stores"} {
+		var results []json.RawMessage
+		getJSON(t, server.URL+path, &results)
+
+		assert.Empty(t, results, path)
+	}
+}
+
+func getJSON(t *testing.T, url string, result any) {
+	t.Helper()
+
+	resp, err := http.DefaultClient.Get(url)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(result))
+}
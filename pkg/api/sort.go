@@ -321,7 +321,7 @@ func (m middlewareRepresentation) provider() string {
 }
 
 func (m middlewareRepresentation) status() string {
-	return m.Status
+	return m.GetStatus()
 }
 
 func (m tcpMiddlewareRepresentation) name() string {
@@ -337,7 +337,7 @@ func (m tcpMiddlewareRepresentation) provider() string {
 }
 
 func (m tcpMiddlewareRepresentation) status() string {
-	return m.Status
+	return m.GetStatus()
 }
 
 func sortByName[T orderedWithName](direction string, results []T) {
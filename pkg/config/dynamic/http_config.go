@@ -1,6 +1,7 @@
 package dynamic
 
 import (
+	"net/http"
 	"reflect"
 	"time"
 
@@ -57,6 +58,8 @@ type Service struct {
 	Weighted     *WeightedRoundRobin  `json:"weighted,omitempty" toml:"weighted,omitempty" yaml:"weighted,omitempty" label:"-" export:"true"`
 	Mirroring    *Mirroring           `json:"mirroring,omitempty" toml:"mirroring,omitempty" yaml:"mirroring,omitempty" label:"-" export:"true"`
 	Failover     *Failover            `json:"failover,omitempty" toml:"failover,omitempty" yaml:"failover,omitempty" label:"-" export:"true"`
+	Files        *Files               `json:"files,omitempty" toml:"files,omitempty" yaml:"files,omitempty" label:"-" export:"true"`
+	Response     *Response            `json:"response,omitempty" toml:"response,omitempty" yaml:"response,omitempty" label:"-" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -98,6 +101,12 @@ type RouterObservabilityConfig struct {
 	// +kubebuilder:validation:Enum=minimal;detailed
 	// +kubebuilder:default=minimal
 	TraceVerbosity types.TracingVerbosity `json:"traceVerbosity,omitempty" toml:"traceVerbosity,omitempty" yaml:"traceVerbosity,omitempty" export:"true"`
+	// SampleRate defines the rate between 0.0 and 1.0 of the requests to trace for this router,
+	// overriding the one of the tracing configuration.
+	SampleRate *float64 `json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
+	// HonorParentDecision defines whether the sampling decision propagated by the clients is followed for this router,
+	// overriding the one of the tracing configuration.
+	HonorParentDecision *bool `json:"honorParentDecision,omitempty" toml:"honorParentDecision,omitempty" yaml:"honorParentDecision,omitempty" export:"true"`
 }
 
 // SetDefaults Default values for a RouterObservabilityConfig.
@@ -135,6 +144,52 @@ type Failover struct {
 
 // +k8s:deepcopy-gen=true
 
+// Files holds the files service configuration.
+// This service serves the files of a local directory.
+type Files struct {
+	// Root defines the directory whose files are served.
+	Root string `json:"root,omitempty" toml:"root,omitempty" yaml:"root,omitempty"`
+	// IndexFiles defines the files served for the requests to a directory, in order of preference.
+	IndexFiles []string `json:"indexFiles,omitempty" toml:"indexFiles,omitempty" yaml:"indexFiles,omitempty" export:"true"`
+	// SPAFallback defines whether the index file of the root directory is served for the requests to missing files,
+	// as expected by the single-page applications routing the requests in the browser.
+	SPAFallback bool `json:"spaFallback,omitempty" toml:"spaFallback,omitempty" yaml:"spaFallback,omitempty" export:"true"`
+	// Precompressed defines whether the precompressed .br, .zst and .gz sidecar files of the requested files
+	// are served to the clients accepting their encoding.
+	Precompressed bool `json:"precompressed,omitempty" toml:"precompressed,omitempty" yaml:"precompressed,omitempty" export:"true"`
+	// DirectoryListing defines whether the content of the directories without index file is listed.
+	DirectoryListing bool `json:"directoryListing,omitempty" toml:"directoryListing,omitempty" yaml:"directoryListing,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a Files.
+func (f *Files) SetDefaults() {
+	f.IndexFiles = []string{"index.html"}
+}
+
+// +k8s:deepcopy-gen=true
+
+// Response holds the response service configuration.
+// This service replies to the requests with a fixed response, without any backend.
+type Response struct {
+	// StatusCode defines the status code of the response.
+	StatusCode int `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty" export:"true"`
+	// Headers defines the headers of the response.
+	Headers map[string]string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+	// Body defines the body of the response.
+	Body string `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty"`
+	// BodyFile defines the path of the file whose content is the body of the response.
+	BodyFile string `json:"bodyFile,omitempty" toml:"bodyFile,omitempty" yaml:"bodyFile,omitempty"`
+	// Template defines whether the body is a Go template, executed with the host and the path of the request.
+	Template bool `json:"template,omitempty" toml:"template,omitempty" yaml:"template,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a Response.
+func (r *Response) SetDefaults() {
+	r.StatusCode = http.StatusOK
+}
+
+// +k8s:deepcopy-gen=true
+
 // MirrorService holds the MirrorService configuration.
 type MirrorService struct {
 	Name    string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
@@ -248,6 +303,9 @@ type ServersLoadBalancer struct {
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
 	ServersTransport   string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	// Locality tunes the preference for the servers located in the same zone, then in the same region, as apache4.
+	// The preference only applies when the apache4 locality is defined in the static configuration.
+	Locality *Locality `json:"locality,omitempty" toml:"locality,omitempty" yaml:"locality,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -296,11 +354,27 @@ func (r *ResponseForwarding) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// Locality holds the locality aware load-balancing configuration.
+// The servers located in the same zone as apache4 are preferred, as long as enough of them are healthy,
+// then the servers located in the same region, then all the servers.
+type Locality struct {
+	// MinHealthyServers defines the minimum number of healthy servers in a zone, or in a region, to keep the traffic within it.
+	// Default: 1
+	MinHealthyServers int `json:"minHealthyServers,omitempty" toml:"minHealthyServers,omitempty" yaml:"minHealthyServers,omitempty" export:"true"`
+	// MinHealthyPercent defines the minimum percentage of healthy servers in a zone, or in a region, to keep the traffic within it.
+	// Default: 0
+	MinHealthyPercent int `json:"minHealthyPercent,omitempty" toml:"minHealthyPercent,omitempty" yaml:"minHealthyPercent,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Server holds the server configuration.
 type Server struct {
 	URL          string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty"`
 	Weight       *int   `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty" export:"true"`
 	PreservePath bool   `json:"preservePath,omitempty" toml:"preservePath,omitempty" yaml:"preservePath,omitempty" export:"true"`
+	Zone         string `json:"zone,omitempty" toml:"zone,omitempty" yaml:"zone,omitempty" export:"true"`
+	Region       string `json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty" export:"true"`
 	Fenced       bool   `json:"fenced,omitempty" toml:"-" yaml:"-" label:"-" file:"-" kv:"-"`
 	// Scheme can only be defined with label Providers.
 	Scheme string `json:"-" toml:"-" yaml:"-" file:"-" kv:"-"`
@@ -352,6 +426,49 @@ type ServersTransport struct {
 	DisableHTTP2        bool                    `description:"Disables HTTP/2 for connections with backend servers." json:"disableHTTP2,omitempty" toml:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty" export:"true"`
 	PeerCertURI         string                  `description:"Defines the URI used to match against SAN URI during the peer certificate verification." json:"peerCertURI,omitempty" toml:"peerCertURI,omitempty" yaml:"peerCertURI,omitempty" export:"true"`
 	Spiffe              *Spiffe                 `description:"Defines the SPIFFE configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	HTTP3               *HTTP3ClientConfig      `description:"Defines the HTTP/3 configuration used to reach the servers with the h3 scheme." json:"http3,omitempty" toml:"http3,omitempty" yaml:"http3,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Resolver            *ResolverConfig         `description:"Defines the resolution of the server hostnames." json:"resolver,omitempty" toml:"resolver,omitempty" yaml:"resolver,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// HTTP3ClientConfig holds the configuration of the QUIC connections used to reach the servers over HTTP/3.
+type HTTP3ClientConfig struct {
+	MaxIdleTimeout  ptypes.Duration `description:"The maximum period for which an idle QUIC connection will remain open before closing itself." json:"maxIdleTimeout,omitempty" toml:"maxIdleTimeout,omitempty" yaml:"maxIdleTimeout,omitempty" export:"true"`
+	KeepAlivePeriod ptypes.Duration `description:"The period at which keep-alive packets are sent to prevent the QUIC connection from being closed while idle. If zero, no keep-alive packet is sent." json:"keepAlivePeriod,omitempty" toml:"keepAlivePeriod,omitempty" yaml:"keepAlivePeriod,omitempty" export:"true"`
+	Allow0RTT       bool            `description:"Allows sending the GET and HEAD requests without body in the 0-RTT data when resuming a QUIC connection." json:"allow0RTT,omitempty" toml:"allow0RTT,omitempty" yaml:"allow0RTT,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (h *HTTP3ClientConfig) SetDefaults() {
+	h.MaxIdleTimeout = ptypes.Duration(30 * time.Second)
+}
+
+// IPVersion is the version of the IP addresses.
+type IPVersion string
+
+const (
+	// IPVersion4 is the IPv4 version.
+	IPVersion4 IPVersion = "ipv4"
+	// IPVersion6 is the IPv6 version.
+	IPVersion6 IPVersion = "ipv6"
+)
+
+// +k8s:deepcopy-gen=true
+
+// ResolverConfig holds the configuration of the resolution of the server hostnames.
+type ResolverConfig struct {
+	Nameservers        []string        `description:"Defines the DNS servers used to resolve the server hostnames, as host:port. If empty, the ones of /etc/resolv.conf are used." json:"nameservers,omitempty" toml:"nameservers,omitempty" yaml:"nameservers,omitempty" export:"true"`
+	MinTTL             ptypes.Duration `description:"Defines the minimum duration the resolved addresses are cached, whatever the TTL of the DNS records." json:"minTTL,omitempty" toml:"minTTL,omitempty" yaml:"minTTL,omitempty" export:"true"`
+	MaxTTL             ptypes.Duration `description:"Defines the maximum duration the resolved addresses are cached, whatever the TTL of the DNS records." json:"maxTTL,omitempty" toml:"maxTTL,omitempty" yaml:"maxTTL,omitempty" export:"true"`
+	PreferredIPVersion IPVersion       `description:"Defines the IP version of the addresses dialed first, ipv4 or ipv6. If empty, the addresses of both versions are dialed alike." json:"preferredIPVersion,omitempty" toml:"preferredIPVersion,omitempty" yaml:"preferredIPVersion,omitempty" export:"true"`
+	ExpandServers      bool            `description:"Expands each server hostname into one load-balanced server per resolved address." json:"expandServers,omitempty" toml:"expandServers,omitempty" yaml:"expandServers,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *ResolverConfig) SetDefaults() {
+	r.MinTTL = ptypes.Duration(time.Second)
+	r.MaxTTL = ptypes.Duration(time.Hour)
 }
 
 // +k8s:deepcopy-gen=true
@@ -22,10 +22,13 @@ type Middleware struct {
 	StripPrefixRegex *StripPrefixRegex `json:"stripPrefixRegex,omitempty" toml:"stripPrefixRegex,omitempty" yaml:"stripPrefixRegex,omitempty" export:"true"`
 	ReplacePath      *ReplacePath      `json:"replacePath,omitempty" toml:"replacePath,omitempty" yaml:"replacePath,omitempty" export:"true"`
 	ReplacePathRegex *ReplacePathRegex `json:"replacePathRegex,omitempty" toml:"replacePathRegex,omitempty" yaml:"replacePathRegex,omitempty" export:"true"`
+	RewriteBody      *RewriteBody      `json:"rewriteBody,omitempty" toml:"rewriteBody,omitempty" yaml:"rewriteBody,omitempty" export:"true"`
 	Chain            *Chain            `json:"chain,omitempty" toml:"chain,omitempty" yaml:"chain,omitempty" export:"true"`
 	// Deprecated: please use IPAllowList instead.
 	IPWhiteList       *IPWhiteList       `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty" export:"true"`
 	IPAllowList       *IPAllowList       `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	IPDenyList        *IPDenyList        `json:"ipDenyList,omitempty" toml:"ipDenyList,omitempty" yaml:"ipDenyList,omitempty" export:"true"`
+	GeoIP             *GeoIP             `json:"geoIP,omitempty" toml:"geoIP,omitempty" yaml:"geoIP,omitempty" export:"true"`
 	Headers           *Headers           `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
 	Errors            *ErrorPage         `json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty" export:"true"`
 	RateLimit         *RateLimit         `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
@@ -159,6 +162,9 @@ type CircuitBreaker struct {
 	RecoveryDuration ptypes.Duration `json:"recoveryDuration,omitempty" toml:"recoveryDuration,omitempty" yaml:"recoveryDuration,omitempty" export:"true"`
 	// ResponseCode is the status code that the circuit breaker will return while it is in the open state.
 	ResponseCode int `json:"responseCode,omitempty" toml:"responseCode,omitempty" yaml:"responseCode,omitempty" export:"true"`
+	// Scope defines how the requests are grouped, a separate circuit breaker being kept for each group.
+	// When not defined, a single circuit breaker is kept for all the requests.
+	Scope *CircuitBreakerScope `json:"scope,omitempty" toml:"scope,omitempty" yaml:"scope,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -171,6 +177,29 @@ func (c *CircuitBreaker) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// CircuitBreakerScope holds the circuit breaker scope configuration.
+// Only one of the grouping options can be defined.
+type CircuitBreakerScope struct {
+	// Server defines whether a separate circuit breaker is kept for each backend server.
+	Server bool `json:"server,omitempty" toml:"server,omitempty" yaml:"server,omitempty" export:"true"`
+	// RequestHeaderName defines the name of the request header whose values group the requests.
+	RequestHeaderName string `json:"requestHeaderName,omitempty" toml:"requestHeaderName,omitempty" yaml:"requestHeaderName,omitempty" export:"true"`
+	// RequestHost defines whether the requests are grouped by their host.
+	RequestHost bool `json:"requestHost,omitempty" toml:"requestHost,omitempty" yaml:"requestHost,omitempty" export:"true"`
+	// MaxBreakers defines the maximum number of circuit breakers kept at once,
+	// the least recently used ones being removed first, preferably among the ones in the standby state.
+	// Default: 1000.
+	// +kubebuilder:validation:Minimum=0
+	MaxBreakers int `json:"maxBreakers,omitempty" toml:"maxBreakers,omitempty" yaml:"maxBreakers,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a CircuitBreakerScope.
+func (c *CircuitBreakerScope) SetDefaults() {
+	c.MaxBreakers = 1000
+}
+
+// +k8s:deepcopy-gen=true
+
 // Compress holds the compress middleware configuration.
 // This middleware compresses responses before sending them to the client, using gzip, brotli, or zstd compression.
 type Compress struct {
@@ -495,8 +524,10 @@ type IPWhiteList struct {
 // More info: https://doc.apache4.io/apache4/v3.5/middlewares/http/ipallowlist/
 type IPAllowList struct {
 	// SourceRange defines the set of allowed IPs (or ranges of allowed IPs by using CIDR notation).
-	SourceRange []string    `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
-	IPStrategy  *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// ExternalSourceRange defines the files and URLs from which additional allowed IPs are loaded.
+	ExternalSourceRange *ExternalSourceRange `json:"externalSourceRange,omitempty" toml:"externalSourceRange,omitempty" yaml:"externalSourceRange,omitempty" export:"true"`
+	IPStrategy          *IPStrategy          `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	// RejectStatusCode defines the HTTP status code used for refused requests.
 	// If not set, the default is 403 (Forbidden).
 	RejectStatusCode int `json:"rejectStatusCode,omitempty" toml:"rejectStatusCode,omitempty" yaml:"rejectStatusCode,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
@@ -504,6 +535,82 @@ type IPAllowList struct {
 
 // +k8s:deepcopy-gen=true
 
+// IPDenyList holds the IP denylist middleware configuration.
+// This middleware rejects requests based on the client IP.
+// More info: https://doc.apache4.io/apache4/v3.5/middlewares/http/ipdenylist/
+type IPDenyList struct {
+	// SourceRange defines the set of denied IPs (or ranges of denied IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// ExternalSourceRange defines the files and URLs from which additional denied IPs are loaded.
+	ExternalSourceRange *ExternalSourceRange `json:"externalSourceRange,omitempty" toml:"externalSourceRange,omitempty" yaml:"externalSourceRange,omitempty" export:"true"`
+	IPStrategy          *IPStrategy          `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// RejectStatusCode defines the HTTP status code used for refused requests.
+	// If not set, the default is 403 (Forbidden).
+	RejectStatusCode int `json:"rejectStatusCode,omitempty" toml:"rejectStatusCode,omitempty" yaml:"rejectStatusCode,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// GeoIP holds the GeoIP middleware configuration.
+// This middleware allows or denies requests based on the location of the client IP,
+// as given by the GeoIP databases of the static configuration,
+// and can forward the location to the backends in request headers.
+// More info: https://doc.apache4.io/apache4/v3.5/middlewares/http/geoip/
+type GeoIP struct {
+	// AllowedCountries defines the ISO 3166-1 alpha-2 codes of the allowed countries.
+	AllowedCountries []string `json:"allowedCountries,omitempty" toml:"allowedCountries,omitempty" yaml:"allowedCountries,omitempty" export:"true"`
+	// DeniedCountries defines the ISO 3166-1 alpha-2 codes of the denied countries.
+	DeniedCountries []string `json:"deniedCountries,omitempty" toml:"deniedCountries,omitempty" yaml:"deniedCountries,omitempty" export:"true"`
+	// AllowedContinents defines the codes of the allowed continents (AF, AN, AS, EU, NA, OC and SA).
+	AllowedContinents []string `json:"allowedContinents,omitempty" toml:"allowedContinents,omitempty" yaml:"allowedContinents,omitempty" export:"true"`
+	// DeniedContinents defines the codes of the denied continents (AF, AN, AS, EU, NA, OC and SA).
+	DeniedContinents []string `json:"deniedContinents,omitempty" toml:"deniedContinents,omitempty" yaml:"deniedContinents,omitempty" export:"true"`
+	// AllowedASNs defines the numbers of the allowed autonomous systems.
+	AllowedASNs []int `json:"allowedASNs,omitempty" toml:"allowedASNs,omitempty" yaml:"allowedASNs,omitempty" export:"true"`
+	// DeniedASNs defines the numbers of the denied autonomous systems.
+	DeniedASNs []int       `json:"deniedASNs,omitempty" toml:"deniedASNs,omitempty" yaml:"deniedASNs,omitempty" export:"true"`
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// RejectStatusCode defines the HTTP status code used for refused requests.
+	// If not set, the default is 403 (Forbidden).
+	RejectStatusCode int `json:"rejectStatusCode,omitempty" toml:"rejectStatusCode,omitempty" yaml:"rejectStatusCode,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// Headers defines the request headers set with the location of the client.
+	Headers *GeoIPHeaders `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// GeoIPHeaders holds the names of the request headers set with the location of the client.
+// The headers are removed from the incoming requests, and are not set when the location is unknown.
+type GeoIPHeaders struct {
+	// Country defines the name of the header holding the country code of the client.
+	Country string `json:"country,omitempty" toml:"country,omitempty" yaml:"country,omitempty" export:"true"`
+	// Continent defines the name of the header holding the continent code of the client.
+	Continent string `json:"continent,omitempty" toml:"continent,omitempty" yaml:"continent,omitempty" export:"true"`
+	// ASN defines the name of the header holding the autonomous system number of the client.
+	ASN string `json:"asn,omitempty" toml:"asn,omitempty" yaml:"asn,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ExternalSourceRange holds the external sources of IP ranges of the IP allowlist and denylist middlewares.
+// The sources contain one IP or CIDR range per line, blank lines and lines starting with # being ignored.
+type ExternalSourceRange struct {
+	// Files defines the paths of the files containing IP ranges.
+	Files []string `json:"files,omitempty" toml:"files,omitempty" yaml:"files,omitempty"`
+	// URLs defines the HTTP(S) URLs of the lists of IP ranges.
+	URLs []string `json:"urls,omitempty" toml:"urls,omitempty" yaml:"urls,omitempty"`
+	// RefreshInterval defines the interval between two reloads of the external sources.
+	// If not set, the default is 1h.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (e *ExternalSourceRange) SetDefaults() {
+	e.RefreshInterval = ptypes.Duration(time.Hour)
+}
+
+// +k8s:deepcopy-gen=true
+
 // InFlightReq holds the in-flight request middleware configuration.
 // This middleware limits the number of requests being processed and served concurrently.
 // More info: https://doc.apache4.io/apache4/v3.5/middlewares/http/inflightreq/
@@ -683,6 +790,38 @@ type ReplacePathRegex struct {
 
 // +k8s:deepcopy-gen=true
 
+// RewriteBody holds the rewrite body middleware configuration.
+// This middleware rewrites the response bodies by applying literal or regular expression replacements,
+// streaming them rather than buffering the whole bodies.
+// More info: https://doc.apache4.io/apache4/v3.5/middlewares/http/rewritebody/
+type RewriteBody struct {
+	// Rewrites defines the replacements applied to the response bodies.
+	Rewrites []BodyRewrite `json:"rewrites,omitempty" toml:"rewrites,omitempty" yaml:"rewrites,omitempty" export:"true"`
+	// ContentTypes defines the media types of the responses whose bodies are rewritten.
+	// Default: text/html.
+	ContentTypes []string `json:"contentTypes,omitempty" toml:"contentTypes,omitempty" yaml:"contentTypes,omitempty" export:"true"`
+	// MaxMatchLength defines the maximum length, in bytes, of the text matched by a rewrite.
+	// It is the number of bytes held back while streaming the bodies, waiting for the matches to complete.
+	// Default: 4096.
+	MaxMatchLength int `json:"maxMatchLength,omitempty" toml:"maxMatchLength,omitempty" yaml:"maxMatchLength,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyRewrite holds a replacement applied to the response bodies.
+type BodyRewrite struct {
+	// Match defines the literal text to replace.
+	// Mutually exclusive with the Regex option.
+	Match string `json:"match,omitempty" toml:"match,omitempty" yaml:"match,omitempty" export:"true"`
+	// Regex defines the regular expression matching the text to replace.
+	// Mutually exclusive with the Match option.
+	Regex string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty" export:"true"`
+	// Replacement defines the replacement text, which can include the variables captured by the Regex option.
+	Replacement string `json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Retry holds the retry middleware configuration.
 // This middleware reissues requests a given number of times to a backend server if that server does not reply.
 // As soon as the server answers, the middleware stops retrying, regardless of the response status.
@@ -8,6 +8,9 @@ type TCPMiddleware struct {
 	// Deprecated: please use IPAllowList instead.
 	IPWhiteList *TCPIPWhiteList `json:"ipWhiteList,omitempty" toml:"ipWhiteList,omitempty" yaml:"ipWhiteList,omitempty" export:"true"`
 	IPAllowList *TCPIPAllowList `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	IPDenyList  *TCPIPDenyList  `json:"ipDenyList,omitempty" toml:"ipDenyList,omitempty" yaml:"ipDenyList,omitempty" export:"true"`
+
+	Plugin map[string]PluginConf `json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -40,4 +43,18 @@ type TCPIPWhiteList struct {
 type TCPIPAllowList struct {
 	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
 	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// ExternalSourceRange defines the files and URLs from which additional allowed IPs are loaded.
+	ExternalSourceRange *ExternalSourceRange `json:"externalSourceRange,omitempty" toml:"externalSourceRange,omitempty" yaml:"externalSourceRange,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPIPDenyList holds the TCP IPDenyList middleware configuration.
+// This middleware rejects connections based on the client IP.
+// More info: https://doc.apache4.io/apache4/v3.5/middlewares/tcp/ipdenylist/
+type TCPIPDenyList struct {
+	// SourceRange defines the denied IPs (or ranges of denied IPs by using CIDR notation).
+	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
+	// ExternalSourceRange defines the files and URLs from which additional denied IPs are loaded.
+	ExternalSourceRange *ExternalSourceRange `json:"externalSourceRange,omitempty" toml:"externalSourceRange,omitempty" yaml:"externalSourceRange,omitempty" export:"true"`
 }
@@ -72,6 +72,22 @@ func (in *BasicAuth) DeepCopy() *BasicAuth {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyRewrite) DeepCopyInto(out *BodyRewrite) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyRewrite.
+func (in *BodyRewrite) DeepCopy() *BodyRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Buffering) DeepCopyInto(out *Buffering) {
 	*out = *in
@@ -112,6 +128,11 @@ func (in *Chain) DeepCopy() *Chain {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CircuitBreaker) DeepCopyInto(out *CircuitBreaker) {
 	*out = *in
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(CircuitBreakerScope)
+		**out = **in
+	}
 	return
 }
 
@@ -125,6 +146,22 @@ func (in *CircuitBreaker) DeepCopy() *CircuitBreaker {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CircuitBreakerScope) DeepCopyInto(out *CircuitBreakerScope) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CircuitBreakerScope.
+func (in *CircuitBreakerScope) DeepCopy() *CircuitBreakerScope {
+	if in == nil {
+		return nil
+	}
+	out := new(CircuitBreakerScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClientTLS) DeepCopyInto(out *ClientTLS) {
 	*out = *in
@@ -334,6 +371,32 @@ func (in *ErrorPage) DeepCopy() *ErrorPage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSourceRange) DeepCopyInto(out *ExternalSourceRange) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSourceRange.
+func (in *ExternalSourceRange) DeepCopy() *ExternalSourceRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSourceRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Failover) DeepCopyInto(out *Failover) {
 	*out = *in
@@ -355,6 +418,27 @@ func (in *Failover) DeepCopy() *Failover {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Files) DeepCopyInto(out *Files) {
+	*out = *in
+	if in.IndexFiles != nil {
+		in, out := &in.IndexFiles, &out.IndexFiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Files.
+func (in *Files) DeepCopy() *Files {
+	if in == nil {
+		return nil
+	}
+	out := new(Files)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ForwardAuth) DeepCopyInto(out *ForwardAuth) {
 	*out = *in
@@ -428,6 +512,78 @@ func (in *GRPCStatus) DeepCopy() *GRPCStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeoIP) DeepCopyInto(out *GeoIP) {
+	*out = *in
+	if in.AllowedCountries != nil {
+		in, out := &in.AllowedCountries, &out.AllowedCountries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedCountries != nil {
+		in, out := &in.DeniedCountries, &out.DeniedCountries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedContinents != nil {
+		in, out := &in.AllowedContinents, &out.AllowedContinents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedContinents != nil {
+		in, out := &in.DeniedContinents, &out.DeniedContinents
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedASNs != nil {
+		in, out := &in.AllowedASNs, &out.AllowedASNs
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeniedASNs != nil {
+		in, out := &in.DeniedASNs, &out.DeniedASNs
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPStrategy != nil {
+		in, out := &in.IPStrategy, &out.IPStrategy
+		*out = new(IPStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(GeoIPHeaders)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeoIP.
+func (in *GeoIP) DeepCopy() *GeoIP {
+	if in == nil {
+		return nil
+	}
+	out := new(GeoIP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeoIPHeaders) DeepCopyInto(out *GeoIPHeaders) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeoIPHeaders.
+func (in *GeoIPHeaders) DeepCopy() *GeoIPHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(GeoIPHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GrpcWeb) DeepCopyInto(out *GrpcWeb) {
 	*out = *in
@@ -449,6 +605,22 @@ func (in *GrpcWeb) DeepCopy() *GrpcWeb {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTP3ClientConfig) DeepCopyInto(out *HTTP3ClientConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTP3ClientConfig.
+func (in *HTTP3ClientConfig) DeepCopy() *HTTP3ClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTP3ClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPConfiguration) DeepCopyInto(out *HTTPConfiguration) {
 	*out = *in
@@ -696,6 +868,11 @@ func (in *IPAllowList) DeepCopyInto(out *IPAllowList) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExternalSourceRange != nil {
+		in, out := &in.ExternalSourceRange, &out.ExternalSourceRange
+		*out = new(ExternalSourceRange)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.IPStrategy != nil {
 		in, out := &in.IPStrategy, &out.IPStrategy
 		*out = new(IPStrategy)
@@ -714,6 +891,37 @@ func (in *IPAllowList) DeepCopy() *IPAllowList {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPDenyList) DeepCopyInto(out *IPDenyList) {
+	*out = *in
+	if in.SourceRange != nil {
+		in, out := &in.SourceRange, &out.SourceRange
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalSourceRange != nil {
+		in, out := &in.ExternalSourceRange, &out.ExternalSourceRange
+		*out = new(ExternalSourceRange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPStrategy != nil {
+		in, out := &in.IPStrategy, &out.IPStrategy
+		*out = new(IPStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPDenyList.
+func (in *IPDenyList) DeepCopy() *IPDenyList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPDenyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPStrategy) DeepCopyInto(out *IPStrategy) {
 	*out = *in
@@ -787,6 +995,22 @@ func (in *InFlightReq) DeepCopy() *InFlightReq {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Locality) DeepCopyInto(out *Locality) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Locality.
+func (in *Locality) DeepCopy() *Locality {
+	if in == nil {
+		return nil
+	}
+	out := new(Locality)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Message) DeepCopyInto(out *Message) {
 	*out = *in
@@ -836,6 +1060,11 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 		*out = new(ReplacePathRegex)
 		**out = **in
 	}
+	if in.RewriteBody != nil {
+		in, out := &in.RewriteBody, &out.RewriteBody
+		*out = new(RewriteBody)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Chain != nil {
 		in, out := &in.Chain, &out.Chain
 		*out = new(Chain)
@@ -851,6 +1080,16 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 		*out = new(IPAllowList)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IPDenyList != nil {
+		in, out := &in.IPDenyList, &out.IPDenyList
+		*out = new(IPDenyList)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GeoIP != nil {
+		in, out := &in.GeoIP, &out.GeoIP
+		*out = new(GeoIP)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Headers != nil {
 		in, out := &in.Headers, &out.Headers
 		*out = new(Headers)
@@ -904,7 +1143,7 @@ func (in *Middleware) DeepCopyInto(out *Middleware) {
 	if in.CircuitBreaker != nil {
 		in, out := &in.CircuitBreaker, &out.CircuitBreaker
 		*out = new(CircuitBreaker)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Compress != nil {
 		in, out := &in.Compress, &out.Compress
@@ -1259,6 +1498,50 @@ func (in *RequestRedirect) DeepCopy() *RequestRedirect {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolverConfig) DeepCopyInto(out *ResolverConfig) {
+	*out = *in
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolverConfig.
+func (in *ResolverConfig) DeepCopy() *ResolverConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolverConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Response) DeepCopyInto(out *Response) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Response.
+func (in *Response) DeepCopy() *Response {
+	if in == nil {
+		return nil
+	}
+	out := new(Response)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResponseForwarding) DeepCopyInto(out *ResponseForwarding) {
 	*out = *in
@@ -1291,6 +1574,32 @@ func (in *Retry) DeepCopy() *Retry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RewriteBody) DeepCopyInto(out *RewriteBody) {
+	*out = *in
+	if in.Rewrites != nil {
+		in, out := &in.Rewrites, &out.Rewrites
+		*out = make([]BodyRewrite, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentTypes != nil {
+		in, out := &in.ContentTypes, &out.ContentTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RewriteBody.
+func (in *RewriteBody) DeepCopy() *RewriteBody {
+	if in == nil {
+		return nil
+	}
+	out := new(RewriteBody)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Router) DeepCopyInto(out *Router) {
 	*out = *in
@@ -1345,6 +1654,16 @@ func (in *RouterObservabilityConfig) DeepCopyInto(out *RouterObservabilityConfig
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SampleRate != nil {
+		in, out := &in.SampleRate, &out.SampleRate
+		*out = new(float64)
+		**out = **in
+	}
+	if in.HonorParentDecision != nil {
+		in, out := &in.HonorParentDecision, &out.HonorParentDecision
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -1488,6 +1807,11 @@ func (in *ServersLoadBalancer) DeepCopyInto(out *ServersLoadBalancer) {
 		*out = new(ResponseForwarding)
 		**out = **in
 	}
+	if in.Locality != nil {
+		in, out := &in.Locality, &out.Locality
+		*out = new(Locality)
+		**out = **in
+	}
 	return
 }
 
@@ -1524,6 +1848,16 @@ func (in *ServersTransport) DeepCopyInto(out *ServersTransport) {
 		*out = new(Spiffe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HTTP3 != nil {
+		in, out := &in.HTTP3, &out.HTTP3
+		*out = new(HTTP3ClientConfig)
+		**out = **in
+	}
+	if in.Resolver != nil {
+		in, out := &in.Resolver, &out.Resolver
+		*out = new(ResolverConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1560,6 +1894,16 @@ func (in *Service) DeepCopyInto(out *Service) {
 		*out = new(Failover)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = new(Files)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(Response)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1782,6 +2126,11 @@ func (in *TCPIPAllowList) DeepCopyInto(out *TCPIPAllowList) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExternalSourceRange != nil {
+		in, out := &in.ExternalSourceRange, &out.ExternalSourceRange
+		*out = new(ExternalSourceRange)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1795,6 +2144,32 @@ func (in *TCPIPAllowList) DeepCopy() *TCPIPAllowList {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPIPDenyList) DeepCopyInto(out *TCPIPDenyList) {
+	*out = *in
+	if in.SourceRange != nil {
+		in, out := &in.SourceRange, &out.SourceRange
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExternalSourceRange != nil {
+		in, out := &in.ExternalSourceRange, &out.ExternalSourceRange
+		*out = new(ExternalSourceRange)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPIPDenyList.
+func (in *TCPIPDenyList) DeepCopy() *TCPIPDenyList {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPIPDenyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPIPWhiteList) DeepCopyInto(out *TCPIPWhiteList) {
 	*out = *in
@@ -1850,6 +2225,18 @@ func (in *TCPMiddleware) DeepCopyInto(out *TCPMiddleware) {
 		*out = new(TCPIPAllowList)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IPDenyList != nil {
+		in, out := &in.IPDenyList, &out.IPDenyList
+		*out = new(TCPIPDenyList)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugin != nil {
+		in, out := &in.Plugin, &out.Plugin
+		*out = make(map[string]PluginConf, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -1897,6 +2284,11 @@ func (in *TCPRouter) DeepCopyInto(out *TCPRouter) {
 		*out = new(RouterTCPTLSConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Observability != nil {
+		in, out := &in.Observability, &out.Observability
+		*out = new(TCPRouterObservabilityConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1910,6 +2302,27 @@ func (in *TCPRouter) DeepCopy() *TCPRouter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPRouterObservabilityConfig) DeepCopyInto(out *TCPRouterObservabilityConfig) {
+	*out = *in
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPRouterObservabilityConfig.
+func (in *TCPRouterObservabilityConfig) DeepCopy() *TCPRouterObservabilityConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPRouterObservabilityConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TCPServer) DeepCopyInto(out *TCPServer) {
 	*out = *in
@@ -1965,6 +2378,11 @@ func (in *TCPServersTransport) DeepCopyInto(out *TCPServersTransport) {
 		*out = new(TLSClientConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Resolver != nil {
+		in, out := &in.Resolver, &out.Resolver
+		*out = new(ResolverConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -178,6 +178,10 @@ func TestDecodeConfiguration(t *testing.T) {
 		"apache4.http.services.Service0.loadbalancer.server.preservepath":              "true",
 		"apache4.http.services.Service0.loadbalancer.server.scheme":                    "foobar",
 		"apache4.http.services.Service0.loadbalancer.server.port":                      "8080",
+		"apache4.http.services.Service0.loadbalancer.server.zone":                      "foobar",
+		"apache4.http.services.Service0.loadbalancer.server.region":                    "foobar",
+		"apache4.http.services.Service0.loadbalancer.locality.minhealthyservers":       "42",
+		"apache4.http.services.Service0.loadbalancer.locality.minhealthypercent":       "42",
 		"apache4.http.services.Service0.loadbalancer.sticky.cookie.name":               "foobar",
 		"apache4.http.services.Service0.loadbalancer.sticky.cookie.secure":             "true",
 		"apache4.http.services.Service0.loadbalancer.sticky.cookie.path":               "/foobar",
@@ -700,8 +704,14 @@ func TestDecodeConfiguration(t *testing.T) {
 								PreservePath: true,
 								Scheme:       "foobar",
 								Port:         "8080",
+								Zone:         "foobar",
+								Region:       "foobar",
 							},
 						},
+						Locality: &dynamic.Locality{
+							MinHealthyServers: 42,
+							MinHealthyPercent: 42,
+						},
 						HealthCheck: &dynamic.ServerHealthCheck{
 							Scheme:            "foobar",
 							Mode:              "foobar",
@@ -1245,8 +1255,14 @@ func TestEncodeConfiguration(t *testing.T) {
 								PreservePath: true,
 								Scheme:       "foobar",
 								Port:         "8080",
+								Zone:         "foobar",
+								Region:       "foobar",
 							},
 						},
+						Locality: &dynamic.Locality{
+							MinHealthyServers: 42,
+							MinHealthyPercent: 42,
+						},
 						HealthCheck: &dynamic.ServerHealthCheck{
 							Scheme:            "foobar",
 							Path:              "foobar",
@@ -1491,6 +1507,10 @@ func TestEncodeConfiguration(t *testing.T) {
 		"apache4.HTTP.Services.Service0.LoadBalancer.server.PreservePath":              "true",
 		"apache4.HTTP.Services.Service0.LoadBalancer.server.Port":                      "8080",
 		"apache4.HTTP.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
+		"apache4.HTTP.Services.Service0.LoadBalancer.server.Zone":                      "foobar",
+		"apache4.HTTP.Services.Service0.LoadBalancer.server.Region":                    "foobar",
+		"apache4.HTTP.Services.Service0.LoadBalancer.Locality.MinHealthyServers":       "42",
+		"apache4.HTTP.Services.Service0.LoadBalancer.Locality.MinHealthyPercent":       "42",
 		"apache4.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Name":               "foobar",
 		"apache4.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.HTTPOnly":           "true",
 		"apache4.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Secure":             "false",
@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"slices"
 	"sort"
 	"strings"
 
@@ -231,6 +232,20 @@ func (c *Configuration) PopulateUsedBy() {
 	}
 }
 
+// appendErrors returns the given errors, followed by the ones returned by the given functions which are not already in them.
+func appendErrors(errs []string, errFuncs []func() []error) []string {
+	all := slices.Clone(errs)
+	for _, errFunc := range errFuncs {
+		for _, err := range errFunc() {
+			if !slices.Contains(all, err.Error()) {
+				all = append(all, err.Error())
+			}
+		}
+	}
+
+	return all
+}
+
 func getProviderName(elementName string) string {
 	parts := strings.Split(elementName, "@")
 	if len(parts) > 1 {
@@ -7,6 +7,7 @@ import (
 	"slices"
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
@@ -110,6 +111,55 @@ type MiddlewareInfo struct {
 	Err    []string `json:"error,omitempty"`
 	Status string   `json:"status,omitempty"`
 	UsedBy []string `json:"usedBy,omitempty"` // list of routers and services using that middleware.
+
+	circuitBreakersMu sync.RWMutex
+	circuitBreakers   []func() []CircuitBreakerStatus // one per instance of the middleware
+
+	externalSourceErrorsMu sync.RWMutex
+	externalSourceErrors   []func() []error // one per instance of the middleware
+}
+
+// CircuitBreakerStatus holds the status of a circuit breaker.
+type CircuitBreakerStatus struct {
+	// Key is the value grouping the requests handled by the circuit breaker, when the circuit breaker is scoped.
+	Key      string              `json:"key,omitempty"`
+	State    string              `json:"state"`
+	Trips    uint64              `json:"trips,omitempty"`
+	LastTrip *CircuitBreakerTrip `json:"lastTrip,omitempty"`
+}
+
+// CircuitBreakerTrip holds the information about a circuit breaker trip.
+type CircuitBreakerTrip struct {
+	Time time.Time `json:"time"`
+	// Value is the value of the metric which made the circuit breaker expression match.
+	Value float64 `json:"value"`
+}
+
+// AddCircuitBreakers registers the function returning the status of the circuit breakers of an instance of the middleware.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) AddCircuitBreakers(status func() []CircuitBreakerStatus) {
+	m.circuitBreakersMu.Lock()
+	defer m.circuitBreakersMu.Unlock()
+
+	m.circuitBreakers = append(m.circuitBreakers, status)
+}
+
+// GetCircuitBreakersStatus returns the status of the circuit breakers of all the instances of the middleware, sorted by key.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) GetCircuitBreakersStatus() []CircuitBreakerStatus {
+	m.circuitBreakersMu.RLock()
+	defer m.circuitBreakersMu.RUnlock()
+
+	var allStatus []CircuitBreakerStatus
+	for _, status := range m.circuitBreakers {
+		allStatus = append(allStatus, status()...)
+	}
+
+	sort.SliceStable(allStatus, func(i, j int) bool {
+		return allStatus[i].Key < allStatus[j].Key
+	})
+
+	return allStatus
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -133,6 +183,40 @@ func (m *MiddlewareInfo) AddError(err error, critical bool) {
 	}
 }
 
+// AddExternalSourceErrors registers the function returning the errors of the external sources of an instance of the middleware,
+// which are reported along with the errors of the middleware as long as they occur.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) AddExternalSourceErrors(errs func() []error) {
+	m.externalSourceErrorsMu.Lock()
+	defer m.externalSourceErrorsMu.Unlock()
+
+	m.externalSourceErrors = append(m.externalSourceErrors, errs)
+}
+
+// GetErrors returns the errors of the middleware, including the current errors of the external sources of all its instances.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) GetErrors() []string {
+	m.externalSourceErrorsMu.RLock()
+	defer m.externalSourceErrorsMu.RUnlock()
+
+	return appendErrors(m.Err, m.externalSourceErrors)
+}
+
+// GetStatus returns the status of the middleware,
+// which is in a warning state while the external sources of one of its instances have errors.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *MiddlewareInfo) GetStatus() string {
+	if m.Status != StatusEnabled {
+		return m.Status
+	}
+
+	if len(m.GetErrors()) > len(m.Err) {
+		return StatusWarning
+	}
+
+	return m.Status
+}
+
 // ServiceInfo holds information about a currently running service.
 type ServiceInfo struct {
 	*dynamic.Service // dynamic configuration
@@ -181,6 +265,15 @@ func (s *ServiceInfo) UpdateServerStatus(server, status string) {
 	s.serverStatus[server] = status
 }
 
+// RemoveServerStatus removes the status of the server from the ServiceInfo.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) RemoveServerStatus(server string) {
+	s.serverStatusMu.Lock()
+	defer s.serverStatusMu.Unlock()
+
+	delete(s.serverStatus, server)
+}
+
 // GetAllStatus returns all the statuses of all the servers in ServiceInfo.
 // It is the responsibility of the caller to check that s is not nil.
 func (s *ServiceInfo) GetAllStatus() map[string]string {
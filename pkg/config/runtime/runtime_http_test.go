@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -215,3 +216,47 @@ func TestGetRoutersByEntryPoints(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddlewareInfo_externalSourceErrors(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		status         string
+		err            []string
+		sourceErrors   []error
+		expectedErrors []string
+		expectedStatus string
+	}{
+		{
+			desc:           "no errors",
+			status:         StatusEnabled,
+			expectedStatus: StatusEnabled,
+		},
+		{
+			desc:           "external source errors",
+			status:         StatusEnabled,
+			sourceErrors:   []error{errors.New("foo")},
+			expectedErrors: []string{"foo"},
+			expectedStatus: StatusWarning,
+		},
+		{
+			desc:           "external source errors of a disabled middleware",
+			status:         StatusDisabled,
+			err:            []string{"bar"},
+			sourceErrors:   []error{errors.New("foo"), errors.New("bar")},
+			expectedErrors: []string{"bar", "foo"},
+			expectedStatus: StatusDisabled,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			info := &MiddlewareInfo{Status: test.status, Err: test.err}
+			info.AddExternalSourceErrors(func() []error { return test.sourceErrors })
+
+			assert.Equal(t, test.expectedErrors, info.GetErrors())
+			assert.Equal(t, test.expectedStatus, info.GetStatus())
+		})
+	}
+}
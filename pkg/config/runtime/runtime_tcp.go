@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
@@ -117,6 +118,43 @@ type TCPMiddlewareInfo struct {
 	Err    []string `json:"error,omitempty"`
 	Status string   `json:"status,omitempty"`
 	UsedBy []string `json:"usedBy,omitempty"` // list of TCP routers and services using that middleware.
+
+	externalSourceErrorsMu sync.RWMutex
+	externalSourceErrors   []func() []error // one per instance of the middleware
+}
+
+// AddExternalSourceErrors registers the function returning the errors of the external sources of an instance of the middleware,
+// which are reported along with the errors of the middleware as long as they occur.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *TCPMiddlewareInfo) AddExternalSourceErrors(errs func() []error) {
+	m.externalSourceErrorsMu.Lock()
+	defer m.externalSourceErrorsMu.Unlock()
+
+	m.externalSourceErrors = append(m.externalSourceErrors, errs)
+}
+
+// GetErrors returns the errors of the middleware, including the current errors of the external sources of all its instances.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *TCPMiddlewareInfo) GetErrors() []string {
+	m.externalSourceErrorsMu.RLock()
+	defer m.externalSourceErrorsMu.RUnlock()
+
+	return appendErrors(m.Err, m.externalSourceErrors)
+}
+
+// GetStatus returns the status of the middleware,
+// which is in a warning state while the external sources of one of its instances have errors.
+// It is the responsibility of the caller to check that m is not nil.
+func (m *TCPMiddlewareInfo) GetStatus() string {
+	if m.Status != StatusEnabled {
+		return m.Status
+	}
+
+	if len(m.GetErrors()) > len(m.Err) {
+		return StatusWarning
+	}
+
+	return m.Status
 }
 
 // AddError adds err to s.Err, if it does not already exist.
@@ -1,15 +1,21 @@
 package static
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 
 	ptypes "github.com/apache4/paerser/types"
 	"github.com/apache4/apache4/v3/pkg/types"
 )
 
+// unixPrefix is the prefix of the entry point addresses which are Unix domain socket paths.
+const unixPrefix = "unix:"
+
 // EntryPoint holds the entry point configuration.
 type EntryPoint struct {
 	Address          string                `description:"Entry point address." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
@@ -24,18 +30,32 @@ type EntryPoint struct {
 	HTTP3            *HTTP3Config          `description:"HTTP/3 configuration." json:"http3,omitempty" toml:"http3,omitempty" yaml:"http3,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	UDP              *UDPConfig            `description:"UDP configuration." json:"udp,omitempty" toml:"udp,omitempty" yaml:"udp,omitempty"`
 	Observability    *ObservabilityConfig  `description:"Observability configuration." json:"observability,omitempty" toml:"observability,omitempty" yaml:"observability,omitempty" export:"true"`
+	UnixSocket       *UnixSocketConfig     `description:"Unix domain socket configuration, for the entry points listening on a socket path (unix:/path/to/socket)." json:"unixSocket,omitempty" toml:"unixSocket,omitempty" yaml:"unixSocket,omitempty" export:"true"`
 }
 
 // GetAddress strips any potential protocol part of the address field of the
 // entry point, in order to return the actual address.
+// For a Unix domain socket, it returns the socket path.
 func (ep *EntryPoint) GetAddress() string {
+	if path, ok := cutUnixPrefix(ep.Address); ok {
+		return path
+	}
+
 	splitN := strings.SplitN(ep.Address, "/", 2)
 	return splitN[0]
 }
 
 // GetProtocol returns the protocol part of the address field of the entry point.
 // If none is specified, it defaults to "tcp".
+// An address starting with "unix:" is a Unix domain socket path, and its protocol is "unix".
 func (ep *EntryPoint) GetProtocol() (string, error) {
+	if path, ok := cutUnixPrefix(ep.Address); ok {
+		if path == "" {
+			return "", errors.New("empty unix socket path")
+		}
+		return "unix", nil
+	}
+
 	splitN := strings.SplitN(ep.Address, "/", 2)
 	if len(splitN) < 2 {
 		return "tcp", nil
@@ -49,6 +69,14 @@ func (ep *EntryPoint) GetProtocol() (string, error) {
 	return "", fmt.Errorf("invalid protocol: %s", splitN[1])
 }
 
+func cutUnixPrefix(address string) (string, bool) {
+	if len(address) < len(unixPrefix) || !strings.EqualFold(address[:len(unixPrefix)], unixPrefix) {
+		return "", false
+	}
+
+	return address[len(unixPrefix):], true
+}
+
 // SetDefaults sets the default values.
 func (ep *EntryPoint) SetDefaults() {
 	ep.Transport = &EntryPointsTransport{}
@@ -62,6 +90,31 @@ func (ep *EntryPoint) SetDefaults() {
 	ep.HTTP2.SetDefaults()
 }
 
+// UnixSocketConfig is the configuration of the Unix domain socket an entry point listens on.
+type UnixSocketConfig struct {
+	Mode  string `description:"File mode of the socket, in octal notation (e.g. 0660)." json:"mode,omitempty" toml:"mode,omitempty" yaml:"mode,omitempty" export:"true"`
+	Owner string `description:"Owner of the socket, as a user name or a numeric user ID." json:"owner,omitempty" toml:"owner,omitempty" yaml:"owner,omitempty" export:"true"`
+	Group string `description:"Group of the socket, as a group name or a numeric group ID." json:"group,omitempty" toml:"group,omitempty" yaml:"group,omitempty" export:"true"`
+}
+
+// FileMode returns the file mode of the socket, or zero when the default one must be kept.
+func (c *UnixSocketConfig) FileMode() (os.FileMode, error) {
+	if c == nil || c.Mode == "" {
+		return 0, nil
+	}
+
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parsing unix socket mode %q: %w", c.Mode, err)
+	}
+
+	if mode > uint64(os.ModePerm) {
+		return 0, fmt.Errorf("invalid unix socket mode %q", c.Mode)
+	}
+
+	return os.FileMode(mode), nil
+}
+
 // HTTPConfig is the HTTP configuration of an entry point.
 type HTTPConfig struct {
 	Redirections          *Redirections `description:"Set of redirection" json:"redirections,omitempty" toml:"redirections,omitempty" yaml:"redirections,omitempty" export:"true"`
@@ -70,6 +123,7 @@ type HTTPConfig struct {
 	EncodeQuerySemicolons bool          `description:"Defines whether request query semicolons should be URLEncoded." json:"encodeQuerySemicolons,omitempty" toml:"encodeQuerySemicolons,omitempty" yaml:"encodeQuerySemicolons,omitempty"`
 	SanitizePath          *bool         `description:"Defines whether to enable request path sanitization (removal of /./, /../ and multiple slash sequences)." json:"sanitizePath,omitempty" toml:"sanitizePath,omitempty" yaml:"sanitizePath,omitempty" export:"true"`
 	MaxHeaderBytes        int           `description:"Maximum size of request headers in bytes." json:"maxHeaderBytes,omitempty" toml:"maxHeaderBytes,omitempty" yaml:"maxHeaderBytes,omitempty" export:"true"`
+	RequestID             *RequestID    `description:"Request ID generation and propagation." json:"requestID,omitempty" toml:"requestID,omitempty" yaml:"requestID,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -79,9 +133,29 @@ func (c *HTTPConfig) SetDefaults() {
 	c.MaxHeaderBytes = http.DefaultMaxHeaderBytes
 }
 
+// RequestID is the request ID configuration of an entry point.
+type RequestID struct {
+	HeaderName     string `description:"Name of the header holding the request ID." json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+	Format         string `description:"Format of the generated request IDs: uuidv4, uuidv7 or ulid." json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty" export:"true"`
+	TrustedIPsOnly bool   `description:"Keeps the incoming request IDs only when the requests come from the forwarded headers trusted IPs." json:"trustedIPsOnly,omitempty" toml:"trustedIPsOnly,omitempty" yaml:"trustedIPsOnly,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *RequestID) SetDefaults() {
+	r.HeaderName = "X-Request-Id"
+	r.Format = "uuidv4"
+}
+
 // HTTP2Config is the HTTP2 configuration of an entry point.
 type HTTP2Config struct {
-	MaxConcurrentStreams int32 `description:"Specifies the number of concurrent streams per connection that each client is allowed to initiate." json:"maxConcurrentStreams,omitempty" toml:"maxConcurrentStreams,omitempty" yaml:"maxConcurrentStreams,omitempty" export:"true"`
+	MaxConcurrentStreams        int32           `description:"Specifies the number of concurrent streams per connection that each client is allowed to initiate." json:"maxConcurrentStreams,omitempty" toml:"maxConcurrentStreams,omitempty" yaml:"maxConcurrentStreams,omitempty" export:"true"`
+	MaxHeaderListSize           int32           `description:"Maximum size of the request header list, in bytes. If zero, the maxHeaderBytes HTTP option is used." json:"maxHeaderListSize,omitempty" toml:"maxHeaderListSize,omitempty" yaml:"maxHeaderListSize,omitempty" export:"true"`
+	MaxFrameSize                int32           `description:"Maximum size of the frames the clients are allowed to send, in bytes (between 16384 and 16777215). If zero, 1MiB is used." json:"maxFrameSize,omitempty" toml:"maxFrameSize,omitempty" yaml:"maxFrameSize,omitempty" export:"true"`
+	InitialStreamWindowSize     int32           `description:"Initial flow-control window size of the streams, in bytes. If zero, 1MiB is used." json:"initialStreamWindowSize,omitempty" toml:"initialStreamWindowSize,omitempty" yaml:"initialStreamWindowSize,omitempty" export:"true"`
+	InitialConnectionWindowSize int32           `description:"Initial flow-control window size of the connections, in bytes. If zero, 1MiB is used." json:"initialConnectionWindowSize,omitempty" toml:"initialConnectionWindowSize,omitempty" yaml:"initialConnectionWindowSize,omitempty" export:"true"`
+	IdleTimeout                 ptypes.Duration `description:"Maximum period for which an idle HTTP/2 connection remains open. If zero, the idleTimeout responding timeout is used." json:"idleTimeout,omitempty" toml:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty" export:"true"`
+	MaxResetStreamRate          int             `description:"Maximum number of RST_STREAM frames per second a client is allowed to send on a connection before it is closed. If zero, the rate is not limited." json:"maxResetStreamRate,omitempty" toml:"maxResetStreamRate,omitempty" yaml:"maxResetStreamRate,omitempty" export:"true"`
+	MaxControlFrameRate         int             `description:"Maximum number of PING, SETTINGS and PRIORITY frames per second a client is allowed to send on a connection before it is closed. If zero, the rate is not limited." json:"maxControlFrameRate,omitempty" toml:"maxControlFrameRate,omitempty" yaml:"maxControlFrameRate,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -165,10 +239,18 @@ func (u *UDPConfig) SetDefaults() {
 
 // ObservabilityConfig holds the observability configuration for an entry point.
 type ObservabilityConfig struct {
-	AccessLogs     *bool                  `description:"Enables access-logs for this entryPoint." json:"accessLogs,omitempty" toml:"accessLogs,omitempty" yaml:"accessLogs,omitempty" export:"true"`
-	Metrics        *bool                  `description:"Enables metrics for this entryPoint." json:"metrics,omitempty" toml:"metrics,omitempty" yaml:"metrics,omitempty" export:"true"`
-	Tracing        *bool                  `description:"Enables tracing for this entryPoint." json:"tracing,omitempty" toml:"tracing,omitempty" yaml:"tracing,omitempty" export:"true"`
-	TraceVerbosity types.TracingVerbosity `description:"Defines the tracing verbosity level for this entryPoint." json:"traceVerbosity,omitempty" toml:"traceVerbosity,omitempty" yaml:"traceVerbosity,omitempty" export:"true"`
+	AccessLogs          *bool                  `description:"Enables access-logs for this entryPoint." json:"accessLogs,omitempty" toml:"accessLogs,omitempty" yaml:"accessLogs,omitempty" export:"true"`
+	Metrics             *bool                  `description:"Enables metrics for this entryPoint." json:"metrics,omitempty" toml:"metrics,omitempty" yaml:"metrics,omitempty" export:"true"`
+	Tracing             *bool                  `description:"Enables tracing for this entryPoint." json:"tracing,omitempty" toml:"tracing,omitempty" yaml:"tracing,omitempty" export:"true"`
+	TraceVerbosity      types.TracingVerbosity `description:"Defines the tracing verbosity level for this entryPoint." json:"traceVerbosity,omitempty" toml:"traceVerbosity,omitempty" yaml:"traceVerbosity,omitempty" export:"true"`
+	SampleRate          *float64               `description:"Sets the rate between 0.0 and 1.0 of requests to trace for this entryPoint, overriding the tracing one." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
+	HonorParentDecision *bool                  `description:"Follows the sampling decision propagated by the clients for this entryPoint, overriding the tracing option." json:"honorParentDecision,omitempty" toml:"honorParentDecision,omitempty" yaml:"honorParentDecision,omitempty" export:"true"`
+	ConnectionTracing   *bool                  `description:"Enables tracing for the TCP connections and UDP sessions of this entryPoint." json:"connectionTracing,omitempty" toml:"connectionTracing,omitempty" yaml:"connectionTracing,omitempty" export:"true"`
+}
+
+// ConnectionTracingEnabled returns whether the TCP connections and UDP sessions are traced by default.
+func (o *ObservabilityConfig) ConnectionTracingEnabled() bool {
+	return o != nil && o.ConnectionTracing != nil && *o.ConnectionTracing
 }
 
 // SetDefaults sets the default values.
@@ -1,6 +1,7 @@
 package static
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -42,7 +43,25 @@ func TestEntryPointProtocol(t *testing.T) {
 			expectedProtocol: "udp",
 			expectedError:    false,
 		},
-
+		{
+			name:             "With unix socket path",
+			address:          "unix:/var/run/apache4.sock",
+			expectedAddress:  "/var/run/apache4.sock",
+			expectedProtocol: "unix",
+			expectedError:    false,
+		},
+		{
+			name:             "With unix socket path in upper case",
+			address:          "UNIX:/var/run/apache4/tcp.sock",
+			expectedAddress:  "/var/run/apache4/tcp.sock",
+			expectedProtocol: "unix",
+			expectedError:    false,
+		},
+		{
+			name:          "With empty unix socket path",
+			address:       "unix:",
+			expectedError: true,
+		},
 		{
 			name:          "With invalid protocol",
 			address:       "127.0.0.1:8080/toto/tata",
@@ -65,3 +84,48 @@ func TestEntryPointProtocol(t *testing.T) {
 		})
 	}
 }
+
+func TestUnixSocketConfig_FileMode(t *testing.T) {
+	tests := []struct {
+		desc          string
+		config        *UnixSocketConfig
+		expectedMode  os.FileMode
+		expectedError bool
+	}{
+		{
+			desc: "Without configuration",
+		},
+		{
+			desc:   "Without mode",
+			config: &UnixSocketConfig{Owner: "apache4"},
+		},
+		{
+			desc:         "With mode",
+			config:       &UnixSocketConfig{Mode: "0660"},
+			expectedMode: 0o660,
+		},
+		{
+			desc:          "With invalid mode",
+			config:        &UnixSocketConfig{Mode: "rw-rw----"},
+			expectedError: true,
+		},
+		{
+			desc:          "With mode out of range",
+			config:        &UnixSocketConfig{Mode: "4755"},
+			expectedError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			mode, err := test.config.FileMode()
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedMode, mode)
+		})
+	}
+}
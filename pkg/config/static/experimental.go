@@ -6,6 +6,7 @@ import "github.com/apache4/apache4/v3/pkg/plugins"
 type Experimental struct {
 	Plugins                map[string]plugins.Descriptor      `description:"Plugins configuration." json:"plugins,omitempty" toml:"plugins,omitempty" yaml:"plugins,omitempty" export:"true"`
 	LocalPlugins           map[string]plugins.LocalDescriptor `description:"Local plugins configuration." json:"localPlugins,omitempty" toml:"localPlugins,omitempty" yaml:"localPlugins,omitempty" export:"true"`
+	PluginRegistry         *plugins.Registry                  `description:"Plugins registry configuration." json:"pluginRegistry,omitempty" toml:"pluginRegistry,omitempty" yaml:"pluginRegistry,omitempty" export:"true"`
 	AbortOnPluginFailure   bool                               `description:"Defines whether all plugins must be loaded successfully for apache4 to start." json:"abortOnPluginFailure,omitempty" toml:"abortOnPluginFailure,omitempty" yaml:"abortOnPluginFailure,omitempty" export:"true"`
 	FastProxy              *FastProxyConfig                   `description:"Enables the FastProxy implementation." json:"fastProxy,omitempty" toml:"fastProxy,omitempty" yaml:"fastProxy,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	OTLPLogs               bool                               `description:"Enables the OpenTelemetry logs integration." json:"otlplogs,omitempty" toml:"otlplogs,omitempty" yaml:"otlplogs,omitempty" export:"true"`
@@ -84,6 +84,12 @@ type Configuration struct {
 	Spiffe *SpiffeClientConfig `description:"SPIFFE integration configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" export:"true"`
 
 	OCSP *tls.OCSPConfig `description:"OCSP configuration." json:"ocsp,omitempty" toml:"ocsp,omitempty" yaml:"ocsp,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	SessionTickets *tls.SessionTicketsConfig `description:"TLS session ticket keys shared by several instances." json:"sessionTickets,omitempty" toml:"sessionTickets,omitempty" yaml:"sessionTickets,omitempty" export:"true"`
+
+	Locality *Locality `description:"Locality of the apache4 instance, for the load-balancers to prefer the servers located nearby." json:"locality,omitempty" toml:"locality,omitempty" yaml:"locality,omitempty" export:"true"`
+
+	GeoIP *GeoIP `description:"GeoIP databases, for the routers and middlewares to use the location of the clients." json:"geoIP,omitempty" toml:"geoIP,omitempty" yaml:"geoIP,omitempty" export:"true"`
 }
 
 // Core configures apache4 core behavior.
@@ -114,6 +120,19 @@ type Global struct {
 	SendAnonymousUsage bool `description:"Periodically send anonymous usage statistics. If the option is not specified, it will be disabled by default." json:"sendAnonymousUsage,omitempty" toml:"sendAnonymousUsage,omitempty" yaml:"sendAnonymousUsage,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
+// Locality holds the location of the apache4 instance.
+type Locality struct {
+	Zone   string `description:"Zone where the apache4 instance runs." json:"zone,omitempty" toml:"zone,omitempty" yaml:"zone,omitempty" export:"true"`
+	Region string `description:"Region where the apache4 instance runs." json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty" export:"true"`
+}
+
+// GeoIP holds the paths of the MaxMind DB files giving the location of the clients.
+// The files are reloaded when they change.
+type GeoIP struct {
+	Database    string `description:"Path of the MaxMind DB file giving the country and continent of the IP addresses (e.g. GeoLite2-Country.mmdb)." json:"database,omitempty" toml:"database,omitempty" yaml:"database,omitempty" export:"true"`
+	ASNDatabase string `description:"Path of the MaxMind DB file giving the autonomous system of the IP addresses (e.g. GeoLite2-ASN.mmdb)." json:"asnDatabase,omitempty" toml:"asnDatabase,omitempty" yaml:"asnDatabase,omitempty" export:"true"`
+}
+
 // ServersTransport options to configure communication between apache4 and the servers.
 type ServersTransport struct {
 	InsecureSkipVerify  bool                  `description:"Disable SSL certificate verification." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
@@ -205,14 +224,16 @@ func (a *LifeCycle) SetDefaults() {
 
 // Tracing holds the tracing configuration.
 type Tracing struct {
-	ServiceName             string             `description:"Defines the service name resource attribute." json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty" export:"true"`
-	ResourceAttributes      map[string]string  `description:"Defines additional resource attributes (key:value)." json:"resourceAttributes,omitempty" toml:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty" export:"true"`
-	CapturedRequestHeaders  []string           `description:"Request headers to add as attributes for server and client spans." json:"capturedRequestHeaders,omitempty" toml:"capturedRequestHeaders,omitempty" yaml:"capturedRequestHeaders,omitempty" export:"true"`
-	CapturedResponseHeaders []string           `description:"Response headers to add as attributes for server and client spans." json:"capturedResponseHeaders,omitempty" toml:"capturedResponseHeaders,omitempty" yaml:"capturedResponseHeaders,omitempty" export:"true"`
-	SafeQueryParams         []string           `description:"Query params to not redact." json:"safeQueryParams,omitempty" toml:"safeQueryParams,omitempty" yaml:"safeQueryParams,omitempty" export:"true"`
-	SampleRate              float64            `description:"Sets the rate between 0.0 and 1.0 of requests to trace." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
-	AddInternals            bool               `description:"Enables tracing for internal services (ping, dashboard, etc...)." json:"addInternals,omitempty" toml:"addInternals,omitempty" yaml:"addInternals,omitempty" export:"true"`
-	OTLP                    *types.OTelTracing `description:"Settings for OpenTelemetry." json:"otlp,omitempty" toml:"otlp,omitempty" yaml:"otlp,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	ServiceName             string              `description:"Defines the service name resource attribute." json:"serviceName,omitempty" toml:"serviceName,omitempty" yaml:"serviceName,omitempty" export:"true"`
+	ResourceAttributes      map[string]string   `description:"Defines additional resource attributes (key:value)." json:"resourceAttributes,omitempty" toml:"resourceAttributes,omitempty" yaml:"resourceAttributes,omitempty" export:"true"`
+	CapturedRequestHeaders  []string            `description:"Request headers to add as attributes for server and client spans." json:"capturedRequestHeaders,omitempty" toml:"capturedRequestHeaders,omitempty" yaml:"capturedRequestHeaders,omitempty" export:"true"`
+	CapturedResponseHeaders []string            `description:"Response headers to add as attributes for server and client spans." json:"capturedResponseHeaders,omitempty" toml:"capturedResponseHeaders,omitempty" yaml:"capturedResponseHeaders,omitempty" export:"true"`
+	SafeQueryParams         []string            `description:"Query params to not redact." json:"safeQueryParams,omitempty" toml:"safeQueryParams,omitempty" yaml:"safeQueryParams,omitempty" export:"true"`
+	SampleRate              float64             `description:"Sets the rate between 0.0 and 1.0 of requests to trace." json:"sampleRate,omitempty" toml:"sampleRate,omitempty" yaml:"sampleRate,omitempty" export:"true"`
+	HonorParentDecision     bool                `description:"Follows the sampling decision propagated by the clients instead of the sample rate." json:"honorParentDecision,omitempty" toml:"honorParentDecision,omitempty" yaml:"honorParentDecision,omitempty" export:"true"`
+	TailSampling            *types.TailSampling `description:"Exports the traces of the failed and slow requests whatever the sample rates." json:"tailSampling,omitempty" toml:"tailSampling,omitempty" yaml:"tailSampling,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	AddInternals            bool                `description:"Enables tracing for internal services (ping, dashboard, etc...)." json:"addInternals,omitempty" toml:"addInternals,omitempty" yaml:"addInternals,omitempty" export:"true"`
+	OTLP                    *types.OTelTracing  `description:"Settings for OpenTelemetry." json:"otlp,omitempty" toml:"otlp,omitempty" yaml:"otlp,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 
 	// Deprecated: please use ResourceAttributes instead.
 	GlobalAttributes map[string]string `description:"(Deprecated) Defines additional resource attributes (key:value)." json:"globalAttributes,omitempty" toml:"globalAttributes,omitempty" yaml:"globalAttributes,omitempty" export:"true"`
@@ -456,6 +477,25 @@ func (c *Configuration) ValidateConfiguration() error {
 		}
 	}
 
+	if c.SessionTickets != nil {
+		if (c.SessionTickets.KeysFile == "") == (c.SessionTickets.KV == nil) {
+			return errors.New("session tickets: either keysFile or kv must be defined")
+		}
+
+		if c.SessionTickets.KV != nil {
+			enabled := map[string]bool{}
+			if c.Providers != nil {
+				enabled["consul"] = c.Providers.Consul != nil
+				enabled["etcd"] = c.Providers.Etcd != nil
+				enabled["redis"] = c.Providers.Redis != nil
+				enabled["zooKeeper"] = c.Providers.ZooKeeper != nil
+			}
+			if !enabled[c.SessionTickets.KV.Provider] {
+				return fmt.Errorf("session tickets: KV provider %q is not enabled", c.SessionTickets.KV.Provider)
+			}
+		}
+	}
+
 	return nil
 }
 
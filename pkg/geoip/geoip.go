@@ -0,0 +1,224 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/containous/alice"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/safe"
+)
+
+// reloadDebounce is the delay without changes after which a database is reloaded,
+// as the databases are usually updated in several writes.
+const reloadDebounce = time.Second
+
+type key struct{}
+
+// Record holds the location data of an IP address.
+type Record struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country.
+	Country string
+	// Continent is the two-letter code of the continent.
+	Continent string
+	// ASN is the number of the autonomous system.
+	ASN uint
+	// ASOrganization is the organization of the autonomous system.
+	ASOrganization string
+}
+
+// Database looks up the location of IP addresses in MaxMind DB files,
+// which are reloaded when they change on disk.
+type Database struct {
+	// databases are the reloaded databases, the first one having a value for a field giving it.
+	databases []*reloadedDatabase
+}
+
+type reloadedDatabase struct {
+	path   string
+	reader atomic.Pointer[mmdbReader]
+}
+
+// NewDatabase loads the MaxMind DB files found at the given paths,
+// and reloads them on changes until the context is done.
+// The empty paths are ignored.
+func NewDatabase(ctx context.Context, paths ...string) (*Database, error) {
+	db := &Database{}
+
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		reader, err := openMMDB(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading GeoIP database %s: %w", path, err)
+		}
+
+		log.Ctx(ctx).Debug().Str("database", path).Msgf("GeoIP database of type %q loaded", reader.dbType)
+
+		rdb := &reloadedDatabase{path: path}
+		rdb.reader.Store(reader)
+
+		db.databases = append(db.databases, rdb)
+	}
+
+	if len(db.databases) == 0 {
+		return nil, errors.New("no GeoIP database")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating GeoIP databases watcher: %w", err)
+	}
+
+	for _, rdb := range db.databases {
+		// The directory is watched, as the databases are usually updated by replacing the file.
+		if err := watcher.Add(filepath.Dir(rdb.path)); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("watching GeoIP database %s: %w", rdb.path, err)
+		}
+	}
+
+	safe.Go(func() { db.watch(ctx, watcher) })
+
+	return db, nil
+}
+
+// Lookup returns the location of the given address.
+// An address not found in the databases has an empty record.
+func (db *Database) Lookup(addr netip.Addr) (Record, error) {
+	var record Record
+	for _, rdb := range db.databases {
+		r, found, err := rdb.reader.Load().lookup(addr)
+		if err != nil {
+			return Record{}, fmt.Errorf("looking up %s in %s: %w", addr, rdb.path, err)
+		}
+		if !found {
+			continue
+		}
+
+		if record.Country == "" {
+			record.Country = r.Country
+		}
+		if record.Continent == "" {
+			record.Continent = r.Continent
+		}
+		if record.ASN == 0 {
+			record.ASN = r.ASN
+			record.ASOrganization = r.ASOrganization
+		}
+	}
+
+	return record, nil
+}
+
+// LookupHost returns the location of the given IP address, with or without a port.
+func (db *Database) LookupHost(host string) (Record, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return Record{}, fmt.Errorf("parsing IP address %q: %w", host, err)
+	}
+
+	return db.Lookup(addr)
+}
+
+func (db *Database) watch(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer func() { _ = watcher.Close() }()
+
+	logger := log.Ctx(ctx)
+
+	pending := make(map[*reloadedDatabase]struct{})
+	timer := time.NewTimer(reloadDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			for _, rdb := range db.databases {
+				if filepath.Clean(event.Name) == filepath.Clean(rdb.path) && event.Has(fsnotify.Write|fsnotify.Create) {
+					pending[rdb] = struct{}{}
+					timer.Reset(reloadDebounce)
+				}
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Error().Err(err).Msg("GeoIP databases watcher error")
+
+		case <-timer.C:
+			for rdb := range pending {
+				reader, err := openMMDB(rdb.path)
+				if err != nil {
+					logger.Error().Err(err).Str("database", rdb.path).Msg("Unable to reload the GeoIP database, keeping the previous one")
+					continue
+				}
+
+				rdb.reader.Store(reader)
+				logger.Info().Str("database", rdb.path).Msg("GeoIP database reloaded")
+			}
+
+			clear(pending)
+		}
+	}
+}
+
+// ParseCountryCode checks that the given country code is an ISO 3166-1 alpha-2 code, and returns it in upper case.
+func ParseCountryCode(code string) (string, error) {
+	if len(code) != 2 || !isASCIILetter(code[0]) || !isASCIILetter(code[1]) {
+		return "", fmt.Errorf("invalid country code %q: expected an ISO 3166-1 alpha-2 code", code)
+	}
+
+	return strings.ToUpper(code), nil
+}
+
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// WithDatabase returns a copy of the context holding the given database.
+func WithDatabase(ctx context.Context, db *Database) context.Context {
+	return context.WithValue(ctx, key{}, db)
+}
+
+// DatabaseFromContext returns the database held by the context, if any.
+func DatabaseFromContext(ctx context.Context) *Database {
+	db, _ := ctx.Value(key{}).(*Database)
+	return db
+}
+
+// WrapHandler returns a constructor adding the given database to the context of the requests.
+func WrapHandler(db *Database) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		if db == nil {
+			return next, nil
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(rw, req.WithContext(WithDatabase(req.Context(), db)))
+		}), nil
+	}
+}
@@ -0,0 +1,191 @@
+package geoip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
+)
+
+func TestDatabase_Lookup(t *testing.T) {
+	dir := t.TempDir()
+
+	countries := filepath.Join(dir, "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, countries, 24, map[string]map[string]any{
+		"192.0.2.0/24": {
+			"country":   map[string]any{"iso_code": "FR"},
+			"continent": map[string]any{"code": "EU"},
+		},
+		"198.51.100.0/24": {
+			"registered_country": map[string]any{"iso_code": "US"},
+			"continent":          map[string]any{"code": "NA"},
+		},
+		"2001:db8::/32": {
+			"country":   map[string]any{"iso_code": "JP"},
+			"continent": map[string]any{"code": "AS"},
+		},
+	})
+
+	asns := filepath.Join(dir, "asn.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, asns, 28, map[string]map[string]any{
+		"192.0.2.0/25": {
+			"autonomous_system_number":       uint32(64496),
+			"autonomous_system_organization": "Example",
+		},
+	})
+
+	db, err := NewDatabase(t.Context(), countries, "", asns)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc     string
+		addr     string
+		expected Record
+	}{
+		{
+			desc:     "country and ASN",
+			addr:     "192.0.2.1",
+			expected: Record{Country: "FR", Continent: "EU", ASN: 64496, ASOrganization: "Example"},
+		},
+		{
+			desc:     "country only",
+			addr:     "192.0.2.129",
+			expected: Record{Country: "FR", Continent: "EU"},
+		},
+		{
+			desc:     "registered country",
+			addr:     "198.51.100.1",
+			expected: Record{Country: "US", Continent: "NA"},
+		},
+		{
+			desc:     "IPv4-mapped IPv6 address",
+			addr:     "::ffff:192.0.2.129",
+			expected: Record{Country: "FR", Continent: "EU"},
+		},
+		{
+			desc:     "IPv6 address",
+			addr:     "2001:db8::1",
+			expected: Record{Country: "JP", Continent: "AS"},
+		},
+		{
+			desc: "unknown address",
+			addr: "203.0.113.1",
+		},
+		{
+			desc: "unknown IPv6 address",
+			addr: "2001:db9::1",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			record, err := db.Lookup(netip.MustParseAddr(test.addr))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, record)
+		})
+	}
+}
+
+func TestDatabase_LookupHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, path, 32, map[string]map[string]any{
+		"192.0.2.0/24": {"country": map[string]any{"iso_code": "FR"}},
+	})
+
+	db, err := NewDatabase(t.Context(), path)
+	require.NoError(t, err)
+
+	record, err := db.LookupHost("192.0.2.1:443")
+	require.NoError(t, err)
+	assert.Equal(t, "FR", record.Country)
+
+	record, err = db.LookupHost("192.0.2.1")
+	require.NoError(t, err)
+	assert.Equal(t, "FR", record.Country)
+
+	_, err = db.LookupHost("foo")
+	require.Error(t, err)
+}
+
+func TestNewDatabase_errors(t *testing.T) {
+	dir := t.TempDir()
+
+	invalid := filepath.Join(dir, "invalid.mmdb")
+	err := os.WriteFile(invalid, []byte("foo"), 0o644)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc  string
+		paths []string
+	}{
+		{
+			desc: "no database",
+		},
+		{
+			desc:  "missing database",
+			paths: []string{filepath.Join(dir, "missing.mmdb")},
+		},
+		{
+			desc:  "invalid database",
+			paths: []string{invalid},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewDatabase(t.Context(), test.paths...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDatabase_reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, path, 24, map[string]map[string]any{
+		"192.0.2.0/24": {"country": map[string]any{"iso_code": "FR"}},
+	})
+
+	db, err := NewDatabase(t.Context(), path)
+	require.NoError(t, err)
+
+	testhelpers.WriteGeoIPDatabase(t, path, 24, map[string]map[string]any{
+		"192.0.2.0/24": {"country": map[string]any{"iso_code": "DE"}},
+	})
+
+	assert.Eventually(t, func() bool {
+		record, err := db.Lookup(netip.MustParseAddr("192.0.2.1"))
+		return err == nil && record.Country == "DE"
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
+func TestWrapHandler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, path, 24, map[string]map[string]any{
+		"192.0.2.0/24": {"country": map[string]any{"iso_code": "FR"}},
+	})
+
+	db, err := NewDatabase(t.Context(), path)
+	require.NoError(t, err)
+
+	var got *Database
+	handler, err := WrapHandler(db)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = DatabaseFromContext(req.Context())
+	}))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Same(t, db, got)
+}
@@ -0,0 +1,489 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+	"sync"
+)
+
+// metadataStartMarker precedes the metadata section, at the end of a MaxMind DB file.
+var metadataStartMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparatorSize is the size of the zeroed separator between the search tree and the data section.
+const dataSectionSeparatorSize = 16
+
+// Data types of the MaxMind DB data section.
+const (
+	typeExtended  = 0
+	typePointer   = 1
+	typeString    = 2
+	typeDouble    = 3
+	typeBytes     = 4
+	typeUint16    = 5
+	typeUint32    = 6
+	typeMap       = 7
+	typeInt32     = 8
+	typeUint64    = 9
+	typeUint128   = 10
+	typeArray     = 11
+	typeContainer = 12
+	typeEndMarker = 13
+	typeBoolean   = 14
+	typeFloat     = 15
+)
+
+// maxDataDepth bounds the nesting of the decoded values, to protect against malformed databases.
+const maxDataDepth = 32
+
+// mmdbReader reads a database in the MaxMind DB format,
+// as described in https://maxmind.github.io/MaxMind-DB/.
+type mmdbReader struct {
+	buffer []byte
+
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	dbType     string
+
+	treeSize uint
+	// ipv4Start is the node of the ::/96 subtree, where the IPv4 addresses are looked up in an IPv6 tree.
+	ipv4Start     uint
+	ipv4StartBits int
+
+	mu sync.RWMutex
+	// records caches the records by data offset, as many networks share the same record.
+	records map[uint]Record
+}
+
+func openMMDB(path string) (*mmdbReader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMMDBReader(buffer)
+}
+
+func newMMDBReader(buffer []byte) (*mmdbReader, error) {
+	metadataStart := bytes.LastIndex(buffer, metadataStartMarker)
+	if metadataStart == -1 {
+		return nil, errors.New("invalid MaxMind DB file: metadata section not found")
+	}
+	metadataStart += len(metadataStartMarker)
+
+	metadata := decoder{buffer: buffer[metadataStart:]}
+	value, _, err := metadata.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("decoding metadata: %w", err)
+	}
+
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, errors.New("invalid MaxMind DB file: metadata is not a map")
+	}
+
+	r := &mmdbReader{
+		buffer:     buffer,
+		nodeCount:  uint(toUint64(fields["node_count"])),
+		recordSize: uint(toUint64(fields["record_size"])),
+		ipVersion:  uint(toUint64(fields["ip_version"])),
+		records:    make(map[uint]Record),
+	}
+	r.dbType, _ = fields["database_type"].(string)
+
+	if major := toUint64(fields["binary_format_major_version"]); major != 2 {
+		return nil, fmt.Errorf("unsupported MaxMind DB format version %d", major)
+	}
+
+	switch r.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", r.recordSize)
+	}
+
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version %d", r.ipVersion)
+	}
+
+	r.treeSize = r.nodeCount * r.recordSize / 4
+	if r.treeSize+dataSectionSeparatorSize > uint(metadataStart-len(metadataStartMarker)) {
+		return nil, errors.New("invalid MaxMind DB file: search tree larger than the file")
+	}
+
+	if r.ipVersion == 6 {
+		node := uint(0)
+		i := 0
+		for ; i < 96 && node < r.nodeCount; i++ {
+			node, err = r.readNode(node, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.ipv4Start = node
+		r.ipv4StartBits = i
+	}
+
+	return r, nil
+}
+
+// lookup returns the record of the network containing the given address,
+// and whether the address has been found.
+func (r *mmdbReader) lookup(addr netip.Addr) (Record, bool, error) {
+	addr = addr.Unmap().WithZone("")
+
+	var ip []byte
+	node := uint(0)
+	bitCount := 0
+	switch {
+	case addr.Is4():
+		a := addr.As4()
+		ip = a[:]
+		if r.ipVersion == 6 {
+			node = r.ipv4Start
+			bitCount = r.ipv4StartBits
+		}
+
+	case addr.Is6():
+		if r.ipVersion == 4 {
+			return Record{}, false, nil
+		}
+		a := addr.As16()
+		ip = a[:]
+
+	default:
+		return Record{}, false, nil
+	}
+
+	var err error
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-uint(i&7))) & 1
+		node, err = r.readNode(node, bit)
+		if err != nil {
+			return Record{}, false, err
+		}
+		bitCount++
+	}
+
+	switch {
+	case node == r.nodeCount:
+		return Record{}, false, nil
+
+	case node > r.nodeCount:
+		offset := node - r.nodeCount - dataSectionSeparatorSize
+		record, err := r.record(offset)
+		return record, err == nil, err
+
+	default:
+		return Record{}, false, fmt.Errorf("invalid search tree: no record after %d bits", bitCount)
+	}
+}
+
+func (r *mmdbReader) readNode(node, bit uint) (uint, error) {
+	offset := node * r.recordSize / 4
+	if offset+r.recordSize/4 > r.treeSize {
+		return 0, fmt.Errorf("invalid search tree: node %d out of bounds", node)
+	}
+
+	b := r.buffer[offset:]
+
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2]), nil
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6]), nil
+
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:])), nil
+	}
+}
+
+func (r *mmdbReader) record(offset uint) (Record, error) {
+	r.mu.RLock()
+	record, ok := r.records[offset]
+	r.mu.RUnlock()
+
+	if ok {
+		return record, nil
+	}
+
+	data := decoder{buffer: r.buffer[r.treeSize+dataSectionSeparatorSize:]}
+	value, _, err := data.decode(offset, 0)
+	if err != nil {
+		return Record{}, fmt.Errorf("decoding record: %w", err)
+	}
+
+	record = newRecord(value)
+
+	r.mu.Lock()
+	r.records[offset] = record
+	r.mu.Unlock()
+
+	return record, nil
+}
+
+// newRecord extracts the fields of interest of the GeoIP2/GeoLite2 Country, City and ASN databases.
+func newRecord(value any) Record {
+	fields, _ := value.(map[string]any)
+
+	record := Record{
+		Country:   lookupString(fields, "country", "iso_code"),
+		Continent: lookupString(fields, "continent", "code"),
+		ASN:       uint(toUint64(fields["autonomous_system_number"])),
+	}
+
+	if record.Country == "" {
+		record.Country = lookupString(fields, "registered_country", "iso_code")
+	}
+	record.ASOrganization, _ = fields["autonomous_system_organization"].(string)
+
+	return record
+}
+
+func lookupString(fields map[string]any, keys ...string) string {
+	var value any = fields
+	for _, key := range keys {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = m[key]
+	}
+
+	s, _ := value.(string)
+	return s
+}
+
+func toUint64(value any) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case int32:
+		if v < 0 {
+			return 0
+		}
+		return uint64(v)
+	default:
+		return 0
+	}
+}
+
+// decoder decodes the values of a MaxMind DB data section.
+type decoder struct {
+	buffer []byte
+}
+
+// decode decodes the value at the given offset, and returns it along with the offset following it.
+func (d decoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > maxDataDepth {
+		return nil, 0, errors.New("maximum data structure depth exceeded")
+	}
+
+	typeNum, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typeNum == typePointer {
+		pointer, next, err := d.decodePointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// A pointer to a pointer is invalid, hence the pointed value is decoded without following pointers again.
+		value, _, err := d.decodePointed(pointer, depth)
+		return value, next, err
+	}
+
+	return d.decodeFromType(typeNum, size, offset, depth)
+}
+
+func (d decoder) decodePointed(offset uint, depth int) (any, uint, error) {
+	typeNum, size, offset, err := d.decodeControl(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if typeNum == typePointer {
+		return nil, 0, errors.New("invalid pointer to a pointer")
+	}
+
+	return d.decodeFromType(typeNum, size, offset, depth)
+}
+
+func (d decoder) decodeControl(offset uint) (int, uint, uint, error) {
+	if offset >= uint(len(d.buffer)) {
+		return 0, 0, 0, errors.New("unexpected end of data")
+	}
+
+	ctrl := d.buffer[offset]
+	offset++
+
+	typeNum := int(ctrl >> 5)
+	if typeNum == typeExtended {
+		if offset >= uint(len(d.buffer)) {
+			return 0, 0, 0, errors.New("unexpected end of data")
+		}
+		typeNum = int(d.buffer[offset]) + 7
+		offset++
+	}
+
+	size := uint(ctrl & 0x1F)
+	if typeNum == typePointer || size < 29 {
+		return typeNum, size, offset, nil
+	}
+
+	extraBytes := size - 28
+	if offset+extraBytes > uint(len(d.buffer)) {
+		return 0, 0, 0, errors.New("unexpected end of data")
+	}
+
+	extra := uintFromBytes(d.buffer[offset : offset+extraBytes])
+	switch size {
+	case 29:
+		size = 29 + extra
+	case 30:
+		size = 285 + extra
+	default:
+		size = 65821 + extra
+	}
+
+	return typeNum, size, offset + extraBytes, nil
+}
+
+func (d decoder) decodePointer(size, offset uint) (uint, uint, error) {
+	pointerSize := (size >> 3) & 0x3
+	n := pointerSize + 1
+	if offset+n > uint(len(d.buffer)) {
+		return 0, 0, errors.New("unexpected end of data")
+	}
+
+	b := d.buffer[offset : offset+n]
+	var pointer uint
+	switch pointerSize {
+	case 0:
+		pointer = (size&0x7)<<8 | uintFromBytes(b)
+	case 1:
+		pointer = (size&0x7)<<16 | uintFromBytes(b) + 2048
+	case 2:
+		pointer = (size&0x7)<<24 | uintFromBytes(b) + 526336
+	default:
+		pointer = uintFromBytes(b)
+	}
+
+	return pointer, offset + n, nil
+}
+
+func (d decoder) decodeFromType(typeNum int, size, offset uint, depth int) (any, uint, error) {
+	switch typeNum {
+	case typeMap:
+		m := make(map[string]any, d.capacity(size, offset))
+		for range size {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("unexpected map key type %T", key)
+			}
+
+			m[k], offset, err = d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, offset, nil
+
+	case typeArray:
+		a := make([]any, 0, d.capacity(size, offset))
+		for range size {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+			offset = next
+		}
+		return a, offset, nil
+
+	case typeBoolean:
+		return size != 0, offset, nil
+
+	case typeContainer, typeEndMarker:
+		return nil, 0, fmt.Errorf("unexpected data type %d", typeNum)
+	}
+
+	if offset+size > uint(len(d.buffer)) {
+		return nil, 0, errors.New("unexpected end of data")
+	}
+	b := d.buffer[offset : offset+size]
+	next := offset + size
+
+	switch typeNum {
+	case typeString:
+		return string(b), next, nil
+
+	case typeBytes:
+		return bytes.Clone(b), next, nil
+
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("invalid double size %d", size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("invalid float size %d", size)
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(b)), next, nil
+
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("invalid unsigned integer size %d", size)
+		}
+		return uint64(uintFromBytes(b)), next, nil
+
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, fmt.Errorf("invalid int32 size %d", size)
+		}
+		return int32(uintFromBytes(b)), next, nil
+
+	case typeUint128:
+		// The 128-bit integers are not used by the fields of interest.
+		return bytes.Clone(b), next, nil
+
+	default:
+		return nil, 0, fmt.Errorf("unknown data type %d", typeNum)
+	}
+}
+
+// capacity returns the capacity to allocate for the given number of elements read from the database,
+// which cannot exceed the number of remaining bytes, as each element takes at least one byte.
+func (d decoder) capacity(size, offset uint) uint {
+	if offset >= uint(len(d.buffer)) {
+		return 0
+	}
+
+	return min(size, uint(len(d.buffer))-offset)
+}
+
+func uintFromBytes(b []byte) uint {
+	var v uint
+	for _, c := range b {
+		v = v<<8 | uint(c)
+	}
+	return v
+}
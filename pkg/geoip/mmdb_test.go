@@ -0,0 +1,149 @@
+package geoip
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_decode(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		buffer        []byte
+		offset        uint
+		expected      any
+		expectedNext  uint
+		expectedError bool
+	}{
+		{
+			desc:         "string",
+			buffer:       []byte{typeString<<5 | 2, 'F', 'R'},
+			expected:     "FR",
+			expectedNext: 3,
+		},
+		{
+			desc:         "extended uint64",
+			buffer:       []byte{2, typeUint64 - 7, 0x01, 0x00},
+			expected:     uint64(256),
+			expectedNext: 4,
+		},
+		{
+			desc:         "boolean",
+			buffer:       []byte{1, typeBoolean - 7},
+			expected:     true,
+			expectedNext: 2,
+		},
+		{
+			desc: "map with a pointer to a key",
+			buffer: []byte{
+				typeString<<5 | 2, 'i', 'd',
+				typeMap<<5 | 1, typePointer << 5, 0, typeUint16<<5 | 1, 42,
+			},
+			offset:       3,
+			expected:     map[string]any{"id": uint64(42)},
+			expectedNext: 8,
+		},
+		{
+			desc:         "extended array",
+			buffer:       []byte{2, typeArray - 7, typeString<<5 | 1, 'a', typeString<<5 | 1, 'b'},
+			expected:     []any{"a", "b"},
+			expectedNext: 6,
+		},
+		{
+			desc: "pointer to a pointer",
+			buffer: []byte{
+				typePointer << 5, 2,
+				typePointer << 5, 0,
+			},
+			offset:        2,
+			expectedError: true,
+		},
+		{
+			desc:          "truncated string",
+			buffer:        []byte{typeString<<5 | 4, 'F', 'R'},
+			expectedError: true,
+		},
+		{
+			desc:          "truncated map with a huge size",
+			buffer:        []byte{typeMap<<5 | 31, 0xFF, 0xFF, 0xFF, typeString<<5 | 1, 'a'},
+			expectedError: true,
+		},
+		{
+			desc:          "truncated array with a huge size",
+			buffer:        []byte{31, typeArray - 7, 0xFF, 0xFF, 0xFF, typeString<<5 | 1, 'a'},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid map key",
+			buffer:        []byte{typeMap<<5 | 1, typeUint16<<5 | 1, 42, typeUint16<<5 | 1, 42},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			value, next, err := decoder{buffer: test.buffer}.decode(test.offset, 0)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, value)
+			assert.Equal(t, test.expectedNext, next)
+		})
+	}
+}
+
+func TestNewMMDBReader_corrupted(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		buffer []byte
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:   "without metadata",
+			buffer: []byte("not a database"),
+		},
+		{
+			desc:   "truncated metadata",
+			buffer: append(slices.Clone(metadataStartMarker), typeMap<<5|2),
+		},
+		{
+			desc:   "metadata map with a huge size",
+			buffer: append(slices.Clone(metadataStartMarker), typeMap<<5|31, 0xFF, 0xFF, 0xFF),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newMMDBReader(test.buffer)
+			require.Error(t, err)
+		})
+	}
+}
+
+func FuzzNewMMDBReader(f *testing.F) {
+	f.Add(slices.Clone(metadataStartMarker))
+	f.Add(append(slices.Clone(metadataStartMarker), typeMap<<5|31, 0xFF, 0xFF, 0xFF))
+	f.Add(append(slices.Clone(metadataStartMarker), 31, typeArray-7, 0xFF, 0xFF, 0xFF))
+
+	f.Fuzz(func(t *testing.T, buffer []byte) {
+		r, err := newMMDBReader(buffer)
+		if err != nil {
+			return
+		}
+
+		_, _, _ = r.lookup(netip.MustParseAddr("81.2.69.142"))
+		_, _, _ = r.lookup(netip.MustParseAddr("2001:db8::1"))
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/types"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -241,6 +242,12 @@ func (shc *ServiceHealthChecker) checkHealthHTTP(ctx context.Context, target *ur
 }
 
 func (shc *ServiceHealthChecker) newRequest(ctx context.Context, target *url.URL) (*http.Request, error) {
+	socketPath, unixSocket := types.UnixSocketPath(target)
+	if unixSocket {
+		// The transport dialer decodes the socket path from the host.
+		target = types.UnixSocketHTTPURL(socketPath)
+	}
+
 	u, err := target.Parse(shc.config.Path)
 	if err != nil {
 		return nil, err
@@ -259,8 +266,11 @@ func (shc *ServiceHealthChecker) newRequest(ctx context.Context, target *url.URL
 		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
-	if shc.config.Hostname != "" {
+	switch {
+	case shc.config.Hostname != "":
 		req.Host = shc.config.Hostname
+	case unixSocket:
+		req.Host = "localhost"
 	}
 
 	for k, v := range shc.config.Headers {
@@ -273,17 +283,22 @@ func (shc *ServiceHealthChecker) newRequest(ctx context.Context, target *url.URL
 // checkHealthGRPC returns an error with a meaningful description if the health check failed.
 // Dedicated to gRPC servers implementing gRPC Health Checking Protocol v1.
 func (shc *ServiceHealthChecker) checkHealthGRPC(ctx context.Context, serverURL *url.URL) error {
-	u, err := serverURL.Parse(shc.config.Path)
-	if err != nil {
-		return fmt.Errorf("failed to parse server URL: %w", err)
-	}
+	var serverAddr string
+	if socketPath, ok := types.UnixSocketPath(serverURL); ok {
+		serverAddr = types.UnixSocketScheme + "://" + socketPath
+	} else {
+		u, err := serverURL.Parse(shc.config.Path)
+		if err != nil {
+			return fmt.Errorf("failed to parse server URL: %w", err)
+		}
 
-	port := u.Port()
-	if shc.config.Port != 0 {
-		port = strconv.Itoa(shc.config.Port)
-	}
+		port := u.Port()
+		if shc.config.Port != 0 {
+			port = strconv.Itoa(shc.config.Port)
+		}
 
-	serverAddr := net.JoinHostPort(u.Hostname(), port)
+		serverAddr = net.JoinHostPort(u.Hostname(), port)
+	}
 
 	var opts []grpc.DialOption
 	switch shc.config.Scheme {
@@ -10,8 +10,7 @@ import (
 
 // Checker allows to check that addresses are in a trusted IPs.
 type Checker struct {
-	authorizedIPs    []*net.IP
-	authorizedIPsNet []*net.IPNet
+	ranges prefixTrie
 }
 
 // NewChecker builds a new Checker given a list of CIDR-Strings to trusted IPs.
@@ -24,7 +23,8 @@ func NewChecker(trustedIPs []string) (*Checker, error) {
 
 	for _, ipMask := range trustedIPs {
 		if ipAddr := net.ParseIP(ipMask); ipAddr != nil {
-			checker.authorizedIPs = append(checker.authorizedIPs, &ipAddr)
+			addr, _ := netip.AddrFromSlice(ipAddr)
+			checker.ranges.insert(netip.PrefixFrom(addr, addr.BitLen()))
 			continue
 		}
 
@@ -32,12 +32,41 @@ func NewChecker(trustedIPs []string) (*Checker, error) {
 		if err != nil {
 			return nil, fmt.Errorf("parsing CIDR trusted IPs %s: %w", ipAddr, err)
 		}
-		checker.authorizedIPsNet = append(checker.authorizedIPsNet, ipAddr)
+
+		addr, _ := netip.AddrFromSlice(ipAddr.IP)
+		ones, _ := ipAddr.Mask.Size()
+		checker.ranges.insert(netip.PrefixFrom(addr, ones))
 	}
 
 	return checker, nil
 }
 
+// NewCheckerFromPrefixes builds a new Checker given a list of IP ranges.
+// Unlike NewChecker, an empty list is allowed, the resulting Checker containing no address.
+func NewCheckerFromPrefixes(prefixes []netip.Prefix) *Checker {
+	checker := &Checker{}
+	for _, prefix := range prefixes {
+		checker.ranges.insert(prefix)
+	}
+
+	return checker
+}
+
+// ParsePrefix parses an IP range given in CIDR notation, or a single IP.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	if addr, err := netip.ParseAddr(s); err == nil {
+		addr = addr.WithZone("")
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP range %q: %w", s, err)
+	}
+
+	return prefix, nil
+}
+
 // IsAuthorized checks if provided request is authorized by the trusted IPs.
 func (ip *Checker) IsAuthorized(addr string) error {
 	var invalidMatches []string
@@ -71,32 +100,24 @@ func (ip *Checker) Contains(addr string) (bool, error) {
 		return false, fmt.Errorf("unable to parse address: %s: %w", addr, err)
 	}
 
-	return ip.ContainsIP(ipAddr), nil
+	return ip.ranges.contains(ipAddr), nil
 }
 
 // ContainsIP checks if provided address is in the trusted IPs.
 func (ip *Checker) ContainsIP(addr net.IP) bool {
-	for _, authorizedIP := range ip.authorizedIPs {
-		if authorizedIP.Equal(addr) {
-			return true
-		}
-	}
-
-	for _, authorizedNet := range ip.authorizedIPsNet {
-		if authorizedNet.Contains(addr) {
-			return true
-		}
+	ipAddr, ok := netip.AddrFromSlice(addr)
+	if !ok {
+		return false
 	}
 
-	return false
+	return ip.ranges.contains(ipAddr)
 }
 
-func parseIP(addr string) (net.IP, error) {
+func parseIP(addr string) (netip.Addr, error) {
 	parsedAddr, err := netip.ParseAddr(addr)
 	if err != nil {
-		return nil, fmt.Errorf("can't parse IP from address %s", addr)
+		return netip.Addr{}, fmt.Errorf("can't parse IP from address %s", addr)
 	}
 
-	ip := parsedAddr.As16()
-	return ip[:], nil
+	return parsedAddr, nil
 }
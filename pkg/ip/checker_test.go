@@ -124,10 +124,11 @@ func TestNew(t *testing.T) {
 				require.EqualError(t, err, test.errMessage)
 			} else {
 				require.NoError(t, err)
-				for index, actual := range ipChecker.authorizedIPsNet {
+				prefixes := ipChecker.ranges.prefixes()
+				require.Len(t, prefixes, len(test.expectedAuthorizedIPs))
+				for index, actual := range prefixes {
 					expected := test.expectedAuthorizedIPs[index]
-					assert.Equal(t, expected.IP, actual.IP)
-					assert.Equal(t, expected.Mask.String(), actual.Mask.String())
+					assert.Equal(t, expected.String(), actual.String())
 				}
 			}
 		})
@@ -0,0 +1,174 @@
+package ip
+
+import (
+	"math/bits"
+	"net/netip"
+)
+
+// prefixTrie is a set of IP ranges stored in a path-compressed binary trie.
+// Looking up an address walks at most one node per bit of the address,
+// whatever the number of ranges, which allows to handle lists of hundreds of thousands of ranges.
+type prefixTrie struct {
+	v4 *trieNode
+	v6 *trieNode
+}
+
+type trieNode struct {
+	prefix netip.Prefix
+	// terminal is true when the prefix of the node is one of the ranges of the set,
+	// and false when the node only exists to split the paths of its children.
+	terminal bool
+	children [2]*trieNode
+}
+
+// insert adds the given range to the set.
+// A range covered by a range of the set is ignored,
+// and the ranges covered by the given range are removed from the set.
+func (t *prefixTrie) insert(prefix netip.Prefix) {
+	prefix = normalizePrefix(prefix)
+
+	root := &t.v6
+	if prefix.Addr().Is4() {
+		root = &t.v4
+	}
+
+	for n := root; ; {
+		cur := *n
+		if cur == nil {
+			*n = &trieNode{prefix: prefix, terminal: true}
+			return
+		}
+
+		common := commonBits(cur.prefix, prefix)
+
+		switch {
+		case common == cur.prefix.Bits() && common == prefix.Bits():
+			// Same range.
+			cur.terminal = true
+			cur.children = [2]*trieNode{}
+			return
+
+		case common == cur.prefix.Bits():
+			// The node is an ancestor of the range.
+			if cur.terminal {
+				return
+			}
+			n = &cur.children[bitAt(prefix.Addr(), common)]
+
+		case common == prefix.Bits():
+			// The range is an ancestor of the node, and then covers its whole subtree.
+			*n = &trieNode{prefix: prefix, terminal: true}
+			return
+
+		default:
+			// The node and the range diverge after their common bits.
+			branch := &trieNode{prefix: netip.PrefixFrom(prefix.Addr(), common).Masked()}
+			branch.children[bitAt(cur.prefix.Addr(), common)] = cur
+			branch.children[bitAt(prefix.Addr(), common)] = &trieNode{prefix: prefix, terminal: true}
+			*n = branch
+			return
+		}
+	}
+}
+
+// contains checks if the given address is in one of the ranges of the set.
+func (t *prefixTrie) contains(addr netip.Addr) bool {
+	addr = addr.WithZone("").Unmap()
+
+	n := t.v6
+	if addr.Is4() {
+		n = t.v4
+	}
+
+	for n != nil {
+		if !n.prefix.Contains(addr) {
+			return false
+		}
+
+		if n.terminal {
+			return true
+		}
+
+		if n.prefix.Bits() == addr.BitLen() {
+			return false
+		}
+
+		n = n.children[bitAt(addr, n.prefix.Bits())]
+	}
+
+	return false
+}
+
+// prefixes returns the ranges of the set, IPv4 ranges first.
+func (t *prefixTrie) prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+
+	var walk func(n *trieNode)
+	walk = func(n *trieNode) {
+		if n == nil {
+			return
+		}
+
+		if n.terminal {
+			prefixes = append(prefixes, n.prefix)
+			return
+		}
+
+		walk(n.children[0])
+		walk(n.children[1])
+	}
+
+	walk(t.v4)
+	walk(t.v6)
+
+	return prefixes
+}
+
+// normalizePrefix masks the given prefix,
+// and converts the IPv4-mapped IPv6 prefixes to IPv4 ones,
+// as the addresses are looked up unmapped.
+func normalizePrefix(prefix netip.Prefix) netip.Prefix {
+	addr := prefix.Addr().WithZone("")
+	if addr.Is4In6() && prefix.Bits() >= 96 {
+		return netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96).Masked()
+	}
+
+	return netip.PrefixFrom(addr, prefix.Bits()).Masked()
+}
+
+// commonBits returns the number of leading bits shared by the two prefixes of the same family,
+// up to the shortest prefix length.
+func commonBits(a, b netip.Prefix) int {
+	maxBits := min(a.Bits(), b.Bits())
+
+	offset := 0
+	if a.Addr().Is4() {
+		offset = 96
+	}
+
+	aBytes := a.Addr().As16()
+	bBytes := b.Addr().As16()
+
+	common := 0
+	for i := offset / 8; i < 16 && common < maxBits; i++ {
+		diff := aBytes[i] ^ bBytes[i]
+		if diff != 0 {
+			common += bits.LeadingZeros8(diff)
+			break
+		}
+		common += 8
+	}
+
+	return min(common, maxBits)
+}
+
+// bitAt returns the bit of the address at the given position, starting from the most significant bit.
+func bitAt(addr netip.Addr, pos int) int {
+	if addr.Is4() {
+		pos += 96
+	}
+
+	b := addr.As16()
+
+	return int(b[pos/8]>>(7-pos%8)) & 1
+}
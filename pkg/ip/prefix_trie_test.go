@@ -0,0 +1,162 @@
+package ip
+
+import (
+	"encoding/binary"
+	"math/rand/v2"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixTrie(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		ranges           []string
+		expectedPrefixes []string
+		passIPs          []string
+		rejectIPs        []string
+	}{
+		{
+			desc:             "empty",
+			expectedPrefixes: nil,
+			rejectIPs:        []string{"10.0.0.1", "::1"},
+		},
+		{
+			desc:             "sibling ranges",
+			ranges:           []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.7/32"},
+			expectedPrefixes: []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.7/32"},
+			passIPs:          []string{"10.0.1.1", "10.0.2.255", "10.0.3.7"},
+			rejectIPs:        []string{"10.0.0.1", "10.0.3.6", "10.0.4.1"},
+		},
+		{
+			desc:             "range covered by a previous range",
+			ranges:           []string{"10.0.0.0/8", "10.0.1.0/24"},
+			expectedPrefixes: []string{"10.0.0.0/8"},
+			passIPs:          []string{"10.0.1.1", "10.255.0.1"},
+			rejectIPs:        []string{"11.0.0.1"},
+		},
+		{
+			desc:             "range covering previous ranges",
+			ranges:           []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.0.0/8"},
+			expectedPrefixes: []string{"10.0.0.0/8"},
+			passIPs:          []string{"10.0.1.1", "10.255.0.1"},
+			rejectIPs:        []string{"11.0.0.1"},
+		},
+		{
+			desc:             "range inserted on a split node",
+			ranges:           []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.0.0/22"},
+			expectedPrefixes: []string{"10.0.0.0/22"},
+			passIPs:          []string{"10.0.0.1", "10.0.3.1"},
+			rejectIPs:        []string{"10.0.4.1"},
+		},
+		{
+			desc:             "unmasked range",
+			ranges:           []string{"10.0.1.42/24"},
+			expectedPrefixes: []string{"10.0.1.0/24"},
+			passIPs:          []string{"10.0.1.1"},
+		},
+		{
+			desc:             "IPv4-mapped IPv6 range",
+			ranges:           []string{"::ffff:10.0.1.0/120"},
+			expectedPrefixes: []string{"10.0.1.0/24"},
+			passIPs:          []string{"10.0.1.1", "::ffff:10.0.1.1"},
+			rejectIPs:        []string{"10.0.2.1"},
+		},
+		{
+			desc:             "IPv4 and IPv6 ranges",
+			ranges:           []string{"2001:db8::/32", "0.0.0.0/0"},
+			expectedPrefixes: []string{"0.0.0.0/0", "2001:db8::/32"},
+			passIPs:          []string{"1.2.3.4", "2001:db8::1", "2001:db8::1%eth0"},
+			rejectIPs:        []string{"2001:db9::1"},
+		},
+		{
+			desc:             "IPv6 ranges",
+			ranges:           []string{"2001:db8:1::/48", "2001:db8:2::1/128"},
+			expectedPrefixes: []string{"2001:db8:1::/48", "2001:db8:2::1/128"},
+			passIPs:          []string{"2001:db8:1:ffff::1", "2001:db8:2::1"},
+			rejectIPs:        []string{"2001:db8:2::2", "::"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var trie prefixTrie
+			for _, r := range test.ranges {
+				trie.insert(netip.MustParsePrefix(r))
+			}
+
+			var prefixes []string
+			for _, prefix := range trie.prefixes() {
+				prefixes = append(prefixes, prefix.String())
+			}
+			assert.Equal(t, test.expectedPrefixes, prefixes)
+
+			for _, testIP := range test.passIPs {
+				assert.Truef(t, trie.contains(netip.MustParseAddr(testIP)), "%s should have passed.", testIP)
+			}
+
+			for _, testIP := range test.rejectIPs {
+				assert.Falsef(t, trie.contains(netip.MustParseAddr(testIP)), "%s should not have passed.", testIP)
+			}
+		})
+	}
+}
+
+func TestPrefixTrie_largeList(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewPCG(42, 42))
+
+	// Only /32 ranges of the 10.0.0.0/8 network, to compare the lookups with a map.
+	listed := make(map[netip.Addr]struct{})
+
+	var trie prefixTrie
+	for range 300_000 {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], 10<<24|rnd.Uint32N(1<<24))
+
+		addr := netip.AddrFrom4(b)
+		listed[addr] = struct{}{}
+		trie.insert(netip.PrefixFrom(addr, 32))
+	}
+
+	assert.Len(t, trie.prefixes(), len(listed))
+
+	for range 100_000 {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], 10<<24|rnd.Uint32N(1<<24))
+
+		addr := netip.AddrFrom4(b)
+		_, expected := listed[addr]
+		if trie.contains(addr) != expected {
+			t.Fatalf("unexpected lookup result for %s: %v", addr, !expected)
+		}
+	}
+
+	for addr := range listed {
+		if !trie.contains(addr) {
+			t.Fatalf("%s should have passed", addr)
+		}
+	}
+}
+
+func BenchmarkPrefixTrie_contains(b *testing.B) {
+	rnd := rand.New(rand.NewPCG(42, 42))
+
+	var trie prefixTrie
+	for range 500_000 {
+		var a [4]byte
+		binary.BigEndian.PutUint32(a[:], rnd.Uint32())
+		trie.insert(netip.PrefixFrom(netip.AddrFrom4(a), 16+int(rnd.Uint32N(17))))
+	}
+
+	addr := netip.MustParseAddr("192.0.2.1")
+
+	b.ResetTimer()
+	for range b.N {
+		trie.contains(addr)
+	}
+}
@@ -32,7 +32,21 @@ const (
 	ddLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	ddOpenConnsName               = "open.connections"
 
-	ddTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
+	ddTLSCertsNotAfterTimestampName       = "tls.certs.notAfterTimestamp"
+	ddTLSClientCertRevocationFailuresName = "tls.clientCerts.revocationFailures.total"
+
+	ddHTTP2AbuseClosedConnsName = "http2.abuse.closedConnections.total"
+
+	ddWasmPluginInvocationsName = "wasmPlugin.invocations.total"
+	ddWasmPluginErrorsName      = "wasmPlugin.errors.total"
+	ddWasmPluginDurationName    = "wasmPlugin.duration"
+	ddWasmPluginMemoryName      = "wasmPlugin.memory.bytes"
+
+	ddIPListHitsName = "ipList.hits.total"
+
+	ddCircuitBreakerStateName         = "circuitBreaker.breakers"
+	ddCircuitBreakerTripsName         = "circuitBreaker.trips.total"
+	ddCircuitBreakerTrippingValueName = "circuitBreaker.trippingValue"
 
 	ddEntryPointReqsName        = "entrypoint.request.total"
 	ddEntryPointReqsTLSName     = "entrypoint.request.tls.total"
@@ -71,12 +85,23 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 	initDatadogClient(ctx, config, datadogLogger)
 
 	registry := &standardRegistry{
-		configReloadsCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0),
-		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
-		openConnectionsGauge:           datadogClient.NewGauge(ddOpenConnsName),
-		tlsCertsNotAfterTimestampGauge: datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		configReloadsCounter:                   datadogClient.NewCounter(ddConfigReloadsName, 1.0),
+		lastConfigReloadSuccessGauge:           datadogClient.NewGauge(ddLastConfigReloadSuccessName),
+		openConnectionsGauge:                   datadogClient.NewGauge(ddOpenConnsName),
+		tlsCertsNotAfterTimestampGauge:         datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
+		tlsClientCertRevocationFailuresCounter: datadogClient.NewCounter(ddTLSClientCertRevocationFailuresName, 1.0),
+		http2AbuseClosedConnectionsCounter:     datadogClient.NewCounter(ddHTTP2AbuseClosedConnsName, 1.0),
+		wasmPluginInvocationsCounter:           datadogClient.NewCounter(ddWasmPluginInvocationsName, 1.0),
+		wasmPluginErrorsCounter:                datadogClient.NewCounter(ddWasmPluginErrorsName, 1.0),
+		wasmPluginMemoryGauge:                  datadogClient.NewGauge(ddWasmPluginMemoryName),
+		ipListHitsCounter:                      datadogClient.NewCounter(ddIPListHitsName, 1.0),
+		circuitBreakerStateGauge:               datadogClient.NewGauge(ddCircuitBreakerStateName),
+		circuitBreakerTripsCounter:             datadogClient.NewCounter(ddCircuitBreakerTripsName, 1.0),
+		circuitBreakerTrippingValueGauge:       datadogClient.NewGauge(ddCircuitBreakerTrippingValueName),
 	}
 
+	registry.wasmPluginDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddWasmPluginDurationName, 1.0), time.Second)
+
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
 		registry.entryPointReqsCounter = NewCounterWithNoopHeaders(datadogClient.NewCounter(ddEntryPointReqsName, 1.0))
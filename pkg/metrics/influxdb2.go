@@ -28,7 +28,21 @@ const (
 	influxDBLastConfigReloadSuccessName = "apache4.config.reload.lastSuccessTimestamp"
 	influxDBOpenConnsName               = "apache4.open.connections"
 
-	influxDBTLSCertsNotAfterTimestampName = "apache4.tls.certs.notAfterTimestamp"
+	influxDBTLSCertsNotAfterTimestampName       = "apache4.tls.certs.notAfterTimestamp"
+	influxDBTLSClientCertRevocationFailuresName = "apache4.tls.clientCerts.revocationFailures.total"
+
+	influxDBHTTP2AbuseClosedConnsName = "apache4.http2.abuse.closedConnections.total"
+
+	influxDBWasmPluginInvocationsName = "apache4.wasmPlugin.invocations.total"
+	influxDBWasmPluginErrorsName      = "apache4.wasmPlugin.errors.total"
+	influxDBWasmPluginDurationName    = "apache4.wasmPlugin.duration"
+	influxDBWasmPluginMemoryName      = "apache4.wasmPlugin.memory.bytes"
+
+	influxDBIPListHitsName = "apache4.ipList.hits.total"
+
+	influxDBCircuitBreakerStateName         = "apache4.circuitBreaker.breakers"
+	influxDBCircuitBreakerTripsName         = "apache4.circuitBreaker.trips.total"
+	influxDBCircuitBreakerTrippingValueName = "apache4.circuitBreaker.trippingValue"
 
 	influxDBEntryPointReqsName        = "apache4.entrypoint.requests.total"
 	influxDBEntryPointReqsTLSName     = "apache4.entrypoint.requests.tls.total"
@@ -79,12 +93,23 @@ func RegisterInfluxDB2(ctx context.Context, config *types.InfluxDB2) Registry {
 	}
 
 	registry := &standardRegistry{
-		configReloadsCounter:           influxDB2Store.NewCounter(influxDBConfigReloadsName),
-		lastConfigReloadSuccessGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
-		openConnectionsGauge:           influxDB2Store.NewGauge(influxDBOpenConnsName),
-		tlsCertsNotAfterTimestampGauge: influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		configReloadsCounter:                   influxDB2Store.NewCounter(influxDBConfigReloadsName),
+		lastConfigReloadSuccessGauge:           influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
+		openConnectionsGauge:                   influxDB2Store.NewGauge(influxDBOpenConnsName),
+		tlsCertsNotAfterTimestampGauge:         influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
+		tlsClientCertRevocationFailuresCounter: influxDB2Store.NewCounter(influxDBTLSClientCertRevocationFailuresName),
+		http2AbuseClosedConnectionsCounter:     influxDB2Store.NewCounter(influxDBHTTP2AbuseClosedConnsName),
+		wasmPluginInvocationsCounter:           influxDB2Store.NewCounter(influxDBWasmPluginInvocationsName),
+		wasmPluginErrorsCounter:                influxDB2Store.NewCounter(influxDBWasmPluginErrorsName),
+		wasmPluginMemoryGauge:                  influxDB2Store.NewGauge(influxDBWasmPluginMemoryName),
+		ipListHitsCounter:                      influxDB2Store.NewCounter(influxDBIPListHitsName),
+		circuitBreakerStateGauge:               influxDB2Store.NewGauge(influxDBCircuitBreakerStateName),
+		circuitBreakerTripsCounter:             influxDB2Store.NewCounter(influxDBCircuitBreakerTripsName),
+		circuitBreakerTrippingValueGauge:       influxDB2Store.NewGauge(influxDBCircuitBreakerTrippingValueName),
 	}
 
+	registry.wasmPluginDurationHistogram, _ = NewHistogramWithScale(influxDB2Store.NewHistogram(influxDBWasmPluginDurationName), time.Second)
+
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
 		registry.entryPointReqsCounter = NewCounterWithNoopHeaders(influxDB2Store.NewCounter(influxDBEntryPointReqsName))
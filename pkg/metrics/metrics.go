@@ -28,6 +28,28 @@ type Registry interface {
 	// TLS
 
 	TLSCertsNotAfterTimestampGauge() metrics.Gauge
+	TLSClientCertRevocationFailuresCounter() metrics.Counter
+
+	// HTTP/2
+
+	HTTP2AbuseClosedConnectionsCounter() metrics.Counter
+
+	// Wasm plugins
+
+	WasmPluginInvocationsCounter() metrics.Counter
+	WasmPluginErrorsCounter() metrics.Counter
+	WasmPluginDurationHistogram() ScalableHistogram
+	WasmPluginMemoryGauge() metrics.Gauge
+
+	// IP lists
+
+	IPListHitsCounter() metrics.Counter
+
+	// circuit breakers
+
+	CircuitBreakerStateGauge() metrics.Gauge
+	CircuitBreakerTripsCounter() metrics.Counter
+	CircuitBreakerTrippingValueGauge() metrics.Gauge
 
 	// entry point metrics
 
@@ -70,6 +92,16 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var openConnectionsGauge []metrics.Gauge
 	var tlsCertsNotAfterTimestampGauge []metrics.Gauge
+	var tlsClientCertRevocationFailuresCounter []metrics.Counter
+	var http2AbuseClosedConnectionsCounter []metrics.Counter
+	var wasmPluginInvocationsCounter []metrics.Counter
+	var wasmPluginErrorsCounter []metrics.Counter
+	var wasmPluginDurationHistogram []ScalableHistogram
+	var wasmPluginMemoryGauge []metrics.Gauge
+	var ipListHitsCounter []metrics.Counter
+	var circuitBreakerStateGauge []metrics.Gauge
+	var circuitBreakerTripsCounter []metrics.Counter
+	var circuitBreakerTrippingValueGauge []metrics.Gauge
 	var entryPointReqsCounter []CounterWithHeaders
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
@@ -101,6 +133,36 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.TLSCertsNotAfterTimestampGauge() != nil {
 			tlsCertsNotAfterTimestampGauge = append(tlsCertsNotAfterTimestampGauge, r.TLSCertsNotAfterTimestampGauge())
 		}
+		if r.TLSClientCertRevocationFailuresCounter() != nil {
+			tlsClientCertRevocationFailuresCounter = append(tlsClientCertRevocationFailuresCounter, r.TLSClientCertRevocationFailuresCounter())
+		}
+		if r.HTTP2AbuseClosedConnectionsCounter() != nil {
+			http2AbuseClosedConnectionsCounter = append(http2AbuseClosedConnectionsCounter, r.HTTP2AbuseClosedConnectionsCounter())
+		}
+		if r.WasmPluginInvocationsCounter() != nil {
+			wasmPluginInvocationsCounter = append(wasmPluginInvocationsCounter, r.WasmPluginInvocationsCounter())
+		}
+		if r.WasmPluginErrorsCounter() != nil {
+			wasmPluginErrorsCounter = append(wasmPluginErrorsCounter, r.WasmPluginErrorsCounter())
+		}
+		if r.WasmPluginDurationHistogram() != nil {
+			wasmPluginDurationHistogram = append(wasmPluginDurationHistogram, r.WasmPluginDurationHistogram())
+		}
+		if r.WasmPluginMemoryGauge() != nil {
+			wasmPluginMemoryGauge = append(wasmPluginMemoryGauge, r.WasmPluginMemoryGauge())
+		}
+		if r.IPListHitsCounter() != nil {
+			ipListHitsCounter = append(ipListHitsCounter, r.IPListHitsCounter())
+		}
+		if r.CircuitBreakerStateGauge() != nil {
+			circuitBreakerStateGauge = append(circuitBreakerStateGauge, r.CircuitBreakerStateGauge())
+		}
+		if r.CircuitBreakerTripsCounter() != nil {
+			circuitBreakerTripsCounter = append(circuitBreakerTripsCounter, r.CircuitBreakerTripsCounter())
+		}
+		if r.CircuitBreakerTrippingValueGauge() != nil {
+			circuitBreakerTrippingValueGauge = append(circuitBreakerTrippingValueGauge, r.CircuitBreakerTrippingValueGauge())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -155,58 +217,78 @@ func NewMultiRegistry(registries []Registry) Registry {
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		routerEnabled:                  len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		openConnectionsGauge:           multi.NewGauge(openConnectionsGauge...),
-		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
-		entryPointReqsCounter:          NewMultiCounterWithHeaders(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: MultiHistogram(entryPointReqDurationHistogram),
-		entryPointReqsBytesCounter:     multi.NewCounter(entryPointReqsBytesCounter...),
-		entryPointRespsBytesCounter:    multi.NewCounter(entryPointRespsBytesCounter...),
-		routerReqsCounter:              NewMultiCounterWithHeaders(routerReqsCounter...),
-		routerReqsTLSCounter:           multi.NewCounter(routerReqsTLSCounter...),
-		routerReqDurationHistogram:     MultiHistogram(routerReqDurationHistogram),
-		routerReqsBytesCounter:         multi.NewCounter(routerReqsBytesCounter...),
-		routerRespsBytesCounter:        multi.NewCounter(routerRespsBytesCounter...),
-		serviceReqsCounter:             NewMultiCounterWithHeaders(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    MultiHistogram(serviceReqDurationHistogram),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
-		serviceReqsBytesCounter:        multi.NewCounter(serviceReqsBytesCounter...),
-		serviceRespsBytesCounter:       multi.NewCounter(serviceRespsBytesCounter...),
+		epEnabled:                              len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
+		svcEnabled:                             len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		routerEnabled:                          len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
+		configReloadsCounter:                   multi.NewCounter(configReloadsCounter...),
+		lastConfigReloadSuccessGauge:           multi.NewGauge(lastConfigReloadSuccessGauge...),
+		openConnectionsGauge:                   multi.NewGauge(openConnectionsGauge...),
+		tlsCertsNotAfterTimestampGauge:         multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		tlsClientCertRevocationFailuresCounter: multi.NewCounter(tlsClientCertRevocationFailuresCounter...),
+		http2AbuseClosedConnectionsCounter:     multi.NewCounter(http2AbuseClosedConnectionsCounter...),
+		wasmPluginInvocationsCounter:           multi.NewCounter(wasmPluginInvocationsCounter...),
+		wasmPluginErrorsCounter:                multi.NewCounter(wasmPluginErrorsCounter...),
+		wasmPluginDurationHistogram:            MultiHistogram(wasmPluginDurationHistogram),
+		wasmPluginMemoryGauge:                  multi.NewGauge(wasmPluginMemoryGauge...),
+		ipListHitsCounter:                      multi.NewCounter(ipListHitsCounter...),
+		circuitBreakerStateGauge:               multi.NewGauge(circuitBreakerStateGauge...),
+		circuitBreakerTripsCounter:             multi.NewCounter(circuitBreakerTripsCounter...),
+		circuitBreakerTrippingValueGauge:       multi.NewGauge(circuitBreakerTrippingValueGauge...),
+		entryPointReqsCounter:                  NewMultiCounterWithHeaders(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:               multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:         MultiHistogram(entryPointReqDurationHistogram),
+		entryPointReqsBytesCounter:             multi.NewCounter(entryPointReqsBytesCounter...),
+		entryPointRespsBytesCounter:            multi.NewCounter(entryPointRespsBytesCounter...),
+		routerReqsCounter:                      NewMultiCounterWithHeaders(routerReqsCounter...),
+		routerReqsTLSCounter:                   multi.NewCounter(routerReqsTLSCounter...),
+		routerReqDurationHistogram:             MultiHistogram(routerReqDurationHistogram),
+		routerReqsBytesCounter:                 multi.NewCounter(routerReqsBytesCounter...),
+		routerRespsBytesCounter:                multi.NewCounter(routerRespsBytesCounter...),
+		serviceReqsCounter:                     NewMultiCounterWithHeaders(serviceReqsCounter...),
+		serviceReqsTLSCounter:                  multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:            MultiHistogram(serviceReqDurationHistogram),
+		serviceRetriesCounter:                  multi.NewCounter(serviceRetriesCounter...),
+		serviceServerUpGauge:                   multi.NewGauge(serviceServerUpGauge...),
+		serviceReqsBytesCounter:                multi.NewCounter(serviceReqsBytesCounter...),
+		serviceRespsBytesCounter:               multi.NewCounter(serviceRespsBytesCounter...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	routerEnabled                  bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	openConnectionsGauge           metrics.Gauge
-	tlsCertsNotAfterTimestampGauge metrics.Gauge
-	entryPointReqsCounter          CounterWithHeaders
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointReqsBytesCounter     metrics.Counter
-	entryPointRespsBytesCounter    metrics.Counter
-	routerReqsCounter              CounterWithHeaders
-	routerReqsTLSCounter           metrics.Counter
-	routerReqDurationHistogram     ScalableHistogram
-	routerReqsBytesCounter         metrics.Counter
-	routerRespsBytesCounter        metrics.Counter
-	serviceReqsCounter             CounterWithHeaders
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
-	serviceReqsBytesCounter        metrics.Counter
-	serviceRespsBytesCounter       metrics.Counter
+	epEnabled                              bool
+	routerEnabled                          bool
+	svcEnabled                             bool
+	configReloadsCounter                   metrics.Counter
+	lastConfigReloadSuccessGauge           metrics.Gauge
+	openConnectionsGauge                   metrics.Gauge
+	tlsCertsNotAfterTimestampGauge         metrics.Gauge
+	tlsClientCertRevocationFailuresCounter metrics.Counter
+	http2AbuseClosedConnectionsCounter     metrics.Counter
+	wasmPluginInvocationsCounter           metrics.Counter
+	wasmPluginErrorsCounter                metrics.Counter
+	wasmPluginDurationHistogram            ScalableHistogram
+	wasmPluginMemoryGauge                  metrics.Gauge
+	ipListHitsCounter                      metrics.Counter
+	circuitBreakerStateGauge               metrics.Gauge
+	circuitBreakerTripsCounter             metrics.Counter
+	circuitBreakerTrippingValueGauge       metrics.Gauge
+	entryPointReqsCounter                  CounterWithHeaders
+	entryPointReqsTLSCounter               metrics.Counter
+	entryPointReqDurationHistogram         ScalableHistogram
+	entryPointReqsBytesCounter             metrics.Counter
+	entryPointRespsBytesCounter            metrics.Counter
+	routerReqsCounter                      CounterWithHeaders
+	routerReqsTLSCounter                   metrics.Counter
+	routerReqDurationHistogram             ScalableHistogram
+	routerReqsBytesCounter                 metrics.Counter
+	routerRespsBytesCounter                metrics.Counter
+	serviceReqsCounter                     CounterWithHeaders
+	serviceReqsTLSCounter                  metrics.Counter
+	serviceReqDurationHistogram            ScalableHistogram
+	serviceRetriesCounter                  metrics.Counter
+	serviceServerUpGauge                   metrics.Gauge
+	serviceReqsBytesCounter                metrics.Counter
+	serviceRespsBytesCounter               metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -237,6 +319,46 @@ func (r *standardRegistry) TLSCertsNotAfterTimestampGauge() metrics.Gauge {
 	return r.tlsCertsNotAfterTimestampGauge
 }
 
+func (r *standardRegistry) TLSClientCertRevocationFailuresCounter() metrics.Counter {
+	return r.tlsClientCertRevocationFailuresCounter
+}
+
+func (r *standardRegistry) HTTP2AbuseClosedConnectionsCounter() metrics.Counter {
+	return r.http2AbuseClosedConnectionsCounter
+}
+
+func (r *standardRegistry) WasmPluginInvocationsCounter() metrics.Counter {
+	return r.wasmPluginInvocationsCounter
+}
+
+func (r *standardRegistry) WasmPluginErrorsCounter() metrics.Counter {
+	return r.wasmPluginErrorsCounter
+}
+
+func (r *standardRegistry) WasmPluginDurationHistogram() ScalableHistogram {
+	return r.wasmPluginDurationHistogram
+}
+
+func (r *standardRegistry) WasmPluginMemoryGauge() metrics.Gauge {
+	return r.wasmPluginMemoryGauge
+}
+
+func (r *standardRegistry) IPListHitsCounter() metrics.Counter {
+	return r.ipListHitsCounter
+}
+
+func (r *standardRegistry) CircuitBreakerStateGauge() metrics.Gauge {
+	return r.circuitBreakerStateGauge
+}
+
+func (r *standardRegistry) CircuitBreakerTripsCounter() metrics.Counter {
+	return r.circuitBreakerTripsCounter
+}
+
+func (r *standardRegistry) CircuitBreakerTrippingValueGauge() metrics.Gauge {
+	return r.circuitBreakerTrippingValueGauge
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() CounterWithHeaders {
 	return r.entryPointReqsCounter
 }
@@ -123,7 +123,28 @@ func RegisterOpenTelemetry(ctx context.Context, config *types.OTLP) Registry {
 		lastConfigReloadSuccessGauge:   newOTLPGaugeFrom(meter, configLastReloadSuccessName, "Last config reload success", "ms"),
 		openConnectionsGauge:           newOTLPGaugeFrom(meter, openConnectionsName, "How many open connections exist, by entryPoint and protocol", "1"),
 		tlsCertsNotAfterTimestampGauge: newOTLPGaugeFrom(meter, tlsCertsNotAfterTimestampName, "Certificate expiration timestamp", "ms"),
-	}
+		tlsClientCertRevocationFailuresCounter: newOTLPCounterFrom(meter, tlsClientCertRevocationFailuresTotalName,
+			"How many client certificates were revoked or had an unknown revocation status, partitioned by TLS option and status."),
+		http2AbuseClosedConnectionsCounter: newOTLPCounterFrom(meter, http2AbuseClosedConnectionsTotalName,
+			"How many HTTP/2 connections were closed for abuse, partitioned by entrypoint and reason."),
+		wasmPluginInvocationsCounter: newOTLPCounterFrom(meter, wasmPluginInvocationsTotalName,
+			"How many times the Wasm plugin guests were invoked, partitioned by middleware."),
+		wasmPluginErrorsCounter: newOTLPCounterFrom(meter, wasmPluginErrorsTotalName,
+			"How many Wasm plugin guest invocations failed, partitioned by middleware and reason."),
+		wasmPluginMemoryGauge: newOTLPGaugeFrom(meter, wasmPluginMemoryName,
+			"The linear memory size of the Wasm plugin guests, partitioned by middleware.", "By"),
+		ipListHitsCounter: newOTLPCounterFrom(meter, ipListHitsTotalName,
+			"How many client IPs matched an IP allowlist or denylist, partitioned by middleware and list type."),
+		circuitBreakerStateGauge: newOTLPGaugeFrom(meter, circuitBreakerStateName,
+			"How many circuit breakers are in each state, partitioned by middleware and state.", "1"),
+		circuitBreakerTripsCounter: newOTLPCounterFrom(meter, circuitBreakerTripsTotalName,
+			"How many times the circuit breakers tripped, partitioned by middleware."),
+		circuitBreakerTrippingValueGauge: newOTLPGaugeFrom(meter, circuitBreakerTrippingValueName,
+			"The value of the expression which last tripped a circuit breaker, partitioned by middleware.", "1"),
+	}
+
+	reg.wasmPluginDurationHistogram, _ = NewHistogramWithScale(newOTLPHistogramFrom(meter, wasmPluginDurationName,
+		"How long the Wasm plugin guests took to execute, partitioned by middleware.", "s"), time.Second)
 
 	if config.AddEntryPointsLabels {
 		reg.entryPointReqsCounter = NewCounterWithNoopHeaders(newOTLPCounterFrom(meter, entryPointReqsTotalName,
@@ -27,8 +27,30 @@ const (
 	openConnectionsName         = MetricNamePrefix + "open_connections"
 
 	// TLS.
-	metricsTLSPrefix              = MetricNamePrefix + "tls_"
-	tlsCertsNotAfterTimestampName = metricsTLSPrefix + "certs_not_after"
+	metricsTLSPrefix                         = MetricNamePrefix + "tls_"
+	tlsCertsNotAfterTimestampName            = metricsTLSPrefix + "certs_not_after"
+	tlsClientCertRevocationFailuresTotalName = metricsTLSPrefix + "client_cert_revocation_failures_total"
+
+	// HTTP/2.
+	metricsHTTP2Prefix                   = MetricNamePrefix + "http2_"
+	http2AbuseClosedConnectionsTotalName = metricsHTTP2Prefix + "abuse_closed_connections_total"
+
+	// Wasm plugins.
+	metricsWasmPluginPrefix        = MetricNamePrefix + "wasm_plugin_"
+	wasmPluginInvocationsTotalName = metricsWasmPluginPrefix + "invocations_total"
+	wasmPluginErrorsTotalName      = metricsWasmPluginPrefix + "errors_total"
+	wasmPluginDurationName         = metricsWasmPluginPrefix + "duration_seconds"
+	wasmPluginMemoryName           = metricsWasmPluginPrefix + "memory_bytes"
+
+	// IP lists.
+	metricsIPListPrefix = MetricNamePrefix + "ip_list_"
+	ipListHitsTotalName = metricsIPListPrefix + "hits_total"
+
+	// Circuit breakers.
+	metricsCircuitBreakerPrefix     = MetricNamePrefix + "circuit_breaker_"
+	circuitBreakerStateName         = metricsCircuitBreakerPrefix + "breakers"
+	circuitBreakerTripsTotalName    = metricsCircuitBreakerPrefix + "trips_total"
+	circuitBreakerTrippingValueName = metricsCircuitBreakerPrefix + "tripping_value"
 
 	// entry point.
 	metricEntryPointPrefix        = MetricNamePrefix + "entrypoint_"
@@ -122,6 +144,47 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: tlsCertsNotAfterTimestampName,
 		Help: "Certificate expiration timestamp",
 	}, []string{"cn", "serial", "sans"})
+	tlsClientCertRevocationFailures := newCounterFrom(stdprometheus.CounterOpts{
+		Name: tlsClientCertRevocationFailuresTotalName,
+		Help: "How many client certificates were revoked or had an unknown revocation status, partitioned by TLS option and status.",
+	}, []string{"tls_option", "status"})
+	http2AbuseClosedConnections := newCounterFrom(stdprometheus.CounterOpts{
+		Name: http2AbuseClosedConnectionsTotalName,
+		Help: "How many HTTP/2 connections were closed for abuse, partitioned by entrypoint and reason.",
+	}, []string{"entrypoint", "reason"})
+	wasmPluginInvocations := newCounterFrom(stdprometheus.CounterOpts{
+		Name: wasmPluginInvocationsTotalName,
+		Help: "How many times the Wasm plugin guests were invoked, partitioned by middleware.",
+	}, []string{"middleware"})
+	wasmPluginErrors := newCounterFrom(stdprometheus.CounterOpts{
+		Name: wasmPluginErrorsTotalName,
+		Help: "How many Wasm plugin guest invocations failed, partitioned by middleware and reason.",
+	}, []string{"middleware", "reason"})
+	wasmPluginDurations := newHistogramFrom(stdprometheus.HistogramOpts{
+		Name:    wasmPluginDurationName,
+		Help:    "How long the Wasm plugin guests took to execute, partitioned by middleware.",
+		Buckets: buckets,
+	}, []string{"middleware"})
+	wasmPluginMemory := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: wasmPluginMemoryName,
+		Help: "The linear memory size of the Wasm plugin guests, partitioned by middleware.",
+	}, []string{"middleware"})
+	ipListHits := newCounterFrom(stdprometheus.CounterOpts{
+		Name: ipListHitsTotalName,
+		Help: "How many client IPs matched an IP allowlist or denylist, partitioned by middleware and list type.",
+	}, []string{"middleware", "list"})
+	circuitBreakerState := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: circuitBreakerStateName,
+		Help: "How many circuit breakers are in each state, partitioned by middleware and state.",
+	}, []string{"middleware", "state"})
+	circuitBreakerTrips := newCounterFrom(stdprometheus.CounterOpts{
+		Name: circuitBreakerTripsTotalName,
+		Help: "How many times the circuit breakers tripped, partitioned by middleware.",
+	}, []string{"middleware"})
+	circuitBreakerTrippingValues := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: circuitBreakerTrippingValueName,
+		Help: "The value of the expression which last tripped a circuit breaker, partitioned by middleware.",
+	}, []string{"middleware"})
 	openConnections := newGaugeFrom(stdprometheus.GaugeOpts{
 		Name: openConnectionsName,
 		Help: "How many open connections exist, by entryPoint and protocol",
@@ -131,18 +194,39 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		configReloads.cv,
 		lastConfigReloadSuccess.gv,
 		tlsCertsNotAfterTimestamp.gv,
+		tlsClientCertRevocationFailures.cv,
+		http2AbuseClosedConnections.cv,
+		wasmPluginInvocations.cv,
+		wasmPluginErrors.cv,
+		wasmPluginDurations.hv,
+		wasmPluginMemory.gv,
+		ipListHits.cv,
+		circuitBreakerState.gv,
+		circuitBreakerTrips.cv,
+		circuitBreakerTrippingValues.gv,
 		openConnections.gv,
 	}
 
 	reg := &standardRegistry{
-		epEnabled:                      config.AddEntryPointsLabels,
-		routerEnabled:                  config.AddRoutersLabels,
-		svcEnabled:                     config.AddServicesLabels,
-		configReloadsCounter:           configReloads,
-		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
-		tlsCertsNotAfterTimestampGauge: tlsCertsNotAfterTimestamp,
-		openConnectionsGauge:           openConnections,
-	}
+		epEnabled:                              config.AddEntryPointsLabels,
+		routerEnabled:                          config.AddRoutersLabels,
+		svcEnabled:                             config.AddServicesLabels,
+		configReloadsCounter:                   configReloads,
+		lastConfigReloadSuccessGauge:           lastConfigReloadSuccess,
+		tlsCertsNotAfterTimestampGauge:         tlsCertsNotAfterTimestamp,
+		tlsClientCertRevocationFailuresCounter: tlsClientCertRevocationFailures,
+		http2AbuseClosedConnectionsCounter:     http2AbuseClosedConnections,
+		wasmPluginInvocationsCounter:           wasmPluginInvocations,
+		wasmPluginErrorsCounter:                wasmPluginErrors,
+		wasmPluginMemoryGauge:                  wasmPluginMemory,
+		ipListHitsCounter:                      ipListHits,
+		circuitBreakerStateGauge:               circuitBreakerState,
+		circuitBreakerTripsCounter:             circuitBreakerTrips,
+		circuitBreakerTrippingValueGauge:       circuitBreakerTrippingValues,
+		openConnectionsGauge:                   openConnections,
+	}
+
+	reg.wasmPluginDurationHistogram, _ = NewHistogramWithScale(wasmPluginDurations, time.Second)
 
 	if config.AddEntryPointsLabels {
 		entryPointReqs := newCounterWithHeadersFrom(stdprometheus.CounterOpts{
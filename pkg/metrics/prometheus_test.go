@@ -113,6 +113,46 @@ func TestPrometheus(t *testing.T) {
 		TLSCertsNotAfterTimestampGauge().
 		With("cn", "value", "serial", "value", "sans", "value").
 		Set(float64(time.Now().Unix()))
+	prometheusRegistry.
+		TLSClientCertRevocationFailuresCounter().
+		With("tls_option", "default", "status", "revoked").
+		Add(1)
+	prometheusRegistry.
+		HTTP2AbuseClosedConnectionsCounter().
+		With("entrypoint", "http", "reason", "reset_stream").
+		Add(1)
+	prometheusRegistry.
+		WasmPluginInvocationsCounter().
+		With("middleware", "demo@file").
+		Add(1)
+	prometheusRegistry.
+		WasmPluginErrorsCounter().
+		With("middleware", "demo@file", "reason", "timeout").
+		Add(1)
+	prometheusRegistry.
+		WasmPluginDurationHistogram().
+		With("middleware", "demo@file").
+		Observe(1)
+	prometheusRegistry.
+		WasmPluginMemoryGauge().
+		With("middleware", "demo@file").
+		Set(65536)
+	prometheusRegistry.
+		IPListHitsCounter().
+		With("middleware", "deny@file", "list", "deny").
+		Add(1)
+	prometheusRegistry.
+		CircuitBreakerStateGauge().
+		With("middleware", "cb@file", "state", "tripped").
+		Set(2)
+	prometheusRegistry.
+		CircuitBreakerTripsCounter().
+		With("middleware", "cb@file").
+		Add(1)
+	prometheusRegistry.
+		CircuitBreakerTrippingValueGauge().
+		With("middleware", "cb@file").
+		Set(3)
 
 	prometheusRegistry.
 		EntryPointReqsCounter().
@@ -215,6 +255,81 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildTimestampAssert(t, tlsCertsNotAfterTimestampName),
 		},
+		{
+			name: tlsClientCertRevocationFailuresTotalName,
+			labels: map[string]string{
+				"tls_option": "default",
+				"status":     "revoked",
+			},
+			assert: buildCounterAssert(t, tlsClientCertRevocationFailuresTotalName, 1),
+		},
+		{
+			name: http2AbuseClosedConnectionsTotalName,
+			labels: map[string]string{
+				"entrypoint": "http",
+				"reason":     "reset_stream",
+			},
+			assert: buildCounterAssert(t, http2AbuseClosedConnectionsTotalName, 1),
+		},
+		{
+			name: wasmPluginInvocationsTotalName,
+			labels: map[string]string{
+				"middleware": "demo@file",
+			},
+			assert: buildCounterAssert(t, wasmPluginInvocationsTotalName, 1),
+		},
+		{
+			name: wasmPluginErrorsTotalName,
+			labels: map[string]string{
+				"middleware": "demo@file",
+				"reason":     "timeout",
+			},
+			assert: buildCounterAssert(t, wasmPluginErrorsTotalName, 1),
+		},
+		{
+			name: wasmPluginDurationName,
+			labels: map[string]string{
+				"middleware": "demo@file",
+			},
+			assert: buildHistogramAssert(t, wasmPluginDurationName, 1),
+		},
+		{
+			name: wasmPluginMemoryName,
+			labels: map[string]string{
+				"middleware": "demo@file",
+			},
+			assert: buildGaugeAssert(t, wasmPluginMemoryName, 65536),
+		},
+		{
+			name: ipListHitsTotalName,
+			labels: map[string]string{
+				"middleware": "deny@file",
+				"list":       "deny",
+			},
+			assert: buildCounterAssert(t, ipListHitsTotalName, 1),
+		},
+		{
+			name: circuitBreakerStateName,
+			labels: map[string]string{
+				"middleware": "cb@file",
+				"state":      "tripped",
+			},
+			assert: buildGaugeAssert(t, circuitBreakerStateName, 2),
+		},
+		{
+			name: circuitBreakerTripsTotalName,
+			labels: map[string]string{
+				"middleware": "cb@file",
+			},
+			assert: buildCounterAssert(t, circuitBreakerTripsTotalName, 1),
+		},
+		{
+			name: circuitBreakerTrippingValueName,
+			labels: map[string]string{
+				"middleware": "cb@file",
+			},
+			assert: buildGaugeAssert(t, circuitBreakerTrippingValueName, 3),
+		},
 		{
 			name: entryPointReqsTotalName,
 			labels: map[string]string{
@@ -21,7 +21,21 @@ const (
 	statsdLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	statsdOpenConnectionsName         = "open.connections"
 
-	statsdTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
+	statsdTLSCertsNotAfterTimestampName       = "tls.certs.notAfterTimestamp"
+	statsdTLSClientCertRevocationFailuresName = "tls.clientCerts.revocationFailures.total"
+
+	statsdHTTP2AbuseClosedConnsName = "http2.abuse.closedConnections.total"
+
+	statsdWasmPluginInvocationsName = "wasmPlugin.invocations.total"
+	statsdWasmPluginErrorsName      = "wasmPlugin.errors.total"
+	statsdWasmPluginDurationName    = "wasmPlugin.duration"
+	statsdWasmPluginMemoryName      = "wasmPlugin.memory.bytes"
+
+	statsdIPListHitsName = "ipList.hits.total"
+
+	statsdCircuitBreakerStateName         = "circuitBreaker.breakers"
+	statsdCircuitBreakerTripsName         = "circuitBreaker.trips.total"
+	statsdCircuitBreakerTrippingValueName = "circuitBreaker.trippingValue"
 
 	statsdEntryPointReqsName        = "entrypoint.request.total"
 	statsdEntryPointReqsTLSName     = "entrypoint.request.tls.total"
@@ -58,12 +72,23 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 	}
 
 	registry := &standardRegistry{
-		configReloadsCounter:           statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
-		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
-		tlsCertsNotAfterTimestampGauge: statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
-		openConnectionsGauge:           statsdClient.NewGauge(statsdOpenConnectionsName),
+		configReloadsCounter:                   statsdClient.NewCounter(statsdConfigReloadsName, 1.0),
+		lastConfigReloadSuccessGauge:           statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
+		tlsCertsNotAfterTimestampGauge:         statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
+		tlsClientCertRevocationFailuresCounter: statsdClient.NewCounter(statsdTLSClientCertRevocationFailuresName, 1.0),
+		http2AbuseClosedConnectionsCounter:     statsdClient.NewCounter(statsdHTTP2AbuseClosedConnsName, 1.0),
+		wasmPluginInvocationsCounter:           statsdClient.NewCounter(statsdWasmPluginInvocationsName, 1.0),
+		wasmPluginErrorsCounter:                statsdClient.NewCounter(statsdWasmPluginErrorsName, 1.0),
+		wasmPluginMemoryGauge:                  statsdClient.NewGauge(statsdWasmPluginMemoryName),
+		ipListHitsCounter:                      statsdClient.NewCounter(statsdIPListHitsName, 1.0),
+		circuitBreakerStateGauge:               statsdClient.NewGauge(statsdCircuitBreakerStateName),
+		circuitBreakerTripsCounter:             statsdClient.NewCounter(statsdCircuitBreakerTripsName, 1.0),
+		circuitBreakerTrippingValueGauge:       statsdClient.NewGauge(statsdCircuitBreakerTrippingValueName),
+		openConnectionsGauge:                   statsdClient.NewGauge(statsdOpenConnectionsName),
 	}
 
+	registry.wasmPluginDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdWasmPluginDurationName, 1.0), time.Millisecond)
+
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
 		registry.entryPointReqsCounter = NewCounterWithNoopHeaders(statsdClient.NewCounter(statsdEntryPointReqsName, 1.0))
@@ -82,6 +82,15 @@ const (
 	TraceID = "TraceId"
 	// SpanID is the unique identifier for apache4’s root span (EntryPoint) within a request trace, formatted as a 16-hex digit string.
 	SpanID = "SpanId"
+	// RequestID is the map key used for the request ID, when the entry point generates or propagates one.
+	RequestID = "RequestId"
+
+	// ClientCountry is the map key used for the country code of the client, when a GeoIP database is configured.
+	ClientCountry = "ClientCountry"
+	// ClientContinent is the map key used for the continent code of the client, when a GeoIP database is configured.
+	ClientContinent = "ClientContinent"
+	// ClientASN is the map key used for the autonomous system number of the client, when a GeoIP ASN database is configured.
+	ClientASN = "ClientASN"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
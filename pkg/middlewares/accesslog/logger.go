@@ -19,9 +19,11 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/sirupsen/logrus"
 	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/logs"
 	"github.com/apache4/apache4/v3/pkg/middlewares/capture"
 	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
+	"github.com/apache4/apache4/v3/pkg/middlewares/requestid"
 	apache4tls "github.com/apache4/apache4/v3/pkg/tls"
 	"github.com/apache4/apache4/v3/pkg/types"
 	"go.opentelemetry.io/contrib/bridges/otellogrus"
@@ -230,6 +232,10 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		}
 	}
 
+	if requestID := requestid.GetRequestID(req.Context()); requestID != "" {
+		logDataTable.Core[RequestID] = requestID
+	}
+
 	reqWithDataTable := req.WithContext(context.WithValue(req.Context(), DataTableKey, logDataTable))
 
 	core[RequestCount] = nextRequestCount()
@@ -267,6 +273,20 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 		core[ClientHost] = forwardedFor
 	}
 
+	if db := geoip.DatabaseFromContext(req.Context()); db != nil {
+		if record, err := db.LookupHost(req.RemoteAddr); err == nil {
+			if record.Country != "" {
+				core[ClientCountry] = record.Country
+			}
+			if record.Continent != "" {
+				core[ClientContinent] = record.Continent
+			}
+			if record.ASN != 0 {
+				core[ClientASN] = record.ASN
+			}
+		}
+	}
+
 	ctx := req.Context()
 	capt, err := capture.FromContext(ctx)
 	if err != nil {
@@ -24,8 +24,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/middlewares/capture"
 	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
+	"github.com/apache4/apache4/v3/pkg/middlewares/requestid"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
 	"github.com/apache4/apache4/v3/pkg/types"
 	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
 	"go.opentelemetry.io/otel/attribute"
@@ -706,6 +709,96 @@ func TestLoggerJSON(t *testing.T) {
 	}
 }
 
+func TestLogger_RequestID(t *testing.T) {
+	logFilePath := filepath.Join(t.TempDir(), logFileNameSuffix)
+
+	logger, err := NewHandler(t.Context(), &types.AccessLog{FilePath: logFilePath, Format: JSONFormat})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := logger.Close()
+		require.NoError(t, err)
+	})
+
+	chain := alice.New(func(next http.Handler) (http.Handler, error) {
+		return requestid.New("X-Request-Id", requestid.FormatUUIDv4, true, nil, next)
+	})
+	chain = chain.Append(capture.Wrap)
+	chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+		return observability.WithObservabilityHandler(next, observability.Observability{
+			AccessLogsEnabled: true,
+		}), nil
+	})
+	chain = chain.Append(logger.AliceConstructor())
+
+	handler, err := chain.Then(http.HandlerFunc(logWriterTestHandlerFunc))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, testPath, nil)
+	req.Header.Set("X-Request-Id", "foo")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	logData, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	jsonData := make(map[string]interface{})
+	err = json.Unmarshal(logData, &jsonData)
+	require.NoError(t, err)
+
+	assert.Equal(t, "foo", jsonData[RequestID])
+}
+
+func TestLogger_GeoIP(t *testing.T) {
+	databasePath := filepath.Join(t.TempDir(), "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, databasePath, 24, map[string]map[string]any{
+		"192.0.2.0/24": {
+			"country":                  map[string]any{"iso_code": "FR"},
+			"continent":                map[string]any{"code": "EU"},
+			"autonomous_system_number": uint32(64496),
+		},
+	})
+
+	db, err := geoip.NewDatabase(t.Context(), databasePath)
+	require.NoError(t, err)
+
+	logFilePath := filepath.Join(t.TempDir(), logFileNameSuffix)
+
+	logger, err := NewHandler(t.Context(), &types.AccessLog{FilePath: logFilePath, Format: JSONFormat})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := logger.Close()
+		require.NoError(t, err)
+	})
+
+	chain := alice.New(geoip.WrapHandler(db))
+	chain = chain.Append(capture.Wrap)
+	chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+		return observability.WithObservabilityHandler(next, observability.Observability{
+			AccessLogsEnabled: true,
+		}), nil
+	})
+	chain = chain.Append(logger.AliceConstructor())
+
+	handler, err := chain.Then(http.HandlerFunc(logWriterTestHandlerFunc))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, testPath, nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	logData, err := os.ReadFile(logFilePath)
+	require.NoError(t, err)
+
+	jsonData := make(map[string]interface{})
+	err = json.Unmarshal(logData, &jsonData)
+	require.NoError(t, err)
+
+	assert.Equal(t, "FR", jsonData[ClientCountry])
+	assert.Equal(t, "EU", jsonData[ClientContinent])
+	assert.InDelta(t, 64496, jsonData[ClientASN], 0)
+}
+
 func TestLogger_AbortedRequest(t *testing.T) {
 	expected := map[string]func(t *testing.T, value interface{}){
 		RequestContentSize:             assertFloat64(0),
@@ -0,0 +1,217 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/vulcand/oxy/v2/memmetrics"
+)
+
+// state is the state of a breaker.
+type state int
+
+const (
+	// stateStandby is the state of a breaker letting all the requests through, while watching the responses.
+	stateStandby state = iota
+	// stateTripped is the state of a breaker serving the fallback to all the requests.
+	stateTripped
+	// stateRecovering is the state of a breaker letting a growing share of the requests through.
+	stateRecovering
+)
+
+var states = []state{stateStandby, stateTripped, stateRecovering}
+
+func (s state) String() string {
+	switch s {
+	case stateTripped:
+		return "tripped"
+	case stateRecovering:
+		return "recovering"
+	default:
+		return "standby"
+	}
+}
+
+// settings are the settings shared by all the breakers of a circuit breaker middleware.
+type settings struct {
+	condition        condition
+	checkPeriod      time.Duration
+	fallbackDuration time.Duration
+	recoveryDuration time.Duration
+
+	// onTransition is called, with the breaker lock held, each time a breaker changes state.
+	onTransition func(from, to state)
+	// onTrip is called, with the breaker lock held, each time a breaker trips.
+	onTrip func(value float64)
+}
+
+// breaker is a circuit breaker state machine.
+// It starts in the standby state, and trips once its condition matches the metrics of the responses.
+// Once tripped, it serves the fallback for the fallback duration,
+// and then lets a linearly growing share of the requests through for the recovery duration,
+// unless the condition matches again.
+type breaker struct {
+	settings *settings
+
+	mu        sync.Mutex
+	metrics   *memmetrics.RTMetrics
+	state     state
+	until     time.Time
+	nextCheck time.Time
+	ratio     *ratioController
+	trips     uint64
+	lastTrip  *runtime.CircuitBreakerTrip
+	lastUsed  time.Time
+	evicted   bool
+}
+
+func newBreaker(settings *settings, now time.Time) (*breaker, error) {
+	metrics, err := memmetrics.NewRTMetrics()
+	if err != nil {
+		return nil, err
+	}
+
+	return &breaker{
+		settings: settings,
+		metrics:  metrics,
+		lastUsed: now,
+	}, nil
+}
+
+// allow returns whether the request can be forwarded, or whether the fallback must be served.
+func (b *breaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastUsed = now
+	b.advance(now)
+
+	switch b.state {
+	case stateTripped:
+		return false
+	case stateRecovering:
+		return b.ratio.allowRequest(now)
+	default:
+		return true
+	}
+}
+
+// record records the response of a forwarded request,
+// and trips the breaker if its condition matches, at most once per check period.
+func (b *breaker) record(statusCode int, latency time.Duration, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.metrics.Record(statusCode, latency)
+
+	if now.Before(b.nextCheck) {
+		return
+	}
+	b.nextCheck = now.Add(b.settings.checkPeriod)
+
+	if b.state == stateTripped {
+		return
+	}
+
+	matched, value := b.settings.condition(b.metrics)
+	if !matched {
+		return
+	}
+
+	b.trips++
+	b.lastTrip = &runtime.CircuitBreakerTrip{Time: now, Value: value}
+	b.setState(stateTripped, now.Add(b.settings.fallbackDuration))
+	b.metrics.Reset()
+
+	if !b.evicted && b.settings.onTrip != nil {
+		b.settings.onTrip(value)
+	}
+}
+
+// status returns the current status of the breaker.
+func (b *breaker) status(key string, now time.Time) runtime.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(now)
+
+	status := runtime.CircuitBreakerStatus{
+		Key:   key,
+		State: b.state.String(),
+		Trips: b.trips,
+	}
+
+	if b.lastTrip != nil {
+		lastTrip := *b.lastTrip
+		status.LastTrip = &lastTrip
+	}
+
+	return status
+}
+
+// evict marks the breaker as evicted, so that it no longer reports its transitions, and returns its state.
+func (b *breaker) evict() state {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.evicted = true
+
+	return b.state
+}
+
+// advance applies the transitions which only depend on the time.
+func (b *breaker) advance(now time.Time) {
+	if b.state == stateTripped && !now.Before(b.until) {
+		b.setState(stateRecovering, now.Add(b.settings.recoveryDuration))
+		b.ratio = newRatioController(b.settings.recoveryDuration, now)
+	}
+
+	if b.state == stateRecovering && now.After(b.until) {
+		b.setState(stateStandby, time.Time{})
+		b.ratio = nil
+	}
+}
+
+func (b *breaker) setState(s state, until time.Time) {
+	from := b.state
+	b.state = s
+	b.until = until
+
+	if !b.evicted && from != s && b.settings.onTransition != nil {
+		b.settings.onTransition(from, s)
+	}
+}
+
+// ratioController lets a linearly growing share of the requests through:
+//
+//	allowedRatio = 0.5 * (now - start) / duration
+//
+// Once half of the requests are allowed, the allowed and denied requests are in equilibrium,
+// and all the following requests are allowed.
+type ratioController struct {
+	duration time.Duration
+	start    time.Time
+	allowed  int
+	denied   int
+}
+
+func newRatioController(duration time.Duration, start time.Time) *ratioController {
+	return &ratioController{
+		duration: duration,
+		start:    start,
+	}
+}
+
+func (r *ratioController) allowRequest(now time.Time) bool {
+	target := 0.5 * float64(now.Sub(r.start)) / float64(r.duration)
+
+	// Would the target ratio still be satisfied if this request was allowed?
+	if float64(r.allowed+1)/float64(r.allowed+1+r.denied) < target {
+		r.allowed++
+		return true
+	}
+
+	r.denied++
+	return false
+}
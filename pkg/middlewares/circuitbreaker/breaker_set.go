@@ -0,0 +1,107 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerSet is a bounded set of breakers, keyed by server or by request key.
+type breakerSet struct {
+	settings    *settings
+	maxBreakers int
+	// onEvict is called, with the set lock held, with the state of each evicted breaker.
+	onEvict func(s state)
+	// onCreate is called, with the set lock held, each time a breaker is created.
+	onCreate func()
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func newBreakerSet(settings *settings, maxBreakers int) *breakerSet {
+	return &breakerSet{
+		settings:    settings,
+		maxBreakers: maxBreakers,
+		breakers:    make(map[string]*breaker),
+	}
+}
+
+// get returns the breaker of the given key, creating it if needed.
+// When the set is full, the least recently used standby breaker is evicted to make room,
+// or the least recently used breaker when none is in standby.
+func (s *breakerSet) get(key string, now time.Time) (*breaker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.breakers[key]; ok {
+		return b, nil
+	}
+
+	if len(s.breakers) >= s.maxBreakers {
+		s.evictOne()
+	}
+
+	b, err := newBreaker(s.settings, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.breakers[key] = b
+	if s.onCreate != nil {
+		s.onCreate()
+	}
+
+	return b, nil
+}
+
+// all returns the breakers of the set by key.
+func (s *breakerSet) all() map[string]*breaker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	breakers := make(map[string]*breaker, len(s.breakers))
+	for key, b := range s.breakers {
+		breakers[key] = b
+	}
+
+	return breakers
+}
+
+// evictOne evicts one breaker, the set lock being held.
+// The linear scan only happens when the set is full.
+func (s *breakerSet) evictOne() {
+	var (
+		found         bool
+		victimKey     string
+		victimUsed    time.Time
+		victimStandby bool
+	)
+
+	for key, b := range s.breakers {
+		b.mu.Lock()
+		standby, used := b.state == stateStandby, b.lastUsed
+		b.mu.Unlock()
+
+		if found {
+			if victimStandby && !standby {
+				continue
+			}
+			if victimStandby == standby && !used.Before(victimUsed) {
+				continue
+			}
+		}
+
+		found, victimKey, victimUsed, victimStandby = true, key, used, standby
+	}
+
+	if !found {
+		return
+	}
+
+	evicted := s.breakers[victimKey].evict()
+	delete(s.breakers, victimKey)
+
+	if s.onEvict != nil {
+		s.onEvict(evicted)
+	}
+}
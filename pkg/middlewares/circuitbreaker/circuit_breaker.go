@@ -1,70 +1,146 @@
 package circuitbreaker
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"net"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
-	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares"
 	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
-	"github.com/vulcand/oxy/v2/cbreaker"
+	ptypes "github.com/apache4/paerser/types"
 )
 
 const typeName = "CircuitBreaker"
 
+const (
+	defaultCheckPeriod      = 100 * time.Millisecond
+	defaultFallbackDuration = 10 * time.Second
+	defaultRecoveryDuration = 10 * time.Second
+	defaultMaxBreakers      = 1000
+)
+
+type serverScopeContextKey struct{}
+
+// breakerCounts counts the breakers by state, per middleware name.
+// As a middleware is instantiated for each of the routers using it,
+// the state gauge reports the breakers of all its instances, which are counted until their context is done.
+var breakerCounts = struct {
+	sync.Mutex
+	states map[string]map[state]int
+}{states: make(map[string]map[state]int)}
+
 type circuitBreaker struct {
-	circuitBreaker *cbreaker.CircuitBreaker
-	name           string
+	next         http.Handler
+	name         string
+	logger       *zerolog.Logger
+	expression   string
+	responseCode int
+	settings     *settings
+	now          func() time.Time
+
+	// breaker is the single breaker of the middleware, when it is not scoped.
+	breaker *breaker
+	// breakers are the breakers of the middleware, keyed by server or by request key, when it is scoped.
+	breakers    *breakerSet
+	serverScope bool
+	requestKey  func(req *http.Request) string
+
+	// states counts the breakers of this instance by state, and released whether they are no longer counted in breakerCounts,
+	// which guards both.
+	states         map[state]int
+	released       bool
+	stateGauge     gokitmetrics.Gauge
+	trips          gokitmetrics.Counter
+	trippingValues gokitmetrics.Gauge
 }
 
 // New creates a new circuit breaker middleware.
-func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, name string) (http.Handler, error) {
+// When info is not nil, the state of the breakers is exposed through it.
+func New(ctx context.Context, next http.Handler, confCircuitBreaker dynamic.CircuitBreaker, metricsRegistry metrics.Registry, info *runtime.MiddlewareInfo, name string) (http.Handler, error) {
 	expression := confCircuitBreaker.Expression
 
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug().Msg("Creating middleware")
 	logger.Debug().Msgf("Setting up with expression: %s", expression)
 
-	responseCode := confCircuitBreaker.ResponseCode
+	cond, err := parseExpression(expression)
+	if err != nil {
+		return nil, err
+	}
 
-	cbOpts := []cbreaker.Option{
-		cbreaker.Fallback(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-			observability.SetStatusErrorf(req.Context(), "blocked by circuit-breaker (%q)", expression)
-			rw.WriteHeader(responseCode)
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
 
-			if _, err := rw.Write([]byte(http.StatusText(responseCode))); err != nil {
-				log.Ctx(req.Context()).Error().Err(err).Send()
-			}
-		})),
-		cbreaker.Logger(logs.NewOxyWrapper(*logger)),
-		cbreaker.Verbose(logger.GetLevel() == zerolog.TraceLevel),
+	cb := &circuitBreaker{
+		next:           next,
+		name:           name,
+		logger:         logger,
+		expression:     expression,
+		responseCode:   confCircuitBreaker.ResponseCode,
+		now:            time.Now,
+		states:         make(map[state]int),
+		stateGauge:     metricsRegistry.CircuitBreakerStateGauge(),
+		trips:          metricsRegistry.CircuitBreakerTripsCounter().With("middleware", name),
+		trippingValues: metricsRegistry.CircuitBreakerTrippingValueGauge().With("middleware", name),
 	}
 
-	if confCircuitBreaker.CheckPeriod > 0 {
-		cbOpts = append(cbOpts, cbreaker.CheckPeriod(time.Duration(confCircuitBreaker.CheckPeriod)))
+	cb.settings = &settings{
+		condition:        cond,
+		checkPeriod:      durationOrDefault(confCircuitBreaker.CheckPeriod, defaultCheckPeriod),
+		fallbackDuration: durationOrDefault(confCircuitBreaker.FallbackDuration, defaultFallbackDuration),
+		recoveryDuration: durationOrDefault(confCircuitBreaker.RecoveryDuration, defaultRecoveryDuration),
+		onTransition:     cb.transition,
+		onTrip:           cb.trip,
 	}
 
-	if confCircuitBreaker.FallbackDuration > 0 {
-		cbOpts = append(cbOpts, cbreaker.FallbackDuration(time.Duration(confCircuitBreaker.FallbackDuration)))
+	if err := cb.setupScope(confCircuitBreaker.Scope); err != nil {
+		return nil, err
 	}
 
-	if confCircuitBreaker.RecoveryDuration > 0 {
-		cbOpts = append(cbOpts, cbreaker.RecoveryDuration(time.Duration(confCircuitBreaker.RecoveryDuration)))
+	if cb.breakers == nil {
+		cb.breaker, err = newBreaker(cb.settings, cb.now())
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	oxyCircuitBreaker, err := cbreaker.New(next, expression, cbOpts...)
-	if err != nil {
-		return nil, err
+	cb.register(ctx)
+
+	if info != nil {
+		info.AddCircuitBreakers(cb.status)
 	}
 
-	return &circuitBreaker{
-		circuitBreaker: oxyCircuitBreaker,
-		name:           name,
-	}, nil
+	return cb, nil
+}
+
+// WrapServerHandler wraps the handler of a load-balanced server,
+// so that the server scoped circuit breakers of the request have a breaker for this server.
+func WrapServerHandler(next http.Handler, server string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		cbs, _ := req.Context().Value(serverScopeContextKey{}).([]*circuitBreaker)
+
+		handler := next
+		for _, cb := range slices.Backward(cbs) {
+			inner := handler
+			handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				cb.serveKey(rw, req, server, inner)
+			})
+		}
+
+		handler.ServeHTTP(rw, req)
+	})
 }
 
 func (c *circuitBreaker) GetTracingInformation() (string, string) {
@@ -72,5 +148,222 @@ func (c *circuitBreaker) GetTracingInformation() (string, string) {
 }
 
 func (c *circuitBreaker) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	c.circuitBreaker.ServeHTTP(rw, req)
+	switch {
+	case c.serverScope:
+		// The breakers are applied by the load-balanced servers handlers, once the server is chosen.
+		cbs, _ := req.Context().Value(serverScopeContextKey{}).([]*circuitBreaker)
+		ctx := context.WithValue(req.Context(), serverScopeContextKey{}, append(slices.Clip(cbs), c))
+		c.next.ServeHTTP(rw, req.WithContext(ctx))
+
+	case c.requestKey != nil:
+		c.serveKey(rw, req, c.requestKey(req), c.next)
+
+	default:
+		c.serve(rw, req, c.breaker, c.next)
+	}
+}
+
+func (c *circuitBreaker) serveKey(rw http.ResponseWriter, req *http.Request, key string, next http.Handler) {
+	b, err := c.breakers.get(key, c.now())
+	if err != nil {
+		log.Ctx(req.Context()).Error().Err(err).Msg("Error while creating circuit breaker")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	c.serve(rw, req, b, next)
+}
+
+func (c *circuitBreaker) serve(rw http.ResponseWriter, req *http.Request, b *breaker, next http.Handler) {
+	if !b.allow(c.now()) {
+		c.fallback(rw, req)
+		return
+	}
+
+	start := c.now()
+	recorder := &statusCodeRecorder{ResponseWriter: rw, status: http.StatusOK}
+
+	next.ServeHTTP(recorder, req)
+
+	now := c.now()
+	b.record(recorder.status, now.Sub(start), now)
+}
+
+func (c *circuitBreaker) fallback(rw http.ResponseWriter, req *http.Request) {
+	observability.SetStatusErrorf(req.Context(), "blocked by circuit-breaker (%q)", c.expression)
+	rw.WriteHeader(c.responseCode)
+
+	if _, err := rw.Write([]byte(http.StatusText(c.responseCode))); err != nil {
+		log.Ctx(req.Context()).Error().Err(err).Send()
+	}
+}
+
+func (c *circuitBreaker) setupScope(scope *dynamic.CircuitBreakerScope) error {
+	if scope == nil {
+		return nil
+	}
+
+	var keys int
+	if scope.Server {
+		keys++
+	}
+	if scope.RequestHeaderName != "" {
+		keys++
+	}
+	if scope.RequestHost {
+		keys++
+	}
+
+	switch {
+	case keys == 0:
+		return nil
+	case keys > 1:
+		return errors.New("server, requestHeaderName and requestHost scope options are mutually exclusive")
+	case scope.MaxBreakers < 0:
+		return errors.New("maxBreakers must be greater than or equal to zero")
+	}
+
+	maxBreakers := scope.MaxBreakers
+	if maxBreakers == 0 {
+		maxBreakers = defaultMaxBreakers
+	}
+
+	c.breakers = newBreakerSet(c.settings, maxBreakers)
+	c.breakers.onCreate = func() { c.count(stateStandby, 1) }
+	c.breakers.onEvict = func(s state) { c.count(s, -1) }
+
+	switch {
+	case scope.Server:
+		c.serverScope = true
+	case scope.RequestHost:
+		c.requestKey = func(req *http.Request) string { return req.Host }
+	default:
+		headerName := scope.RequestHeaderName
+		c.requestKey = func(req *http.Request) string { return req.Header.Get(headerName) }
+	}
+
+	return nil
+}
+
+// status returns the status of the breakers of the middleware.
+func (c *circuitBreaker) status() []runtime.CircuitBreakerStatus {
+	now := c.now()
+
+	if c.breakers == nil {
+		return []runtime.CircuitBreakerStatus{c.breaker.status("", now)}
+	}
+
+	var statuses []runtime.CircuitBreakerStatus
+	for key, b := range c.breakers.all() {
+		statuses = append(statuses, b.status(key, now))
+	}
+
+	return statuses
+}
+
+func (c *circuitBreaker) transition(from, to state) {
+	c.logger.Debug().Msgf("Circuit breaker state changed from %s to %s", from, to)
+
+	breakerCounts.Lock()
+	defer breakerCounts.Unlock()
+
+	c.countLocked(from, -1)
+	c.countLocked(to, 1)
+	c.updateStateGaugeLocked()
+}
+
+func (c *circuitBreaker) trip(value float64) {
+	c.trips.Add(1)
+	c.trippingValues.Set(value)
+}
+
+func (c *circuitBreaker) count(s state, delta int) {
+	breakerCounts.Lock()
+	defer breakerCounts.Unlock()
+
+	c.countLocked(s, delta)
+	c.updateStateGaugeLocked()
+}
+
+// register counts the breakers of this instance with the ones of the other instances of the middleware,
+// until the given context is done.
+func (c *circuitBreaker) register(ctx context.Context) {
+	breakerCounts.Lock()
+	defer breakerCounts.Unlock()
+
+	if c.breaker != nil {
+		c.countLocked(stateStandby, 1)
+	}
+	c.updateStateGaugeLocked()
+
+	context.AfterFunc(ctx, c.release)
+}
+
+// release stops counting the breakers of this instance, which is no longer used.
+func (c *circuitBreaker) release() {
+	breakerCounts.Lock()
+	defer breakerCounts.Unlock()
+
+	for s, n := range c.states {
+		c.countLocked(s, -n)
+	}
+	c.released = true
+	c.updateStateGaugeLocked()
+
+	for _, n := range breakerCounts.states[c.name] {
+		if n != 0 {
+			return
+		}
+	}
+	delete(breakerCounts.states, c.name)
+}
+
+func (c *circuitBreaker) countLocked(s state, delta int) {
+	c.states[s] += delta
+
+	if c.released {
+		return
+	}
+
+	if breakerCounts.states[c.name] == nil {
+		breakerCounts.states[c.name] = make(map[state]int)
+	}
+	breakerCounts.states[c.name][s] += delta
+}
+
+func (c *circuitBreaker) updateStateGaugeLocked() {
+	for _, s := range states {
+		c.stateGauge.With("middleware", c.name, "state", s.String()).Set(float64(breakerCounts.states[c.name][s]))
+	}
+}
+
+func durationOrDefault(d ptypes.Duration, defaultDuration time.Duration) time.Duration {
+	if d > 0 {
+		return time.Duration(d)
+	}
+
+	return defaultDuration
+}
+
+type statusCodeRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader captures the status code for later retrieval.
+func (s *statusCodeRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack hijacks the connection.
+func (s *statusCodeRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return s.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (s *statusCodeRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
 }
@@ -0,0 +1,387 @@
+package circuitbreaker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/vulcand/oxy/v2/memmetrics"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.CircuitBreaker
+		expectedErr bool
+	}{
+		{
+			desc:   "expression",
+			config: dynamic.CircuitBreaker{Expression: "NetworkErrorRatio() > 0.5"},
+		},
+		{
+			desc:        "invalid expression",
+			config:      dynamic.CircuitBreaker{Expression: "Foo() > 0.5"},
+			expectedErr: true,
+		},
+		{
+			desc: "request host scope",
+			config: dynamic.CircuitBreaker{
+				Expression: "NetworkErrorRatio() > 0.5",
+				Scope:      &dynamic.CircuitBreakerScope{RequestHost: true},
+			},
+		},
+		{
+			desc: "mutually exclusive scopes",
+			config: dynamic.CircuitBreaker{
+				Expression: "NetworkErrorRatio() > 0.5",
+				Scope:      &dynamic.CircuitBreakerScope{Server: true, RequestHeaderName: "X-Tenant"},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "negative max breakers",
+			config: dynamic.CircuitBreaker{
+				Expression: "NetworkErrorRatio() > 0.5",
+				Scope:      &dynamic.CircuitBreakerScope{RequestHost: true, MaxBreakers: -1},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+
+			_, err := New(context.Background(), next, test.config, nil, nil, "cb")
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseExpression(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		expression    string
+		codes         []int
+		expectedMatch bool
+		expectedValue float64
+	}{
+		{
+			desc:          "network error ratio",
+			expression:    "NetworkErrorRatio() > 0.5",
+			codes:         []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			expectedMatch: true,
+			expectedValue: 2.0 / 3.0,
+		},
+		{
+			desc:          "response code ratio not matching",
+			expression:    "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+			codes:         []int{http.StatusInternalServerError, http.StatusOK},
+			expectedMatch: false,
+			expectedValue: 0.5,
+		},
+		{
+			desc:          "integer constant",
+			expression:    "ResponseCodeRatio(500, 600, 0, 600) >= 1",
+			codes:         []int{http.StatusInternalServerError},
+			expectedMatch: true,
+			expectedValue: 1,
+		},
+		{
+			desc:          "or with the value of the matching operand",
+			expression:    "NetworkErrorRatio() > 0.5 || ResponseCodeRatio(500, 600, 0, 600) > 0.25",
+			codes:         []int{http.StatusInternalServerError, http.StatusOK},
+			expectedMatch: true,
+			expectedValue: 0.5,
+		},
+		{
+			desc:          "and with the value of the first operand",
+			expression:    "ResponseCodeRatio(500, 600, 0, 600) > 0.25 && NetworkErrorRatio() < 0.5",
+			codes:         []int{http.StatusInternalServerError, http.StatusOK},
+			expectedMatch: true,
+			expectedValue: 0.5,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cond, err := parseExpression(test.expression)
+			require.NoError(t, err)
+
+			m, err := memmetrics.NewRTMetrics()
+			require.NoError(t, err)
+
+			for _, code := range test.codes {
+				m.Record(code, time.Millisecond)
+			}
+
+			matched, value := cond(m)
+			assert.Equal(t, test.expectedMatch, matched)
+			assert.InDelta(t, test.expectedValue, value, 0.0001)
+		})
+	}
+}
+
+func TestCircuitBreaker_states(t *testing.T) {
+	registry := &circuitBreakerTestRegistry{
+		Registry:       metrics.NewVoidRegistry(),
+		trips:          &testhelpers.CollectingCounter{},
+		trippingValues: &testhelpers.CollectingGauge{},
+	}
+	info := &runtime.MiddlewareInfo{}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	handler, err := New(context.Background(), next, dynamic.CircuitBreaker{
+		Expression:       "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+		CheckPeriod:      ptypes.Duration(100 * time.Millisecond),
+		FallbackDuration: ptypes.Duration(10 * time.Second),
+		RecoveryDuration: ptypes.Duration(10 * time.Second),
+		ResponseCode:     http.StatusServiceUnavailable,
+	}, registry, info, "cb")
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	handler.(*circuitBreaker).now = clock.Now
+	start := clock.now
+
+	assert.Equal(t, []runtime.CircuitBreakerStatus{{State: "standby"}}, info.GetCircuitBreakersStatus())
+
+	// The first failing response trips the breaker.
+	assert.Equal(t, http.StatusInternalServerError, serve(handler, ""))
+	assert.Equal(t, []runtime.CircuitBreakerStatus{{
+		State:    "tripped",
+		Trips:    1,
+		LastTrip: &runtime.CircuitBreakerTrip{Time: start, Value: 1},
+	}}, info.GetCircuitBreakersStatus())
+	assert.InDelta(t, 1, registry.trips.CounterValue, 0)
+	assert.InDelta(t, 1, registry.trippingValues.GaugeValue, 0)
+
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, ""))
+
+	// The recovering breaker first denies the requests, and lets a growing share of them through.
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, ""))
+	assert.Equal(t, "recovering", info.GetCircuitBreakersStatus()[0].State)
+
+	clock.Advance(10*time.Second + time.Millisecond)
+	assert.Equal(t, "standby", info.GetCircuitBreakersStatus()[0].State)
+	assert.Equal(t, uint64(1), info.GetCircuitBreakersStatus()[0].Trips)
+}
+
+func TestCircuitBreaker_requestHeaderScope(t *testing.T) {
+	info := &runtime.MiddlewareInfo{}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("X-Tenant") == "failing" {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	handler, err := New(context.Background(), next, dynamic.CircuitBreaker{
+		Expression:   "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+		ResponseCode: http.StatusServiceUnavailable,
+		Scope:        &dynamic.CircuitBreakerScope{RequestHeaderName: "X-Tenant", MaxBreakers: 2},
+	}, nil, info, "cb")
+	require.NoError(t, err)
+
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	handler.(*circuitBreaker).now = clock.Now
+
+	assert.Equal(t, http.StatusInternalServerError, serve(handler, "failing"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, "failing"))
+	assert.Equal(t, http.StatusOK, serve(handler, "healthy"))
+
+	statuses := info.GetCircuitBreakersStatus()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "failing", statuses[0].Key)
+	assert.Equal(t, "tripped", statuses[0].State)
+	assert.Equal(t, "healthy", statuses[1].Key)
+	assert.Equal(t, "standby", statuses[1].State)
+
+	// The set being full, the least recently used standby breaker is evicted, rather than the tripped one.
+	clock.Advance(time.Second)
+	assert.Equal(t, http.StatusOK, serve(handler, "other"))
+
+	statuses = info.GetCircuitBreakersStatus()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "failing", statuses[0].Key)
+	assert.Equal(t, "other", statuses[1].Key)
+}
+
+func TestWrapServerHandler(t *testing.T) {
+	info := &runtime.MiddlewareInfo{}
+
+	servers := map[string]http.Handler{}
+	for _, server := range []string{"http://failing", "http://healthy"} {
+		servers[server] = WrapServerHandler(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			if server == "http://failing" {
+				rw.WriteHeader(http.StatusInternalServerError)
+			}
+		}), server)
+	}
+
+	// The load balancer is simulated by choosing the server from a header.
+	loadBalancer := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		servers[req.Header.Get("X-Tenant")].ServeHTTP(rw, req)
+	})
+
+	handler, err := New(context.Background(), loadBalancer, dynamic.CircuitBreaker{
+		Expression:   "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+		ResponseCode: http.StatusServiceUnavailable,
+		Scope:        &dynamic.CircuitBreakerScope{Server: true},
+	}, nil, info, "cb")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, serve(handler, "http://failing"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(handler, "http://failing"))
+	assert.Equal(t, http.StatusOK, serve(handler, "http://healthy"))
+
+	statuses := info.GetCircuitBreakersStatus()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "http://failing", statuses[0].Key)
+	assert.Equal(t, "tripped", statuses[0].State)
+	assert.Equal(t, "http://healthy", statuses[1].Key)
+	assert.Equal(t, "standby", statuses[1].State)
+}
+
+func TestCircuitBreaker_stateGauge(t *testing.T) {
+	gauge := &labeledGauge{mu: &sync.Mutex{}, values: make(map[string]float64)}
+	registry := &stateGaugeTestRegistry{Registry: metrics.NewVoidRegistry(), stateGauge: gauge}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	config := dynamic.CircuitBreaker{
+		Expression:   "ResponseCodeRatio(500, 600, 0, 600) > 0.5",
+		ResponseCode: http.StatusServiceUnavailable,
+	}
+
+	// The middleware is instantiated for each of the routers using it.
+	ctx, cancel := context.WithCancel(t.Context())
+	first, err := New(ctx, next, config, registry, nil, "stateGauge@test")
+	require.NoError(t, err)
+
+	second, err := New(t.Context(), next, config, registry, nil, "stateGauge@test")
+	require.NoError(t, err)
+
+	assert.InDelta(t, 2, gauge.value("stateGauge@test", "standby"), 0)
+
+	assert.Equal(t, http.StatusInternalServerError, serve(first, ""))
+	assert.InDelta(t, 1, gauge.value("stateGauge@test", "standby"), 0)
+	assert.InDelta(t, 1, gauge.value("stateGauge@test", "tripped"), 0)
+
+	assert.Equal(t, http.StatusInternalServerError, serve(second, ""))
+	assert.InDelta(t, 0, gauge.value("stateGauge@test", "standby"), 0)
+	assert.InDelta(t, 2, gauge.value("stateGauge@test", "tripped"), 0)
+
+	// The breakers of an instance are no longer counted once its context is done.
+	cancel()
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		assert.InDelta(c, 1, gauge.value("stateGauge@test", "tripped"), 0)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func serve(handler http.Handler, tenant string) int {
+	req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+	req.Header.Set("X-Tenant", tenant)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	return recorder.Code
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+type circuitBreakerTestRegistry struct {
+	metrics.Registry
+
+	trips          *testhelpers.CollectingCounter
+	trippingValues *testhelpers.CollectingGauge
+}
+
+func (r *circuitBreakerTestRegistry) CircuitBreakerTripsCounter() gokitmetrics.Counter {
+	return r.trips
+}
+
+func (r *circuitBreakerTestRegistry) CircuitBreakerTrippingValueGauge() gokitmetrics.Gauge {
+	return r.trippingValues
+}
+
+type stateGaugeTestRegistry struct {
+	metrics.Registry
+
+	stateGauge gokitmetrics.Gauge
+}
+
+func (r *stateGaugeTestRegistry) CircuitBreakerStateGauge() gokitmetrics.Gauge {
+	return r.stateGauge
+}
+
+// labeledGauge is a gauge holding a value per label values.
+type labeledGauge struct {
+	mu          *sync.Mutex
+	values      map[string]float64
+	labelValues []string
+}
+
+func (g *labeledGauge) With(labelValues ...string) gokitmetrics.Gauge {
+	return &labeledGauge{mu: g.mu, values: g.values, labelValues: append(slices.Clone(g.labelValues), labelValues...)}
+}
+
+func (g *labeledGauge) Set(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.values[strings.Join(g.labelValues, ",")] = value
+}
+
+func (g *labeledGauge) Add(delta float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.values[strings.Join(g.labelValues, ",")] += delta
+}
+
+func (g *labeledGauge) value(middleware, state string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.values["middleware,"+middleware+",state,"+state]
+}
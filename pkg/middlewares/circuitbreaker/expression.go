@@ -0,0 +1,133 @@
+package circuitbreaker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vulcand/oxy/v2/memmetrics"
+	"github.com/vulcand/predicate"
+)
+
+// condition evaluates the tripping expression against the metrics of a breaker,
+// and returns whether it matches, along with the value of the metric which made it match.
+type condition func(m *memmetrics.RTMetrics) (bool, float64)
+
+// mapper returns the value of a metric.
+type mapper func(m *memmetrics.RTMetrics) float64
+
+// parseExpression parses the tripping expression of the circuit breaker.
+func parseExpression(expression string) (condition, error) {
+	parser, err := predicate.NewParser(predicate.Def{
+		Operators: predicate.Operators{
+			AND: and,
+			OR:  or,
+			EQ:  comparison("eq", func(a, b float64) bool { return a == b }),
+			NEQ: comparison("neq", func(a, b float64) bool { return a != b }),
+			LT:  comparison("lt", func(a, b float64) bool { return a < b }),
+			LE:  comparison("le", func(a, b float64) bool { return a <= b }),
+			GT:  comparison("gt", func(a, b float64) bool { return a > b }),
+			GE:  comparison("ge", func(a, b float64) bool { return a >= b }),
+		},
+		Functions: map[string]any{
+			"LatencyAtQuantileMS": latencyAtQuantileMS,
+			"NetworkErrorRatio":   networkErrorRatio,
+			"ResponseCodeRatio":   responseCodeRatio,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := parser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	cond, ok := out.(condition)
+	if !ok {
+		return nil, fmt.Errorf("expected a condition, got %T", out)
+	}
+
+	return cond, nil
+}
+
+func latencyAtQuantileMS(quantile float64) mapper {
+	return func(m *memmetrics.RTMetrics) float64 {
+		h, err := m.LatencyHistogram()
+		if err != nil {
+			return 0
+		}
+
+		// The latency is truncated to the millisecond, as it used to be compared to an integer.
+		return float64(h.LatencyAtQuantile(quantile) / time.Millisecond)
+	}
+}
+
+func networkErrorRatio() mapper {
+	return func(m *memmetrics.RTMetrics) float64 {
+		return m.NetworkErrorRatio()
+	}
+}
+
+func responseCodeRatio(startA, endA, startB, endB int) mapper {
+	return func(m *memmetrics.RTMetrics) float64 {
+		return m.ResponseCodeRatio(startA, endA, startB, endB)
+	}
+}
+
+// or matches when one of the conditions matches, with the value of the first matching one.
+func or(conds ...condition) condition {
+	return func(m *memmetrics.RTMetrics) (bool, float64) {
+		for _, cond := range conds {
+			if matched, value := cond(m); matched {
+				return true, value
+			}
+		}
+
+		return false, 0
+	}
+}
+
+// and matches when all the conditions match, with the value of the first one.
+func and(conds ...condition) condition {
+	return func(m *memmetrics.RTMetrics) (bool, float64) {
+		var first float64
+		for i, cond := range conds {
+			matched, value := cond(m)
+			if !matched {
+				return false, 0
+			}
+
+			if i == 0 {
+				first = value
+			}
+		}
+
+		return true, first
+	}
+}
+
+// comparison returns an operator comparing the value of a metric with a constant.
+func comparison(name string, compare func(a, b float64) bool) func(any, any) (condition, error) {
+	return func(left, right any) (condition, error) {
+		metric, ok := left.(mapper)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported argument: %T", name, left)
+		}
+
+		var value float64
+		switch v := right.(type) {
+		case int:
+			value = float64(v)
+		case float64:
+			value = v
+		default:
+			return nil, fmt.Errorf("%s: expected a number, got %T", name, right)
+		}
+
+		return func(m *memmetrics.RTMetrics) (bool, float64) {
+			current := metric(m)
+			return compare(current, value), current
+		}, nil
+	}
+}
@@ -0,0 +1,248 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	geoipdb "github.com/apache4/apache4/v3/pkg/geoip"
+	"github.com/apache4/apache4/v3/pkg/ip"
+	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
+)
+
+const (
+	typeName = "GeoIP"
+)
+
+// continents are the continent codes used by the GeoIP databases.
+var continents = map[string]struct{}{
+	"AF": {}, "AN": {}, "AS": {}, "EU": {}, "NA": {}, "OC": {}, "SA": {},
+}
+
+// geoIP is a middleware that allows or denies the requests based on the location of the client IP,
+// and that forwards this location to the backends.
+type geoIP struct {
+	next             http.Handler
+	name             string
+	strategy         ip.Strategy
+	rejectStatusCode int
+	headers          dynamic.GeoIPHeaders
+
+	allowedCountries  map[string]struct{}
+	deniedCountries   map[string]struct{}
+	allowedContinents map[string]struct{}
+	deniedContinents  map[string]struct{}
+	allowedASNs       map[uint]struct{}
+	deniedASNs        map[uint]struct{}
+}
+
+// New builds a new GeoIP middleware.
+// The GeoIP databases are looked up in the request context, where the entry points add them.
+func New(ctx context.Context, next http.Handler, config dynamic.GeoIP, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	rejectStatusCode := config.RejectStatusCode
+	// If RejectStatusCode is not given, default to Forbidden (403).
+	if rejectStatusCode == 0 {
+		rejectStatusCode = http.StatusForbidden
+	} else if http.StatusText(rejectStatusCode) == "" {
+		return nil, fmt.Errorf("invalid HTTP status code %d", rejectStatusCode)
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	g := &geoIP{
+		next:             next,
+		name:             name,
+		strategy:         strategy,
+		rejectStatusCode: rejectStatusCode,
+	}
+
+	if config.Headers != nil {
+		g.headers = *config.Headers
+	}
+
+	if g.allowedCountries, err = parseCountries(config.AllowedCountries); err != nil {
+		return nil, err
+	}
+	if g.deniedCountries, err = parseCountries(config.DeniedCountries); err != nil {
+		return nil, err
+	}
+	if g.allowedContinents, err = parseContinents(config.AllowedContinents); err != nil {
+		return nil, err
+	}
+	if g.deniedContinents, err = parseContinents(config.DeniedContinents); err != nil {
+		return nil, err
+	}
+	if g.allowedASNs, err = parseASNs(config.AllowedASNs); err != nil {
+		return nil, err
+	}
+	if g.deniedASNs, err = parseASNs(config.DeniedASNs); err != nil {
+		return nil, err
+	}
+
+	if !g.hasRules() && g.headers == (dynamic.GeoIPHeaders{}) {
+		return nil, errors.New("no allowed or denied locations, and no headers")
+	}
+
+	return g, nil
+}
+
+func (g *geoIP) GetTracingInformation() (string, string) {
+	return g.name, typeName
+}
+
+func (g *geoIP) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), g.name, typeName)
+	ctx := logger.WithContext(req.Context())
+
+	g.removeHeaders(req)
+
+	db := geoipdb.DatabaseFromContext(req.Context())
+	if db == nil {
+		if g.hasRules() {
+			logger.Error().Msg("Rejecting request: no GeoIP database configured")
+			observability.SetStatusErrorf(req.Context(), "Rejecting request: no GeoIP database configured")
+			reject(ctx, g.rejectStatusCode, rw)
+			return
+		}
+
+		g.next.ServeHTTP(rw, req)
+		return
+	}
+
+	clientIP := g.strategy.GetIP(req)
+
+	record, err := db.LookupHost(clientIP)
+	if err != nil {
+		// The location of the client is unknown, which is only allowed when there are no allowed locations.
+		logger.Debug().Err(err).Msgf("Unable to look up IP %s", clientIP)
+	}
+
+	if reason := g.rejection(record); reason != "" {
+		logger.Debug().Msgf("Rejecting IP %s: %s", clientIP, reason)
+		observability.SetStatusErrorf(req.Context(), "Rejecting IP %s: %s", clientIP, reason)
+		reject(ctx, g.rejectStatusCode, rw)
+		return
+	}
+
+	logger.Debug().Msgf("Accepting IP %s", clientIP)
+
+	g.setHeaders(req, record)
+
+	g.next.ServeHTTP(rw, req)
+}
+
+func (g *geoIP) hasRules() bool {
+	return len(g.allowedCountries) > 0 || len(g.deniedCountries) > 0 ||
+		len(g.allowedContinents) > 0 || len(g.deniedContinents) > 0 ||
+		len(g.allowedASNs) > 0 || len(g.deniedASNs) > 0
+}
+
+// rejection returns the reason why the given location is rejected, or an empty string if it is accepted.
+// A location is rejected if it matches any denied location,
+// or if there are allowed locations and it matches none of them.
+func (g *geoIP) rejection(record geoipdb.Record) string {
+	if _, ok := g.deniedCountries[record.Country]; ok {
+		return fmt.Sprintf("country %s is denied", record.Country)
+	}
+	if _, ok := g.deniedContinents[record.Continent]; ok {
+		return fmt.Sprintf("continent %s is denied", record.Continent)
+	}
+	if _, ok := g.deniedASNs[record.ASN]; ok {
+		return fmt.Sprintf("AS%d is denied", record.ASN)
+	}
+
+	if len(g.allowedCountries) == 0 && len(g.allowedContinents) == 0 && len(g.allowedASNs) == 0 {
+		return ""
+	}
+
+	if _, ok := g.allowedCountries[record.Country]; ok {
+		return ""
+	}
+	if _, ok := g.allowedContinents[record.Continent]; ok {
+		return ""
+	}
+	if _, ok := g.allowedASNs[record.ASN]; ok {
+		return ""
+	}
+
+	return "location is not allowed"
+}
+
+// removeHeaders removes the location headers sent by the client, which cannot be trusted.
+func (g *geoIP) removeHeaders(req *http.Request) {
+	for _, name := range []string{g.headers.Country, g.headers.Continent, g.headers.ASN} {
+		if name != "" {
+			req.Header.Del(name)
+		}
+	}
+}
+
+func (g *geoIP) setHeaders(req *http.Request, record geoipdb.Record) {
+	if g.headers.Country != "" && record.Country != "" {
+		req.Header.Set(g.headers.Country, record.Country)
+	}
+	if g.headers.Continent != "" && record.Continent != "" {
+		req.Header.Set(g.headers.Continent, record.Continent)
+	}
+	if g.headers.ASN != "" && record.ASN != 0 {
+		req.Header.Set(g.headers.ASN, strconv.FormatUint(uint64(record.ASN), 10))
+	}
+}
+
+func parseCountries(codes []string) (map[string]struct{}, error) {
+	countries := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		country, err := geoipdb.ParseCountryCode(code)
+		if err != nil {
+			return nil, err
+		}
+		countries[country] = struct{}{}
+	}
+
+	return countries, nil
+}
+
+func parseContinents(codes []string) (map[string]struct{}, error) {
+	parsed := make(map[string]struct{}, len(codes))
+	for _, code := range codes {
+		continent := strings.ToUpper(code)
+		if _, ok := continents[continent]; !ok {
+			return nil, fmt.Errorf("invalid continent code %q: expected one of AF, AN, AS, EU, NA, OC or SA", code)
+		}
+		parsed[continent] = struct{}{}
+	}
+
+	return parsed, nil
+}
+
+func parseASNs(numbers []int) (map[uint]struct{}, error) {
+	asns := make(map[uint]struct{}, len(numbers))
+	for _, number := range numbers {
+		if number <= 0 {
+			return nil, fmt.Errorf("invalid autonomous system number %d", number)
+		}
+		asns[uint(number)] = struct{}{}
+	}
+
+	return asns, nil
+}
+
+func reject(ctx context.Context, statusCode int, rw http.ResponseWriter) {
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,259 @@
+package geoip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	geoipdb "github.com/apache4/apache4/v3/pkg/geoip"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.GeoIP
+		expectedError bool
+	}{
+		{
+			desc:          "empty configuration",
+			expectedError: true,
+		},
+		{
+			desc:   "allowed countries",
+			config: dynamic.GeoIP{AllowedCountries: []string{"FR", "de"}},
+		},
+		{
+			desc:   "headers only",
+			config: dynamic.GeoIP{Headers: &dynamic.GeoIPHeaders{Country: "X-Country-Code"}},
+		},
+		{
+			desc:          "invalid country",
+			config:        dynamic.GeoIP{DeniedCountries: []string{"France"}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid continent",
+			config:        dynamic.GeoIP{AllowedContinents: []string{"XX"}},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid ASN",
+			config:        dynamic.GeoIP{DeniedASNs: []int{-1}},
+			expectedError: true,
+		},
+		{
+			desc: "invalid reject status code",
+			config: dynamic.GeoIP{
+				AllowedCountries: []string{"FR"},
+				RejectStatusCode: 1,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(t.Context(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), test.config, "geoip")
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, handler)
+		})
+	}
+}
+
+func TestGeoIP_ServeHTTP(t *testing.T) {
+	dir := t.TempDir()
+
+	countries := filepath.Join(dir, "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, countries, 24, map[string]map[string]any{
+		"10.0.0.0/24": {
+			"country":   map[string]any{"iso_code": "FR"},
+			"continent": map[string]any{"code": "EU"},
+		},
+		"10.0.1.0/24": {
+			"country":   map[string]any{"iso_code": "US"},
+			"continent": map[string]any{"code": "NA"},
+		},
+		"10.0.2.0/24": {
+			"country":   map[string]any{"iso_code": "JP"},
+			"continent": map[string]any{"code": "AS"},
+		},
+	})
+
+	asns := filepath.Join(dir, "asn.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, asns, 24, map[string]map[string]any{
+		"10.0.1.0/25": {"autonomous_system_number": uint32(64496)},
+	})
+
+	db, err := geoipdb.NewDatabase(t.Context(), countries, asns)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.GeoIP
+		noDatabase      bool
+		remoteAddr      string
+		xForwardedFor   string
+		expectedStatus  int
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:           "allowed country",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"fr"}},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "not allowed country",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			remoteAddr:     "10.0.1.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "unknown location with allowed countries",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			remoteAddr:     "192.0.2.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "unknown location with denied countries",
+			config:         dynamic.GeoIP{DeniedCountries: []string{"FR"}},
+			remoteAddr:     "192.0.2.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "denied country",
+			config:         dynamic.GeoIP{DeniedCountries: []string{"US"}},
+			remoteAddr:     "10.0.1.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "allowed continent",
+			config:         dynamic.GeoIP{AllowedContinents: []string{"EU", "AS"}},
+			remoteAddr:     "10.0.2.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "denied ASN in an allowed continent",
+			config:         dynamic.GeoIP{AllowedContinents: []string{"NA"}, DeniedASNs: []int{64496}},
+			remoteAddr:     "10.0.1.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "allowed ASN in a not allowed country",
+			config:         dynamic.GeoIP{AllowedCountries: []string{"FR"}, AllowedASNs: []int{64496}},
+			remoteAddr:     "10.0.1.1:1234",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "custom reject status code",
+			config: dynamic.GeoIP{
+				DeniedCountries:  []string{"FR"},
+				RejectStatusCode: http.StatusNotFound,
+			},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc: "IP strategy",
+			config: dynamic.GeoIP{
+				AllowedCountries: []string{"JP"},
+				IPStrategy:       &dynamic.IPStrategy{Depth: 1},
+			},
+			remoteAddr:     "10.0.0.1:1234",
+			xForwardedFor:  "10.0.2.1",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "headers",
+			config: dynamic.GeoIP{
+				Headers: &dynamic.GeoIPHeaders{Country: "X-Country-Code", Continent: "X-Continent-Code", ASN: "X-ASN"},
+			},
+			remoteAddr:     "10.0.1.1:1234",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Country-Code":   "US",
+				"X-Continent-Code": "NA",
+				"X-ASN":            "64496",
+			},
+		},
+		{
+			desc: "headers of a location without autonomous system",
+			config: dynamic.GeoIP{
+				Headers: &dynamic.GeoIPHeaders{Country: "X-Country-Code", ASN: "X-ASN"},
+			},
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Country-Code": "FR",
+				"X-ASN":          "",
+			},
+		},
+		{
+			desc:           "no GeoIP database with rules",
+			config:         dynamic.GeoIP{DeniedCountries: []string{"US"}},
+			noDatabase:     true,
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc: "no GeoIP database with headers only",
+			config: dynamic.GeoIP{
+				Headers: &dynamic.GeoIPHeaders{Country: "X-Country-Code"},
+			},
+			noDatabase:     true,
+			remoteAddr:     "10.0.0.1:1234",
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"X-Country-Code": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var headers http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				headers = req.Header
+			})
+
+			handler, err := New(t.Context(), next, test.config, "geoip")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", http.NoBody)
+			req.RemoteAddr = test.remoteAddr
+			// Spoofed location headers, which must be removed.
+			req.Header.Set("X-Country-Code", "XX")
+			req.Header.Set("X-ASN", "1")
+			if test.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+
+			ctx := t.Context()
+			if !test.noDatabase {
+				ctx = geoipdb.WithDatabase(ctx, db)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req.WithContext(ctx))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, headers.Get(name), name)
+			}
+		})
+	}
+}
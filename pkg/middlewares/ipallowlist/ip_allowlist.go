@@ -2,14 +2,17 @@ package ipallowlist
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
 	"github.com/apache4/apache4/v3/pkg/ip"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"github.com/apache4/apache4/v3/pkg/middlewares/iplist"
 	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
 )
 
@@ -20,21 +23,20 @@ const (
 // ipAllowLister is a middleware that provides Checks of the Requesting IP against a set of Allowlists.
 type ipAllowLister struct {
 	next             http.Handler
-	allowLister      *ip.Checker
+	allowLister      *iplist.Ranges
 	strategy         ip.Strategy
 	name             string
 	rejectStatusCode int
+	hits             gokitmetrics.Counter
 }
 
-// New builds a new IPAllowLister given a list of CIDR-Strings to allow.
-func New(ctx context.Context, next http.Handler, config dynamic.IPAllowList, name string) (http.Handler, error) {
+// New builds a new IPAllowLister given a list of CIDR-Strings to allow,
+// and the external sources of additional CIDRs.
+// When info is not nil, the external sources which have not been loaded yet are reported through it.
+func New(ctx context.Context, next http.Handler, config dynamic.IPAllowList, metricsRegistry metrics.Registry, info *runtime.MiddlewareInfo, name string) (http.Handler, error) {
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug().Msg("Creating middleware")
 
-	if len(config.SourceRange) == 0 {
-		return nil, errors.New("sourceRange is empty, IPAllowLister not created")
-	}
-
 	rejectStatusCode := config.RejectStatusCode
 	// If RejectStatusCode is not given, default to Forbidden (403).
 	if rejectStatusCode == 0 {
@@ -43,24 +45,33 @@ func New(ctx context.Context, next http.Handler, config dynamic.IPAllowList, nam
 		return nil, fmt.Errorf("invalid HTTP status code %d", rejectStatusCode)
 	}
 
-	checker, err := ip.NewChecker(config.SourceRange)
+	strategy, err := config.IPStrategy.Get()
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse CIDRs %s: %w", config.SourceRange, err)
+		return nil, err
 	}
 
-	strategy, err := config.IPStrategy.Get()
+	ranges, err := iplist.New(logger.WithContext(ctx), config.SourceRange, config.ExternalSourceRange)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w, IPAllowLister not created", err)
+	}
+
+	if info != nil {
+		info.AddExternalSourceErrors(ranges.Errors)
 	}
 
 	logger.Debug().Msgf("Setting up IPAllowLister with sourceRange: %s", config.SourceRange)
 
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
 	return &ipAllowLister{
 		strategy:         strategy,
-		allowLister:      checker,
+		allowLister:      ranges,
 		next:             next,
 		name:             name,
 		rejectStatusCode: rejectStatusCode,
+		hits:             metricsRegistry.IPListHitsCounter().With("middleware", name, "list", "allow"),
 	}, nil
 }
 
@@ -73,7 +84,10 @@ func (al *ipAllowLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	ctx := logger.WithContext(req.Context())
 
 	clientIP := al.strategy.GetIP(req)
-	err := al.allowLister.IsAuthorized(clientIP)
+	allowed, err := al.allowLister.Contains(clientIP)
+	if err == nil && !allowed {
+		err = fmt.Errorf("%q matched none of the trusted IPs", clientIP)
+	}
 	if err != nil {
 		logger.Debug().Msgf("Rejecting IP %s: %v", clientIP, err)
 		observability.SetStatusErrorf(req.Context(), "Rejecting IP %s: %v", clientIP, err)
@@ -82,6 +96,8 @@ func (al *ipAllowLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 	logger.Debug().Msgf("Accepting IP %s", clientIP)
 
+	al.hits.Add(1)
+
 	al.next.ServeHTTP(rw, req)
 }
 
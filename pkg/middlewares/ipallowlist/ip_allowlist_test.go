@@ -44,7 +44,7 @@ func TestNewIPAllowLister(t *testing.T) {
 			t.Parallel()
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
-			allowLister, err := New(t.Context(), next, test.allowList, "apache4Test")
+			allowLister, err := New(t.Context(), next, test.allowList, nil, nil, "apache4Test")
 
 			if test.expectedError {
 				assert.Error(t, err)
@@ -104,7 +104,7 @@ func TestIPAllowLister_ServeHTTP(t *testing.T) {
 			t.Parallel()
 
 			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
-			allowLister, err := New(t.Context(), next, test.allowList, "apache4Test")
+			allowLister, err := New(t.Context(), next, test.allowList, nil, nil, "apache4Test")
 			require.NoError(t, err)
 
 			recorder := httptest.NewRecorder()
@@ -0,0 +1,114 @@
+package ipdenylist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/ip"
+	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"github.com/apache4/apache4/v3/pkg/middlewares/iplist"
+	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
+)
+
+const (
+	typeName = "IPDenyLister"
+)
+
+// ipDenyLister is a middleware that rejects the requests whose IP is in a set of Denylists.
+type ipDenyLister struct {
+	next             http.Handler
+	denyLister       *iplist.Ranges
+	strategy         ip.Strategy
+	name             string
+	rejectStatusCode int
+	hits             gokitmetrics.Counter
+}
+
+// New builds a new IPDenyLister given a list of CIDR-Strings to deny,
+// and the external sources of additional CIDRs.
+// When info is not nil, the external sources which have not been loaded yet are reported through it.
+func New(ctx context.Context, next http.Handler, config dynamic.IPDenyList, metricsRegistry metrics.Registry, info *runtime.MiddlewareInfo, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	rejectStatusCode := config.RejectStatusCode
+	// If RejectStatusCode is not given, default to Forbidden (403).
+	if rejectStatusCode == 0 {
+		rejectStatusCode = http.StatusForbidden
+	} else if http.StatusText(rejectStatusCode) == "" {
+		return nil, fmt.Errorf("invalid HTTP status code %d", rejectStatusCode)
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	ranges, err := iplist.New(logger.WithContext(ctx), config.SourceRange, config.ExternalSourceRange)
+	if err != nil {
+		return nil, fmt.Errorf("%w, IPDenyLister not created", err)
+	}
+
+	if info != nil {
+		info.AddExternalSourceErrors(ranges.Errors)
+	}
+
+	logger.Debug().Msgf("Setting up IPDenyLister with sourceRange: %s", config.SourceRange)
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &ipDenyLister{
+		strategy:         strategy,
+		denyLister:       ranges,
+		next:             next,
+		name:             name,
+		rejectStatusCode: rejectStatusCode,
+		hits:             metricsRegistry.IPListHitsCounter().With("middleware", name, "list", "deny"),
+	}, nil
+}
+
+func (dl *ipDenyLister) GetTracingInformation() (string, string) {
+	return dl.name, typeName
+}
+
+func (dl *ipDenyLister) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), dl.name, typeName)
+	ctx := logger.WithContext(req.Context())
+
+	clientIP := dl.strategy.GetIP(req)
+	denied, err := dl.denyLister.Contains(clientIP)
+	if err != nil {
+		logger.Debug().Msgf("Rejecting IP %s: %v", clientIP, err)
+		observability.SetStatusErrorf(req.Context(), "Rejecting IP %s: %v", clientIP, err)
+		reject(ctx, dl.rejectStatusCode, rw)
+		return
+	}
+
+	if denied {
+		dl.hits.Add(1)
+
+		logger.Debug().Msgf("Rejecting IP %s: matched the denied IPs", clientIP)
+		observability.SetStatusErrorf(req.Context(), "Rejecting IP %s: matched the denied IPs", clientIP)
+		reject(ctx, dl.rejectStatusCode, rw)
+		return
+	}
+	logger.Debug().Msgf("Accepting IP %s", clientIP)
+
+	dl.next.ServeHTTP(rw, req)
+}
+
+func reject(ctx context.Context, statusCode int, rw http.ResponseWriter) {
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Send()
+	}
+}
@@ -0,0 +1,128 @@
+package ipdenylist
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestNewIPDenyLister(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		denyList      dynamic.IPDenyList
+		expectedError bool
+	}{
+		{
+			desc:          "empty config",
+			denyList:      dynamic.IPDenyList{},
+			expectedError: true,
+		},
+		{
+			desc: "invalid IP",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"foo"},
+			},
+			expectedError: true,
+		},
+		{
+			desc: "valid IP",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"10.10.10.10"},
+			},
+		},
+		{
+			desc: "invalid HTTP status code",
+			denyList: dynamic.IPDenyList{
+				SourceRange:      []string{"10.10.10.10"},
+				RejectStatusCode: 600,
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			denyLister, err := New(t.Context(), next, test.denyList, nil, nil, "apache4Test")
+
+			if test.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, denyLister)
+			}
+		})
+	}
+}
+
+func TestIPDenyLister_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		denyList   dynamic.IPDenyList
+		remoteAddr string
+		expected   int
+	}{
+		{
+			desc: "authorized with remote address",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.21:1234",
+			expected:   200,
+		},
+		{
+			desc: "non authorized with remote address",
+			denyList: dynamic.IPDenyList{
+				SourceRange: []string{"20.20.20.20"},
+			},
+			remoteAddr: "20.20.20.20:1234",
+			expected:   403,
+		},
+		{
+			desc: "authorized with remote address, reject 404",
+			denyList: dynamic.IPDenyList{
+				SourceRange:      []string{"20.20.20.20"},
+				RejectStatusCode: 404,
+			},
+			remoteAddr: "20.20.20.21:1234",
+			expected:   200,
+		},
+		{
+			desc: "non authorized with remote address, reject 404",
+			denyList: dynamic.IPDenyList{
+				SourceRange:      []string{"20.20.20.20"},
+				RejectStatusCode: 404,
+			},
+			remoteAddr: "20.20.20.20:1234",
+			expected:   404,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			denyLister, err := New(t.Context(), next, test.denyList, nil, nil, "apache4Test")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+
+			req := httptest.NewRequest(http.MethodGet, "http://10.10.10.10", nil)
+
+			if len(test.remoteAddr) > 0 {
+				req.RemoteAddr = test.remoteAddr
+			}
+
+			denyLister.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
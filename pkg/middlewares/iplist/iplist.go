@@ -0,0 +1,374 @@
+package iplist
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/ip"
+	"github.com/apache4/apache4/v3/pkg/safe"
+)
+
+const (
+	defaultRefreshInterval = time.Hour
+	fetchTimeout           = 30 * time.Second
+	// minRetryInterval is the delay before fetching again a list which failed to be fetched,
+	// doubled on each consecutive failure, up to the refresh interval.
+	minRetryInterval = 5 * time.Second
+)
+
+// errNotLoaded is the error of a URL which has not been fetched yet.
+var errNotLoaded = errors.New("not loaded yet")
+
+// Ranges is a set of IP ranges made of inline ranges and of ranges loaded from external sources.
+// The external sources are reloaded periodically until the context given at creation is done,
+// the ranges of a source being kept when it fails to reload.
+type Ranges struct {
+	inline          []netip.Prefix
+	files           []string
+	urls            []string
+	refreshInterval time.Duration
+
+	loadedMu sync.Mutex
+	// loaded holds the last ranges successfully loaded from each source.
+	loaded map[string][]netip.Prefix
+	// errs holds the error of each URL which has not been loaded yet.
+	errs map[string]error
+
+	checker atomic.Pointer[ip.Checker]
+}
+
+// New builds the IP ranges from the given inline ranges and external sources.
+// The files must be readable at creation,
+// whereas the URLs are fetched in the background, unless they have already been fetched by another middleware,
+// and an unreachable URL is reported by Errors until it has been loaded.
+func New(ctx context.Context, sourceRange []string, external *dynamic.ExternalSourceRange) (*Ranges, error) {
+	r := &Ranges{
+		refreshInterval: defaultRefreshInterval,
+		loaded:          make(map[string][]netip.Prefix),
+		errs:            make(map[string]error),
+	}
+
+	for _, s := range sourceRange {
+		prefix, err := ip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse CIDRs %s: %w", sourceRange, err)
+		}
+		r.inline = append(r.inline, prefix)
+	}
+
+	if external != nil {
+		r.files = external.Files
+		r.urls = external.URLs
+
+		if external.RefreshInterval > 0 {
+			r.refreshInterval = time.Duration(external.RefreshInterval)
+		}
+	}
+
+	if len(r.inline) == 0 && len(r.files) == 0 && len(r.urls) == 0 {
+		return nil, errors.New("sourceRange and externalSourceRange are empty")
+	}
+
+	for _, file := range r.files {
+		prefixes, err := readFile(ctx, file)
+		if err != nil {
+			return nil, fmt.Errorf("loading IP ranges from %s: %w", file, err)
+		}
+		r.loaded[file] = prefixes
+	}
+
+	for _, u := range r.urls {
+		r.setURL(u, cached(u))
+	}
+
+	r.update()
+
+	if len(r.files) > 0 || len(r.urls) > 0 {
+		safe.Go(func() { r.refresh(ctx) })
+	}
+
+	return r, nil
+}
+
+// Contains checks if the given address, with or without a port, is in one of the ranges.
+func (r *Ranges) Contains(addr string) (bool, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	return r.checker.Load().Contains(host)
+}
+
+// Errors returns an error for each URL the ranges have not been loaded from yet.
+func (r *Ranges) Errors() []error {
+	r.loadedMu.Lock()
+	defer r.loadedMu.Unlock()
+
+	var errs []error
+	for _, u := range r.urls {
+		if err, ok := r.errs[u]; ok {
+			errs = append(errs, fmt.Errorf("IP ranges from %s not loaded: %w", u, err))
+		}
+	}
+
+	return errs
+}
+
+// refresh fetches the URLs right away, then reloads the files at each refresh interval,
+// and fetches the URLs once their list is to be fetched again.
+func (r *Ranges) refresh(ctx context.Context) {
+	urlsDue := r.loadURLs(ctx)
+	filesDue := time.Now().Add(r.refreshInterval)
+
+	for {
+		next := filesDue
+		if urlsDue.Before(next) {
+			next = urlsDue
+		}
+
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+
+		case <-timer.C:
+			if !time.Now().Before(filesDue) {
+				r.loadFiles(ctx)
+				filesDue = time.Now().Add(r.refreshInterval)
+			}
+
+			urlsDue = r.loadURLs(ctx)
+		}
+	}
+}
+
+func (r *Ranges) loadFiles(ctx context.Context) {
+	for _, file := range r.files {
+		prefixes, err := readFile(ctx, file)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("Unable to reload IP ranges from %s, keeping the previous ones", file)
+			continue
+		}
+
+		r.loadedMu.Lock()
+		r.loaded[file] = prefixes
+		r.loadedMu.Unlock()
+	}
+
+	r.update()
+}
+
+// loadURLs loads the lists of the URLs, fetching the ones which are due,
+// and returns when the next one is to be fetched.
+func (r *Ranges) loadURLs(ctx context.Context) time.Time {
+	next := time.Now().Add(r.refreshInterval)
+
+	for _, u := range r.urls {
+		entry, err := fetchCached(ctx, u, r.refreshInterval)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("Unable to load IP ranges from %s, retrying in %s", u, retryDelay(entry.failures, r.refreshInterval))
+		}
+
+		r.setURL(u, entry)
+
+		if nextFetch := entry.nextFetch(r.refreshInterval); nextFetch.Before(next) {
+			next = nextFetch
+		}
+	}
+
+	r.update()
+
+	return next
+}
+
+// setURL sets the ranges of the given URL from its cached list,
+// keeping the last ranges successfully fetched when it fails to be fetched again.
+func (r *Ranges) setURL(u string, entry cachedList) {
+	r.loadedMu.Lock()
+	defer r.loadedMu.Unlock()
+
+	switch {
+	case !entry.fetchedAt.IsZero():
+		r.loaded[u] = entry.prefixes
+		delete(r.errs, u)
+	case entry.err != nil:
+		r.errs[u] = entry.err
+	default:
+		r.errs[u] = errNotLoaded
+	}
+}
+
+func (r *Ranges) update() {
+	r.loadedMu.Lock()
+	prefixes := slices.Clone(r.inline)
+	for _, loaded := range r.loaded {
+		prefixes = append(prefixes, loaded...)
+	}
+	r.loadedMu.Unlock()
+
+	r.checker.Store(ip.NewCheckerFromPrefixes(prefixes))
+}
+
+func readFile(ctx context.Context, path string) ([]netip.Prefix, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return parse(ctx, path, file)
+}
+
+// urlCache holds the lists fetched from URLs, shared by all the middlewares,
+// to avoid fetching the lists again each time the middlewares are rebuilt on a configuration change,
+// and to back off from the URLs which fail to be fetched.
+var urlCache = struct {
+	sync.Mutex
+	entries map[string]cachedList
+}{entries: make(map[string]cachedList)}
+
+type cachedList struct {
+	// prefixes is the last list successfully fetched, if any.
+	prefixes  []netip.Prefix
+	fetchedAt time.Time
+
+	// err is the error of the last fetch, if it failed.
+	err      error
+	failedAt time.Time
+	failures int
+}
+
+// nextFetch returns when the list is to be fetched again.
+func (c cachedList) nextFetch(maxAge time.Duration) time.Time {
+	if c.err != nil {
+		return c.failedAt.Add(retryDelay(c.failures, maxAge))
+	}
+
+	return c.fetchedAt.Add(maxAge)
+}
+
+// cached returns the cached list of the given URL, without fetching it.
+func cached(u string) cachedList {
+	urlCache.Lock()
+	defer urlCache.Unlock()
+
+	return urlCache.entries[u]
+}
+
+// fetchCached returns the cached list of the given URL, fetching it first if it is due,
+// that is, if it has been fetched for longer than maxAge,
+// or if the retry delay following its last failure has elapsed.
+// The returned error is the error of the fetch made by this call, if any.
+func fetchCached(ctx context.Context, u string, maxAge time.Duration) (cachedList, error) {
+	entry := cached(u)
+
+	if time.Now().Before(entry.nextFetch(maxAge)) {
+		return entry, nil
+	}
+
+	prefixes, err := fetch(ctx, u)
+	if err != nil {
+		entry.err = err
+		entry.failedAt = time.Now()
+		entry.failures++
+	} else {
+		entry = cachedList{prefixes: prefixes, fetchedAt: time.Now()}
+	}
+
+	urlCache.Lock()
+	urlCache.entries[u] = entry
+	urlCache.Unlock()
+
+	return entry, err
+}
+
+// retryDelay returns the delay before fetching again a list which failed to be fetched the given number of consecutive times.
+func retryDelay(failures int, maxAge time.Duration) time.Duration {
+	delay := minRetryInterval
+	for i := 1; i < failures && delay < maxAge; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxAge)
+}
+
+func fetch(ctx context.Context, u string) ([]netip.Prefix, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return parse(ctx, u, resp.Body)
+}
+
+// parse reads one IP or CIDR range per line.
+// Blank lines and comments, starting with # or ;, are ignored,
+// as well as anything following the range on the same line.
+// Invalid ranges are skipped and reported.
+func parse(ctx context.Context, source string, r io.Reader) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	var invalid int
+	var firstErr error
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line, _, _ = strings.Cut(line, ";")
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		prefix, err := ip.ParsePrefix(fields[0])
+		if err != nil {
+			invalid++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		prefixes = append(prefixes, prefix)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if invalid > 0 {
+		log.Ctx(ctx).Warn().Err(firstErr).Msgf("Skipped %d invalid IP ranges from %s", invalid, source)
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,290 @@
+package iplist
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+
+	listFile := filepath.Join(dir, "list.txt")
+	err := os.WriteFile(listFile, []byte("# Comment\n\n10.0.0.0/8 ; Private\n192.0.2.1\nfoo\n2001:db8::/32\n"), 0o644)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/list":
+			_, _ = rw.Write([]byte("198.51.100.0/24\n"))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		desc           string
+		sourceRange    []string
+		external       *dynamic.ExternalSourceRange
+		passIPs        []string
+		rejectIPs      []string
+		expectedErrors int
+		expectedError  bool
+	}{
+		{
+			desc:          "no ranges",
+			expectedError: true,
+		},
+		{
+			desc:          "no external sources",
+			external:      &dynamic.ExternalSourceRange{},
+			expectedError: true,
+		},
+		{
+			desc:          "invalid inline range",
+			sourceRange:   []string{"foo"},
+			expectedError: true,
+		},
+		{
+			desc:        "inline ranges",
+			sourceRange: []string{"10.0.0.0/8", "192.0.2.1"},
+			passIPs:     []string{"10.1.2.3", "192.0.2.1:443"},
+			rejectIPs:   []string{"192.0.2.2", "2001:db8::1"},
+		},
+		{
+			desc:          "missing file",
+			external:      &dynamic.ExternalSourceRange{Files: []string{filepath.Join(dir, "missing.txt")}},
+			expectedError: true,
+		},
+		{
+			desc:        "file",
+			sourceRange: []string{"203.0.113.0/24"},
+			external:    &dynamic.ExternalSourceRange{Files: []string{listFile}},
+			passIPs:     []string{"10.1.2.3", "192.0.2.1", "[2001:db8::1]:443", "203.0.113.1"},
+			rejectIPs:   []string{"192.0.2.2", "198.51.100.1"},
+		},
+		{
+			desc:      "URL",
+			external:  &dynamic.ExternalSourceRange{URLs: []string{server.URL + "/list"}},
+			passIPs:   []string{"198.51.100.1"},
+			rejectIPs: []string{"10.1.2.3"},
+		},
+		{
+			desc:           "unreachable URL",
+			external:       &dynamic.ExternalSourceRange{URLs: []string{server.URL + "/missing"}},
+			rejectIPs:      []string{"198.51.100.1", "10.1.2.3"},
+			expectedErrors: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ranges, err := New(t.Context(), test.sourceRange, test.external)
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			waitFetched(t, ranges)
+			assert.Len(t, ranges.Errors(), test.expectedErrors)
+
+			for _, testIP := range test.passIPs {
+				ok, err := ranges.Contains(testIP)
+				require.NoError(t, err)
+				assert.Truef(t, ok, "%s should have passed.", testIP)
+			}
+
+			for _, testIP := range test.rejectIPs {
+				ok, err := ranges.Contains(testIP)
+				require.NoError(t, err)
+				assert.Falsef(t, ok, "%s should not have passed.", testIP)
+			}
+		})
+	}
+}
+
+func TestRanges_refresh(t *testing.T) {
+	listFile := filepath.Join(t.TempDir(), "list.txt")
+	err := os.WriteFile(listFile, []byte("10.0.0.1\n"), 0o644)
+	require.NoError(t, err)
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = fmt.Fprintf(rw, "192.0.2.%d\n", fetches.Add(1))
+	}))
+	t.Cleanup(server.Close)
+
+	ranges, err := New(t.Context(), nil, &dynamic.ExternalSourceRange{
+		Files:           []string{listFile},
+		URLs:            []string{server.URL},
+		RefreshInterval: ptypes.Duration(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	waitFetched(t, ranges)
+
+	assertContains(t, ranges, "10.0.0.1", true)
+	assertContains(t, ranges, "192.0.2.1", true)
+
+	err = os.WriteFile(listFile, []byte("10.0.0.2\n"), 0o644)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		ok, _ := ranges.Contains("10.0.0.2")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		ok, _ := ranges.Contains("192.0.2.1")
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assertContains(t, ranges, "10.0.0.1", false)
+}
+
+func TestRanges_refreshKeepsRangesOnError(t *testing.T) {
+	listFile := filepath.Join(t.TempDir(), "list.txt")
+	err := os.WriteFile(listFile, []byte("10.0.0.1\n"), 0o644)
+	require.NoError(t, err)
+
+	ranges, err := New(t.Context(), nil, &dynamic.ExternalSourceRange{
+		Files:           []string{listFile},
+		RefreshInterval: ptypes.Duration(50 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(listFile))
+
+	time.Sleep(200 * time.Millisecond)
+
+	assertContains(t, ranges, "10.0.0.1", true)
+}
+
+func TestFetchCached(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		_, _ = rw.Write([]byte("192.0.2.0/24\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	for range 3 {
+		entry, err := fetchCached(t.Context(), server.URL, time.Hour)
+		require.NoError(t, err)
+		assert.Len(t, entry.prefixes, 1)
+	}
+
+	assert.EqualValues(t, 1, fetches.Load())
+
+	_, err := fetchCached(t.Context(), server.URL, 0)
+	require.NoError(t, err)
+
+	assert.EqualValues(t, 2, fetches.Load())
+}
+
+func TestFetchCached_backoff(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if fetches.Add(1) < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = rw.Write([]byte("192.0.2.0/24\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	entry, err := fetchCached(t.Context(), server.URL, time.Hour)
+	require.Error(t, err)
+	assert.Equal(t, 1, entry.failures)
+	assert.WithinDuration(t, time.Now().Add(minRetryInterval), entry.nextFetch(time.Hour), time.Second)
+
+	// The failure is cached until the retry delay elapses.
+	entry, err = fetchCached(t.Context(), server.URL, time.Hour)
+	require.NoError(t, err)
+	require.Error(t, entry.err)
+	assert.EqualValues(t, 1, fetches.Load())
+
+	// The retry delay is bounded by the refresh interval.
+	_, err = fetchCached(t.Context(), server.URL, 0)
+	require.Error(t, err)
+	assert.EqualValues(t, 2, fetches.Load())
+
+	entry, err = fetchCached(t.Context(), server.URL, 0)
+	require.NoError(t, err)
+	require.NoError(t, entry.err)
+	assert.Len(t, entry.prefixes, 1)
+	assert.EqualValues(t, 3, fetches.Load())
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert.Equal(t, minRetryInterval, retryDelay(1, time.Hour))
+	assert.Equal(t, 4*minRetryInterval, retryDelay(3, time.Hour))
+	assert.Equal(t, time.Hour, retryDelay(100, time.Hour))
+	assert.Equal(t, time.Second, retryDelay(1, time.Second))
+}
+
+func TestNew_cachedURL(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetches.Add(1)
+		_, _ = rw.Write([]byte("192.0.2.0/24\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	external := &dynamic.ExternalSourceRange{URLs: []string{server.URL}}
+
+	ranges, err := New(t.Context(), nil, external)
+	require.NoError(t, err)
+
+	// The URL is fetched in the background.
+	require.Len(t, ranges.Errors(), 1)
+	assert.ErrorIs(t, ranges.Errors()[0], errNotLoaded)
+
+	waitFetched(t, ranges)
+	assert.Empty(t, ranges.Errors())
+
+	// The list already fetched is used right away by the ranges built afterward.
+	ranges, err = New(t.Context(), nil, external)
+	require.NoError(t, err)
+
+	assert.Empty(t, ranges.Errors())
+	assertContains(t, ranges, "192.0.2.1", true)
+	assert.EqualValues(t, 1, fetches.Load())
+}
+
+// waitFetched waits for the URLs of the ranges to have been fetched at least once.
+func waitFetched(t *testing.T, ranges *Ranges) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		for _, err := range ranges.Errors() {
+			if errors.Is(err, errNotLoaded) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func assertContains(t *testing.T, ranges *Ranges, addr string, expected bool) {
+	t.Helper()
+
+	ok, err := ranges.Contains(addr)
+	require.NoError(t, err)
+	assert.Equalf(t, expected, ok, "unexpected result for %s", addr)
+}
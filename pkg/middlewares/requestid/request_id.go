@@ -0,0 +1,155 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/apache4/apache4/v3/pkg/ip"
+)
+
+// Supported formats of the generated request IDs.
+const (
+	FormatUUIDv4 = "uuidv4"
+	FormatUUIDv7 = "uuidv7"
+	FormatULID   = "ulid"
+)
+
+type key struct{}
+
+// RequestID is an HTTP handler wrapper that sets the request ID header,
+// generating a new ID when the request does not have one.
+// Unless insecure is set,
+// the incoming request IDs are replaced when the remote address is not one of the trusted ones.
+type RequestID struct {
+	headerName string
+	generate   func() (string, error)
+	insecure   bool
+	ipChecker  *ip.Checker
+	next       http.Handler
+}
+
+// New creates a new RequestID.
+func New(headerName, format string, insecure bool, trustedIPs []string, next http.Handler) (*RequestID, error) {
+	if headerName == "" {
+		return nil, errors.New("empty request ID header name")
+	}
+
+	var generate func() (string, error)
+	switch format {
+	case FormatUUIDv4, "":
+		generate = newUUIDv4
+	case FormatUUIDv7:
+		generate = newUUIDv7
+	case FormatULID:
+		generate = newULID
+	default:
+		return nil, fmt.Errorf("unsupported request ID format: %q", format)
+	}
+
+	var ipChecker *ip.Checker
+	if len(trustedIPs) > 0 {
+		var err error
+		ipChecker, err = ip.NewChecker(trustedIPs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RequestID{
+		headerName: http.CanonicalHeaderKey(headerName),
+		generate:   generate,
+		insecure:   insecure,
+		ipChecker:  ipChecker,
+		next:       next,
+	}, nil
+}
+
+func (r *RequestID) isTrustedIP(addr string) bool {
+	if r.ipChecker == nil {
+		return false
+	}
+	return r.ipChecker.IsAuthorized(addr) == nil
+}
+
+// ServeHTTP implements http.Handler.
+func (r *RequestID) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	id := req.Header.Get(r.headerName)
+	if id != "" && !r.insecure && !r.isTrustedIP(req.RemoteAddr) {
+		id = ""
+	}
+
+	if id == "" {
+		var err error
+		id, err = r.generate()
+		if err != nil {
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// The ID is forwarded to the upstream servers, and echoed to the client.
+	req.Header.Set(r.headerName, id)
+	rw.Header().Set(r.headerName, id)
+
+	r.next.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), key{}, id)))
+}
+
+// GetRequestID returns the request ID stored in the given context, if any.
+func GetRequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(key{}).(string); ok {
+		return id
+	}
+
+	return ""
+}
+
+func newUUIDv4() (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func newUUIDv7() (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// crockfordAlphabet is the base32 alphabet of the ULIDs.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID generates a ULID (https://github.com/ulid/spec):
+// a 48 bits timestamp in milliseconds followed by 80 random bits, encoded in 26 base32 characters.
+func newULID() (string, error) {
+	var id [16]byte
+
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", err
+	}
+
+	// The 128 bits are encoded from the most significant ones, the first character holding only 3 bits.
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(out[:]), nil
+}
@@ -0,0 +1,102 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestID_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		format     string
+		insecure   bool
+		trustedIPs []string
+		remoteAddr string
+		incomingID string
+		expectedID string
+		expectedRe string
+	}{
+		{
+			desc:       "generated UUIDv4",
+			format:     FormatUUIDv4,
+			remoteAddr: "10.0.0.1:1234",
+			expectedRe: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			desc:       "generated UUIDv7",
+			format:     FormatUUIDv7,
+			remoteAddr: "10.0.0.1:1234",
+			expectedRe: `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			desc:       "generated ULID",
+			format:     FormatULID,
+			remoteAddr: "10.0.0.1:1234",
+			expectedRe: `^[0-7][0-9A-HJKMNP-TV-Z]{25}$`,
+		},
+		{
+			desc:       "incoming ID with insecure",
+			insecure:   true,
+			remoteAddr: "10.0.0.1:1234",
+			incomingID: "foo",
+			expectedID: "foo",
+		},
+		{
+			desc:       "incoming ID from a trusted IP",
+			trustedIPs: []string{"10.0.0.0/24"},
+			remoteAddr: "10.0.0.1:1234",
+			incomingID: "foo",
+			expectedID: "foo",
+		},
+		{
+			desc:       "incoming ID from an untrusted IP",
+			trustedIPs: []string{"10.0.0.0/24"},
+			remoteAddr: "10.0.1.1:1234",
+			incomingID: "foo",
+			expectedRe: `^[0-9a-f-]{36}$`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var upstreamID, contextID string
+			next := http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				upstreamID = req.Header.Get("X-Request-Id")
+				contextID = GetRequestID(req.Context())
+			})
+
+			handler, err := New("x-request-id", test.format, test.insecure, test.trustedIPs, next)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.incomingID != "" {
+				req.Header.Set("X-Request-Id", test.incomingID)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if test.expectedID != "" {
+				assert.Equal(t, test.expectedID, upstreamID)
+			} else {
+				assert.Regexp(t, regexp.MustCompile(test.expectedRe), upstreamID)
+			}
+
+			assert.Equal(t, upstreamID, contextID)
+			assert.Equal(t, upstreamID, rw.Header().Get("X-Request-Id"))
+		})
+	}
+}
+
+func TestNew_invalidFormat(t *testing.T) {
+	_, err := New("X-Request-Id", "foo", false, nil, http.NotFoundHandler())
+	assert.Error(t, err)
+}
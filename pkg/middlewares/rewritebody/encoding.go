@@ -0,0 +1,70 @@
+package rewritebody
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	brotliName   = "br"
+	gzipName     = "gzip"
+	zstdName     = "zstd"
+	identityName = "identity"
+)
+
+// encoder compresses the written bytes.
+type encoder interface {
+	io.WriteCloser
+	// Flush writes the compressed bytes of all the bytes written so far.
+	Flush() error
+}
+
+// supportedEncoding returns whether a body with the given content encoding can be rewritten.
+func supportedEncoding(encoding string) bool {
+	switch encoding {
+	case "", identityName, gzipName, brotliName, zstdName:
+		return true
+	default:
+		return false
+	}
+}
+
+// newDecoder returns a reader decompressing the given reader with the given content encoding.
+func newDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case gzipName:
+		return gzip.NewReader(r)
+	case brotliName:
+		return io.NopCloser(brotli.NewReader(r)), nil
+	case zstdName:
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd reader: %w", err)
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
+
+// newEncoder returns a writer compressing the bytes written to the given writer with the given content encoding.
+func newEncoder(encoding string, w io.Writer) (encoder, error) {
+	switch encoding {
+	case gzipName:
+		return gzip.NewWriter(w), nil
+	case brotliName:
+		return brotli.NewWriter(w), nil
+	case zstdName:
+		writer, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd writer: %w", err)
+		}
+		return writer, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+	}
+}
@@ -0,0 +1,136 @@
+package rewritebody
+
+import (
+	"io"
+	"regexp"
+)
+
+// rewrite is a compiled BodyRewrite.
+type rewrite struct {
+	re          *regexp.Regexp
+	replacement []byte
+	// expand defines whether the replacement includes the variables captured by the regular expression.
+	expand bool
+}
+
+// appendReplacement appends the replacement of the match at the given location of src to dst.
+func (r rewrite) appendReplacement(dst, src []byte, loc []int) []byte {
+	if !r.expand {
+		return append(dst, r.replacement...)
+	}
+
+	return r.re.Expand(dst, r.replacement, src, loc)
+}
+
+// replacer applies the rewrites to a stream, writing the rewritten stream to the underlying writer.
+// As a match could span several writes, the last maxMatchLength bytes written are held back,
+// until enough bytes are written to find the matches starting in them, or until the stream is closed.
+// When several rewrites match, the leftmost match is replaced, the first rewrite winning ties,
+// and the replaced text is never matched again.
+type replacer struct {
+	w              io.Writer
+	rewrites       []rewrite
+	maxMatchLength int
+
+	buf []byte
+	out []byte
+}
+
+func newReplacer(w io.Writer, rewrites []rewrite, maxMatchLength int) *replacer {
+	return &replacer{
+		w:              w,
+		rewrites:       rewrites,
+		maxMatchLength: maxMatchLength,
+	}
+}
+
+// Write rewrites the given bytes.
+func (r *replacer) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+
+	if err := r.replace(false); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close rewrites the bytes held back, as the stream is ended.
+func (r *replacer) Close() error {
+	return r.replace(true)
+}
+
+// replace writes the rewritten buffered bytes, but the ones which could start a match not entirely buffered yet,
+// unless the stream is ended.
+func (r *replacer) replace(ended bool) error {
+	// Only the matches starting before the limit are known to be entirely buffered,
+	// as a match is no longer than maxMatchLength.
+	limit := len(r.buf)
+	if !ended {
+		limit -= r.maxMatchLength
+	}
+
+	if limit < 0 {
+		return nil
+	}
+
+	r.out = r.out[:0]
+
+	var pos int
+	for pos < len(r.buf) {
+		rw, loc := r.nextMatch(r.buf[pos:])
+		if loc == nil || pos+loc[0] > limit {
+			break
+		}
+
+		if loc[0] == loc[1] {
+			if pos+loc[0] == len(r.buf) {
+				break
+			}
+
+			// An empty match is not replaced, the byte following it is written as is.
+			r.out = append(r.out, r.buf[pos:pos+loc[0]+1]...)
+			pos += loc[0] + 1
+			continue
+		}
+
+		r.out = append(r.out, r.buf[pos:pos+loc[0]]...)
+		r.out = rw.appendReplacement(r.out, r.buf[pos:], loc)
+		pos += loc[1]
+	}
+
+	if pos < limit {
+		r.out = append(r.out, r.buf[pos:limit]...)
+		pos = limit
+	}
+
+	r.buf = r.buf[:copy(r.buf, r.buf[pos:])]
+
+	if len(r.out) == 0 {
+		return nil
+	}
+
+	_, err := r.w.Write(r.out)
+	return err
+}
+
+// nextMatch returns the rewrite with the leftmost match in b, and the location of the match.
+func (r *replacer) nextMatch(b []byte) (rewrite, []int) {
+	var next rewrite
+	var nextLoc []int
+
+	for _, rw := range r.rewrites {
+		var loc []int
+		if rw.expand {
+			loc = rw.re.FindSubmatchIndex(b)
+		} else {
+			loc = rw.re.FindIndex(b)
+		}
+
+		if loc != nil && (nextLoc == nil || loc[0] < nextLoc[0]) {
+			next, nextLoc = rw, loc
+		}
+	}
+
+	return next, nextLoc
+}
@@ -0,0 +1,115 @@
+package rewritebody
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplacer(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rewrites []rewrite
+		input    string
+		expected string
+	}{
+		{
+			desc:     "literal",
+			rewrites: []rewrite{literalRewrite("http://internal", "https://example.com")},
+			input:    `<a href="http://internal/foo">http://internal</a>`,
+			expected: `<a href="https://example.com/foo">https://example.com</a>`,
+		},
+		{
+			desc:     "literal replacement is not expanded",
+			rewrites: []rewrite{literalRewrite("foo", "$1")},
+			input:    "foo bar foo",
+			expected: "$1 bar $1",
+		},
+		{
+			desc: "regex with captured variables",
+			rewrites: []rewrite{{
+				re:          regexp.MustCompile(`http://([a-z]+)\.internal`),
+				replacement: []byte("https://$1.example.com"),
+				expand:      true,
+			}},
+			input:    "http://foo.internal/ and http://bar.internal/",
+			expected: "https://foo.example.com/ and https://bar.example.com/",
+		},
+		{
+			desc: "leftmost match first",
+			rewrites: []rewrite{
+				literalRewrite("bar", "1"),
+				literalRewrite("foo", "2"),
+			},
+			input:    "foobar barfoo",
+			expected: "21 12",
+		},
+		{
+			desc: "first rewrite wins ties",
+			rewrites: []rewrite{
+				literalRewrite("foo", "1"),
+				literalRewrite("foobar", "2"),
+			},
+			input:    "foobar",
+			expected: "1bar",
+		},
+		{
+			desc:     "replaced text is not matched again",
+			rewrites: []rewrite{literalRewrite("a", "aa")},
+			input:    "aaa",
+			expected: "aaaaaa",
+		},
+		{
+			desc:     "no match",
+			rewrites: []rewrite{literalRewrite("foo", "bar")},
+			input:    strings.Repeat("fo", 100),
+			expected: strings.Repeat("fo", 100),
+		},
+		{
+			desc:     "match at the end",
+			rewrites: []rewrite{literalRewrite("foo", "bar")},
+			input:    strings.Repeat("x", 100) + "foo",
+			expected: strings.Repeat("x", 100) + "bar",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// The output does not depend on how the input is split in writes.
+			for _, chunkSize := range []int{1, 2, 7, len(test.input)} {
+				var out bytes.Buffer
+				r := newReplacer(&out, test.rewrites, 32)
+
+				for input := test.input; len(input) > 0; {
+					n := min(chunkSize, len(input))
+
+					written, err := r.Write([]byte(input[:n]))
+					require.NoError(t, err)
+					require.Equal(t, n, written)
+
+					// No more than the maximum match length is held back.
+					assert.LessOrEqual(t, len(r.buf), 32)
+
+					input = input[n:]
+				}
+
+				require.NoError(t, r.Close())
+
+				assert.Equal(t, test.expected, out.String(), "chunk size %d", chunkSize)
+			}
+		})
+	}
+}
+
+func literalRewrite(match, replacement string) rewrite {
+	return rewrite{
+		re:          regexp.MustCompile(regexp.QuoteMeta(match)),
+		replacement: []byte(replacement),
+	}
+}
@@ -0,0 +1,350 @@
+package rewritebody
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	contentEncoding = "Content-Encoding"
+	contentLength   = "Content-Length"
+	contentType     = "Content-Type"
+	etag            = "ETag"
+)
+
+// responseWriter rewrites the body of the response, when it has one of the configured content types.
+// The encoded bodies are decoded by a dedicated goroutine, fed through a pipe,
+// and encoded again once rewritten.
+type responseWriter struct {
+	rw          http.ResponseWriter
+	rewriteBody *rewriteBody
+
+	statusCode    int
+	statusCodeSet bool
+	hijacked      bool
+
+	// decided defines whether it was decided if the body is rewritten,
+	// which happens on the first write of the body.
+	decided   bool
+	rewriting bool
+
+	// body is the writer the body is written to, when rewritten.
+	body     io.WriteCloser
+	out      *bodyWriter
+	decoding bool
+	// decoded is closed once the decoding goroutine is done, and decodeErr holds its error.
+	decoded   chan struct{}
+	decodeErr error
+}
+
+func newResponseWriter(rw http.ResponseWriter, rewriteBody *rewriteBody) *responseWriter {
+	return &responseWriter{
+		rw:          rw,
+		rewriteBody: rewriteBody,
+		statusCode:  http.StatusOK,
+	}
+}
+
+func (r *responseWriter) Header() http.Header {
+	return r.rw.Header()
+}
+
+func (r *responseWriter) WriteHeader(statusCode int) {
+	// Informational responses are forwarded as is.
+	if statusCode >= 100 && statusCode <= 199 {
+		r.rw.WriteHeader(statusCode)
+		return
+	}
+
+	if r.statusCodeSet {
+		return
+	}
+
+	r.statusCode = statusCode
+	r.statusCodeSet = true
+
+	// As a response without body is never rewritten, its headers are sent right away.
+	if !bodyAllowed(statusCode) {
+		r.decided = true
+		r.rw.WriteHeader(statusCode)
+	}
+}
+
+func (r *responseWriter) Write(p []byte) (int, error) {
+	if !r.decided {
+		if len(p) == 0 {
+			return 0, nil
+		}
+
+		if err := r.decide(); err != nil {
+			return 0, err
+		}
+	}
+
+	if !r.rewriting {
+		return r.rw.Write(p)
+	}
+
+	return r.body.Write(p)
+}
+
+// Flush sends the rewritten bytes to the client.
+// The bytes held back by the rewriting, which could start a match, are not sent.
+func (r *responseWriter) Flush() {
+	if !r.decided {
+		// Without any byte of the body written yet, the response is not rewritten.
+		r.decided = true
+		r.rw.WriteHeader(r.statusCode)
+	}
+
+	if r.rewriting {
+		r.out.flushing.Store(true)
+
+		if r.decoding {
+			// The decoding goroutine is the one writing to the response.
+			return
+		}
+
+		r.out.flush()
+		return
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.rw)
+	}
+
+	r.hijacked = true
+	return hijacker.Hijack()
+}
+
+// decide decides whether the body is rewritten, according to the response headers,
+// and prepares the rewriting.
+func (r *responseWriter) decide() error {
+	r.decided = true
+
+	encoding := strings.TrimSpace(r.rw.Header().Get(contentEncoding))
+	if !r.rewritable() || !supportedEncoding(encoding) {
+		r.rw.WriteHeader(r.statusCode)
+		return nil
+	}
+
+	r.rewriting = true
+
+	// The length of the rewritten body is only known once it is entirely rewritten.
+	r.rw.Header().Del(contentLength)
+	// The rewritten body is only semantically equivalent to the original one.
+	if tag := r.rw.Header().Get(etag); tag != "" && !strings.HasPrefix(tag, "W/") {
+		r.rw.Header().Set(etag, "W/"+tag)
+	}
+
+	r.out = &bodyWriter{rw: r.rw, statusCode: r.statusCode}
+
+	if encoding == "" || encoding == identityName {
+		r.body = newReplacer(r.out, r.rewriteBody.rewrites, r.rewriteBody.maxMatchLength)
+		return nil
+	}
+
+	enc, err := newEncoder(encoding, r.out)
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+
+	r.body = pw
+	r.decoding = true
+	r.decoded = make(chan struct{})
+
+	go r.decode(encoding, pr, enc)
+
+	return nil
+}
+
+// rewritable returns whether the response is rewritten, according to its status code and content type.
+func (r *responseWriter) rewritable() bool {
+	if r.statusCode < http.StatusOK || r.statusCode == http.StatusPartialContent || !bodyAllowed(r.statusCode) {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.rw.Header().Get(contentType))
+	if err != nil {
+		return false
+	}
+
+	return slices.Contains(r.rewriteBody.contentTypes, mediaType)
+}
+
+// decode decodes the body read from the pipe, rewrites it, and encodes it again.
+func (r *responseWriter) decode(encoding string, pr *io.PipeReader, enc encoder) {
+	defer close(r.decoded)
+
+	err := r.rewriteEncoded(encoding, pr, enc)
+
+	// Unblocks the writes of the body in case of error, which is then returned to them.
+	_ = pr.CloseWithError(err)
+
+	r.decodeErr = err
+}
+
+func (r *responseWriter) rewriteEncoded(encoding string, pr *io.PipeReader, enc encoder) error {
+	decoder, err := newDecoder(encoding, pr)
+	if err != nil {
+		return fmt.Errorf("decoding body: %w", err)
+	}
+	defer func() { _ = decoder.Close() }()
+
+	replacer := newReplacer(enc, r.rewriteBody.rewrites, r.rewriteBody.maxMatchLength)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := decoder.Read(buf)
+		if n > 0 {
+			if _, werr := replacer.Write(buf[:n]); werr != nil {
+				return werr
+			}
+
+			if r.out.flushing.Load() {
+				if ferr := enc.Flush(); ferr != nil {
+					return ferr
+				}
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("decoding body: %w", err)
+		}
+	}
+
+	r.out.closing = true
+
+	if err := replacer.Close(); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// close writes the end of the rewritten body.
+func (r *responseWriter) close() error {
+	if r.hijacked {
+		return nil
+	}
+
+	if !r.decided {
+		// Nothing was written, the response is not rewritten.
+		r.rw.WriteHeader(r.statusCode)
+		return nil
+	}
+
+	if !r.rewriting {
+		return nil
+	}
+
+	if r.decoding {
+		_ = r.body.Close()
+		<-r.decoded
+
+		if r.decodeErr != nil {
+			if !r.out.headersSent {
+				r.rw.WriteHeader(http.StatusBadGateway)
+			}
+			return r.decodeErr
+		}
+	} else {
+		r.out.closing = true
+
+		if err := r.body.Close(); err != nil {
+			return err
+		}
+	}
+
+	return r.out.close()
+}
+
+// bodyWriter writes the rewritten body to the response, sending the headers right before the first bytes.
+// Once closing, the bytes are buffered instead, so that the Content-Length header can be set
+// when the whole rewritten body is known before sending the headers.
+type bodyWriter struct {
+	rw          http.ResponseWriter
+	statusCode  int
+	headersSent bool
+	closing     bool
+	buf         []byte
+
+	// flushing defines whether the response is flushed after each write, as the handler flushes it.
+	flushing atomic.Bool
+}
+
+func (b *bodyWriter) Write(p []byte) (int, error) {
+	if !b.headersSent {
+		if b.closing {
+			b.buf = append(b.buf, p...)
+			return len(p), nil
+		}
+
+		b.writeHeader()
+	}
+
+	n, err := b.rw.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	if b.flushing.Load() {
+		b.flush()
+	}
+
+	return n, nil
+}
+
+func (b *bodyWriter) writeHeader() {
+	b.headersSent = true
+	b.rw.WriteHeader(b.statusCode)
+}
+
+func (b *bodyWriter) flush() {
+	if !b.headersSent {
+		b.writeHeader()
+	}
+
+	if flusher, ok := b.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// close writes the buffered bytes, with the Content-Length header when the headers are not sent yet.
+func (b *bodyWriter) close() error {
+	if b.headersSent {
+		return nil
+	}
+
+	b.rw.Header().Set(contentLength, strconv.Itoa(len(b.buf)))
+	b.writeHeader()
+
+	_, err := b.rw.Write(b.buf)
+	return err
+}
+
+// bodyAllowed returns whether a response with the given status code can have a body.
+func bodyAllowed(statusCode int) bool {
+	return statusCode != http.StatusNoContent && statusCode != http.StatusNotModified
+}
@@ -0,0 +1,159 @@
+package rewritebody
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/middlewares"
+)
+
+const typeName = "RewriteBody"
+
+// defaultMaxMatchLength is the default maximum length (in bytes) of the text matched by a rewrite.
+const defaultMaxMatchLength = 4096
+
+var defaultContentTypes = []string{"text/html"}
+
+// rewriteBody is a middleware rewriting the response bodies.
+type rewriteBody struct {
+	next           http.Handler
+	name           string
+	rewrites       []rewrite
+	contentTypes   []string
+	maxMatchLength int
+}
+
+// New creates a new rewrite body middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.RewriteBody, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if len(config.Rewrites) == 0 {
+		return nil, errors.New("at least one rewrite must be specified")
+	}
+
+	maxMatchLength := defaultMaxMatchLength
+	if config.MaxMatchLength < 0 {
+		return nil, fmt.Errorf("invalid maximum match length: %d", config.MaxMatchLength)
+	}
+	if config.MaxMatchLength > 0 {
+		maxMatchLength = config.MaxMatchLength
+	}
+
+	var rewrites []rewrite
+	for i, rw := range config.Rewrites {
+		switch {
+		case rw.Match != "" && rw.Regex != "":
+			return nil, fmt.Errorf("rewrite %d: match and regex options are mutually exclusive", i)
+
+		case rw.Match != "":
+			if len(rw.Match) > maxMatchLength {
+				return nil, fmt.Errorf("rewrite %d: match is longer than the maximum match length %d", i, maxMatchLength)
+			}
+
+			rewrites = append(rewrites, rewrite{
+				re:          regexp.MustCompile(regexp.QuoteMeta(rw.Match)),
+				replacement: []byte(rw.Replacement),
+			})
+
+		case rw.Regex != "":
+			re, err := regexp.Compile(rw.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("rewrite %d: compiling regex %q: %w", i, rw.Regex, err)
+			}
+
+			if re.MatchString("") {
+				return nil, fmt.Errorf("rewrite %d: regex %q matches the empty string", i, rw.Regex)
+			}
+
+			// The body is rewritten chunk by chunk, whose boundaries would be taken as the beginning or end of the text.
+			if hasAssertion(rw.Regex) {
+				return nil, fmt.Errorf("rewrite %d: regex %q contains an anchor or a word boundary, which are not supported", i, rw.Regex)
+			}
+
+			rewrites = append(rewrites, rewrite{
+				re:          re,
+				replacement: []byte(rw.Replacement),
+				expand:      strings.Contains(rw.Replacement, "$"),
+			})
+
+		default:
+			return nil, fmt.Errorf("rewrite %d: either match or regex option must be specified", i)
+		}
+	}
+
+	contentTypes := config.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultContentTypes
+	}
+
+	var mediaTypes []string
+	for _, v := range contentTypes {
+		mediaType, _, err := mime.ParseMediaType(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing content type: %w", err)
+		}
+
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+
+	return &rewriteBody{
+		next:           next,
+		name:           name,
+		rewrites:       rewrites,
+		contentTypes:   mediaTypes,
+		maxMatchLength: maxMatchLength,
+	}, nil
+}
+
+// hasAssertion returns whether the regex contains an anchor (e.g. ^, $, \A or \z) or a word boundary (\b or \B).
+func hasAssertion(regex string) bool {
+	re, err := syntax.Parse(regex, syntax.Perl)
+	if err != nil {
+		return false
+	}
+
+	return containsAssertion(re)
+}
+
+func containsAssertion(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	}
+
+	for _, sub := range re.Sub {
+		if containsAssertion(sub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *rewriteBody) GetTracingInformation() (string, string) {
+	return r.name, typeName
+}
+
+func (r *rewriteBody) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodHead {
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+
+	responseWriter := newResponseWriter(rw, r)
+	defer func() {
+		if err := responseWriter.close(); err != nil {
+			logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+			logger.Debug().Err(err).Msg("Error while rewriting the response body")
+		}
+	}()
+
+	r.next.ServeHTTP(responseWriter, req)
+}
@@ -0,0 +1,418 @@
+package rewritebody
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.RewriteBody
+		expectedErr bool
+	}{
+		{
+			desc: "literal and regex rewrites",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{
+					{Match: "foo", Replacement: "bar"},
+					{Regex: "f(o+)", Replacement: "b$1"},
+				},
+			},
+		},
+		{
+			desc:        "no rewrite",
+			config:      dynamic.RewriteBody{},
+			expectedErr: true,
+		},
+		{
+			desc: "match and regex",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Match: "foo", Regex: "foo"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "neither match nor regex",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Replacement: "foo"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid regex",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo("}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "regex matching the empty string",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "a*"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "regex with a beginning of text anchor",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "^a"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "regex with an end of line anchor",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "(?m)foo$"}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "regex with a word boundary",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: `foo|\bbar`}},
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "match longer than the maximum match length",
+			config: dynamic.RewriteBody{
+				Rewrites:       []dynamic.BodyRewrite{{Match: "foobar"}},
+				MaxMatchLength: 3,
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "negative maximum match length",
+			config: dynamic.RewriteBody{
+				Rewrites:       []dynamic.BodyRewrite{{Match: "foo"}},
+				MaxMatchLength: -1,
+			},
+			expectedErr: true,
+		},
+		{
+			desc: "invalid content type",
+			config: dynamic.RewriteBody{
+				Rewrites:     []dynamic.BodyRewrite{{Match: "foo"}},
+				ContentTypes: []string{"text/html; ;"},
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(t.Context(), http.NotFoundHandler(), test.config, "rewriteBody")
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRewriteBody(t *testing.T) {
+	smallBody := `<a href="http://internal/foo">foo</a>`
+	largeBody := strings.Repeat(smallBody, 1000)
+
+	testCases := []struct {
+		desc             string
+		method           string
+		statusCode       int
+		contentType      string
+		contentEncoding  string
+		etag             string
+		body             string
+		expectedBody     string
+		expectedStreamed bool
+		expectedETag     string
+	}{
+		{
+			desc:         "small body",
+			contentType:  "text/html; charset=utf-8",
+			body:         smallBody,
+			expectedBody: strings.ReplaceAll(smallBody, "http://internal", "https://example.com"),
+		},
+		{
+			desc:             "large body",
+			contentType:      "text/html",
+			body:             largeBody,
+			expectedBody:     strings.ReplaceAll(largeBody, "http://internal", "https://example.com"),
+			expectedStreamed: true,
+		},
+		{
+			desc:             "gzip encoded body",
+			contentType:      "text/html",
+			contentEncoding:  gzipName,
+			body:             largeBody,
+			expectedBody:     strings.ReplaceAll(largeBody, "http://internal", "https://example.com"),
+			expectedStreamed: true,
+		},
+		{
+			desc:            "brotli encoded body",
+			contentType:     "text/html",
+			contentEncoding: brotliName,
+			body:            largeBody,
+			expectedBody:    strings.ReplaceAll(largeBody, "http://internal", "https://example.com"),
+		},
+		{
+			desc:            "zstd encoded body",
+			contentType:     "text/html",
+			contentEncoding: zstdName,
+			body:            largeBody,
+			expectedBody:    strings.ReplaceAll(largeBody, "http://internal", "https://example.com"),
+		},
+		{
+			desc:            "small encoded body",
+			contentType:     "text/html",
+			contentEncoding: gzipName,
+			body:            smallBody,
+			expectedBody:    strings.ReplaceAll(smallBody, "http://internal", "https://example.com"),
+		},
+		{
+			desc:            "unsupported encoding",
+			contentType:     "text/html",
+			contentEncoding: "deflate",
+			body:            smallBody,
+			expectedBody:    smallBody,
+		},
+		{
+			desc:         "other content type",
+			contentType:  "application/json",
+			body:         smallBody,
+			expectedBody: smallBody,
+		},
+		{
+			desc:         "partial content",
+			statusCode:   http.StatusPartialContent,
+			contentType:  "text/html",
+			body:         smallBody,
+			expectedBody: smallBody,
+		},
+		{
+			desc:         "strong ETag",
+			contentType:  "text/html",
+			etag:         `"foo"`,
+			body:         "foo",
+			expectedBody: "foo",
+			expectedETag: `W/"foo"`,
+		},
+		{
+			desc:        "HEAD request",
+			method:      http.MethodHead,
+			contentType: "text/html",
+			etag:        `"foo"`,
+			body:        smallBody,
+			// The responses to HEAD requests are not rewritten.
+			expectedBody: smallBody,
+			expectedETag: `"foo"`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body := encode(t, test.contentEncoding, test.body)
+
+				rw.Header().Set(contentType, test.contentType)
+				rw.Header().Set(contentLength, strconv.Itoa(len(body)))
+				if test.contentEncoding != "" {
+					rw.Header().Set(contentEncoding, test.contentEncoding)
+				}
+				if test.etag != "" {
+					rw.Header().Set(etag, test.etag)
+				}
+
+				if test.statusCode != 0 {
+					rw.WriteHeader(test.statusCode)
+				}
+
+				// The body is written in chunks, as a proxied body would be.
+				for len(body) > 0 {
+					n := min(1000, len(body))
+					_, err := rw.Write(body[:n])
+					require.NoError(t, err)
+					body = body[n:]
+				}
+			})
+
+			handler, err := New(t.Context(), next, dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Match: "http://internal", Replacement: "https://example.com"}},
+			}, "rewriteBody")
+			require.NoError(t, err)
+
+			method := http.MethodGet
+			if test.method != "" {
+				method = test.method
+			}
+
+			req := httptest.NewRequest(method, "http://localhost", nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, req)
+
+			expectedStatusCode := http.StatusOK
+			if test.statusCode != 0 {
+				expectedStatusCode = test.statusCode
+			}
+			assert.Equal(t, expectedStatusCode, recorder.Code)
+
+			// The Content-Length header is only missing when the body is streamed,
+			// its length not being known when sending the headers.
+			if test.expectedStreamed {
+				assert.Empty(t, recorder.Header().Get(contentLength))
+			} else {
+				assert.Equal(t, strconv.Itoa(recorder.Body.Len()), recorder.Header().Get(contentLength))
+			}
+			assert.Equal(t, test.expectedETag, recorder.Header().Get(etag))
+			assert.Equal(t, test.contentEncoding, recorder.Header().Get(contentEncoding))
+
+			assert.Equal(t, test.expectedBody, decode(t, test.contentEncoding, recorder.Body.Bytes()))
+		})
+	}
+}
+
+func TestRewriteBody_noBody(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(contentType, "text/html")
+		rw.WriteHeader(http.StatusNoContent)
+	})
+
+	handler, err := New(t.Context(), next, dynamic.RewriteBody{
+		Rewrites: []dynamic.BodyRewrite{{Match: "foo", Replacement: "bar"}},
+	}, "rewriteBody")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func TestRewriteBody_flush(t *testing.T) {
+	flushed := make(chan struct{})
+	done := make(chan struct{})
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(contentType, "text/html")
+
+		_, err := rw.Write([]byte("foo " + strings.Repeat("x", 100)))
+		require.NoError(t, err)
+
+		rw.(http.Flusher).Flush()
+		close(flushed)
+
+		<-done
+	})
+
+	handler, err := New(t.Context(), next, dynamic.RewriteBody{
+		Rewrites:       []dynamic.BodyRewrite{{Match: "foo", Replacement: "bar"}},
+		MaxMatchLength: 10,
+	}, "rewriteBody")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	go func() {
+		<-flushed
+		defer close(done)
+
+		// The bytes which could start a match are held back.
+		assert.True(t, recorder.Flushed)
+		assert.Equal(t, "bar "+strings.Repeat("x", 90), recorder.Body.String())
+	}()
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Equal(t, "bar "+strings.Repeat("x", 100), recorder.Body.String())
+}
+
+func encode(t *testing.T, encoding, body string) []byte {
+	t.Helper()
+
+	// The bodies with an unsupported encoding are left as is.
+	if encoding == "" || !supportedEncoding(encoding) {
+		return []byte(body)
+	}
+
+	var buf bytes.Buffer
+
+	enc, err := newEncoder(encoding, &buf)
+	require.NoError(t, err)
+
+	_, err = enc.Write([]byte(body))
+	require.NoError(t, err)
+	require.NoError(t, enc.Close())
+
+	return buf.Bytes()
+}
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+
+	if encoding == "" || !supportedEncoding(encoding) {
+		return string(body)
+	}
+
+	decoder, err := newDecoder(encoding, bytes.NewReader(body))
+	require.NoError(t, err)
+
+	decoded, err := io.ReadAll(decoder)
+	require.NoError(t, err)
+	require.NoError(t, decoder.Close())
+
+	return string(decoded)
+}
+
+func TestRewriteBody_chunks(t *testing.T) {
+	const body = "aaab foo aab xfoox"
+
+	for _, chunkSize := range []int{1, 2, 3, 5, len(body)} {
+		t.Run(strconv.Itoa(chunkSize), func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set(contentType, "text/html")
+
+				for b := body; len(b) > 0; {
+					n := min(chunkSize, len(b))
+
+					_, err := rw.Write([]byte(b[:n]))
+					require.NoError(t, err)
+
+					rw.(http.Flusher).Flush()
+
+					b = b[n:]
+				}
+			})
+
+			handler, err := New(t.Context(), next, dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{
+					{Regex: "a+b", Replacement: "X"},
+					{Match: "foo", Replacement: "bar"},
+				},
+				MaxMatchLength: 8,
+			}, "rewriteBody")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+			assert.Equal(t, "X bar X xbarx", recorder.Body.String())
+		})
+	}
+}
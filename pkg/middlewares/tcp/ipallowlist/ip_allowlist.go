@@ -2,12 +2,14 @@ package ipallowlist
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
-	"github.com/apache4/apache4/v3/pkg/ip"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"github.com/apache4/apache4/v3/pkg/middlewares/iplist"
 	"github.com/apache4/apache4/v3/pkg/tcp"
 )
 
@@ -18,30 +20,38 @@ const (
 // ipAllowLister is a middleware that provides Checks of the Requesting IP against a set of Allowlists.
 type ipAllowLister struct {
 	next        tcp.Handler
-	allowLister *ip.Checker
+	allowLister *iplist.Ranges
 	name        string
+	hits        gokitmetrics.Counter
 }
 
-// New builds a new TCP IPAllowLister given a list of CIDR-Strings to allow.
-func New(ctx context.Context, next tcp.Handler, config dynamic.TCPIPAllowList, name string) (tcp.Handler, error) {
+// New builds a new TCP IPAllowLister given a list of CIDR-Strings to allow,
+// and the external sources of additional CIDRs.
+// When info is not nil, the external sources which have not been loaded yet are reported through it.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPIPAllowList, metricsRegistry metrics.Registry, info *runtime.TCPMiddlewareInfo, name string) (tcp.Handler, error) {
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug().Msg("Creating middleware")
 
-	if len(config.SourceRange) == 0 {
-		return nil, errors.New("sourceRange is empty, IPAllowLister not created")
+	ranges, err := iplist.New(logger.WithContext(ctx), config.SourceRange, config.ExternalSourceRange)
+	if err != nil {
+		return nil, fmt.Errorf("%w, IPAllowLister not created", err)
 	}
 
-	checker, err := ip.NewChecker(config.SourceRange)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse CIDRs %s: %w", config.SourceRange, err)
+	if info != nil {
+		info.AddExternalSourceErrors(ranges.Errors)
 	}
 
 	logger.Debug().Msgf("Setting up IPAllowLister with sourceRange: %s", config.SourceRange)
 
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
 	return &ipAllowLister{
-		allowLister: checker,
+		allowLister: ranges,
 		next:        next,
 		name:        name,
+		hits:        metricsRegistry.IPListHitsCounter().With("middleware", name, "list", "allow"),
 	}, nil
 }
 
@@ -50,7 +60,10 @@ func (al *ipAllowLister) ServeTCP(conn tcp.WriteCloser) {
 
 	addr := conn.RemoteAddr().String()
 
-	err := al.allowLister.IsAuthorized(addr)
+	allowed, err := al.allowLister.Contains(addr)
+	if err == nil && !allowed {
+		err = fmt.Errorf("%q matched none of the trusted IPs", addr)
+	}
 	if err != nil {
 		logger.Error().Err(err).Msgf("Connection from %s rejected", addr)
 		conn.Close()
@@ -59,5 +72,7 @@ func (al *ipAllowLister) ServeTCP(conn tcp.WriteCloser) {
 
 	logger.Debug().Msgf("Connection from %s accepted", addr)
 
+	al.hits.Add(1)
+
 	al.next.ServeTCP(conn)
 }
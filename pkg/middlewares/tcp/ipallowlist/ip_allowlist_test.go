@@ -43,7 +43,7 @@ func TestNewIPAllowLister(t *testing.T) {
 			t.Parallel()
 
 			next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {})
-			allowLister, err := New(t.Context(), next, test.allowList, "apache4Test")
+			allowLister, err := New(t.Context(), next, test.allowList, nil, nil, "apache4Test")
 
 			if test.expectedError {
 				assert.Error(t, err)
@@ -92,7 +92,7 @@ func TestIPAllowLister_ServeHTTP(t *testing.T) {
 				require.NoError(t, err)
 			})
 
-			allowLister, err := New(t.Context(), next, test.allowList, "apache4Test")
+			allowLister, err := New(t.Context(), next, test.allowList, nil, nil, "apache4Test")
 			require.NoError(t, err)
 
 			server, client := net.Pipe()
@@ -0,0 +1,81 @@
+package ipdenylist
+
+import (
+	"context"
+	"fmt"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"github.com/apache4/apache4/v3/pkg/middlewares/iplist"
+	"github.com/apache4/apache4/v3/pkg/tcp"
+)
+
+const (
+	typeName = "IPDenyListerTCP"
+)
+
+// ipDenyLister is a middleware that rejects the connections whose IP is in a set of Denylists.
+type ipDenyLister struct {
+	next       tcp.Handler
+	denyLister *iplist.Ranges
+	name       string
+	hits       gokitmetrics.Counter
+}
+
+// New builds a new TCP IPDenyLister given a list of CIDR-Strings to deny,
+// and the external sources of additional CIDRs.
+// When info is not nil, the external sources which have not been loaded yet are reported through it.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPIPDenyList, metricsRegistry metrics.Registry, info *runtime.TCPMiddlewareInfo, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	ranges, err := iplist.New(logger.WithContext(ctx), config.SourceRange, config.ExternalSourceRange)
+	if err != nil {
+		return nil, fmt.Errorf("%w, IPDenyLister not created", err)
+	}
+
+	if info != nil {
+		info.AddExternalSourceErrors(ranges.Errors)
+	}
+
+	logger.Debug().Msgf("Setting up IPDenyLister with sourceRange: %s", config.SourceRange)
+
+	if metricsRegistry == nil {
+		metricsRegistry = metrics.NewVoidRegistry()
+	}
+
+	return &ipDenyLister{
+		denyLister: ranges,
+		next:       next,
+		name:       name,
+		hits:       metricsRegistry.IPListHitsCounter().With("middleware", name, "list", "deny"),
+	}, nil
+}
+
+func (dl *ipDenyLister) ServeTCP(conn tcp.WriteCloser) {
+	logger := middlewares.GetLogger(context.Background(), dl.name, typeName)
+
+	addr := conn.RemoteAddr().String()
+
+	denied, err := dl.denyLister.Contains(addr)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Connection from %s rejected", addr)
+		conn.Close()
+		return
+	}
+
+	if denied {
+		dl.hits.Add(1)
+
+		logger.Debug().Msgf("Connection from %s rejected: matched the denied IPs", addr)
+		conn.Close()
+		return
+	}
+
+	logger.Debug().Msgf("Connection from %s accepted", addr)
+
+	dl.next.ServeTCP(conn)
+}
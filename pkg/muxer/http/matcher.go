@@ -9,22 +9,24 @@ import (
 	"unicode/utf8"
 
 	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/ip"
 	"github.com/apache4/apache4/v3/pkg/middlewares/requestdecorator"
 )
 
 var httpFuncs = matcherBuilderFuncs{
-	"ClientIP":     expectNParameters(clientIP, 1),
-	"Method":       expectNParameters(method, 1),
-	"Host":         expectNParameters(host, 1),
-	"HostRegexp":   expectNParameters(hostRegexp, 1),
-	"Path":         expectNParameters(path, 1),
-	"PathRegexp":   expectNParameters(pathRegexp, 1),
-	"PathPrefix":   expectNParameters(pathPrefix, 1),
-	"Header":       expectNParameters(header, 2),
-	"HeaderRegexp": expectNParameters(headerRegexp, 2),
-	"Query":        expectNParameters(query, 1, 2),
-	"QueryRegexp":  expectNParameters(queryRegexp, 1, 2),
+	"ClientIP":      expectNParameters(clientIP, 1),
+	"ClientCountry": expectNParameters(clientCountry, 1),
+	"Method":        expectNParameters(method, 1),
+	"Host":          expectNParameters(host, 1),
+	"HostRegexp":    expectNParameters(hostRegexp, 1),
+	"Path":          expectNParameters(path, 1),
+	"PathRegexp":    expectNParameters(pathRegexp, 1),
+	"PathPrefix":    expectNParameters(pathPrefix, 1),
+	"Header":        expectNParameters(header, 2),
+	"HeaderRegexp":  expectNParameters(headerRegexp, 2),
+	"Query":         expectNParameters(query, 1, 2),
+	"QueryRegexp":   expectNParameters(queryRegexp, 1, 2),
 }
 
 func expectNParameters(fn func(*matchersTree, ...string) error, n ...int) func(*matchersTree, ...string) error {
@@ -46,7 +48,13 @@ func clientIP(tree *matchersTree, clientIP ...string) error {
 	strategy := ip.RemoteAddrStrategy{}
 
 	tree.matcher = func(req *http.Request) bool {
-		ok, err := checker.Contains(strategy.GetIP(req))
+		remoteIP := strategy.GetIP(req)
+		if remoteIP == "" {
+			// The clients of a unix socket have no remote IP.
+			return false
+		}
+
+		ok, err := checker.Contains(remoteIP)
 		if err != nil {
 			log.Ctx(req.Context()).Warn().Err(err).Msg("ClientIP matcher: could not match remote address")
 			return false
@@ -58,6 +66,39 @@ func clientIP(tree *matchersTree, clientIP ...string) error {
 	return nil
 }
 
+func clientCountry(tree *matchersTree, countries ...string) error {
+	country, err := geoip.ParseCountryCode(countries[0])
+	if err != nil {
+		return fmt.Errorf("parsing ClientCountry matcher: %w", err)
+	}
+
+	strategy := ip.RemoteAddrStrategy{}
+
+	tree.matcher = func(req *http.Request) bool {
+		db := geoip.DatabaseFromContext(req.Context())
+		if db == nil {
+			log.Ctx(req.Context()).Warn().Msg("ClientCountry matcher: no GeoIP database configured")
+			return false
+		}
+
+		remoteIP := strategy.GetIP(req)
+		if remoteIP == "" {
+			// The clients of a unix socket have no remote IP.
+			return false
+		}
+
+		record, err := db.LookupHost(remoteIP)
+		if err != nil {
+			log.Ctx(req.Context()).Warn().Err(err).Msg("ClientCountry matcher: could not look up remote address")
+			return false
+		}
+
+		return record.Country == country
+	}
+
+	return nil
+}
+
 func method(tree *matchersTree, methods ...string) error {
 	method := strings.ToUpper(methods[0])
 
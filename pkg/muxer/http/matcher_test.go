@@ -3,12 +3,15 @@ package http
 import (
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/middlewares/requestdecorator"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
 )
 
 func TestClientIPMatcher(t *testing.T) {
@@ -97,6 +100,102 @@ func TestClientIPMatcher(t *testing.T) {
 	}
 }
 
+func TestClientCountryMatcher(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "country.mmdb")
+	testhelpers.WriteGeoIPDatabase(t, dbPath, 24, map[string]map[string]any{
+		"10.0.0.0/24":   {"country": map[string]any{"iso_code": "FR"}},
+		"2001:db8::/32": {"country": map[string]any{"iso_code": "DE"}},
+	})
+
+	db, err := geoip.NewDatabase(t.Context(), dbPath)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		rule          string
+		db            *geoip.Database
+		expected      map[string]int
+		expectedError bool
+	}{
+		{
+			desc:          "invalid ClientCountry matcher (no parameter)",
+			rule:          "ClientCountry()",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid ClientCountry matcher (not a country code)",
+			rule:          "ClientCountry(`France`)",
+			expectedError: true,
+		},
+		{
+			desc:          "invalid ClientCountry matcher (too many parameters)",
+			rule:          "ClientCountry(`FR`, `DE`)",
+			expectedError: true,
+		},
+		{
+			desc: "valid ClientCountry matcher",
+			rule: "ClientCountry(`FR`)",
+			db:   db,
+			expected: map[string]int{
+				"10.0.0.1:1234":       http.StatusOK,
+				"10.0.1.1:1234":       http.StatusNotFound,
+				"[2001:db8::1]:1234":  http.StatusNotFound,
+				"[2001:db9::1]:1234":  http.StatusNotFound,
+				"[::ffff:10.0.0.1]:1": http.StatusOK,
+			},
+		},
+		{
+			desc: "valid ClientCountry matcher with an IPv6 address",
+			rule: "ClientCountry(`de`)",
+			db:   db,
+			expected: map[string]int{
+				"10.0.0.1:1234":      http.StatusNotFound,
+				"[2001:db8::1]:1234": http.StatusOK,
+			},
+		},
+		{
+			desc: "valid ClientCountry matcher without GeoIP database",
+			rule: "ClientCountry(`FR`)",
+			expected: map[string]int{
+				"10.0.0.1:1234": http.StatusNotFound,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			parser, err := NewSyntaxParser()
+			require.NoError(t, err)
+
+			muxer := NewMuxer(parser)
+
+			err = muxer.AddRoute(test.rule, "", 0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			if test.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			handler, err := geoip.WrapHandler(test.db)(muxer)
+			require.NoError(t, err)
+
+			results := make(map[string]int)
+			for remoteAddr := range test.expected {
+				w := httptest.NewRecorder()
+
+				req := httptest.NewRequest(http.MethodGet, "https://example.com", http.NoBody)
+				req.RemoteAddr = remoteAddr
+
+				handler.ServeHTTP(w, req)
+				results[remoteAddr] = w.Code
+			}
+			assert.Equal(t, test.expected, results)
+		})
+	}
+}
+
 func TestMethodMatcher(t *testing.T) {
 	testCases := []struct {
 		desc          string
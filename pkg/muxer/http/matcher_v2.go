@@ -143,7 +143,13 @@ func clientIPV2(tree *matchersTree, clientIPs ...string) error {
 	strategy := ip.RemoteAddrStrategy{}
 
 	tree.matcher = func(req *http.Request) bool {
-		ok, err := checker.Contains(strategy.GetIP(req))
+		remoteIP := strategy.GetIP(req)
+		if remoteIP == "" {
+			// The clients of a unix socket have no remote IP.
+			return false
+		}
+
+		ok, err := checker.Contains(remoteIP)
 		if err != nil {
 			log.Ctx(req.Context()).Warn().Err(err).Msg("\"ClientIP\" matcher: could not match remote address")
 			return false
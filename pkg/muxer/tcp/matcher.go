@@ -8,12 +8,14 @@ import (
 
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/ip"
 )
 
 var tcpFuncs = map[string]func(*matchersTree, ...string) error{
 	"ALPN":          expect1Parameter(alpn),
 	"ClientIP":      expect1Parameter(clientIP),
+	"ClientCountry": expect1Parameter(clientCountry),
 	"HostSNI":       expect1Parameter(hostSNI),
 	"HostSNIRegexp": expect1Parameter(hostSNIRegexp),
 }
@@ -56,6 +58,10 @@ func clientIP(tree *matchersTree, clientIP ...string) error {
 	}
 
 	tree.matcher = func(meta ConnData) bool {
+		if meta.remoteIP == "" {
+			return false
+		}
+
 		ok, err := checker.Contains(meta.remoteIP)
 		if err != nil {
 			log.Warn().Err(err).Msg("ClientIP matcher: could not match remote address")
@@ -67,6 +73,19 @@ func clientIP(tree *matchersTree, clientIP ...string) error {
 	return nil
 }
 
+func clientCountry(tree *matchersTree, countries ...string) error {
+	country, err := geoip.ParseCountryCode(countries[0])
+	if err != nil {
+		return fmt.Errorf("parsing ClientCountry matcher: %w", err)
+	}
+
+	tree.matcher = func(meta ConnData) bool {
+		return meta.clientCountry == country
+	}
+
+	return nil
+}
+
 var hostOrIP = regexp.MustCompile(`^[[:word:]\.\-\:]+$`)
 
 // hostSNI checks if the SNI Host of the connection match the matcher host.
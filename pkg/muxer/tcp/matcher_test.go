@@ -320,6 +320,72 @@ func Test_ClientIP(t *testing.T) {
 	}
 }
 
+func Test_ClientCountry(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		rule     string
+		expected map[string]bool
+		buildErr bool
+	}{
+		{
+			desc:     "Invalid ClientCountry matcher (empty country)",
+			rule:     "ClientCountry(``)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid ClientCountry matcher (not a country code)",
+			rule:     "ClientCountry(`FRA`)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid ClientCountry matcher (too many parameters)",
+			rule:     "ClientCountry(`FR`, `DE`)",
+			buildErr: true,
+		},
+		{
+			desc: "valid ClientCountry matcher",
+			rule: "ClientCountry(`FR`)",
+			expected: map[string]bool{
+				"FR": true,
+				"DE": false,
+				"":   false,
+			},
+		},
+		{
+			desc: "valid lower case ClientCountry matcher",
+			rule: "ClientCountry(`fr`)",
+			expected: map[string]bool{
+				"FR": true,
+				"DE": false,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			muxer, err := NewMuxer()
+			require.NoError(t, err)
+
+			err = muxer.AddRoute(test.rule, "", 0, tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+			if test.buildErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			for country, match := range test.expected {
+				var meta ConnData
+				meta.SetClientCountry(country)
+
+				handler, _ := muxer.Match(meta)
+				assert.Equal(t, match, handler != nil, country)
+			}
+		})
+	}
+}
+
 func Test_ALPN(t *testing.T) {
 	testCases := []struct {
 		desc     string
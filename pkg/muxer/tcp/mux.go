@@ -15,16 +15,22 @@ import (
 
 // ConnData contains TCP connection metadata.
 type ConnData struct {
-	serverName string
-	remoteIP   string
-	alpnProtos []string
+	serverName    string
+	remoteIP      string
+	alpnProtos    []string
+	clientCountry string
 }
 
 // NewConnData builds a connData struct from the given parameters.
+// The clients of a unix socket have no remote IP.
 func NewConnData(serverName string, conn tcp.WriteCloser, alpnProtos []string) (ConnData, error) {
-	remoteIP, _, err := net.SplitHostPort(conn.RemoteAddr().String())
-	if err != nil {
-		return ConnData{}, fmt.Errorf("error while parsing remote address %q: %w", conn.RemoteAddr().String(), err)
+	var remoteIP string
+	if _, ok := conn.RemoteAddr().(*net.UnixAddr); !ok {
+		var err error
+		remoteIP, _, err = net.SplitHostPort(conn.RemoteAddr().String())
+		if err != nil {
+			return ConnData{}, fmt.Errorf("error while parsing remote address %q: %w", conn.RemoteAddr().String(), err)
+		}
 	}
 
 	// as per https://datatracker.ietf.org/doc/html/rfc6066:
@@ -39,6 +45,11 @@ func NewConnData(serverName string, conn tcp.WriteCloser, alpnProtos []string) (
 	}, nil
 }
 
+// SetClientCountry sets the country code of the client, as found in the GeoIP database.
+func (c *ConnData) SetClientCountry(country string) {
+	c.clientCountry = country
+}
+
 // Muxer defines a muxer that handles TCP routing with rules.
 type Muxer struct {
 	routes   routes
@@ -313,6 +313,26 @@ func Test_addTCPRoute(t *testing.T) {
 	}
 }
 
+func TestNewConnData_unixSocket(t *testing.T) {
+	conn := &fakeConn{
+		call:       map[string]int{},
+		remoteAddr: &net.UnixAddr{Name: "@", Net: "unix"},
+	}
+
+	connData, err := NewConnData("foo", conn, nil)
+	require.NoError(t, err)
+	assert.Empty(t, connData.remoteIP)
+
+	router, err := NewMuxer()
+	require.NoError(t, err)
+
+	err = router.AddRoute("ClientIP(`0.0.0.0/0`)", "", 0, tcp.HandlerFunc(func(conn tcp.WriteCloser) {}))
+	require.NoError(t, err)
+
+	handler, _ := router.Match(connData)
+	assert.Nil(t, handler)
+}
+
 func TestParseHostSNI(t *testing.T) {
 	testCases := []struct {
 		desc          string
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 
 	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/tcp"
 )
 
 // Constructor creates a plugin handler.
@@ -21,19 +23,34 @@ type middlewareBuilder interface {
 	newMiddleware(config map[string]interface{}, middlewareName string) (pluginMiddleware, error)
 }
 
+// TCPConstructor creates a plugin TCP handler.
+type TCPConstructor func(context.Context, tcp.Handler) (tcp.Handler, error)
+
+type pluginTCPMiddleware interface {
+	NewHandler(ctx context.Context, next tcp.Handler) (tcp.Handler, error)
+}
+
+type tcpMiddlewareBuilder interface {
+	newTCPMiddleware(config map[string]interface{}, middlewareName string) (pluginTCPMiddleware, error)
+}
+
 // Builder is a plugin builder.
 type Builder struct {
-	providerBuilders   map[string]providerBuilder
-	middlewareBuilders map[string]middlewareBuilder
+	providerBuilders      map[string]providerBuilder
+	middlewareBuilders    map[string]middlewareBuilder
+	tcpMiddlewareBuilders map[string]tcpMiddlewareBuilder
+	watchedPlugins        []*watchedPlugin
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(client *Client, plugins map[string]Descriptor, localPlugins map[string]LocalDescriptor) (*Builder, error) {
+// The metrics registry receives the metrics of the Wasm middleware plugins.
+func NewBuilder(client *Client, plugins map[string]Descriptor, localPlugins map[string]LocalDescriptor, metricsRegistry metrics.Registry) (*Builder, error) {
 	ctx := context.Background()
 
 	pb := &Builder{
-		middlewareBuilders: map[string]middlewareBuilder{},
-		providerBuilders:   map[string]providerBuilder{},
+		middlewareBuilders:    map[string]middlewareBuilder{},
+		tcpMiddlewareBuilders: map[string]tcpMiddlewareBuilder{},
+		providerBuilders:      map[string]providerBuilder{},
 	}
 
 	for pName, desc := range plugins {
@@ -52,15 +69,23 @@ func NewBuilder(client *Client, plugins map[string]Descriptor, localPlugins map[
 
 		switch manifest.Type {
 		case typeMiddleware:
-			middleware, err := newMiddlewareBuilder(logCtx, client.GoPath(), manifest, desc.ModuleName, desc.Settings)
+			middleware, err := newMiddlewareBuilder(logCtx, client.GoPath(), manifest, desc.ModuleName, desc.Settings, metricsRegistry)
 			if err != nil {
 				return nil, err
 			}
 
 			pb.middlewareBuilders[pName] = middleware
 
+		case typeTCPMiddleware:
+			middleware, err := newTCPMiddlewareBuilder(logCtx, client.GoPath(), manifest, desc.ModuleName, desc.Settings)
+			if err != nil {
+				return nil, err
+			}
+
+			pb.tcpMiddlewareBuilders[pName] = middleware
+
 		case typeProvider:
-			pBuilder, err := newProviderBuilder(logCtx, manifest, client.GoPath(), desc.Settings)
+			pBuilder, err := newProviderBuilder(logCtx, manifest, client.GoPath(), desc.ModuleName, desc.Settings)
 			if err != nil {
 				return nil, fmt.Errorf("%s: %w", desc.ModuleName, err)
 			}
@@ -73,6 +98,17 @@ func NewBuilder(client *Client, plugins map[string]Descriptor, localPlugins map[
 	}
 
 	for pName, desc := range localPlugins {
+		if desc.Watch {
+			// The build errors of the watched plugins are reported on the middlewares using them.
+			p := newWatchedPlugin(pName, localGoPath, desc, metricsRegistry)
+
+			pb.middlewareBuilders[pName] = p
+			pb.tcpMiddlewareBuilders[pName] = p
+			pb.watchedPlugins = append(pb.watchedPlugins, p)
+
+			continue
+		}
+
 		manifest, err := ReadManifest(localGoPath, desc.ModuleName)
 		if err != nil {
 			return nil, fmt.Errorf("%s: failed to read manifest: %w", desc.ModuleName, err)
@@ -87,15 +123,23 @@ func NewBuilder(client *Client, plugins map[string]Descriptor, localPlugins map[
 
 		switch manifest.Type {
 		case typeMiddleware:
-			middleware, err := newMiddlewareBuilder(logCtx, localGoPath, manifest, desc.ModuleName, desc.Settings)
+			middleware, err := newMiddlewareBuilder(logCtx, localGoPath, manifest, desc.ModuleName, desc.Settings, metricsRegistry)
 			if err != nil {
 				return nil, err
 			}
 
 			pb.middlewareBuilders[pName] = middleware
 
+		case typeTCPMiddleware:
+			middleware, err := newTCPMiddlewareBuilder(logCtx, localGoPath, manifest, desc.ModuleName, desc.Settings)
+			if err != nil {
+				return nil, err
+			}
+
+			pb.tcpMiddlewareBuilders[pName] = middleware
+
 		case typeProvider:
-			builder, err := newProviderBuilder(logCtx, manifest, localGoPath, desc.Settings)
+			builder, err := newProviderBuilder(logCtx, manifest, localGoPath, desc.ModuleName, desc.Settings)
 			if err != nil {
 				return nil, fmt.Errorf("%s: %w", desc.ModuleName, err)
 			}
@@ -128,7 +172,26 @@ func (b Builder) Build(pName string, config map[string]interface{}, middlewareNa
 	return nil, fmt.Errorf("unknown plugin type: %s", pName)
 }
 
-func newMiddlewareBuilder(ctx context.Context, goPath string, manifest *Manifest, moduleName string, settings Settings) (middlewareBuilder, error) {
+// BuildTCP builds a TCP middleware plugin.
+func (b Builder) BuildTCP(pName string, config map[string]interface{}, middlewareName string) (TCPConstructor, error) {
+	if b.tcpMiddlewareBuilders == nil {
+		return nil, fmt.Errorf("no plugin definitions in the static configuration: %s", pName)
+	}
+
+	// plugin (pName) can be located in yaegi or wasm TCP middleware builders.
+	if descriptor, ok := b.tcpMiddlewareBuilders[pName]; ok {
+		m, err := descriptor.newTCPMiddleware(config, middlewareName)
+		if err != nil {
+			return nil, err
+		}
+
+		return m.NewHandler, nil
+	}
+
+	return nil, fmt.Errorf("unknown TCP plugin type: %s", pName)
+}
+
+func newMiddlewareBuilder(ctx context.Context, goPath string, manifest *Manifest, moduleName string, settings Settings, metricsRegistry metrics.Registry) (middlewareBuilder, error) {
 	switch manifest.Runtime {
 	case runtimeWasm:
 		wasmPath, err := getWasmPath(manifest)
@@ -136,7 +199,7 @@ func newMiddlewareBuilder(ctx context.Context, goPath string, manifest *Manifest
 			return nil, fmt.Errorf("wasm path: %w", err)
 		}
 
-		return newWasmMiddlewareBuilder(goPath, moduleName, wasmPath, settings)
+		return newWasmMiddlewareBuilder(goPath, moduleName, wasmPath, settings, metricsRegistry)
 
 	case runtimeYaegi, "":
 		i, err := newInterpreter(ctx, goPath, manifest, settings)
@@ -151,22 +214,53 @@ func newMiddlewareBuilder(ctx context.Context, goPath string, manifest *Manifest
 	}
 }
 
-func newProviderBuilder(ctx context.Context, manifest *Manifest, goPath string, settings Settings) (providerBuilder, error) {
+func newTCPMiddlewareBuilder(ctx context.Context, goPath string, manifest *Manifest, moduleName string, settings Settings) (tcpMiddlewareBuilder, error) {
+	switch manifest.Runtime {
+	case runtimeWasm:
+		wasmPath, err := getWasmPath(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("wasm path: %w", err)
+		}
+
+		return newWasmTCPMiddlewareBuilder(goPath, moduleName, wasmPath, settings)
+
+	case runtimeYaegi, "":
+		i, err := newInterpreter(ctx, goPath, manifest, settings)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Yaegi interpreter: %w", err)
+		}
+
+		return newYaegiTCPMiddlewareBuilder(i, manifest.BasePkg, manifest.Import)
+
+	default:
+		return nil, fmt.Errorf("unknown plugin runtime: %s", manifest.Runtime)
+	}
+}
+
+func newProviderBuilder(ctx context.Context, manifest *Manifest, goPath, moduleName string, settings Settings) (providerBuilder, error) {
 	switch manifest.Runtime {
+	case runtimeWasm:
+		wasmPath, err := getWasmPath(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("wasm path: %w", err)
+		}
+
+		return newWasmProviderBuilder(goPath, moduleName, wasmPath, settings)
+
 	case runtimeYaegi, "":
 		i, err := newInterpreter(ctx, goPath, manifest, settings)
 		if err != nil {
-			return providerBuilder{}, err
+			return nil, err
 		}
 
-		return providerBuilder{
+		return yaegiProviderBuilder{
 			interpreter: i,
 			Import:      manifest.Import,
 			BasePkg:     manifest.BasePkg,
 		}, nil
 
 	default:
-		return providerBuilder{}, fmt.Errorf("unknown plugin runtime: %s", manifest.Runtime)
+		return nil, fmt.Errorf("unknown plugin runtime: %s", manifest.Runtime)
 	}
 }
 
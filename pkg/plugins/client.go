@@ -39,9 +39,15 @@ const (
 	hashHeader = "X-Plugin-Hash"
 )
 
+const signatureExt = ".sig"
+
+// ErrIntegrityCheck is returned when a plugin archive does not match its pinned digest or signature.
+var ErrIntegrityCheck = errors.New("plugin integrity check failed")
+
 // ClientOptions the options of a apache4 plugins client.
 type ClientOptions struct {
-	Output string
+	Output   string
+	Registry *Registry
 }
 
 // Client a apache4 plugins client.
@@ -49,6 +55,11 @@ type Client struct {
 	HTTPClient *http.Client
 	baseURL    *url.URL
 
+	// directory and ociLayout are the local sources of the archives, used instead of the registry.
+	directory string
+	ociLayout string
+	verifier  *signatureVerifier
+
 	archives  string
 	stateFile string
 	goPath    string
@@ -57,13 +68,47 @@ type Client struct {
 
 // NewClient creates a new apache4 plugins client.
 func NewClient(opts ClientOptions) (*Client, error) {
-	baseURL, err := url.Parse(pluginsURL)
-	if err != nil {
-		return nil, err
+	registry := opts.Registry
+	if registry == nil {
+		registry = &Registry{}
+	}
+
+	var sources int
+	for _, source := range []string{registry.URL, registry.Directory, registry.OCILayout} {
+		if source != "" {
+			sources++
+		}
+	}
+
+	if sources > 1 {
+		return nil, errors.New("only one of the registry URL, directory, and OCI layout can be set")
+	}
+
+	var baseURL *url.URL
+	if registry.Directory == "" && registry.OCILayout == "" {
+		registryURL := pluginsURL
+		if registry.URL != "" {
+			registryURL = registry.URL
+		}
+
+		var err error
+		baseURL, err = url.Parse(registryURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry URL: %w", err)
+		}
+	}
+
+	var verifier *signatureVerifier
+	if len(registry.PublicKeys) > 0 {
+		var err error
+		verifier, err = newSignatureVerifier(registry.PublicKeys)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	sourcesRootPath := filepath.Join(filepath.FromSlash(opts.Output), sourcesFolder)
-	err = resetDirectory(sourcesRootPath)
+	err := resetDirectory(sourcesRootPath)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +133,10 @@ func NewClient(opts ClientOptions) (*Client, error) {
 		HTTPClient: client.StandardClient(),
 		baseURL:    baseURL,
 
+		directory: registry.Directory,
+		ociLayout: registry.OCILayout,
+		verifier:  verifier,
+
 		archives:  archivesPath,
 		stateFile: filepath.Join(archivesPath, stateFilename),
 
@@ -126,8 +175,32 @@ func ReadManifest(goPath, moduleName string) (*Manifest, error) {
 	return m, nil
 }
 
-// Download downloads a plugin archive.
+// Download downloads a plugin archive, and its signature when public keys are configured.
+// The archive is copied from the local directory or OCI layout when one of them is configured.
 func (c *Client) Download(ctx context.Context, pName, pVersion string) (string, error) {
+	switch {
+	case c.directory != "":
+		return c.copyFromDirectory(pName, pVersion)
+
+	case c.ociLayout != "":
+		return c.extractFromOCILayout(pName, pVersion)
+	}
+
+	hash, err := c.downloadFromRegistry(ctx, pName, pVersion)
+	if err != nil {
+		return "", err
+	}
+
+	if c.verifier != nil {
+		if err = c.downloadSignature(ctx, pName, pVersion); err != nil {
+			return "", fmt.Errorf("failed to download signature: %w", err)
+		}
+	}
+
+	return hash, nil
+}
+
+func (c *Client) downloadFromRegistry(ctx context.Context, pName, pVersion string) (string, error) {
 	filename := c.buildArchivePath(pName, pVersion)
 
 	var hash string
@@ -201,8 +274,81 @@ func (c *Client) Download(ctx context.Context, pName, pVersion string) (string,
 	}
 }
 
+func (c *Client) downloadSignature(ctx context.Context, pName, pVersion string) error {
+	endpoint, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "signature", pName, pVersion))
+	if err != nil {
+		return fmt.Errorf("failed to parse endpoint URL: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call service: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error: %d: %s", resp.StatusCode, string(data))
+	}
+
+	return os.WriteFile(c.buildArchivePath(pName, pVersion)+signatureExt, data, 0o600)
+}
+
 // Check checks the plugin archive integrity.
-func (c *Client) Check(ctx context.Context, pName, pVersion, hash string) error {
+// The archive is verified against the pinned hash and the configured public keys,
+// and only relies on the registry validation when none of them are set.
+func (c *Client) Check(ctx context.Context, pName, pVersion, pinnedHash, hash string) error {
+	if pinnedHash != "" {
+		expected := strings.ToLower(strings.TrimPrefix(pinnedHash, "sha256:"))
+		if expected != hash {
+			return fmt.Errorf("%w: the archive SHA-256 digest %s does not match the pinned digest %s", ErrIntegrityCheck, hash, expected)
+		}
+	}
+
+	if c.verifier != nil {
+		if err := c.checkSignature(pName, pVersion); err != nil {
+			return fmt.Errorf("%w: %w", ErrIntegrityCheck, err)
+		}
+	}
+
+	if pinnedHash != "" || c.verifier != nil {
+		return nil
+	}
+
+	if c.baseURL == nil {
+		return fmt.Errorf("%w: a pinned hash or public keys are required to verify the archives of a local source", ErrIntegrityCheck)
+	}
+
+	return c.checkFromRegistry(ctx, pName, pVersion, hash)
+}
+
+func (c *Client) checkSignature(pName, pVersion string) error {
+	archivePath := c.buildArchivePath(pName, pVersion)
+
+	signature, err := os.ReadFile(archivePath + signatureExt)
+	if err != nil {
+		return fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	return c.verifier.verify(data, signature)
+}
+
+func (c *Client) checkFromRegistry(ctx context.Context, pName, pVersion, hash string) error {
 	endpoint, err := c.baseURL.Parse(path.Join(c.baseURL.Path, "validate", pName, pVersion))
 	if err != nil {
 		return fmt.Errorf("failed to parse endpoint URL: %w", err)
@@ -228,7 +374,7 @@ func (c *Client) Check(ctx context.Context, pName, pVersion, hash string) error
 		return nil
 	}
 
-	return errors.New("plugin integrity check failed")
+	return ErrIntegrityCheck
 }
 
 // Unzip unzip a plugin archive.
@@ -365,6 +511,10 @@ func (c *Client) CleanArchives(plugins map[string]Descriptor) error {
 				if err = os.RemoveAll(archivePath); err != nil {
 					return fmt.Errorf("failed to remove archive %s: %w", archivePath, err)
 				}
+
+				if err = os.RemoveAll(archivePath + signatureExt); err != nil {
+					return fmt.Errorf("failed to remove signature %s: %w", archivePath+signatureExt, err)
+				}
 			}
 		}
 	}
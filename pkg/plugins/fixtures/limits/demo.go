@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"unsafe"
+)
+
+var (
+	iterations uint64
+	allocated  []byte
+)
+
+// Built by the tests (see buildWasmFixture) with
+// GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -ldflags="-s -w" -trimpath -o plugin.wasm .
+func main() {}
+
+//go:wasmexport handle_request
+func handleRequest() uint64 {
+	buf := make([]byte, 256)
+	n := getURI(unsafe.Pointer(&buf[0]), uint32(len(buf)))
+	if n > uint32(len(buf)) {
+		n = uint32(len(buf))
+	}
+
+	switch uri := string(buf[:n]); {
+	case strings.HasPrefix(uri, "/loop"):
+		for {
+			iterations++
+		}
+	case strings.HasPrefix(uri, "/alloc"):
+		allocated = make([]byte, 64<<20)
+		for i := range allocated {
+			allocated[i] = byte(i)
+		}
+
+		writeResponse("allocated")
+		return 0
+	case strings.HasPrefix(uri, "/reply"):
+		writeResponse("reply")
+		return 0
+	default:
+		return 1
+	}
+}
+
+//go:wasmexport handle_response
+func handleResponse(reqCtx, isError uint32) {}
+
+func writeResponse(msg string) {
+	setStatusCode(200)
+
+	b := []byte(msg)
+	writeBody(1, unsafe.Pointer(&b[0]), uint32(len(b)))
+}
+
+//go:wasmimport http_handler get_uri
+func getURI(buf unsafe.Pointer, bufLimit uint32) uint32
+
+//go:wasmimport http_handler set_status_code
+func setStatusCode(code uint32)
+
+//go:wasmimport http_handler write_body
+func writeBody(kind uint32, buf unsafe.Pointer, bufLen uint32)
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+type config struct {
+	URL      string `json:"url"`
+	Interval int32  `json:"interval"`
+}
+
+type httpRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+type httpResponse struct {
+	StatusCode int    `json:"statusCode"`
+	Body       []byte `json:"body"`
+}
+
+var cfg config
+
+// Built by the tests (see buildWasmFixture) with
+// GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -ldflags="-s -w" -trimpath -o plugin.wasm .
+func main() {}
+
+//go:wasmexport init
+func initProvider() int32 {
+	buf := make([]byte, 1024)
+	n := getConfig(unsafe.Pointer(&buf[0]), uint32(len(buf)))
+	if n > uint32(len(buf)) {
+		return 1
+	}
+
+	if err := json.Unmarshal(buf[:n], &cfg); err != nil {
+		logMessage(2, "Could not load config "+err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+//go:wasmexport provide
+func provide() int32 {
+	host, ok := fetchHost()
+	if !ok {
+		return cfg.Interval
+	}
+
+	data, _ := json.Marshal(map[string]any{
+		"http": map[string]any{
+			"routers": map[string]any{
+				"demo": map[string]any{
+					"rule":    "Host(`" + host + "`)",
+					"service": "demo",
+				},
+			},
+		},
+	})
+
+	if emitConfiguration(unsafe.Pointer(&data[0]), uint32(len(data))) != 0 {
+		return -1
+	}
+
+	return cfg.Interval
+}
+
+//go:wasmexport stop
+func stop() {
+	logMessage(0, "Provider stopped")
+}
+
+func fetchHost() (string, bool) {
+	req, _ := json.Marshal(httpRequest{Method: "GET", URL: cfg.URL})
+
+	n := sendHTTPRequest(unsafe.Pointer(&req[0]), uint32(len(req)))
+	if n < 0 {
+		logMessage(1, "HTTP request failed")
+		return "", false
+	}
+
+	buf := make([]byte, n)
+	getHTTPResponse(unsafe.Pointer(&buf[0]), uint32(len(buf)))
+
+	var resp httpResponse
+	if err := json.Unmarshal(buf, &resp); err != nil || resp.StatusCode != 200 {
+		logMessage(1, "Invalid HTTP response")
+		return "", false
+	}
+
+	return string(resp.Body), true
+}
+
+func logMessage(level int32, msg string) {
+	b := []byte(msg)
+	log(level, unsafe.Pointer(&b[0]), uint32(len(b)))
+}
+
+//go:wasmimport apache4_provider get_config
+func getConfig(buf unsafe.Pointer, bufLimit uint32) uint32
+
+//go:wasmimport apache4_provider log
+func log(level int32, buf unsafe.Pointer, bufLen uint32)
+
+//go:wasmimport apache4_provider emit_configuration
+func emitConfiguration(buf unsafe.Pointer, bufLen uint32) int32
+
+//go:wasmimport apache4_provider http_request
+func sendHTTPRequest(buf unsafe.Pointer, bufLen uint32) int32
+
+//go:wasmimport apache4_provider get_http_response
+func getHTTPResponse(buf unsafe.Pointer, bufLimit uint32) uint32
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"unsafe"
+)
+
+type config struct {
+	Reply string `json:"reply"`
+}
+
+var cfg config
+
+// Built by the tests (see buildWasmFixture) with
+// GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -ldflags="-s -w" -trimpath -o plugin.wasm .
+func main() {}
+
+func init() {
+	buf := make([]byte, 1024)
+	n := getConfig(unsafe.Pointer(&buf[0]), uint32(len(buf)))
+	if n == 0 {
+		return
+	}
+
+	if err := json.Unmarshal(buf[:n], &cfg); err != nil {
+		logMessage(2, "Could not load config "+err.Error())
+	}
+}
+
+//go:wasmexport handle_conn
+func handleConn() uint32 {
+	buf := make([]byte, 4)
+	n := peek(unsafe.Pointer(&buf[0]), uint32(len(buf)))
+	if n < 0 {
+		return 0
+	}
+
+	switch string(buf[:n]) {
+	case "PING":
+		read(unsafe.Pointer(&buf[0]), uint32(len(buf)))
+
+		reply := []byte(cfg.Reply)
+		if len(reply) > 0 {
+			write(unsafe.Pointer(&reply[0]), uint32(len(reply)))
+		}
+		return 0
+	case "DENY":
+		logMessage(0, "Connection denied")
+		return 0
+	default:
+		return 1
+	}
+}
+
+func logMessage(level int32, msg string) {
+	b := []byte(msg)
+	log(level, unsafe.Pointer(&b[0]), uint32(len(b)))
+}
+
+//go:wasmimport apache4_tcp get_config
+func getConfig(buf unsafe.Pointer, bufLimit uint32) uint32
+
+//go:wasmimport apache4_tcp log
+func log(level int32, buf unsafe.Pointer, bufLen uint32)
+
+//go:wasmimport apache4_tcp peek
+func peek(buf unsafe.Pointer, bufLen uint32) int32
+
+//go:wasmimport apache4_tcp read
+func read(buf unsafe.Pointer, bufLen uint32) int32
+
+//go:wasmimport apache4_tcp write
+func write(buf unsafe.Pointer, bufLen uint32) int32
@@ -0,0 +1,3 @@
+module tcpmiddleware
+
+go 1.24.0
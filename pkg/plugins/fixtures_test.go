@@ -0,0 +1,30 @@
+package plugins
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// buildWasmFixture compiles the Go Wasm plugin of the given fixtures directory,
+// and returns the path of the built binary.
+// The plugins are built with the Go toolchain running the tests rather than committed,
+// as the binaries built with the standard Go compiler weigh several megabytes.
+func buildWasmFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	output := filepath.Join(t.TempDir(), "plugin.wasm")
+
+	cmd := exec.Command("go", "build", "-buildmode=c-shared", "-ldflags=-s -w", "-trimpath", "-o", output, ".")
+	cmd.Dir = filepath.Join("fixtures", name)
+	// The fixtures are standalone modules, built regardless of the flags of the module under test.
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm", "GOFLAGS=", "GOWORK=off")
+
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	return output
+}
@@ -3,28 +3,56 @@ package plugins
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	handlerapi "github.com/http-wasm/http-wasm-host-go/api/handler"
 	"github.com/http-wasm/http-wasm-host-go/handler"
 	wasm "github.com/http-wasm/http-wasm-host-go/handler/nethttp"
+	"github.com/rs/zerolog"
 	"github.com/tetratelabs/wazero"
+	wazeroapi "github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
 	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// wasmMaxMemoryPages is the maximum number of 64KiB memory pages of a wasm guest.
+const wasmMaxMemoryPages = 65536
+
+// wasmDefaultMaxIdleInstances is the maximum number of idle guest instances kept in the pool, when no pool size is set.
+// Each instance holding its own memory, the instances beyond it are closed once their request is handled.
+const wasmDefaultMaxIdleInstances = 8
+
+const (
+	wasmErrorReasonTimeout = "timeout"
+	wasmErrorReasonError   = "error"
+)
+
+var errWasmExecutionTimeout = errors.New("wasm guest execution timeout")
+
 type wasmMiddlewareBuilder struct {
-	path     string
-	cache    wazero.CompilationCache
-	settings Settings
+	path            string
+	cache           wazero.CompilationCache
+	settings        Settings
+	metricsRegistry metrics.Registry
 }
 
-func newWasmMiddlewareBuilder(goPath, moduleName, wasmPath string, settings Settings) (*wasmMiddlewareBuilder, error) {
+func newWasmMiddlewareBuilder(goPath, moduleName, wasmPath string, settings Settings, metricsRegistry metrics.Registry) (*wasmMiddlewareBuilder, error) {
 	ctx := context.Background()
 	path := filepath.Join(goPath, "src", moduleName, wasmPath)
 	cache := wazero.NewCompilationCache()
@@ -34,12 +62,17 @@ func newWasmMiddlewareBuilder(goPath, moduleName, wasmPath string, settings Sett
 		return nil, fmt.Errorf("loading Wasm binary: %w", err)
 	}
 
-	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCompilationCache(cache))
+	rtConfig, err := newWasmRuntimeConfig(cache, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := wazero.NewRuntimeWithConfig(ctx, rtConfig)
 	if _, err = rt.CompileModule(ctx, code); err != nil {
 		return nil, fmt.Errorf("compiling guest module: %w", err)
 	}
 
-	return &wasmMiddlewareBuilder{path: path, cache: cache, settings: settings}, nil
+	return &wasmMiddlewareBuilder{path: path, cache: cache, settings: settings, metricsRegistry: metricsRegistry}, nil
 }
 
 func (b wasmMiddlewareBuilder) newMiddleware(config map[string]interface{}, middlewareName string) (pluginMiddleware, error) {
@@ -51,60 +84,35 @@ func (b wasmMiddlewareBuilder) newMiddleware(config map[string]interface{}, midd
 }
 
 func (b wasmMiddlewareBuilder) newHandler(ctx context.Context, next http.Handler, cfg reflect.Value, middlewareName string) (http.Handler, error) {
-	h, applyCtx, err := b.buildMiddleware(ctx, next, cfg, middlewareName)
+	h, err := b.buildMiddleware(ctx, next, cfg, middlewareName)
 	if err != nil {
 		return nil, fmt.Errorf("building Wasm middleware: %w", err)
 	}
 
-	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		h.ServeHTTP(rw, req.WithContext(applyCtx(req.Context())))
-	}), nil
+	return h, nil
 }
 
-func (b *wasmMiddlewareBuilder) buildMiddleware(ctx context.Context, next http.Handler, cfg reflect.Value, middlewareName string) (http.Handler, func(ctx context.Context) context.Context, error) {
+func (b *wasmMiddlewareBuilder) buildMiddleware(ctx context.Context, next http.Handler, cfg reflect.Value, middlewareName string) (http.Handler, error) {
 	code, err := os.ReadFile(b.path)
 	if err != nil {
-		return nil, nil, fmt.Errorf("loading binary: %w", err)
+		return nil, fmt.Errorf("loading binary: %w", err)
 	}
 
-	rt := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().WithCompilationCache(b.cache))
-
-	guestModule, err := rt.CompileModule(ctx, code)
+	rtConfig, err := newWasmRuntimeConfig(b.cache, b.settings)
 	if err != nil {
-		return nil, nil, fmt.Errorf("compiling guest module: %w", err)
+		return nil, err
 	}
 
-	applyCtx, err := InstantiateHost(ctx, rt, guestModule, b.settings)
-	if err != nil {
-		return nil, nil, fmt.Errorf("instantiating host module: %w", err)
+	if b.settings.ExecutionTimeout > 0 {
+		// The guest instances are closed when their execution context is canceled by the timeout.
+		rtConfig = rtConfig.WithCloseOnContextDone(true)
 	}
 
 	logger := middlewares.GetLogger(ctx, middlewareName, "wasm")
 
-	config := wazero.NewModuleConfig().WithSysWalltime().WithStartFunctions("_start", "_initialize")
-	for _, env := range b.settings.Envs {
-		config = config.WithEnv(env, os.Getenv(env))
-	}
-
-	if len(b.settings.Mounts) > 0 {
-		fsConfig := wazero.NewFSConfig()
-		for _, mount := range b.settings.Mounts {
-			withDir := fsConfig.WithDirMount
-			prefix, readOnly := strings.CutSuffix(mount, ":ro")
-			if readOnly {
-				withDir = fsConfig.WithReadOnlyDirMount
-			}
-			parts := strings.Split(prefix, ":")
-			switch {
-			case len(parts) == 1:
-				fsConfig = withDir(parts[0], parts[0])
-			case len(parts) == 2:
-				fsConfig = withDir(parts[0], parts[1])
-			default:
-				return nil, nil, fmt.Errorf("invalid directory %q", mount)
-			}
-		}
-		config = config.WithFSConfig(fsConfig)
+	config, err := newWasmModuleConfig(b.settings)
+	if err != nil {
+		return nil, err
 	}
 
 	opts := []handler.Option{
@@ -116,39 +124,61 @@ func (b *wasmMiddlewareBuilder) buildMiddleware(ctx context.Context, next http.H
 	if i != nil {
 		config, ok := i.(map[string]interface{})
 		if !ok {
-			return nil, nil, fmt.Errorf("could not type assert config: %T", i)
+			return nil, fmt.Errorf("could not type assert config: %T", i)
 		}
 
 		data, err := json.Marshal(config)
 		if err != nil {
-			return nil, nil, fmt.Errorf("marshaling config: %w", err)
+			return nil, fmt.Errorf("marshaling config: %w", err)
 		}
 
 		opts = append(opts, handler.GuestConfig(data))
 	}
 
-	opts = append(opts, handler.Runtime(func(ctx context.Context) (wazero.Runtime, error) {
-		return rt, nil
-	}))
+	registry := b.metricsRegistry
+	if registry == nil {
+		registry = metrics.NewVoidRegistry()
+	}
 
-	mw, err := wasm.NewMiddleware(applyCtx(ctx), code, opts...)
-	if err != nil {
-		return nil, nil, fmt.Errorf("creating middleware: %w", err)
+	h := &wasmHandler{
+		ctx:              ctx,
+		code:             code,
+		rtConfig:         rtConfig,
+		settings:         b.settings,
+		opts:             opts,
+		logger:           logger,
+		next:             next,
+		middlewareName:   middlewareName,
+		timeout:          time.Duration(b.settings.ExecutionTimeout),
+		maxIdleInstances: wasmDefaultMaxIdleInstances,
+		metrics: wasmPluginMetrics{
+			invocations: registry.WasmPluginInvocationsCounter().With("middleware", middlewareName),
+			errors:      registry.WasmPluginErrorsCounter().With("middleware", middlewareName),
+			duration:    registry.WasmPluginDurationHistogram().With("middleware", middlewareName),
+			memory:      registry.WasmPluginMemoryGauge().With("middleware", middlewareName),
+		},
 	}
 
-	h := mw.NewHandler(ctx, next)
+	if b.settings.PoolSize > 0 {
+		h.slots = make(chan struct{}, b.settings.PoolSize)
+		h.maxIdleInstances = b.settings.PoolSize
+	}
+
+	// Instantiates a first guest to report the initialization errors when building the middleware.
+	inst, err := h.newInstance()
+	if err != nil {
+		return nil, err
+	}
+	h.putInstance(inst)
 
 	// apache4 does not Close the middleware when creating a new instance on a configuration change.
-	// When the middleware is marked to be GC, we need to close it so the wasm instance is properly closed.
+	// When the middleware is marked to be GC, we need to close it so the wasm instances are properly closed.
 	// Reference: https://github.com/apache4/apache4/issues/11119
-	runtime.SetFinalizer(h, func(_ http.Handler) {
-		if err := mw.Close(ctx); err != nil {
-			logger.Err(err).Msg("[wasm] middleware Close failed")
-		} else {
-			logger.Debug().Msg("[wasm] middleware Close ok")
-		}
+	runtime.SetFinalizer(h, func(h *wasmHandler) {
+		h.close()
 	})
-	return h, applyCtx, nil
+
+	return h, nil
 }
 
 // WasmMiddleware is an HTTP handler plugin wrapper.
@@ -162,3 +192,417 @@ type WasmMiddleware struct {
 func (m WasmMiddleware) NewHandler(ctx context.Context, next http.Handler) (http.Handler, error) {
 	return m.builder.newHandler(ctx, next, m.config, m.middlewareName)
 }
+
+// wasmHandler handles the requests with a pool of guest instances.
+// Each instance has its own runtime, to be closed on its own when its execution fails,
+// and handles one request at a time.
+type wasmHandler struct {
+	ctx            context.Context
+	code           []byte
+	rtConfig       wazero.RuntimeConfig
+	settings       Settings
+	opts           []handler.Option
+	logger         *zerolog.Logger
+	next           http.Handler
+	middlewareName string
+	timeout        time.Duration
+	metrics        wasmPluginMetrics
+
+	// slots limits the number of requests handled concurrently, when a pool size is set.
+	slots chan struct{}
+
+	// maxIdleInstances is the maximum number of idle instances kept in the pool,
+	// the instances created for a peak of concurrent requests being closed afterward.
+	maxIdleInstances int
+
+	instancesMu sync.Mutex
+	instances   []*wasmInstance
+}
+
+// wasmInstance is a guest instance, along with its runtime.
+type wasmInstance struct {
+	mw       wasm.Middleware
+	handler  http.Handler
+	applyCtx ContextApplier
+}
+
+// wasmPluginMetrics are the metrics of the guest executions of a middleware.
+type wasmPluginMetrics struct {
+	invocations gokitmetrics.Counter
+	errors      gokitmetrics.Counter
+	duration    metrics.ScalableHistogram
+	memory      gokitmetrics.Gauge
+}
+
+func (h *wasmHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		case <-req.Context().Done():
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	inst, err := h.getInstance()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("[wasm] Unable to instantiate the guest module")
+		h.metrics.errors.With("reason", wasmErrorReasonError).Add(1)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	exec := newWasmExecution(inst.applyCtx(req.Context()), h.middlewareName, h.timeout, h.metrics)
+	defer exec.release()
+
+	wrw := &wasmResponseWriter{ResponseWriter: rw, exec: exec}
+	serveWasmInstance(inst, wrw, req.WithContext(exec.ctx), exec)
+
+	if exec.err == nil {
+		h.putInstance(inst)
+		return
+	}
+
+	// The guest instance state is undefined after a failed execution.
+	h.closeInstance(inst)
+
+	h.logger.Error().Err(exec.err).Msg("[wasm] Error while handling the request")
+
+	if wrw.written {
+		return
+	}
+
+	code := http.StatusInternalServerError
+	if errors.Is(exec.err, errWasmExecutionTimeout) {
+		code = http.StatusServiceUnavailable
+	}
+
+	http.Error(rw, http.StatusText(code), code)
+}
+
+func serveWasmInstance(inst *wasmInstance, rw http.ResponseWriter, req *http.Request, exec *wasmExecution) {
+	defer func() {
+		// The guest execution failures after the next handler call are raised as panics.
+		if exec.err != nil {
+			_ = recover()
+		}
+	}()
+
+	inst.handler.ServeHTTP(rw, req)
+}
+
+func (h *wasmHandler) getInstance() (*wasmInstance, error) {
+	h.instancesMu.Lock()
+	if n := len(h.instances); n > 0 {
+		inst := h.instances[n-1]
+		h.instances = h.instances[:n-1]
+		h.instancesMu.Unlock()
+
+		return inst, nil
+	}
+	h.instancesMu.Unlock()
+
+	return h.newInstance()
+}
+
+func (h *wasmHandler) putInstance(inst *wasmInstance) {
+	h.instancesMu.Lock()
+	if len(h.instances) < h.maxIdleInstances {
+		h.instances = append(h.instances, inst)
+		h.instancesMu.Unlock()
+
+		return
+	}
+	h.instancesMu.Unlock()
+
+	h.closeInstance(inst)
+}
+
+func (h *wasmHandler) newInstance() (*wasmInstance, error) {
+	rt := wazero.NewRuntimeWithConfig(h.ctx, h.rtConfig)
+
+	guestModule, err := rt.CompileModule(h.ctx, h.code)
+	if err != nil {
+		_ = rt.Close(h.ctx)
+		return nil, fmt.Errorf("compiling guest module: %w", err)
+	}
+
+	applyCtx, err := InstantiateHost(h.ctx, rt, guestModule, h.settings)
+	if err != nil {
+		_ = rt.Close(h.ctx)
+		return nil, fmt.Errorf("instantiating host module: %w", err)
+	}
+
+	opts := append(slices.Clip(h.opts), handler.Runtime(func(ctx context.Context) (wazero.Runtime, error) {
+		return rt, nil
+	}))
+
+	// The listener tracks the guest executions, as the middleware does not report them.
+	ctx := experimental.WithFunctionListenerFactory(applyCtx(h.ctx), wasmGuestListenerFactory{})
+
+	mw, err := wasm.NewMiddleware(ctx, h.code, opts...)
+	if err != nil {
+		_ = rt.Close(h.ctx)
+		return nil, fmt.Errorf("creating middleware: %w", err)
+	}
+
+	return &wasmInstance{
+		mw:       mw,
+		handler:  mw.NewHandler(h.ctx, wasmNextHandler{next: h.next}),
+		applyCtx: applyCtx,
+	}, nil
+}
+
+func (h *wasmHandler) closeInstance(inst *wasmInstance) {
+	if err := inst.mw.Close(h.ctx); err != nil {
+		h.logger.Err(err).Msg("[wasm] middleware Close failed")
+	} else {
+		h.logger.Debug().Msg("[wasm] middleware Close ok")
+	}
+}
+
+func (h *wasmHandler) close() {
+	h.instancesMu.Lock()
+	defer h.instancesMu.Unlock()
+
+	for _, inst := range h.instances {
+		h.closeInstance(inst)
+	}
+	h.instances = nil
+}
+
+type wasmExecutionKey struct{}
+
+// wasmExecution tracks the guest executions while handling a request.
+// The execution timeout applies to the total duration of the guest function calls,
+// without the duration of the next handler.
+type wasmExecution struct {
+	// ctx is the context of the guest function calls, only canceled by the execution timeout.
+	ctx context.Context
+	// reqCtx is the request context, given back to the next handler.
+	reqCtx context.Context
+	cancel context.CancelCauseFunc
+
+	middlewareName string
+	remaining      time.Duration
+	metrics        wasmPluginMetrics
+
+	timer *time.Timer
+	start time.Time
+	span  trace.Span
+	err   error
+}
+
+func newWasmExecution(ctx context.Context, middlewareName string, timeout time.Duration, metrics wasmPluginMetrics) *wasmExecution {
+	exec := &wasmExecution{
+		reqCtx:         ctx,
+		middlewareName: middlewareName,
+		remaining:      timeout,
+		metrics:        metrics,
+	}
+
+	guestCtx := ctx
+	if timeout > 0 {
+		guestCtx, exec.cancel = context.WithCancelCause(context.WithoutCancel(ctx))
+	}
+
+	exec.ctx = context.WithValue(guestCtx, wasmExecutionKey{}, exec)
+
+	return exec
+}
+
+func (e *wasmExecution) begin(ctx context.Context, function string) {
+	e.start = time.Now()
+	e.metrics.invocations.Add(1)
+
+	// The guest spans are only created as children of a recorded request span.
+	if parent := trace.SpanFromContext(ctx); parent.IsRecording() {
+		tracer := parent.TracerProvider().Tracer("github.com/apache4/apache4")
+		_, e.span = tracer.Start(ctx, "WasmPlugin", trace.WithSpanKind(trace.SpanKindInternal))
+		e.span.SetAttributes(
+			attribute.String("apache4.middleware.name", e.middlewareName),
+			attribute.String("apache4.wasm.function", function),
+		)
+	}
+
+	if e.cancel != nil {
+		e.timer = time.AfterFunc(max(e.remaining, 0), func() {
+			e.cancel(errWasmExecutionTimeout)
+		})
+	}
+}
+
+func (e *wasmExecution) end(mod wazeroapi.Module, err error) {
+	if e.timer != nil {
+		// The guest instance could have been closed by the timeout even if the call succeeded.
+		if !e.timer.Stop() {
+			err = errWasmExecutionTimeout
+		}
+		e.remaining -= time.Since(e.start)
+	}
+
+	e.metrics.duration.ObserveFromStart(e.start)
+
+	var memorySize uint32
+	if mem := mod.Memory(); mem != nil {
+		memorySize = mem.Size()
+		e.metrics.memory.Set(float64(memorySize))
+	}
+
+	if err != nil && e.err == nil {
+		e.err = err
+
+		reason := wasmErrorReasonError
+		if errors.Is(err, errWasmExecutionTimeout) {
+			reason = wasmErrorReasonTimeout
+		}
+		e.metrics.errors.With("reason", reason).Add(1)
+	}
+
+	if e.span != nil {
+		e.span.SetAttributes(attribute.Int64("apache4.wasm.memory_bytes", int64(memorySize)))
+		if err != nil {
+			e.span.SetStatus(codes.Error, err.Error())
+		}
+
+		e.span.End()
+		e.span = nil
+	}
+}
+
+func (e *wasmExecution) release() {
+	if e.cancel != nil {
+		e.cancel(nil)
+	}
+}
+
+// wasmGuestListenerFactory creates the listeners of the guest functions handling the requests.
+type wasmGuestListenerFactory struct{}
+
+func (wasmGuestListenerFactory) NewFunctionListener(def wazeroapi.FunctionDefinition) experimental.FunctionListener {
+	for _, name := range def.ExportNames() {
+		if name == handlerapi.FuncHandleRequest || name == handlerapi.FuncHandleResponse {
+			return wasmGuestListener{function: name}
+		}
+	}
+
+	return nil
+}
+
+// wasmGuestListener reports the guest function calls to the execution of the request.
+type wasmGuestListener struct {
+	function string
+}
+
+func (l wasmGuestListener) Before(ctx context.Context, _ wazeroapi.Module, _ wazeroapi.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+	if exec, ok := ctx.Value(wasmExecutionKey{}).(*wasmExecution); ok {
+		exec.begin(ctx, l.function)
+	}
+}
+
+func (l wasmGuestListener) After(ctx context.Context, mod wazeroapi.Module, _ wazeroapi.FunctionDefinition, _ []uint64) {
+	if exec, ok := ctx.Value(wasmExecutionKey{}).(*wasmExecution); ok {
+		exec.end(mod, nil)
+	}
+}
+
+func (l wasmGuestListener) Abort(ctx context.Context, mod wazeroapi.Module, _ wazeroapi.FunctionDefinition, err error) {
+	if exec, ok := ctx.Value(wasmExecutionKey{}).(*wasmExecution); ok {
+		exec.end(mod, err)
+	}
+}
+
+// wasmNextHandler calls the next handler with the request context, as the guest execution context is not canceled with the request.
+type wasmNextHandler struct {
+	next http.Handler
+}
+
+func (h wasmNextHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	exec, ok := req.Context().Value(wasmExecutionKey{}).(*wasmExecution)
+	if !ok {
+		h.next.ServeHTTP(rw, req)
+		return
+	}
+
+	// The next handler writes to the original response writer, to keep its optional interfaces.
+	if wrw, ok := rw.(*wasmResponseWriter); ok {
+		wrw.written = true
+		rw = wrw.ResponseWriter
+	}
+
+	h.next.ServeHTTP(rw, req.WithContext(exec.reqCtx))
+}
+
+// wasmResponseWriter drops the response written by the middleware after a guest execution failure,
+// so the failure details are not sent to the client.
+type wasmResponseWriter struct {
+	http.ResponseWriter
+
+	exec    *wasmExecution
+	written bool
+}
+
+func (w *wasmResponseWriter) WriteHeader(code int) {
+	if w.exec.err != nil {
+		return
+	}
+
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *wasmResponseWriter) Write(b []byte) (int, error) {
+	if w.exec.err != nil {
+		return len(b), nil
+	}
+
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// newWasmRuntimeConfig creates the runtime configuration, capping the guest memory to the number of pages from the settings.
+func newWasmRuntimeConfig(cache wazero.CompilationCache, settings Settings) (wazero.RuntimeConfig, error) {
+	config := wazero.NewRuntimeConfig().WithCompilationCache(cache)
+
+	if settings.MaxMemoryPages > 0 {
+		if settings.MaxMemoryPages > wasmMaxMemoryPages {
+			return nil, fmt.Errorf("maxMemoryPages must be lower than or equal to %d", wasmMaxMemoryPages)
+		}
+
+		config = config.WithMemoryLimitPages(settings.MaxMemoryPages)
+	}
+
+	return config, nil
+}
+
+// newWasmModuleConfig creates the guest module configuration, forwarding the environment variables and mounting the directories from the settings.
+func newWasmModuleConfig(settings Settings) (wazero.ModuleConfig, error) {
+	config := wazero.NewModuleConfig().WithSysWalltime().WithStartFunctions("_start", "_initialize")
+	for _, env := range settings.Envs {
+		config = config.WithEnv(env, os.Getenv(env))
+	}
+
+	if len(settings.Mounts) > 0 {
+		fsConfig := wazero.NewFSConfig()
+		for _, mount := range settings.Mounts {
+			withDir := fsConfig.WithDirMount
+			prefix, readOnly := strings.CutSuffix(mount, ":ro")
+			if readOnly {
+				withDir = fsConfig.WithReadOnlyDirMount
+			}
+			parts := strings.Split(prefix, ":")
+			switch {
+			case len(parts) == 1:
+				fsConfig = withDir(parts[0], parts[0])
+			case len(parts) == 2:
+				fsConfig = withDir(parts[0], parts[1])
+			default:
+				return nil, fmt.Errorf("invalid directory %q", mount)
+			}
+		}
+		config = config.WithFSConfig(fsConfig)
+	}
+
+	return config, nil
+}
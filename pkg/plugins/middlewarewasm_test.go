@@ -1,18 +1,25 @@
 package plugins
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tetratelabs/wazero"
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
 )
 
 func TestSettingsWithoutSocket(t *testing.T) {
@@ -102,13 +109,13 @@ func TestSettingsWithoutSocket(t *testing.T) {
 
 			cfg := reflect.ValueOf(config)
 
-			m, applyCtx, err := builder.buildMiddleware(ctx, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			m, err := builder.buildMiddleware(ctx, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 				rw.WriteHeader(http.StatusTeapot)
 			}), cfg, "test")
 			require.NoError(t, err)
 
 			rw := httptest.NewRecorder()
-			req := httptest.NewRequestWithContext(applyCtx(ctx), "GET", "/", http.NoBody)
+			req := httptest.NewRequestWithContext(ctx, "GET", "/", http.NoBody)
 
 			m.ServeHTTP(rw, req)
 
@@ -117,3 +124,202 @@ func TestSettingsWithoutSocket(t *testing.T) {
 		})
 	}
 }
+
+func TestWasmMiddleware_limits(t *testing.T) {
+	pluginPath := buildWasmFixture(t, "limits")
+	cache := wazero.NewCompilationCache()
+
+	testCases := []struct {
+		desc           string
+		settings       Settings
+		path           string
+		expectedStatus int
+		expectedBody   string
+		expectedReason string
+	}{
+		{
+			desc:           "response from the guest",
+			path:           "/reply",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "reply",
+		},
+		{
+			desc:           "next handler duration excluded from the execution timeout",
+			settings:       Settings{ExecutionTimeout: ptypes.Duration(100 * time.Millisecond)},
+			path:           "/",
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			desc:           "execution timeout",
+			settings:       Settings{ExecutionTimeout: ptypes.Duration(100 * time.Millisecond)},
+			path:           "/loop",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   http.StatusText(http.StatusServiceUnavailable) + "\n",
+			expectedReason: wasmErrorReasonTimeout,
+		},
+		{
+			desc:           "allocation within the memory limit",
+			settings:       Settings{MaxMemoryPages: 4096},
+			path:           "/alloc",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "allocated",
+		},
+		{
+			desc:           "allocation over the memory limit",
+			settings:       Settings{MaxMemoryPages: 512},
+			path:           "/alloc",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   http.StatusText(http.StatusInternalServerError) + "\n",
+			expectedReason: wasmErrorReasonError,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			registry := newWasmPluginTestRegistry()
+			builder := &wasmMiddlewareBuilder{path: pluginPath, cache: cache, settings: test.settings, metricsRegistry: registry}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+				rw.WriteHeader(http.StatusTeapot)
+			})
+
+			h, err := builder.buildMiddleware(t.Context(), next, reflect.ValueOf(map[string]interface{}{}), "test")
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, test.path, http.NoBody))
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedBody, rw.Body.String())
+			assert.Positive(t, registry.invocations.CounterValue)
+			assert.Positive(t, registry.memory.GaugeValue)
+
+			if test.expectedReason == "" {
+				assert.Zero(t, registry.errors.CounterValue)
+				return
+			}
+
+			assert.Equal(t, 1.0, registry.errors.CounterValue)
+			assert.Equal(t, []string{"reason", test.expectedReason}, registry.errors.LastLabelValues)
+
+			// The failed guest instance is replaced for the next requests.
+			rw = httptest.NewRecorder()
+			h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/reply", http.NoBody))
+
+			assert.Equal(t, http.StatusOK, rw.Code)
+			assert.Equal(t, "reply", rw.Body.String())
+		})
+	}
+}
+
+func TestWasmMiddleware_poolSize(t *testing.T) {
+	builder := &wasmMiddlewareBuilder{path: buildWasmFixture(t, "limits"), cache: wazero.NewCompilationCache(), settings: Settings{PoolSize: 1}}
+
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-release
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	h, err := builder.buildMiddleware(t.Context(), next, reflect.ValueOf(map[string]interface{}{}), "test")
+	require.NoError(t, err)
+
+	firstRW := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.ServeHTTP(firstRW, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	}()
+
+	// Waits for the first request to hold the only guest instance.
+	time.Sleep(100 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequestWithContext(ctx, http.MethodGet, "/reply", http.NoBody))
+	assert.Equal(t, http.StatusServiceUnavailable, rw.Code)
+
+	close(release)
+	<-done
+	assert.Equal(t, http.StatusTeapot, firstRW.Code)
+
+	rw = httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/reply", http.NoBody))
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestWasmMiddleware_maxIdleInstances(t *testing.T) {
+	builder := &wasmMiddlewareBuilder{path: buildWasmFixture(t, "limits"), cache: wazero.NewCompilationCache()}
+
+	var handling sync.WaitGroup
+	release := make(chan struct{})
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		handling.Done()
+		<-release
+		rw.WriteHeader(http.StatusTeapot)
+	})
+
+	h, err := builder.buildMiddleware(t.Context(), next, reflect.ValueOf(map[string]interface{}{}), "test")
+	require.NoError(t, err)
+
+	wh, ok := h.(*wasmHandler)
+	require.True(t, ok)
+	wh.maxIdleInstances = 2
+
+	// A peak of concurrent requests creates an instance per request.
+	var done sync.WaitGroup
+	for range 4 {
+		handling.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+			assert.Equal(t, http.StatusTeapot, rw.Code)
+		}()
+	}
+
+	handling.Wait()
+	close(release)
+	done.Wait()
+
+	wh.instancesMu.Lock()
+	defer wh.instancesMu.Unlock()
+
+	assert.Len(t, wh.instances, 2)
+}
+
+type wasmPluginTestRegistry struct {
+	metrics.Registry
+
+	invocations *testhelpers.CollectingCounter
+	errors      *testhelpers.CollectingCounter
+	memory      *testhelpers.CollectingGauge
+}
+
+func newWasmPluginTestRegistry() *wasmPluginTestRegistry {
+	return &wasmPluginTestRegistry{
+		Registry:    metrics.NewVoidRegistry(),
+		invocations: &testhelpers.CollectingCounter{},
+		errors:      &testhelpers.CollectingCounter{},
+		memory:      &testhelpers.CollectingGauge{},
+	}
+}
+
+func (r *wasmPluginTestRegistry) WasmPluginInvocationsCounter() gokitmetrics.Counter {
+	return r.invocations
+}
+
+func (r *wasmPluginTestRegistry) WasmPluginErrorsCounter() gokitmetrics.Counter {
+	return r.errors
+}
+
+func (r *wasmPluginTestRegistry) WasmPluginMemoryGauge() gokitmetrics.Gauge {
+	return r.memory
+}
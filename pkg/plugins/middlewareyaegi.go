@@ -80,7 +80,13 @@ func (b yaegiMiddlewareBuilder) newHandler(ctx context.Context, next http.Handle
 }
 
 func (b yaegiMiddlewareBuilder) createConfig(config map[string]interface{}) (reflect.Value, error) {
-	results := b.fnCreateConfig.Call(nil)
+	return createYaegiConfig(b.fnCreateConfig, config)
+}
+
+// createYaegiConfig creates the plugin configuration with the CreateConfig function,
+// and fills it with the given configuration.
+func createYaegiConfig(fnCreateConfig reflect.Value, config map[string]interface{}) (reflect.Value, error) {
+	results := fnCreateConfig.Call(nil)
 	if len(results) != 1 {
 		return reflect.Value{}, fmt.Errorf("invalid number of return for the CreateConfig function: %d", len(results))
 	}
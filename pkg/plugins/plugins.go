@@ -35,7 +35,7 @@ func SetupRemotePlugins(client *Client, plugins map[string]Descriptor) error {
 			return fmt.Errorf("unable to download plugin %s: %w", desc.ModuleName, err)
 		}
 
-		err = client.Check(ctx, desc.ModuleName, desc.Version, hash)
+		err = client.Check(ctx, desc.ModuleName, desc.Version, desc.Hash, hash)
 		if err != nil {
 			_ = client.ResetAll()
 			return fmt.Errorf("unable to check archive integrity of the plugin %s: %w", desc.ModuleName, err)
@@ -138,20 +138,19 @@ func checkLocalPluginManifest(descriptor LocalDescriptor) error {
 	var errs *multierror.Error
 
 	switch m.Type {
-	case typeMiddleware:
+	case typeMiddleware, typeTCPMiddleware, typeProvider:
 		if m.Runtime != runtimeYaegi && m.Runtime != runtimeWasm && m.Runtime != "" {
 			errs = multierror.Append(errs, fmt.Errorf("%s: unsupported runtime '%q'", descriptor.ModuleName, m.Runtime))
 		}
 
-	case typeProvider:
-		if m.Runtime != runtimeYaegi && m.Runtime != "" {
-			errs = multierror.Append(errs, fmt.Errorf("%s: unsupported runtime '%q'", descriptor.ModuleName, m.Runtime))
-		}
-
 	default:
 		errs = multierror.Append(errs, fmt.Errorf("%s: unsupported type %q", descriptor.ModuleName, m.Type))
 	}
 
+	if descriptor.Watch && m.Type == typeProvider {
+		errs = multierror.Append(errs, fmt.Errorf("%s: watch mode is not supported by provider plugins", descriptor.ModuleName))
+	}
+
 	if m.IsYaegiPlugin() {
 		if m.Import == "" {
 			errs = multierror.Append(errs, fmt.Errorf("%s: missing import", descriptor.ModuleName))
@@ -46,8 +46,10 @@ func (p _PP) Stop() error {
 func ppSymbols() map[string]map[string]reflect.Value {
 	return map[string]map[string]reflect.Value{
 		"github.com/apache4/apache4/v3/pkg/plugins/plugins": {
-			"PP":  reflect.ValueOf((*PP)(nil)),
-			"_PP": reflect.ValueOf((*_PP)(nil)),
+			"PP":          reflect.ValueOf((*PP)(nil)),
+			"_PP":         reflect.ValueOf((*_PP)(nil)),
+			"TCPHandler":  reflect.ValueOf((*TCPHandler)(nil)),
+			"_TCPHandler": reflect.ValueOf((*_TCPHandler)(nil)),
 		},
 	}
 }
@@ -63,10 +65,14 @@ func (b Builder) BuildProvider(pName string, config map[string]interface{}) (pro
 		return nil, fmt.Errorf("unknown plugin type: %s", pName)
 	}
 
-	return newProvider(builder, config, "plugin-"+pName)
+	return builder.newProvider(config, "plugin-"+pName)
 }
 
-type providerBuilder struct {
+type providerBuilder interface {
+	newProvider(config map[string]interface{}, providerName string) (provider.Provider, error)
+}
+
+type yaegiProviderBuilder struct {
 	// Import plugin's import/package
 	Import string `json:"import,omitempty" toml:"import,omitempty" yaml:"import,omitempty"`
 
@@ -82,7 +88,7 @@ type Provider struct {
 	pp   PP
 }
 
-func newProvider(builder providerBuilder, config map[string]interface{}, providerName string) (*Provider, error) {
+func (builder yaegiProviderBuilder) newProvider(config map[string]interface{}, providerName string) (provider.Provider, error) {
 	basePkg := builder.BasePkg
 	if basePkg == "" {
 		basePkg = strings.ReplaceAll(path.Base(builder.Import), "-", "_")
@@ -0,0 +1,367 @@
+package plugins
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/http-wasm/http-wasm-host-go/api"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/tetratelabs/wazero"
+	wazeroapi "github.com/tetratelabs/wazero/api"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/provider"
+	"github.com/apache4/apache4/v3/pkg/safe"
+)
+
+const (
+	// wasmProviderHostModule is the name of the host module imported by the Wasm providers.
+	wasmProviderHostModule = "apache4_provider"
+
+	// wasmProviderInit is the optional function exported by the Wasm providers, called once the module is instantiated.
+	// A non-zero result is an initialization error.
+	wasmProviderInit = "init"
+	// wasmProviderProvide is the function exported by the Wasm providers to emit their configurations.
+	// It returns the delay in milliseconds before being called again, 0 to not be called anymore, and a negative value on error.
+	wasmProviderProvide = "provide"
+	// wasmProviderStop is the optional function exported by the Wasm providers, called when apache4 stops the provider.
+	wasmProviderStop = "stop"
+)
+
+const (
+	wasmProviderHTTPTimeout        = 30 * time.Second
+	wasmProviderMaxHTTPResponseLen = 10 << 20
+)
+
+// wasmHTTPRequest is the JSON representation of the HTTP requests sent by the Wasm providers.
+type wasmHTTPRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+}
+
+// wasmHTTPResponse is the JSON representation of the HTTP responses received by the Wasm providers.
+type wasmHTTPResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+type wasmProviderBuilder struct {
+	path     string
+	cache    wazero.CompilationCache
+	settings Settings
+}
+
+func newWasmProviderBuilder(goPath, moduleName, wasmPath string, settings Settings) (*wasmProviderBuilder, error) {
+	ctx := context.Background()
+	path := filepath.Join(goPath, "src", moduleName, wasmPath)
+	cache := wazero.NewCompilationCache()
+
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading Wasm binary: %w", err)
+	}
+
+	rtConfig, err := newWasmRuntimeConfig(cache, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := wazero.NewRuntimeWithConfig(ctx, rtConfig)
+	defer func() { _ = rt.Close(ctx) }()
+
+	guestModule, err := rt.CompileModule(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("compiling guest module: %w", err)
+	}
+
+	if _, ok := guestModule.ExportedFunctions()[wasmProviderProvide]; !ok {
+		return nil, fmt.Errorf("guest module does not export the %s function", wasmProviderProvide)
+	}
+
+	return &wasmProviderBuilder{path: path, cache: cache, settings: settings}, nil
+}
+
+func (b *wasmProviderBuilder) newProvider(config map[string]interface{}, providerName string) (provider.Provider, error) {
+	var guestConfig []byte
+	if config != nil {
+		var err error
+		guestConfig, err = json.Marshal(config)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling config: %w", err)
+		}
+	}
+
+	return &WasmProvider{
+		name:        providerName,
+		guestConfig: guestConfig,
+		builder:     b,
+		httpClient:  &http.Client{Timeout: wasmProviderHTTPTimeout},
+	}, nil
+}
+
+// WasmProvider is a Wasm plugin's provider.
+// The guest module is instantiated once, and its exported functions are never called concurrently.
+type WasmProvider struct {
+	name        string
+	guestConfig []byte
+	builder     *wasmProviderBuilder
+	httpClient  *http.Client
+
+	logger   zerolog.Logger
+	rt       wazero.Runtime
+	applyCtx ContextApplier
+	mod      wazeroapi.Module
+
+	// configurationChan and lastHTTPResponse are only used during the guest calls.
+	configurationChan chan<- dynamic.Message
+	lastHTTPResponse  []byte
+
+	stopOnce sync.Once
+}
+
+// Init instantiates the guest module and calls its init function.
+func (p *WasmProvider) Init() error {
+	ctx := context.Background()
+
+	p.logger = log.With().Str(logs.ProviderName, p.name).Logger()
+
+	code, err := os.ReadFile(p.builder.path)
+	if err != nil {
+		return fmt.Errorf("loading binary: %w", err)
+	}
+
+	rtConfig, err := newWasmRuntimeConfig(p.builder.cache, p.builder.settings)
+	if err != nil {
+		return err
+	}
+
+	// Closing the module when the context is done allows to stop a provider blocked in a guest call.
+	p.rt = wazero.NewRuntimeWithConfig(ctx, rtConfig.WithCloseOnContextDone(true))
+
+	guestModule, err := p.rt.CompileModule(ctx, code)
+	if err != nil {
+		return p.closeOnError(ctx, fmt.Errorf("compiling guest module: %w", err))
+	}
+
+	p.applyCtx, err = InstantiateHost(ctx, p.rt, guestModule, p.builder.settings)
+	if err != nil {
+		return p.closeOnError(ctx, fmt.Errorf("instantiating host module: %w", err))
+	}
+
+	if err = p.instantiateHostModule(ctx); err != nil {
+		return p.closeOnError(ctx, fmt.Errorf("instantiating %s host module: %w", wasmProviderHostModule, err))
+	}
+
+	moduleConfig, err := newWasmModuleConfig(p.builder.settings)
+	if err != nil {
+		return p.closeOnError(ctx, err)
+	}
+
+	p.mod, err = p.rt.InstantiateModule(p.applyCtx(ctx), guestModule, moduleConfig)
+	if err != nil {
+		return p.closeOnError(ctx, fmt.Errorf("instantiating guest module: %w", err))
+	}
+
+	if fn := p.mod.ExportedFunction(wasmProviderInit); fn != nil {
+		results, err := fn.Call(p.applyCtx(ctx))
+		if err != nil {
+			return p.closeOnError(ctx, fmt.Errorf("calling %s: %w", wasmProviderInit, err))
+		}
+
+		if len(results) > 0 && int32(results[0]) != 0 {
+			return p.closeOnError(ctx, fmt.Errorf("%s failed with code %d", wasmProviderInit, int32(results[0])))
+		}
+	}
+
+	return nil
+}
+
+// Provide calls the guest provide function until the provider is stopped,
+// the configurations are emitted by the guest during the calls.
+func (p *WasmProvider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
+	if p.mod == nil {
+		return fmt.Errorf("error from %s: provider not initialized", p.name)
+	}
+
+	p.configurationChan = configurationChan
+
+	pool.GoCtx(func(ctx context.Context) {
+		logger := log.Ctx(ctx).With().Str(logs.ProviderName, p.name).Logger()
+		defer p.stop(logger)
+
+		provideFn := p.mod.ExportedFunction(wasmProviderProvide)
+
+		for {
+			results, err := provideFn.Call(p.applyCtx(ctx))
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Error().Err(err).Msg("Error while calling the provider")
+				}
+				return
+			}
+
+			delay := int32(results[0])
+			switch {
+			case delay < 0:
+				logger.Error().Msgf("Provider failed with code %d", delay)
+				return
+			case delay == 0:
+				// The guest does not expect to be called again, but stays alive until the provider is stopped.
+				<-ctx.Done()
+				return
+			}
+
+			timer := time.NewTimer(time.Duration(delay) * time.Millisecond)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	})
+
+	return nil
+}
+
+// stop calls the guest stop function, if the guest has not been closed by a canceled call, and closes the runtime.
+func (p *WasmProvider) stop(logger zerolog.Logger) {
+	p.stopOnce.Do(func() {
+		ctx := context.Background()
+
+		if fn := p.mod.ExportedFunction(wasmProviderStop); fn != nil && !p.mod.IsClosed() {
+			if _, err := fn.Call(p.applyCtx(ctx)); err != nil {
+				logger.Error().Err(err).Msg("Failed to stop the provider")
+			}
+		}
+
+		if err := p.rt.Close(ctx); err != nil {
+			logger.Error().Err(err).Msg("Failed to close the provider runtime")
+		}
+	})
+}
+
+func (p *WasmProvider) closeOnError(ctx context.Context, err error) error {
+	if closeErr := p.rt.Close(ctx); closeErr != nil {
+		return errors.Join(err, closeErr)
+	}
+
+	return err
+}
+
+// instantiateHostModule instantiates the host functions available to the guest.
+// The buffers are allocated by the guest, and the functions return -1 when the operation fails.
+func (p *WasmProvider) instantiateHostModule(ctx context.Context) error {
+	wasmLogger := logs.NewWasmLogger(&p.logger)
+
+	_, err := p.rt.NewHostModuleBuilder(wasmProviderHostModule).
+		NewFunctionBuilder().
+		WithFunc(func(_ context.Context, mod wazeroapi.Module, buf, bufLimit uint32) uint32 {
+			return writeIfFits(mod, buf, bufLimit, p.guestConfig)
+		}).
+		Export("get_config").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, level int32, buf, bufLen uint32) {
+			wasmLogger.Log(ctx, api.LogLevel(level), string(mustRead(mod, buf, bufLen)))
+		}).
+		Export("log").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLen uint32) int32 {
+			return p.emitConfiguration(ctx, mustRead(mod, buf, bufLen))
+		}).
+		Export("emit_configuration").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLen uint32) int32 {
+			return p.sendHTTPRequest(ctx, mustRead(mod, buf, bufLen))
+		}).
+		Export("http_request").
+		NewFunctionBuilder().
+		WithFunc(func(_ context.Context, mod wazeroapi.Module, buf, bufLimit uint32) uint32 {
+			return writeIfFits(mod, buf, bufLimit, p.lastHTTPResponse)
+		}).
+		Export("get_http_response").
+		Instantiate(ctx)
+
+	return err
+}
+
+// emitConfiguration sends the JSON dynamic configuration emitted by the guest to apache4.
+func (p *WasmProvider) emitConfiguration(ctx context.Context, data []byte) int32 {
+	if p.configurationChan == nil {
+		p.logger.Error().Msg("Configuration emitted outside of the provide function")
+		return -1
+	}
+
+	cfg := &dynamic.Configuration{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		p.logger.Error().Err(err).Msg("Failed to unmarshal configuration")
+		return -1
+	}
+
+	select {
+	case <-ctx.Done():
+		return -1
+	case p.configurationChan <- dynamic.Message{ProviderName: p.name, Configuration: cfg}:
+		return 0
+	}
+}
+
+// sendHTTPRequest sends the JSON HTTP request of the guest, and returns the length of the JSON response,
+// which can then be retrieved by the guest with the get_http_response function.
+func (p *WasmProvider) sendHTTPRequest(ctx context.Context, data []byte) int32 {
+	p.lastHTTPResponse = nil
+
+	var wasmReq wasmHTTPRequest
+	if err := json.Unmarshal(data, &wasmReq); err != nil {
+		p.logger.Error().Err(err).Msg("Failed to unmarshal HTTP request")
+		return -1
+	}
+
+	req, err := http.NewRequestWithContext(ctx, wasmReq.Method, wasmReq.URL, bytes.NewReader(wasmReq.Body))
+	if err != nil {
+		p.logger.Error().Err(err).Msg("Failed to create HTTP request")
+		return -1
+	}
+
+	for name, values := range wasmReq.Headers {
+		req.Header[http.CanonicalHeaderKey(name)] = values
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		p.logger.Debug().Err(err).Msg("Failed to send HTTP request")
+		return -1
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, wasmProviderMaxHTTPResponseLen))
+	if err != nil {
+		p.logger.Debug().Err(err).Msg("Failed to read HTTP response")
+		return -1
+	}
+
+	p.lastHTTPResponse, err = json.Marshal(wasmHTTPResponse{
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       body,
+	})
+	if err != nil {
+		p.logger.Error().Err(err).Msg("Failed to marshal HTTP response")
+		return -1
+	}
+
+	return int32(len(p.lastHTTPResponse))
+}
@@ -0,0 +1,60 @@
+package plugins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/safe"
+)
+
+func TestWasmProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("example.com"))
+	}))
+	t.Cleanup(srv.Close)
+
+	builder := &wasmProviderBuilder{path: buildWasmFixture(t, "provider"), cache: wazero.NewCompilationCache()}
+
+	p, err := builder.newProvider(map[string]interface{}{"url": srv.URL, "interval": 10}, "plugin-test")
+	require.NoError(t, err)
+
+	require.NoError(t, p.Init())
+
+	configurationChan := make(chan dynamic.Message)
+	pool := safe.NewPool(context.Background())
+
+	require.NoError(t, p.Provide(configurationChan, pool))
+
+	// The provide function is called again after the interval.
+	for range 2 {
+		select {
+		case msg := <-configurationChan:
+			assert.Equal(t, "plugin-test", msg.ProviderName)
+			require.NotNil(t, msg.Configuration.HTTP)
+			require.Contains(t, msg.Configuration.HTTP.Routers, "demo")
+			assert.Equal(t, "Host(`example.com`)", msg.Configuration.HTTP.Routers["demo"].Rule)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the configuration")
+		}
+	}
+
+	pool.Stop()
+
+	assert.True(t, p.(*WasmProvider).mod.IsClosed())
+}
+
+func TestWasmProvider_initError(t *testing.T) {
+	builder := &wasmProviderBuilder{path: buildWasmFixture(t, "provider"), cache: wazero.NewCompilationCache()}
+
+	p, err := builder.newProvider(map[string]interface{}{"interval": "invalid"}, "plugin-test")
+	require.NoError(t, err)
+
+	require.Error(t, p.Init())
+}
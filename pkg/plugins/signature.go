@@ -0,0 +1,188 @@
+package plugins
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	minisignUntrustedCommentPrefix = "untrusted comment:"
+	minisignTrustedCommentPrefix   = "trusted comment: "
+
+	minisignKeyIDLen = 8
+)
+
+var (
+	minisignAlgEd25519       = []byte("Ed")
+	minisignAlgHashedEd25519 = []byte("ED")
+)
+
+type minisignPublicKey struct {
+	keyID [minisignKeyIDLen]byte
+	key   ed25519.PublicKey
+}
+
+// signatureVerifier verifies the plugin archive signatures,
+// either minisign signatures, or base64 encoded signatures of PEM encoded ECDSA and Ed25519 keys (cosign sign-blob style).
+type signatureVerifier struct {
+	minisignKeys []minisignPublicKey
+	pemKeys      []any
+}
+
+func newSignatureVerifier(publicKeys []string) (*signatureVerifier, error) {
+	v := &signatureVerifier{}
+
+	for i, publicKey := range publicKeys {
+		publicKey = strings.TrimSpace(publicKey)
+
+		if strings.HasPrefix(publicKey, "-----BEGIN") {
+			key, err := parsePEMPublicKey(publicKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key %d: %w", i, err)
+			}
+
+			v.pemKeys = append(v.pemKeys, key)
+			continue
+		}
+
+		key, err := parseMinisignPublicKey(publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %w", i, err)
+		}
+
+		v.minisignKeys = append(v.minisignKeys, key)
+	}
+
+	return v, nil
+}
+
+func parsePEMPublicKey(publicKey string) (any, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	switch key.(type) {
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// parseMinisignPublicKey parses a minisign public key, with or without its untrusted comment line.
+func parseMinisignPublicKey(publicKey string) (minisignPublicKey, error) {
+	lines := strings.Split(publicKey, "\n")
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil {
+		return minisignPublicKey{}, fmt.Errorf("decoding minisign key: %w", err)
+	}
+
+	if len(raw) != len(minisignAlgEd25519)+minisignKeyIDLen+ed25519.PublicKeySize || !bytes.Equal(raw[:2], minisignAlgEd25519) {
+		return minisignPublicKey{}, errors.New("invalid minisign key")
+	}
+
+	var key minisignPublicKey
+	copy(key.keyID[:], raw[2:2+minisignKeyIDLen])
+	key.key = ed25519.PublicKey(raw[2+minisignKeyIDLen:])
+
+	return key, nil
+}
+
+func (v *signatureVerifier) verify(data, signature []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte(minisignUntrustedCommentPrefix)) {
+		return v.verifyMinisign(data, signature)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+
+	for _, key := range v.pemKeys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(k, digest[:], sig) {
+				return nil
+			}
+		case ed25519.PublicKey:
+			if ed25519.Verify(k, data, sig) {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("signature not verified by any public key")
+}
+
+// verifyMinisign verifies a minisign signature file:
+// an untrusted comment line, the signature, a trusted comment line, and the global signature of the signature and trusted comment.
+func (v *signatureVerifier) verifyMinisign(data, signature []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(signature)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], minisignTrustedCommentPrefix) {
+		return errors.New("invalid minisign signature")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil {
+		return fmt.Errorf("decoding minisign signature: %w", err)
+	}
+
+	if len(sig) != 2+minisignKeyIDLen+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return fmt.Errorf("decoding minisign global signature: %w", err)
+	}
+
+	message := data
+	switch {
+	case bytes.Equal(sig[:2], minisignAlgHashedEd25519):
+		sum := blake2b.Sum512(data)
+		message = sum[:]
+	case !bytes.Equal(sig[:2], minisignAlgEd25519):
+		return fmt.Errorf("unsupported minisign signature algorithm %q", sig[:2])
+	}
+
+	keyID := sig[2 : 2+minisignKeyIDLen]
+	trustedComment := strings.TrimPrefix(strings.TrimRight(lines[2], "\r"), minisignTrustedCommentPrefix)
+
+	for _, key := range v.minisignKeys {
+		if !bytes.Equal(key.keyID[:], keyID) {
+			continue
+		}
+
+		if !ed25519.Verify(key.key, message, sig[2+minisignKeyIDLen:]) {
+			return errors.New("invalid minisign signature")
+		}
+
+		if !ed25519.Verify(key.key, slices.Concat(sig[2+minisignKeyIDLen:], []byte(trustedComment)), globalSig) {
+			return errors.New("invalid minisign trusted comment signature")
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("no public key matches the minisign key ID %X", keyID)
+}
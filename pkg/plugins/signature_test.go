@@ -0,0 +1,168 @@
+package plugins
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+)
+
+func TestSignatureVerifier(t *testing.T) {
+	data := []byte("plugin archive")
+
+	minisignPub, minisignKey := generateMinisignKey(t)
+	_, otherMinisignKey := generateMinisignKey(t)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaPub := encodePEMPublicKey(t, &ecdsaKey.PublicKey)
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	edPEMPub := encodePEMPublicKey(t, edPub)
+
+	digest := sha256.Sum256(data)
+	ecdsaSig, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		publicKeys []string
+		data       []byte
+		signature  string
+		expectErr  bool
+	}{
+		{
+			desc:       "minisign signature",
+			publicKeys: []string{minisignPub},
+			data:       data,
+			signature:  minisignKey.sign(t, data, false),
+		},
+		{
+			desc:       "minisign prehashed signature",
+			publicKeys: []string{"untrusted comment: minisign public key\n" + minisignPub},
+			data:       data,
+			signature:  minisignKey.sign(t, data, true),
+		},
+		{
+			desc:       "minisign signature of another key",
+			publicKeys: []string{minisignPub},
+			data:       data,
+			signature:  otherMinisignKey.sign(t, data, false),
+			expectErr:  true,
+		},
+		{
+			desc:       "minisign signature of altered data",
+			publicKeys: []string{minisignPub},
+			data:       []byte("altered plugin archive"),
+			signature:  minisignKey.sign(t, data, false),
+			expectErr:  true,
+		},
+		{
+			desc:       "ECDSA signature",
+			publicKeys: []string{minisignPub, ecdsaPub},
+			data:       data,
+			signature:  base64.StdEncoding.EncodeToString(ecdsaSig),
+		},
+		{
+			desc:       "Ed25519 signature",
+			publicKeys: []string{edPEMPub},
+			data:       data,
+			signature:  base64.StdEncoding.EncodeToString(ed25519.Sign(edKey, data)),
+		},
+		{
+			desc:       "ECDSA signature of altered data",
+			publicKeys: []string{ecdsaPub},
+			data:       []byte("altered plugin archive"),
+			signature:  base64.StdEncoding.EncodeToString(ecdsaSig),
+			expectErr:  true,
+		},
+		{
+			desc:       "ECDSA signature without the ECDSA key",
+			publicKeys: []string{edPEMPub},
+			data:       data,
+			signature:  base64.StdEncoding.EncodeToString(ecdsaSig),
+			expectErr:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			verifier, err := newSignatureVerifier(test.publicKeys)
+			require.NoError(t, err)
+
+			err = verifier.verify(test.data, []byte(test.signature))
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewSignatureVerifier_invalidKey(t *testing.T) {
+	_, err := newSignatureVerifier([]string{"invalid"})
+	assert.Error(t, err)
+}
+
+type testMinisignKey struct {
+	keyID      []byte
+	privateKey ed25519.PrivateKey
+}
+
+func generateMinisignKey(t *testing.T) (string, testMinisignKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyID := make([]byte, minisignKeyIDLen)
+	_, err = rand.Read(keyID)
+	require.NoError(t, err)
+
+	encoded := base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...))
+
+	return encoded, testMinisignKey{keyID: keyID, privateKey: priv}
+}
+
+func (k testMinisignKey) sign(t *testing.T, data []byte, prehashed bool) string {
+	t.Helper()
+
+	alg := []byte("Ed")
+	if prehashed {
+		alg = []byte("ED")
+		sum := blake2b.Sum512(data)
+		data = sum[:]
+	}
+
+	sig := ed25519.Sign(k.privateKey, data)
+	trustedComment := "timestamp:1700000000"
+	globalSig := ed25519.Sign(k.privateKey, append(append([]byte{}, sig...), trustedComment...))
+
+	return "untrusted comment: signature from minisign secret key\n" +
+		base64.StdEncoding.EncodeToString(append(append(alg, k.keyID...), sig...)) + "\n" +
+		"trusted comment: " + trustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(globalSig) + "\n"
+}
+
+func encodePEMPublicKey(t *testing.T, key any) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
@@ -0,0 +1,186 @@
+package plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// ociArchiveMediaType is the media type of the OCI layer holding a plugin archive.
+	ociArchiveMediaType = "application/vnd.apache4.plugin.archive.v1+zip"
+	// ociSignatureMediaType is the media type of the OCI layer holding a plugin archive signature.
+	ociSignatureMediaType = "application/vnd.apache4.plugin.signature.v1"
+)
+
+// copyFromDirectory copies the plugin archive, and its signature if any, from the local directory of archives.
+func (c *Client) copyFromDirectory(pName, pVersion string) (string, error) {
+	src := filepath.Join(c.directory, filepath.FromSlash(pName), pVersion+".zip")
+	dest := c.buildArchivePath(pName, pVersion)
+
+	// Removes the signature of a previous copy, as the archive might not be signed anymore.
+	if err := os.RemoveAll(dest + signatureExt); err != nil {
+		return "", fmt.Errorf("failed to remove signature: %w", err)
+	}
+
+	if err := copyFile(src, dest); err != nil {
+		return "", fmt.Errorf("failed to copy archive: %w", err)
+	}
+
+	err := copyFile(src+signatureExt, dest+signatureExt)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("failed to copy signature: %w", err)
+	}
+
+	hash, err := computeHash(dest)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// extractFromOCILayout extracts the plugin archive, and its signature if any, from the layers of the image
+// referenced as <moduleName>:<version> in the local OCI image layout.
+func (c *Client) extractFromOCILayout(pName, pVersion string) (string, error) {
+	var index ocispec.Index
+	if err := readOCIJSON(filepath.Join(c.ociLayout, ocispec.ImageIndexFile), &index); err != nil {
+		return "", fmt.Errorf("failed to read OCI index: %w", err)
+	}
+
+	ref := pName + ":" + pVersion
+
+	var manifestDesc *ocispec.Descriptor
+	for _, desc := range index.Manifests {
+		if desc.Annotations[ocispec.AnnotationRefName] == ref {
+			manifestDesc = &desc
+			break
+		}
+	}
+
+	if manifestDesc == nil {
+		return "", fmt.Errorf("no image referenced as %s in the OCI layout", ref)
+	}
+
+	manifestPath, err := c.ociBlobPath(manifestDesc.Digest)
+	if err != nil {
+		return "", err
+	}
+
+	var manifest ocispec.Manifest
+	if err = readOCIJSON(manifestPath, &manifest); err != nil {
+		return "", fmt.Errorf("failed to read OCI manifest: %w", err)
+	}
+
+	dest := c.buildArchivePath(pName, pVersion)
+
+	// Removes the signature of a previous extraction, as the archive might not be signed anymore.
+	if err = os.RemoveAll(dest + signatureExt); err != nil {
+		return "", fmt.Errorf("failed to remove signature: %w", err)
+	}
+
+	var found bool
+	for _, layer := range manifest.Layers {
+		var layerDest string
+		switch layer.MediaType {
+		case ociArchiveMediaType:
+			layerDest = dest
+			found = true
+		case ociSignatureMediaType:
+			layerDest = dest + signatureExt
+		default:
+			continue
+		}
+
+		if err = c.extractOCIBlob(layer.Digest, layerDest); err != nil {
+			return "", err
+		}
+	}
+
+	if !found {
+		return "", fmt.Errorf("no layer of type %s in the image %s", ociArchiveMediaType, ref)
+	}
+
+	hash, err := computeHash(dest)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+// extractOCIBlob copies a blob of the OCI layout, after having verified its content matches its digest.
+func (c *Client) extractOCIBlob(dgst digest.Digest, dest string) error {
+	src, err := c.ociBlobPath(dgst)
+	if err != nil {
+		return err
+	}
+
+	if err = copyFile(src, dest); err != nil {
+		return fmt.Errorf("failed to copy blob %s: %w", dgst, err)
+	}
+
+	file, err := os.Open(dest)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	actual, err := dgst.Algorithm().FromReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to compute blob digest: %w", err)
+	}
+
+	if actual != dgst {
+		return fmt.Errorf("%w: the blob digest %s does not match the expected digest %s", ErrIntegrityCheck, actual, dgst)
+	}
+
+	return nil
+}
+
+func (c *Client) ociBlobPath(dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", fmt.Errorf("invalid digest %q: %w", dgst, err)
+	}
+
+	return filepath.Join(c.ociLayout, ocispec.ImageBlobsDir, dgst.Algorithm().String(), dgst.Encoded()), nil
+}
+
+func readOCIJSON(filename string, v any) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = in.Close() }()
+
+	if err = os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create file %q: %w", dest, err)
+	}
+
+	defer func() { _ = out.Close() }()
+
+	_, err = io.Copy(out, in)
+	return err
+}
@@ -0,0 +1,209 @@
+package plugins
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testPluginName    = "github.com/apache4/plugindemo"
+	testPluginVersion = "v1.0.0"
+)
+
+func TestClient_DownloadAndCheck(t *testing.T) {
+	archive := []byte("plugin archive")
+	sum := sha256.Sum256(archive)
+	hash := hex.EncodeToString(sum[:])
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signature := []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(edKey, archive)))
+	publicKeys := []string{encodePEMPublicKey(t, edPub)}
+
+	testCases := []struct {
+		desc              string
+		registry          func(t *testing.T) *Registry
+		pinnedHash        string
+		expectDownloadErr bool
+		expectCheckErr    bool
+	}{
+		{
+			desc: "directory with pinned hash",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{Directory: writeArchiveDirectory(t, archive, nil)}
+			},
+			pinnedHash: "sha256:" + hash,
+		},
+		{
+			desc: "directory with mismatching pinned hash",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{Directory: writeArchiveDirectory(t, archive, nil)}
+			},
+			pinnedHash:     hex.EncodeToString(make([]byte, sha256.Size)),
+			expectCheckErr: true,
+		},
+		{
+			desc: "directory without pinned hash nor public keys",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{Directory: writeArchiveDirectory(t, archive, nil)}
+			},
+			expectCheckErr: true,
+		},
+		{
+			desc: "directory with signature",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{Directory: writeArchiveDirectory(t, archive, signature), PublicKeys: publicKeys}
+			},
+		},
+		{
+			desc: "directory without signature",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{Directory: writeArchiveDirectory(t, archive, nil), PublicKeys: publicKeys}
+			},
+			pinnedHash:     hash,
+			expectCheckErr: true,
+		},
+		{
+			desc: "missing archive in directory",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{Directory: t.TempDir()}
+			},
+			expectDownloadErr: true,
+		},
+		{
+			desc: "OCI layout with pinned hash",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{OCILayout: writeOCILayout(t, archive, nil, false)}
+			},
+			pinnedHash: hash,
+		},
+		{
+			desc: "OCI layout with signature",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{OCILayout: writeOCILayout(t, archive, signature, false), PublicKeys: publicKeys}
+			},
+		},
+		{
+			desc: "OCI layout with altered blob",
+			registry: func(t *testing.T) *Registry {
+				t.Helper()
+				return &Registry{OCILayout: writeOCILayout(t, archive, nil, true)}
+			},
+			expectDownloadErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := NewClient(ClientOptions{Output: t.TempDir(), Registry: test.registry(t)})
+			require.NoError(t, err)
+
+			gotHash, err := client.Download(t.Context(), testPluginName, testPluginVersion)
+			if test.expectDownloadErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, hash, gotHash)
+
+			err = client.Check(t.Context(), testPluginName, testPluginVersion, test.pinnedHash, gotHash)
+			if test.expectCheckErr {
+				assert.ErrorIs(t, err, ErrIntegrityCheck)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNewClient_severalSources(t *testing.T) {
+	_, err := NewClient(ClientOptions{Output: t.TempDir(), Registry: &Registry{URL: "https://plugins.example.com", Directory: t.TempDir()}})
+	assert.Error(t, err)
+}
+
+func writeArchiveDirectory(t *testing.T, archive, signature []byte) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, filepath.FromSlash(testPluginName), testPluginVersion+".zip")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(archivePath), 0o755))
+	require.NoError(t, os.WriteFile(archivePath, archive, 0o644))
+
+	if signature != nil {
+		require.NoError(t, os.WriteFile(archivePath+signatureExt, signature, 0o644))
+	}
+
+	return dir
+}
+
+func writeOCILayout(t *testing.T, archive, signature []byte, alter bool) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	writeBlob := func(data []byte) digest.Digest {
+		dgst := digest.FromBytes(data)
+		blobPath := filepath.Join(dir, ocispec.ImageBlobsDir, dgst.Algorithm().String(), dgst.Encoded())
+		require.NoError(t, os.MkdirAll(filepath.Dir(blobPath), 0o755))
+		require.NoError(t, os.WriteFile(blobPath, data, 0o644))
+		return dgst
+	}
+
+	archiveDigest := writeBlob(archive)
+	layers := []ocispec.Descriptor{{MediaType: ociArchiveMediaType, Digest: archiveDigest, Size: int64(len(archive))}}
+
+	if signature != nil {
+		layers = append(layers, ocispec.Descriptor{MediaType: ociSignatureMediaType, Digest: writeBlob(signature), Size: int64(len(signature))})
+	}
+
+	if alter {
+		blobPath := filepath.Join(dir, ocispec.ImageBlobsDir, archiveDigest.Algorithm().String(), archiveDigest.Encoded())
+		require.NoError(t, os.WriteFile(blobPath, []byte("altered plugin archive"), 0o644))
+	}
+
+	manifest, err := json.Marshal(ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+		Layers:    layers,
+	})
+	require.NoError(t, err)
+
+	index, err := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{{
+			MediaType:   ocispec.MediaTypeImageManifest,
+			Digest:      writeBlob(manifest),
+			Size:        int64(len(manifest)),
+			Annotations: map[string]string{ocispec.AnnotationRefName: testPluginName + ":" + testPluginVersion},
+		}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ocispec.ImageIndexFile), index, 0o644))
+
+	return dir
+}
@@ -0,0 +1,365 @@
+package plugins
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/http-wasm/http-wasm-host-go/api"
+	"github.com/rs/zerolog"
+	"github.com/tetratelabs/wazero"
+	wazeroapi "github.com/tetratelabs/wazero/api"
+	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/middlewares"
+	"github.com/apache4/apache4/v3/pkg/tcp"
+)
+
+const (
+	// wasmTCPHostModule is the name of the host module imported by the Wasm TCP middlewares.
+	wasmTCPHostModule = "apache4_tcp"
+	// wasmTCPHandleConn is the function exported by the Wasm TCP middlewares to handle a connection.
+	// It returns 1 to pass the connection to the next handler, and 0 to close it.
+	wasmTCPHandleConn = "handle_conn"
+	// wasmTCPMaxReadSize is the maximum number of bytes read at once by the Wasm TCP middlewares,
+	// whatever the size of the buffer they read into, for the host not to allocate an arbitrary amount of memory.
+	wasmTCPMaxReadSize = 32 * 1024
+)
+
+type wasmTCPConnKey struct{}
+
+type wasmTCPMiddlewareBuilder struct {
+	path     string
+	cache    wazero.CompilationCache
+	settings Settings
+}
+
+func newWasmTCPMiddlewareBuilder(goPath, moduleName, wasmPath string, settings Settings) (*wasmTCPMiddlewareBuilder, error) {
+	ctx := context.Background()
+	path := filepath.Join(goPath, "src", moduleName, wasmPath)
+	cache := wazero.NewCompilationCache()
+
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading Wasm binary: %w", err)
+	}
+
+	rtConfig, err := newWasmRuntimeConfig(cache, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := wazero.NewRuntimeWithConfig(ctx, rtConfig)
+	guestModule, err := rt.CompileModule(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("compiling guest module: %w", err)
+	}
+
+	if _, ok := guestModule.ExportedFunctions()[wasmTCPHandleConn]; !ok {
+		return nil, fmt.Errorf("guest module does not export the %s function", wasmTCPHandleConn)
+	}
+
+	return &wasmTCPMiddlewareBuilder{path: path, cache: cache, settings: settings}, nil
+}
+
+func (b wasmTCPMiddlewareBuilder) newTCPMiddleware(config map[string]interface{}, middlewareName string) (pluginTCPMiddleware, error) {
+	return &WasmTCPMiddleware{
+		middlewareName: middlewareName,
+		config:         config,
+		builder:        b,
+	}, nil
+}
+
+func (b *wasmTCPMiddlewareBuilder) buildHandler(ctx context.Context, next tcp.Handler, cfg map[string]interface{}, middlewareName string) (*wasmTCPHandler, error) {
+	code, err := os.ReadFile(b.path)
+	if err != nil {
+		return nil, fmt.Errorf("loading binary: %w", err)
+	}
+
+	rtConfig, err := newWasmRuntimeConfig(b.cache, b.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	rt := wazero.NewRuntimeWithConfig(ctx, rtConfig)
+
+	guestModule, err := rt.CompileModule(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("compiling guest module: %w", err)
+	}
+
+	applyCtx, err := InstantiateHost(ctx, rt, guestModule, b.settings)
+	if err != nil {
+		return nil, fmt.Errorf("instantiating host module: %w", err)
+	}
+
+	moduleConfig, err := newWasmModuleConfig(b.settings)
+	if err != nil {
+		return nil, err
+	}
+
+	var guestConfig []byte
+	if cfg != nil {
+		guestConfig, err = json.Marshal(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling config: %w", err)
+		}
+	}
+
+	logger := middlewares.GetLogger(ctx, middlewareName, "wasm")
+
+	h := &wasmTCPHandler{
+		ctx:          applyCtx(ctx),
+		rt:           rt,
+		guestModule:  guestModule,
+		moduleConfig: moduleConfig,
+		guestConfig:  guestConfig,
+		logger:       logger,
+		next:         next,
+	}
+
+	if err = h.instantiateHostModule(ctx); err != nil {
+		return nil, fmt.Errorf("instantiating %s host module: %w", wasmTCPHostModule, err)
+	}
+
+	// Instantiates a first guest to report the initialization errors when building the middleware.
+	mod, err := h.newInstance()
+	if err != nil {
+		return nil, err
+	}
+	h.putInstance(mod)
+
+	// apache4 does not Close the middleware when creating a new instance on a configuration change.
+	// When the middleware is marked to be GC, we need to close the runtime so the wasm instances are properly closed.
+	runtime.SetFinalizer(h, func(h *wasmTCPHandler) {
+		if err := h.rt.Close(ctx); err != nil {
+			logger.Err(err).Msg("[wasm] TCP middleware Close failed")
+		} else {
+			logger.Debug().Msg("[wasm] TCP middleware Close ok")
+		}
+	})
+
+	return h, nil
+}
+
+// WasmTCPMiddleware is a TCP handler plugin wrapper.
+type WasmTCPMiddleware struct {
+	middlewareName string
+	config         map[string]interface{}
+	builder        wasmTCPMiddlewareBuilder
+}
+
+// NewHandler creates a new TCP handler.
+func (m WasmTCPMiddleware) NewHandler(ctx context.Context, next tcp.Handler) (tcp.Handler, error) {
+	h, err := m.builder.buildHandler(ctx, next, m.config, m.middlewareName)
+	if err != nil {
+		return nil, fmt.Errorf("building Wasm TCP middleware: %w", err)
+	}
+
+	return h, nil
+}
+
+// wasmTCPHandler calls the guest handle_conn function for each connection.
+// As a guest instance cannot be called concurrently, the idle instances are pooled.
+type wasmTCPHandler struct {
+	ctx          context.Context
+	rt           wazero.Runtime
+	guestModule  wazero.CompiledModule
+	moduleConfig wazero.ModuleConfig
+	guestConfig  []byte
+	logger       *zerolog.Logger
+	next         tcp.Handler
+
+	instancesMu sync.Mutex
+	instances   []wazeroapi.Module
+}
+
+func (h *wasmTCPHandler) ServeTCP(conn tcp.WriteCloser) {
+	mod, err := h.getInstance()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("[wasm] Unable to instantiate the guest module")
+		_ = conn.Close()
+		return
+	}
+
+	wConn := &wasmTCPConn{WriteCloser: conn, reader: bufio.NewReader(conn)}
+
+	results, err := mod.ExportedFunction(wasmTCPHandleConn).Call(context.WithValue(h.ctx, wasmTCPConnKey{}, wConn))
+	if err != nil {
+		// The guest instance state is undefined after a failed call.
+		_ = mod.Close(h.ctx)
+
+		h.logger.Error().Err(err).Msg("[wasm] Error while handling the TCP connection")
+		_ = conn.Close()
+		return
+	}
+
+	h.putInstance(mod)
+
+	// Resets the read deadline which could have been set by the guest.
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		h.logger.Debug().Err(err).Msg("[wasm] Error while resetting the TCP connection read deadline")
+	}
+
+	if len(results) == 0 || uint32(results[0]) == 0 {
+		_ = conn.Close()
+		return
+	}
+
+	h.next.ServeTCP(wConn)
+}
+
+func (h *wasmTCPHandler) getInstance() (wazeroapi.Module, error) {
+	h.instancesMu.Lock()
+	if n := len(h.instances); n > 0 {
+		mod := h.instances[n-1]
+		h.instances = h.instances[:n-1]
+		h.instancesMu.Unlock()
+
+		return mod, nil
+	}
+	h.instancesMu.Unlock()
+
+	return h.newInstance()
+}
+
+func (h *wasmTCPHandler) putInstance(mod wazeroapi.Module) {
+	h.instancesMu.Lock()
+	defer h.instancesMu.Unlock()
+
+	h.instances = append(h.instances, mod)
+}
+
+func (h *wasmTCPHandler) newInstance() (wazeroapi.Module, error) {
+	// The guest modules are anonymous as they are instantiated several times in the same runtime.
+	mod, err := h.rt.InstantiateModule(h.ctx, h.guestModule, h.moduleConfig.WithName(""))
+	if err != nil {
+		return nil, fmt.Errorf("instantiating guest module: %w", err)
+	}
+
+	return mod, nil
+}
+
+// instantiateHostModule instantiates the host functions available to the guest while handling a connection.
+// The buffers are allocated by the guest, and the functions return -1 when the connection operation fails.
+func (h *wasmTCPHandler) instantiateHostModule(ctx context.Context) error {
+	wasmLogger := logs.NewWasmLogger(h.logger)
+
+	_, err := h.rt.NewHostModuleBuilder(wasmTCPHostModule).
+		NewFunctionBuilder().
+		WithFunc(func(_ context.Context, mod wazeroapi.Module, buf, bufLimit uint32) uint32 {
+			return writeIfFits(mod, buf, bufLimit, h.guestConfig)
+		}).
+		Export("get_config").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, level int32, buf, bufLen uint32) {
+			wasmLogger.Log(ctx, api.LogLevel(level), string(mustRead(mod, buf, bufLen)))
+		}).
+		Export("log").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLimit uint32) uint32 {
+			return writeIfFits(mod, buf, bufLimit, []byte(connFromContext(ctx).RemoteAddr().String()))
+		}).
+		Export("get_remote_addr").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLimit uint32) uint32 {
+			return writeIfFits(mod, buf, bufLimit, []byte(connFromContext(ctx).LocalAddr().String()))
+		}).
+		Export("get_local_addr").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, timeoutMs uint32) int32 {
+			var deadline time.Time
+			if timeoutMs > 0 {
+				deadline = time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+			}
+
+			if err := connFromContext(ctx).SetReadDeadline(deadline); err != nil {
+				return -1
+			}
+			return 0
+		}).
+		Export("set_read_deadline").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLen uint32) int32 {
+			// The peeked bytes are not consumed, they are read again by the next handler.
+			data, err := connFromContext(ctx).reader.Peek(min(int(bufLen), connFromContext(ctx).reader.Size()))
+			if len(data) == 0 && err != nil {
+				return -1
+			}
+
+			mustWrite(mod, buf, data)
+			return int32(len(data))
+		}).
+		Export("peek").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLen uint32) int32 {
+			data := make([]byte, min(bufLen, wasmTCPMaxReadSize))
+			n, err := connFromContext(ctx).reader.Read(data)
+			if n == 0 && err != nil {
+				return -1
+			}
+
+			mustWrite(mod, buf, data[:n])
+			return int32(n)
+		}).
+		Export("read").
+		NewFunctionBuilder().
+		WithFunc(func(ctx context.Context, mod wazeroapi.Module, buf, bufLen uint32) int32 {
+			n, err := connFromContext(ctx).Write(mustRead(mod, buf, bufLen))
+			if err != nil {
+				return -1
+			}
+			return int32(n)
+		}).
+		Export("write").
+		Instantiate(ctx)
+
+	return err
+}
+
+func connFromContext(ctx context.Context) *wasmTCPConn {
+	conn, ok := ctx.Value(wasmTCPConnKey{}).(*wasmTCPConn)
+	if !ok {
+		panic("no TCP connection in the context")
+	}
+	return conn
+}
+
+// writeIfFits writes the given data to the guest memory when it fits in the buffer, and returns the data length.
+func writeIfFits(mod wazeroapi.Module, buf, bufLimit uint32, data []byte) uint32 {
+	if uint32(len(data)) <= bufLimit {
+		mustWrite(mod, buf, data)
+	}
+	return uint32(len(data))
+}
+
+func mustWrite(mod wazeroapi.Module, offset uint32, data []byte) {
+	if !mod.Memory().Write(offset, data) {
+		panic(fmt.Sprintf("out of memory writing %d bytes at offset %d", len(data), offset))
+	}
+}
+
+func mustRead(mod wazeroapi.Module, offset, byteCount uint32) []byte {
+	data, ok := mod.Memory().Read(offset, byteCount)
+	if !ok {
+		panic(fmt.Sprintf("out of memory reading %d bytes at offset %d", byteCount, offset))
+	}
+	return data
+}
+
+// wasmTCPConn is the connection handled by the guest,
+// which replays the peeked bytes to the next handler.
+type wasmTCPConn struct {
+	tcp.WriteCloser
+
+	reader *bufio.Reader
+}
+
+func (c *wasmTCPConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
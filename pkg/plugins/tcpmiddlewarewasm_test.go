@@ -0,0 +1,82 @@
+package plugins
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tetratelabs/wazero"
+	"github.com/apache4/apache4/v3/pkg/tcp"
+)
+
+func TestWasmTCPMiddleware(t *testing.T) {
+	pluginPath := buildWasmFixture(t, "tcpmiddleware")
+	cache := wazero.NewCompilationCache()
+
+	ctx := log.Logger.WithContext(t.Context())
+
+	testCases := []struct {
+		desc          string
+		payload       string
+		expected      string
+		expectForward bool
+	}{
+		{
+			desc:     "handled by the plugin",
+			payload:  "PING",
+			expected: "PONG",
+		},
+		{
+			desc:    "closed by the plugin",
+			payload: "DENY",
+		},
+		{
+			desc:          "forwarded to the next handler",
+			payload:       "HELLO",
+			expected:      "HELLO",
+			expectForward: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := &wasmTCPMiddlewareBuilder{path: pluginPath, cache: cache}
+
+			m, err := builder.newTCPMiddleware(map[string]interface{}{"reply": "PONG"}, "test")
+			require.NoError(t, err)
+
+			forwarded := make(chan struct{}, 1)
+			h, err := m.NewHandler(ctx, tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+				forwarded <- struct{}{}
+
+				// Echoes the payload, including the bytes peeked by the plugin.
+				buf := make([]byte, len(test.payload))
+				_, _ = io.ReadFull(conn, buf)
+				_, _ = conn.Write(buf)
+				_ = conn.Close()
+			}))
+			require.NoError(t, err)
+
+			clientConn, serverConn := net.Pipe()
+			t.Cleanup(func() { _ = clientConn.Close() })
+
+			go h.ServeTCP(closeWriteConn{Conn: serverConn})
+
+			_, err = clientConn.Write([]byte(test.payload))
+			require.NoError(t, err)
+
+			require.NoError(t, clientConn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			got, err := io.ReadAll(clientConn)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, string(got))
+			assert.Equal(t, test.expectForward, len(forwarded) == 1)
+		})
+	}
+}
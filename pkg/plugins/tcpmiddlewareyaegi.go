@@ -0,0 +1,153 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/apache4/apache4/v3/pkg/tcp"
+	"github.com/apache4/yaegi/interp"
+)
+
+// TCPHandler the interface of a plugin's TCP middleware handler.
+type TCPHandler interface {
+	ServeTCP(conn net.Conn)
+}
+
+type _TCPHandler struct {
+	IValue    interface{}
+	WServeTCP func(conn net.Conn)
+}
+
+func (h _TCPHandler) ServeTCP(conn net.Conn) {
+	h.WServeTCP(conn)
+}
+
+type yaegiTCPMiddlewareBuilder struct {
+	fnNew          reflect.Value
+	fnCreateConfig reflect.Value
+}
+
+func newYaegiTCPMiddlewareBuilder(i *interp.Interpreter, basePkg, imp string) (*yaegiTCPMiddlewareBuilder, error) {
+	if basePkg == "" {
+		basePkg = strings.ReplaceAll(path.Base(imp), "-", "_")
+	}
+
+	// The wrapper converts the handler returned by the plugin,
+	// which only relies on the standard library types, to the TCPHandler interface known by apache4.
+	_, err := i.Eval(`package tcpwrapper
+
+import (
+	"context"
+
+	` + basePkg + ` "` + imp + `"
+	"github.com/apache4/apache4/v3/pkg/plugins"
+)
+
+func New(ctx context.Context, next plugins.TCPHandler, config *` + basePkg + `.Config, name string) (plugins.TCPHandler, error) {
+	h, err := ` + basePkg + `.New(ctx, next, config, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var hv plugins.TCPHandler = h
+	return hv, nil
+}
+`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to eval wrapper: %w", err)
+	}
+
+	fnNew, err := i.Eval(`tcpwrapper.New`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to eval New: %w", err)
+	}
+
+	fnCreateConfig, err := i.Eval(basePkg + `.CreateConfig`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to eval CreateConfig: %w", err)
+	}
+
+	return &yaegiTCPMiddlewareBuilder{
+		fnNew:          fnNew,
+		fnCreateConfig: fnCreateConfig,
+	}, nil
+}
+
+func (b yaegiTCPMiddlewareBuilder) newTCPMiddleware(config map[string]interface{}, middlewareName string) (pluginTCPMiddleware, error) {
+	vConfig, err := createYaegiConfig(b.fnCreateConfig, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &YaegiTCPMiddleware{
+		middlewareName: middlewareName,
+		config:         vConfig,
+		builder:        b,
+	}, nil
+}
+
+func (b yaegiTCPMiddlewareBuilder) newHandler(ctx context.Context, next tcp.Handler, cfg reflect.Value, middlewareName string) (tcp.Handler, error) {
+	var pluginNext TCPHandler = tcpNextHandler{next: next}
+
+	args := []reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(&pluginNext).Elem(), cfg, reflect.ValueOf(middlewareName)}
+	results := b.fnNew.Call(args)
+
+	if len(results) > 1 && results[1].Interface() != nil {
+		err, ok := results[1].Interface().(error)
+		if !ok {
+			return nil, fmt.Errorf("invalid error type: %T", results[0].Interface())
+		}
+
+		return nil, err
+	}
+
+	handler, ok := results[0].Interface().(TCPHandler)
+	if !ok {
+		return nil, fmt.Errorf("invalid handler type: %T", results[0].Interface())
+	}
+
+	return tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		handler.ServeTCP(conn)
+	}), nil
+}
+
+// YaegiTCPMiddleware is a TCP handler plugin wrapper.
+type YaegiTCPMiddleware struct {
+	middlewareName string
+	config         reflect.Value
+	builder        yaegiTCPMiddlewareBuilder
+}
+
+// NewHandler creates a new TCP handler.
+func (m *YaegiTCPMiddleware) NewHandler(ctx context.Context, next tcp.Handler) (tcp.Handler, error) {
+	return m.builder.newHandler(ctx, next, m.config, m.middlewareName)
+}
+
+// tcpNextHandler exposes the next TCP handler of the chain to the plugins.
+type tcpNextHandler struct {
+	next tcp.Handler
+}
+
+func (h tcpNextHandler) ServeTCP(conn net.Conn) {
+	if wc, ok := conn.(tcp.WriteCloser); ok {
+		h.next.ServeTCP(wc)
+		return
+	}
+
+	// The plugin wrapped the connection without exposing the CloseWrite method.
+	h.next.ServeTCP(closeWriteConn{Conn: conn})
+}
+
+// closeWriteConn is a net.Conn which cannot be half-closed,
+// the whole connection is closed when the write side is.
+type closeWriteConn struct {
+	net.Conn
+}
+
+func (c closeWriteConn) CloseWrite() error {
+	return c.Close()
+}
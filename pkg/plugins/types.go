@@ -1,19 +1,25 @@
 package plugins
 
+import ptypes "github.com/apache4/paerser/types"
+
 const (
 	runtimeYaegi = "yaegi"
 	runtimeWasm  = "wasm"
 )
 
 const (
-	typeMiddleware = "middleware"
-	typeProvider   = "provider"
+	typeMiddleware    = "middleware"
+	typeTCPMiddleware = "tcpMiddleware"
+	typeProvider      = "provider"
 )
 
 type Settings struct {
-	Envs      []string `description:"Environment variables to forward to the wasm guest." json:"envs,omitempty" toml:"envs,omitempty" yaml:"envs,omitempty"`
-	Mounts    []string `description:"Directory to mount to the wasm guest." json:"mounts,omitempty" toml:"mounts,omitempty" yaml:"mounts,omitempty"`
-	UseUnsafe bool     `description:"Allow the plugin to use unsafe package." json:"useUnsafe,omitempty" toml:"useUnsafe,omitempty" yaml:"useUnsafe,omitempty"`
+	Envs             []string        `description:"Environment variables to forward to the wasm guest." json:"envs,omitempty" toml:"envs,omitempty" yaml:"envs,omitempty"`
+	Mounts           []string        `description:"Directory to mount to the wasm guest." json:"mounts,omitempty" toml:"mounts,omitempty" yaml:"mounts,omitempty"`
+	UseUnsafe        bool            `description:"Allow the plugin to use unsafe package." json:"useUnsafe,omitempty" toml:"useUnsafe,omitempty" yaml:"useUnsafe,omitempty"`
+	MaxMemoryPages   uint32          `description:"Maximum number of 64KiB memory pages the wasm guest can allocate." json:"maxMemoryPages,omitempty" toml:"maxMemoryPages,omitempty" yaml:"maxMemoryPages,omitempty"`
+	ExecutionTimeout ptypes.Duration `description:"Maximum duration of the wasm guest execution for a request (works only for middleware plugins)." json:"executionTimeout,omitempty" toml:"executionTimeout,omitempty" yaml:"executionTimeout,omitempty"`
+	PoolSize         int             `description:"Maximum number of wasm guest instances handling requests concurrently (works only for middleware plugins)." json:"poolSize,omitempty" toml:"poolSize,omitempty" yaml:"poolSize,omitempty"`
 }
 
 // Descriptor The static part of a plugin configuration.
@@ -26,6 +32,17 @@ type Descriptor struct {
 
 	// Settings (optional)
 	Settings Settings `description:"Plugin's settings (works only for wasm plugins)." json:"settings,omitempty" toml:"settings,omitempty" yaml:"settings,omitempty" export:"true"`
+
+	// Hash (optional)
+	Hash string `description:"Pinned SHA-256 digest of the plugin's archive." json:"hash,omitempty" toml:"hash,omitempty" yaml:"hash,omitempty" export:"true"`
+}
+
+// Registry The source of the plugin archives.
+type Registry struct {
+	URL        string   `description:"URL of the plugin registry (defaults to the Plugin Catalog)." json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" export:"true"`
+	Directory  string   `description:"Directory of the plugin archives, stored as <moduleName>/<version>.zip." json:"directory,omitempty" toml:"directory,omitempty" yaml:"directory,omitempty" export:"true"`
+	OCILayout  string   `description:"Directory of an OCI image layout containing the plugin archives, referenced as <moduleName>:<version>." json:"ociLayout,omitempty" toml:"ociLayout,omitempty" yaml:"ociLayout,omitempty" export:"true"`
+	PublicKeys []string `description:"Public keys verifying the plugin archive signatures (minisign, or PEM encoded ECDSA and Ed25519 keys)." json:"publicKeys,omitempty" toml:"publicKeys,omitempty" yaml:"publicKeys,omitempty" export:"true"`
 }
 
 // LocalDescriptor The static part of a local plugin configuration.
@@ -35,6 +52,9 @@ type LocalDescriptor struct {
 
 	// Settings (optional)
 	Settings Settings `description:"Plugin's settings (works only for wasm plugins)." json:"settings,omitempty" toml:"settings,omitempty" yaml:"settings,omitempty" export:"true"`
+
+	// Watch (optional)
+	Watch bool `description:"Rebuilds the plugin when its sources change (works only for middleware plugins)." json:"watch,omitempty" toml:"watch,omitempty" yaml:"watch,omitempty" export:"true"`
 }
 
 // Manifest The plugin manifest.
@@ -0,0 +1,237 @@
+package plugins
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/safe"
+)
+
+// watchDebounce is the delay without changes after which a watched plugin is rebuilt,
+// as editors and compilers usually write the sources in several steps.
+const watchDebounce = 500 * time.Millisecond
+
+// watchedPlugin is a local plugin rebuilt when its sources change.
+// The last build error is returned when a middleware is created from the plugin,
+// so it is reported on the middlewares using it.
+type watchedPlugin struct {
+	name            string
+	goPath          string
+	descriptor      LocalDescriptor
+	metricsRegistry metrics.Registry
+
+	mu            sync.RWMutex
+	middleware    middlewareBuilder
+	tcpMiddleware tcpMiddlewareBuilder
+	err           error
+}
+
+func newWatchedPlugin(name, goPath string, descriptor LocalDescriptor, metricsRegistry metrics.Registry) *watchedPlugin {
+	p := &watchedPlugin{
+		name:            name,
+		goPath:          goPath,
+		descriptor:      descriptor,
+		metricsRegistry: metricsRegistry,
+	}
+
+	p.load()
+
+	return p
+}
+
+// load (re)builds the plugin from its sources.
+func (p *watchedPlugin) load() {
+	var middleware middlewareBuilder
+	var tcpMiddleware tcpMiddlewareBuilder
+
+	manifest, err := ReadManifest(p.goPath, p.descriptor.ModuleName)
+	if err == nil {
+		logger := log.With().
+			Str("plugin", "plugin-"+p.name).
+			Str("module", p.descriptor.ModuleName).
+			Str("runtime", manifest.Runtime).
+			Logger()
+		logCtx := logger.WithContext(context.Background())
+
+		switch manifest.Type {
+		case typeMiddleware:
+			middleware, err = newMiddlewareBuilder(logCtx, p.goPath, manifest, p.descriptor.ModuleName, p.descriptor.Settings, p.metricsRegistry)
+
+		case typeTCPMiddleware:
+			tcpMiddleware, err = newTCPMiddlewareBuilder(logCtx, p.goPath, manifest, p.descriptor.ModuleName, p.descriptor.Settings)
+
+		default:
+			err = fmt.Errorf("unsupported plugin type in watch mode: %s", manifest.Type)
+		}
+	} else {
+		err = fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	if err != nil {
+		err = fmt.Errorf("%s: %w", p.descriptor.ModuleName, err)
+		log.Error().Err(err).Str("plugin", "plugin-"+p.name).Msg("Unable to build the watched plugin")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.middleware = middleware
+	p.tcpMiddleware = tcpMiddleware
+	p.err = err
+}
+
+func (p *watchedPlugin) newMiddleware(config map[string]interface{}, middlewareName string) (pluginMiddleware, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	if p.middleware == nil {
+		return nil, fmt.Errorf("%s: not a middleware plugin", p.descriptor.ModuleName)
+	}
+
+	return p.middleware.newMiddleware(config, middlewareName)
+}
+
+func (p *watchedPlugin) newTCPMiddleware(config map[string]interface{}, middlewareName string) (pluginTCPMiddleware, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	if p.tcpMiddleware == nil {
+		return nil, fmt.Errorf("%s: not a TCP middleware plugin", p.descriptor.ModuleName)
+	}
+
+	return p.tcpMiddleware.newTCPMiddleware(config, middlewareName)
+}
+
+func (p *watchedPlugin) sourceDir() string {
+	return filepath.Join(p.goPath, "src", filepath.FromSlash(p.descriptor.ModuleName))
+}
+
+// WatchLocalPlugins watches the sources of the local plugins in watch mode.
+// When the sources of a plugin change, the plugin is rebuilt and onReload is called,
+// to rebuild the routers with the new plugin instances.
+func (b Builder) WatchLocalPlugins(pool *safe.Pool, onReload func()) error {
+	if len(b.watchedPlugins) == 0 {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating plugins watcher: %w", err)
+	}
+
+	dirs := make(map[string]*watchedPlugin)
+	for _, p := range b.watchedPlugins {
+		dir := p.sourceDir()
+
+		err = addWatchedDirs(watcher, dir)
+		if err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("%s: %w", p.descriptor.ModuleName, err)
+		}
+
+		dirs[dir] = p
+	}
+
+	pool.GoCtx(func(ctx context.Context) {
+		defer func() { _ = watcher.Close() }()
+
+		timer := time.NewTimer(watchDebounce)
+		timer.Stop()
+
+		changed := make(map[*watchedPlugin]struct{})
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case evt := <-watcher.Events:
+				p := findWatchedPlugin(dirs, evt.Name)
+				if p == nil {
+					continue
+				}
+
+				if evt.Has(fsnotify.Create) {
+					if info, err := os.Stat(evt.Name); err == nil && info.IsDir() {
+						if err := addWatchedDirs(watcher, evt.Name); err != nil {
+							log.Error().Err(err).Str("plugin", "plugin-"+p.name).Msg("Unable to watch the plugin sources")
+						}
+					}
+				}
+
+				changed[p] = struct{}{}
+				timer.Reset(watchDebounce)
+
+			case <-timer.C:
+				for p := range changed {
+					log.Info().Str("plugin", "plugin-"+p.name).Msg("Plugin sources changed, rebuilding the plugin")
+					p.load()
+				}
+
+				clear(changed)
+
+				onReload()
+
+			case err := <-watcher.Errors:
+				log.Error().Err(err).Msg("Plugins watcher event error")
+			}
+		}
+	})
+
+	return nil
+}
+
+// addWatchedDirs watches the given directory and its subdirectories.
+func addWatchedDirs(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path != root {
+				return nil
+			}
+			return err
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if err := watcher.Add(path); err != nil {
+			return fmt.Errorf("error adding plugins watcher on %s: %w", path, err)
+		}
+
+		return nil
+	})
+}
+
+// findWatchedPlugin returns the watched plugin owning the given file.
+func findWatchedPlugin(dirs map[string]*watchedPlugin, name string) *watchedPlugin {
+	dir := name
+	for {
+		if p, ok := dirs[dir]; ok {
+			return p
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,73 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/safe"
+)
+
+func TestBuilder_WatchLocalPlugins(t *testing.T) {
+	const moduleName = "github.com/apache4/watched"
+
+	goPath := t.TempDir()
+	pluginDir := filepath.Join(goPath, goPathSrc, filepath.FromSlash(moduleName))
+	require.NoError(t, os.MkdirAll(pluginDir, 0o755))
+
+	manifest := "displayName: Watched\ntype: middleware\nruntime: wasm\nsummary: Watched plugin\ntestData: {}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, pluginManifest), []byte(manifest), 0o644))
+
+	code, err := os.ReadFile("./fixtures/withoutsocket/plugin.wasm")
+	require.NoError(t, err)
+
+	wasmPath := filepath.Join(pluginDir, "plugin.wasm")
+	require.NoError(t, os.WriteFile(wasmPath, code, 0o644))
+
+	p := newWatchedPlugin("watched", goPath, LocalDescriptor{ModuleName: moduleName, Watch: true}, nil)
+
+	builder := Builder{
+		middlewareBuilders:    map[string]middlewareBuilder{"watched": p},
+		tcpMiddlewareBuilders: map[string]tcpMiddlewareBuilder{"watched": p},
+		watchedPlugins:        []*watchedPlugin{p},
+	}
+
+	pool := safe.NewPool(t.Context())
+	t.Cleanup(pool.Stop)
+
+	reloaded := make(chan struct{}, 1)
+	err = builder.WatchLocalPlugins(pool, func() { reloaded <- struct{}{} })
+	require.NoError(t, err)
+
+	_, err = builder.Build("watched", map[string]interface{}{}, "test")
+	require.NoError(t, err)
+
+	_, err = builder.BuildTCP("watched", map[string]interface{}{}, "test")
+	assert.Error(t, err)
+
+	// The compile error is reported when building the middleware.
+	require.NoError(t, os.WriteFile(wasmPath, []byte("invalid"), 0o644))
+	waitReload(t, reloaded)
+
+	_, err = builder.Build("watched", map[string]interface{}{}, "test")
+	assert.ErrorContains(t, err, moduleName)
+
+	require.NoError(t, os.WriteFile(wasmPath, code, 0o644))
+	waitReload(t, reloaded)
+
+	_, err = builder.Build("watched", map[string]interface{}{}, "test")
+	assert.NoError(t, err)
+}
+
+func waitReload(t *testing.T, reloaded <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-reloaded:
+	case <-time.After(30 * time.Second):
+		t.Fatal("the plugin has not been reloaded")
+	}
+}
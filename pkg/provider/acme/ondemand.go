@@ -0,0 +1,232 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/logs"
+	apache4tls "github.com/apache4/apache4/v3/pkg/tls"
+	"github.com/apache4/apache4/v3/pkg/types"
+	"golang.org/x/sync/singleflight"
+)
+
+// OnDemand configures the issuance of certificates during the TLS handshake,
+// for server names which are not known ahead of time.
+type OnDemand struct {
+	Ask                  string          `description:"URL queried to authorize the issuance of a certificate, the domain being given in the domain query parameter. A 2xx response authorizes the issuance." json:"ask,omitempty" toml:"ask,omitempty" yaml:"ask,omitempty"`
+	AllowedDomains       []string        `description:"Regular expressions, matching the whole domain, of the domains allowed to get a certificate." json:"allowedDomains,omitempty" toml:"allowedDomains,omitempty" yaml:"allowedDomains,omitempty"`
+	AskTimeout           ptypes.Duration `description:"Timeout of the authorization request." json:"askTimeout,omitempty" toml:"askTimeout,omitempty" yaml:"askTimeout,omitempty" export:"true"`
+	RateLimit            ptypes.Duration `description:"Minimum duration between two certificate orders for the same domain." json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+	FailureCacheDuration ptypes.Duration `description:"Duration during which a denied authorization or a failed order is cached." json:"failureCacheDuration,omitempty" toml:"failureCacheDuration,omitempty" yaml:"failureCacheDuration,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (o *OnDemand) SetDefaults() {
+	o.AskTimeout = ptypes.Duration(5 * time.Second)
+	o.RateLimit = ptypes.Duration(10 * time.Minute)
+	o.FailureCacheDuration = ptypes.Duration(5 * time.Minute)
+}
+
+// obtainFunc obtains a certificate for the given domain.
+// It returns no certificate and no error when the domain is already being resolved.
+type obtainFunc func(ctx context.Context, domain string) (*tls.Certificate, error)
+
+// onDemandResolver authorizes and obtains certificates during TLS handshakes.
+type onDemandResolver struct {
+	ask            string
+	allowedDomains []*regexp.Regexp
+	client         *http.Client
+	obtain         obtainFunc
+
+	// orders holds the domains for which a certificate has been ordered recently.
+	orders *cache.Cache
+	// failures holds the errors of the last denied authorizations and failed orders.
+	failures *cache.Cache
+
+	group singleflight.Group
+}
+
+func newOnDemandResolver(config *OnDemand, obtain obtainFunc) (*onDemandResolver, error) {
+	if config.Ask == "" && len(config.AllowedDomains) == 0 {
+		return nil, errors.New("on-demand certificates require an ask URL or allowed domains")
+	}
+
+	if config.Ask != "" {
+		if _, err := url.Parse(config.Ask); err != nil {
+			return nil, fmt.Errorf("parsing ask URL: %w", err)
+		}
+	}
+
+	r := &onDemandResolver{
+		ask:    config.Ask,
+		client: &http.Client{Timeout: time.Duration(config.AskTimeout)},
+		obtain: obtain,
+	}
+
+	for _, allowedDomain := range config.AllowedDomains {
+		re, err := regexp.Compile("^(?:" + allowedDomain + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("compiling allowed domain %q: %w", allowedDomain, err)
+		}
+
+		r.allowedDomains = append(r.allowedDomains, re)
+	}
+
+	// A zero default expiration means no expiration for the cache, hence the caches are only enabled for positive durations.
+	if config.RateLimit > 0 {
+		r.orders = cache.New(time.Duration(config.RateLimit), time.Duration(config.RateLimit))
+	}
+
+	if config.FailureCacheDuration > 0 {
+		r.failures = cache.New(time.Duration(config.FailureCacheDuration), time.Duration(config.FailureCacheDuration))
+	}
+
+	return r, nil
+}
+
+func (r *onDemandResolver) resolve(ctx context.Context, serverName string) (*tls.Certificate, error) {
+	domain := types.CanonicalDomain(serverName)
+
+	// No SNI, or an IP address which cannot get a certificate from an ACME CA.
+	if domain == "" || net.ParseIP(domain) != nil {
+		return nil, nil
+	}
+
+	if r.failures != nil {
+		if err, ok := r.failures.Get(domain); ok {
+			return nil, err.(error)
+		}
+	}
+
+	cert, err, _ := r.group.Do(domain, func() (any, error) {
+		if r.orders != nil {
+			if _, ok := r.orders.Get(domain); ok {
+				return nil, fmt.Errorf("certificate already ordered recently for domain %s", domain)
+			}
+		}
+
+		if err := r.authorize(ctx, domain); err != nil {
+			r.cacheFailure(domain, err)
+			return nil, err
+		}
+
+		if r.orders != nil {
+			r.orders.SetDefault(domain, struct{}{})
+		}
+
+		cert, err := r.obtain(ctx, domain)
+		if err != nil {
+			r.cacheFailure(domain, err)
+			return nil, err
+		}
+
+		return cert, nil
+	})
+	if err != nil || cert == nil {
+		return nil, err
+	}
+
+	return cert.(*tls.Certificate), nil
+}
+
+// authorize checks that a certificate can be issued for the given domain.
+func (r *onDemandResolver) authorize(ctx context.Context, domain string) error {
+	if len(r.allowedDomains) > 0 && !r.isAllowed(domain) {
+		return fmt.Errorf("domain %s is not allowed", domain)
+	}
+
+	if r.ask == "" {
+		return nil
+	}
+
+	askURL, err := url.Parse(r.ask)
+	if err != nil {
+		return fmt.Errorf("parsing ask URL: %w", err)
+	}
+
+	query := askURL.Query()
+	query.Set("domain", domain)
+	askURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, askURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("creating ask request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("querying ask URL: %w", err)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("domain %s denied by ask URL with status code %d", domain, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (r *onDemandResolver) isAllowed(domain string) bool {
+	for _, re := range r.allowedDomains {
+		if re.MatchString(domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *onDemandResolver) cacheFailure(domain string, err error) {
+	if r.failures != nil {
+		r.failures.SetDefault(domain, err)
+	}
+}
+
+// ResolveCertificate implements the tls.CertificateResolver interface,
+// and obtains a certificate for the requested server name when on-demand certificates are enabled.
+func (p *Provider) ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if p.onDemand == nil {
+		return nil, nil
+	}
+
+	logger := log.With().Str(logs.ProviderName, p.ResolverName+resolverSuffix).Str("serverName", clientHello.ServerName).Logger()
+
+	return p.onDemand.resolve(logger.WithContext(clientHello.Context()), clientHello.ServerName)
+}
+
+func (p *Provider) obtainOnDemand(ctx context.Context, domain string) (*tls.Certificate, error) {
+	log.Ctx(ctx).Debug().Msgf("Obtaining on-demand certificate for domain %s", domain)
+
+	dom, crt, err := p.resolveCertificate(ctx, types.Domain{Main: domain}, apache4tls.DefaultTLSStoreName)
+	if err != nil {
+		return nil, err
+	}
+
+	if crt == nil {
+		return nil, nil
+	}
+
+	if err = p.addCertificateForDomain(dom, crt, apache4tls.DefaultTLSStoreName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Strs("domains", dom.ToStrArray()).Msg("Error adding on-demand certificate for domain")
+	}
+
+	cert, err := tls.X509KeyPair(crt.Certificate, crt.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("loading on-demand certificate for domain %s: %w", domain, err)
+	}
+
+	return &cert, nil
+}
@@ -0,0 +1,217 @@
+package acme
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/apache4/paerser/types"
+)
+
+func TestNewOnDemandResolver(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    OnDemand
+		expectErr bool
+	}{
+		{
+			desc:      "no ask URL nor allowed domains",
+			config:    OnDemand{},
+			expectErr: true,
+		},
+		{
+			desc:      "invalid allowed domain",
+			config:    OnDemand{AllowedDomains: []string{"("}},
+			expectErr: true,
+		},
+		{
+			desc:   "ask URL",
+			config: OnDemand{Ask: "http://127.0.0.1/ask"},
+		},
+		{
+			desc:   "allowed domains",
+			config: OnDemand{AllowedDomains: []string{`.+\.example\.com`}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newOnDemandResolver(&test.config, nil)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestOnDemandResolver_authorize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Query().Get("domain") == "allowed.example.com" {
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		desc      string
+		config    OnDemand
+		domain    string
+		expectErr bool
+	}{
+		{
+			desc:   "allowed by regular expression",
+			config: OnDemand{AllowedDomains: []string{`.+\.example\.com`}},
+			domain: "foo.example.com",
+		},
+		{
+			desc:      "regular expression must match the whole domain",
+			config:    OnDemand{AllowedDomains: []string{`.+\.example\.com`}},
+			domain:    "foo.example.com.evil.org",
+			expectErr: true,
+		},
+		{
+			desc:   "allowed by ask URL",
+			config: OnDemand{Ask: server.URL},
+			domain: "allowed.example.com",
+		},
+		{
+			desc:      "denied by ask URL",
+			config:    OnDemand{Ask: server.URL},
+			domain:    "denied.example.com",
+			expectErr: true,
+		},
+		{
+			desc:      "allowed by regular expression but denied by ask URL",
+			config:    OnDemand{Ask: server.URL, AllowedDomains: []string{`.+\.example\.com`}},
+			domain:    "denied.example.com",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := newOnDemandResolver(&test.config, nil)
+			require.NoError(t, err)
+
+			err = r.authorize(context.Background(), test.domain)
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestOnDemandResolver_resolve(t *testing.T) {
+	expected := &tls.Certificate{}
+
+	testCases := []struct {
+		desc           string
+		config         OnDemand
+		serverNames    []string
+		obtainErr      error
+		expectedCert   *tls.Certificate
+		expectErr      bool
+		expectedOrders int32
+	}{
+		{
+			desc:        "no server name",
+			config:      OnDemand{AllowedDomains: []string{".*"}},
+			serverNames: []string{""},
+		},
+		{
+			desc:        "IP address",
+			config:      OnDemand{AllowedDomains: []string{".*"}},
+			serverNames: []string{"127.0.0.1"},
+		},
+		{
+			desc:           "certificate obtained",
+			config:         OnDemand{AllowedDomains: []string{".*"}},
+			serverNames:    []string{"Foo.Example.com"},
+			expectedCert:   expected,
+			expectedOrders: 1,
+		},
+		{
+			desc:        "domain not allowed",
+			config:      OnDemand{AllowedDomains: []string{`.+\.example\.org`}},
+			serverNames: []string{"foo.example.com"},
+			expectErr:   true,
+		},
+		{
+			desc:           "failure is cached",
+			config:         OnDemand{AllowedDomains: []string{".*"}, FailureCacheDuration: ptypes.Duration(time.Minute)},
+			serverNames:    []string{"foo.example.com", "foo.example.com"},
+			obtainErr:      errors.New("order failed"),
+			expectErr:      true,
+			expectedOrders: 1,
+		},
+		{
+			desc:           "orders are rate limited",
+			config:         OnDemand{AllowedDomains: []string{".*"}, RateLimit: ptypes.Duration(time.Minute)},
+			serverNames:    []string{"foo.example.com", "foo.example.com"},
+			obtainErr:      errors.New("order failed"),
+			expectErr:      true,
+			expectedOrders: 1,
+		},
+		{
+			desc:           "orders are not limited",
+			config:         OnDemand{AllowedDomains: []string{".*"}},
+			serverNames:    []string{"foo.example.com", "foo.example.com"},
+			obtainErr:      errors.New("order failed"),
+			expectErr:      true,
+			expectedOrders: 2,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var orders atomic.Int32
+			obtain := func(_ context.Context, domain string) (*tls.Certificate, error) {
+				orders.Add(1)
+				assert.Equal(t, "foo.example.com", domain)
+
+				return expected, test.obtainErr
+			}
+
+			r, err := newOnDemandResolver(&test.config, obtain)
+			require.NoError(t, err)
+
+			for _, serverName := range test.serverNames {
+				var cert *tls.Certificate
+				cert, err = r.resolve(context.Background(), serverName)
+				if test.expectErr {
+					assert.Error(t, err)
+				} else {
+					assert.NoError(t, err)
+				}
+
+				if test.obtainErr == nil {
+					assert.Equal(t, test.expectedCert, cert)
+				}
+			}
+
+			assert.Equal(t, test.expectedOrders, orders.Load())
+		})
+	}
+}
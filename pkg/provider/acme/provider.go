@@ -60,6 +60,8 @@ type Configuration struct {
 	DNSChallenge  *DNSChallenge  `description:"Activate DNS-01 Challenge." json:"dnsChallenge,omitempty" toml:"dnsChallenge,omitempty" yaml:"dnsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	HTTPChallenge *HTTPChallenge `description:"Activate HTTP-01 Challenge." json:"httpChallenge,omitempty" toml:"httpChallenge,omitempty" yaml:"httpChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	TLSChallenge  *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge." json:"tlsChallenge,omitempty" toml:"tlsChallenge,omitempty" yaml:"tlsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	OnDemand *OnDemand `description:"Activate the on-demand issuance of certificates during the TLS handshake." json:"onDemand,omitempty" toml:"onDemand,omitempty" yaml:"onDemand,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -140,11 +142,17 @@ type Provider struct {
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+	onDemand               *onDemandResolver
 }
 
-// SetTLSManager sets the tls manager to use.
+// SetTLSManager sets the tls manager to use,
+// and registers the provider as an on-demand resolver if on-demand certificates are enabled.
 func (p *Provider) SetTLSManager(tlsManager *apache4tls.Manager) {
 	p.tlsManager = tlsManager
+
+	if p.onDemand != nil {
+		tlsManager.AddOnDemandResolver(p.ResolverName, p)
+	}
 }
 
 // SetConfigListenerChan initializes the configFromListenerChan.
@@ -196,6 +204,13 @@ func (p *Provider) Init() error {
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
+	if p.OnDemand != nil {
+		p.onDemand, err = newOnDemandResolver(p.OnDemand, p.obtainOnDemand)
+		if err != nil {
+			return fmt.Errorf("unable to initialize on-demand certificates: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -279,6 +279,12 @@ func (p *Provider) addServer(item itemData, loadBalancer *dynamic.ServersLoadBal
 		return errors.New("address is missing")
 	}
 
+	// For the locality aware load-balancers, the Consul datacenters are the regions of the servers,
+	// unless defined by the labels.
+	if loadBalancer.Locality != nil && loadBalancer.Servers[0].Region == "" {
+		loadBalancer.Servers[0].Region = item.Datacenter
+	}
+
 	if loadBalancer.Servers[0].URL != "" {
 		if loadBalancer.Servers[0].Scheme != "" || loadBalancer.Servers[0].Port != "" {
 			return errors.New("defining scheme or port is not allowed when URL is defined")
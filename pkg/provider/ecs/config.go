@@ -255,6 +255,16 @@ func (p *Provider) addServer(instance ecsInstance, loadBalancer *dynamic.Servers
 		loadBalancer.Servers = []dynamic.Server{{}}
 	}
 
+	// The task availability zone and region are the locality of the server, unless defined by the labels.
+	if instance.machine != nil {
+		if loadBalancer.Servers[0].Zone == "" {
+			loadBalancer.Servers[0].Zone = instance.machine.zone
+		}
+		if loadBalancer.Servers[0].Region == "" {
+			loadBalancer.Servers[0].Region = instance.machine.region
+		}
+	}
+
 	if loadBalancer.Servers[0].URL != "" {
 		if loadBalancer.Servers[0].Scheme != "" || loadBalancer.Servers[0].Port != "" {
 			return errors.New("defining scheme or port is not allowed when URL is defined")
@@ -67,6 +67,8 @@ type machine struct {
 	privateIP    string
 	ports        []portMapping
 	healthStatus ecstypes.HealthStatus
+	zone         string
+	region       string
 }
 
 type awsClient struct {
@@ -355,6 +357,9 @@ func (p *Provider) listInstances(ctx context.Context, client *awsClient) ([]ecsI
 					}
 				}
 
+				mach.zone = aws.ToString(task.AvailabilityZone)
+				mach.region = client.ecs.Options().Region
+
 				instance := ecsInstance{
 					Name:                fmt.Sprintf("%s-%s", strings.Replace(aws.ToString(task.Group), ":", "-", 1), aws.ToString(container.Name)),
 					ID:                  key[len(key)-12:],
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/mitchellh/hashstructure"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	ptypes "github.com/apache4/paerser/types"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
@@ -290,9 +291,12 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			StripPrefixRegex:  middleware.Spec.StripPrefixRegex,
 			ReplacePath:       middleware.Spec.ReplacePath,
 			ReplacePathRegex:  middleware.Spec.ReplacePathRegex,
+			RewriteBody:       middleware.Spec.RewriteBody,
 			Chain:             createChainMiddleware(ctxMid, middleware.Namespace, middleware.Spec.Chain),
 			IPWhiteList:       middleware.Spec.IPWhiteList,
 			IPAllowList:       middleware.Spec.IPAllowList,
+			IPDenyList:        middleware.Spec.IPDenyList,
+			GeoIP:             middleware.Spec.GeoIP,
 			Headers:           middleware.Spec.Headers,
 			Errors:            errorPage,
 			RateLimit:         rateLimit,
@@ -320,6 +324,7 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			InFlightConn: middlewareTCP.Spec.InFlightConn,
 			IPWhiteList:  middlewareTCP.Spec.IPWhiteList,
 			IPAllowList:  middlewareTCP.Spec.IPAllowList,
+			IPDenyList:   middlewareTCP.Spec.IPDenyList,
 		}
 	}
 
@@ -449,6 +454,27 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			}
 		}
 
+		var http3Config *dynamic.HTTP3ClientConfig
+		if serversTransport.Spec.HTTP3 != nil {
+			http3Config = &dynamic.HTTP3ClientConfig{}
+			http3Config.SetDefaults()
+			http3Config.Allow0RTT = serversTransport.Spec.HTTP3.Allow0RTT
+
+			if serversTransport.Spec.HTTP3.MaxIdleTimeout != nil {
+				err := http3Config.MaxIdleTimeout.Set(serversTransport.Spec.HTTP3.MaxIdleTimeout.String())
+				if err != nil {
+					logger.Error().Err(err).Msg("Error while reading MaxIdleTimeout")
+				}
+			}
+
+			if serversTransport.Spec.HTTP3.KeepAlivePeriod != nil {
+				err := http3Config.KeepAlivePeriod.Set(serversTransport.Spec.HTTP3.KeepAlivePeriod.String())
+				if err != nil {
+					logger.Error().Err(err).Msg("Error while reading KeepAlivePeriod")
+				}
+			}
+		}
+
 		id := provider.Normalize(makeID(serversTransport.Namespace, serversTransport.Name))
 		conf.HTTP.ServersTransports[id] = &dynamic.ServersTransport{
 			ServerName:          serversTransport.Spec.ServerName,
@@ -460,6 +486,8 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			ForwardingTimeouts:  forwardingTimeout,
 			PeerCertURI:         serversTransport.Spec.PeerCertURI,
 			Spiffe:              serversTransport.Spec.Spiffe,
+			HTTP3:               http3Config,
+			Resolver:            createResolverConfig(logger, serversTransport.Spec.Resolver),
 		}
 	}
 
@@ -569,6 +597,8 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 			tcpServerTransport.TLS.Spiffe = serversTransportTCP.Spec.TLS.Spiffe
 		}
 
+		tcpServerTransport.Resolver = createResolverConfig(logger, serversTransportTCP.Spec.Resolver)
+
 		id := provider.Normalize(makeID(serversTransportTCP.Namespace, serversTransportTCP.Name))
 		conf.TCP.ServersTransports[id] = &tcpServerTransport
 	}
@@ -577,6 +607,34 @@ func (p *Provider) loadConfigurationFromCRD(ctx context.Context, client Client)
 }
 
 // getServicePort always returns a valid port, an error otherwise.
+func createResolverConfig(logger zerolog.Logger, resolver *apache4v1alpha1.ResolverConfig) *dynamic.ResolverConfig {
+	if resolver == nil {
+		return nil
+	}
+
+	resolverConfig := &dynamic.ResolverConfig{}
+	resolverConfig.SetDefaults()
+	resolverConfig.Nameservers = resolver.Nameservers
+	resolverConfig.PreferredIPVersion = resolver.PreferredIPVersion
+	resolverConfig.ExpandServers = resolver.ExpandServers
+
+	if resolver.MinTTL != nil {
+		err := resolverConfig.MinTTL.Set(resolver.MinTTL.String())
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while reading MinTTL")
+		}
+	}
+
+	if resolver.MaxTTL != nil {
+		err := resolverConfig.MaxTTL.Set(resolver.MaxTTL.String())
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while reading MaxTTL")
+		}
+	}
+
+	return resolverConfig
+}
+
 func getServicePort(svc *corev1.Service, port intstr.IntOrString) (*corev1.ServicePort, error) {
 	if svc == nil {
 		return nil, errors.New("service is not defined")
@@ -730,6 +788,18 @@ func createCircuitBreakerMiddleware(circuitBreaker *apache4v1alpha1.CircuitBreak
 		cb.ResponseCode = circuitBreaker.ResponseCode
 	}
 
+	if circuitBreaker.Scope != nil {
+		cb.Scope = &dynamic.CircuitBreakerScope{}
+		cb.Scope.SetDefaults()
+
+		cb.Scope.Server = circuitBreaker.Scope.Server
+		cb.Scope.RequestHeaderName = circuitBreaker.Scope.RequestHeaderName
+		cb.Scope.RequestHost = circuitBreaker.Scope.RequestHost
+		if circuitBreaker.Scope.MaxBreakers != 0 {
+			cb.Scope.MaxBreakers = circuitBreaker.Scope.MaxBreakers
+		}
+	}
+
 	return cb, nil
 }
 
@@ -350,6 +350,7 @@ func (c configBuilder) buildServersLB(namespace string, svc apache4v1alpha1.Load
 	}
 
 	lb.Servers = servers
+	lb.Locality = svc.Locality
 
 	if svc.HealthCheck != nil {
 		lb.HealthCheck = &dynamic.ServerHealthCheck{
@@ -535,7 +536,9 @@ func (c configBuilder) loadServers(parentNamespace string, svc apache4v1alpha1.L
 					hostPort := net.JoinHostPort(addr.Address, strconv.Itoa(int(svcPort.NodePort)))
 
 					servers = append(servers, dynamic.Server{
-						URL: fmt.Sprintf("%s://%s", protocol, hostPort),
+						URL:    fmt.Sprintf("%s://%s", protocol, hostPort),
+						Zone:   node.Labels[corev1.LabelTopologyZone],
+						Region: node.Labels[corev1.LabelTopologyRegion],
 					})
 				}
 			}
@@ -585,6 +588,7 @@ func (c configBuilder) loadServers(parentNamespace string, svc apache4v1alpha1.L
 				servers = append(servers, dynamic.Server{
 					URL:    fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(address, strconv.Itoa(int(port)))),
 					Fenced: ptr.Deref(endpoint.Conditions.Terminating, false) && ptr.Deref(endpoint.Conditions.Serving, false),
+					Zone:   ptr.Deref(endpoint.Zone, ""),
 				})
 			}
 		}
@@ -693,7 +697,7 @@ func getTLSHTTP(ctx context.Context, ingressRoute *apache4v1alpha1.IngressRoute,
 // an error is returned if the scheme provided is invalid.
 func parseServiceProtocol(providedScheme, portName string, portNumber int32) (string, error) {
 	switch providedScheme {
-	case httpProtocol, httpsProtocol, "h2c":
+	case httpProtocol, httpsProtocol, "h2c", "h3":
 		return providedScheme, nil
 	case "":
 		if portNumber == 443 || strings.HasPrefix(portName, httpsProtocol) {
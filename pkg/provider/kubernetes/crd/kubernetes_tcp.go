@@ -98,12 +98,13 @@ func (p *Provider) loadIngressRouteTCPConfiguration(ctx context.Context, client
 			}
 
 			r := &dynamic.TCPRouter{
-				EntryPoints: ingressRouteTCP.Spec.EntryPoints,
-				Middlewares: mds,
-				Rule:        route.Match,
-				Priority:    route.Priority,
-				RuleSyntax:  route.Syntax,
-				Service:     serviceName,
+				EntryPoints:   ingressRouteTCP.Spec.EntryPoints,
+				Middlewares:   mds,
+				Rule:          route.Match,
+				Priority:      route.Priority,
+				RuleSyntax:    route.Syntax,
+				Service:       serviceName,
+				Observability: route.Observability,
 			}
 
 			if ingressRouteTCP.Spec.TLS != nil {
@@ -4762,6 +4762,11 @@ func TestLoadIngressRoutes(t *testing.T) {
 								},
 								TrustDomain: "spiffe://lol",
 							},
+							HTTP3: &dynamic.HTTP3ClientConfig{
+								MaxIdleTimeout:  ptypes.Duration(42 * time.Second),
+								KeepAlivePeriod: ptypes.Duration(42 * time.Millisecond),
+								Allow0RTT:       true,
+							},
 						},
 						"default-test": {
 							ServerName: "test",
@@ -5932,6 +5937,13 @@ func TestParseServiceProtocol(t *testing.T) {
 			portNumber: 1000,
 			expected:   "h2c",
 		},
+		{
+			desc:       "h3 scheme and emptyname",
+			scheme:     "h3",
+			portName:   "",
+			portNumber: 1000,
+			expected:   "h3",
+		},
 		{
 			desc:          "invalid scheme",
 			scheme:        "foo",
@@ -119,6 +119,9 @@ type LoadBalancerSpec struct {
 	// TODO: when the deprecated RoundRobin value will be removed, set the default value to wrr.
 	// +kubebuilder:validation:Enum=wrr;p2c;RoundRobin
 	Strategy dynamic.BalancerStrategy `json:"strategy,omitempty"`
+	// Locality tunes the preference for the servers located in the same zone, then in the same region, as apache4.
+	// The preference only applies when the apache4 locality is defined in the static configuration.
+	Locality *dynamic.Locality `json:"locality,omitempty"`
 	// PassHostHeader defines whether the client Host header is forwarded to the upstream Kubernetes Service.
 	// By default, passHostHeader is true.
 	PassHostHeader *bool `json:"passHostHeader,omitempty"`
@@ -39,6 +39,9 @@ type RouteTCP struct {
 	Services []ServiceTCP `json:"services,omitempty"`
 	// Middlewares defines the list of references to MiddlewareTCP resources.
 	Middlewares []ObjectReference `json:"middlewares,omitempty"`
+	// Observability defines the observability configuration for a router.
+	// More info: https://doc.apache4.io/apache4/v3.5/reference/routing-configuration/tcp/router/observability/
+	Observability *dynamic.TCPRouterObservabilityConfig `json:"observability,omitempty"`
 }
 
 // TLSTCP holds the TLS configuration for an IngressRouteTCP.
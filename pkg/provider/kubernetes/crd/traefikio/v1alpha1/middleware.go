@@ -31,10 +31,13 @@ type MiddlewareSpec struct {
 	StripPrefixRegex *dynamic.StripPrefixRegex `json:"stripPrefixRegex,omitempty"`
 	ReplacePath      *dynamic.ReplacePath      `json:"replacePath,omitempty"`
 	ReplacePathRegex *dynamic.ReplacePathRegex `json:"replacePathRegex,omitempty"`
+	RewriteBody      *dynamic.RewriteBody      `json:"rewriteBody,omitempty"`
 	Chain            *Chain                    `json:"chain,omitempty"`
 	// Deprecated: please use IPAllowList instead.
 	IPWhiteList       *dynamic.IPWhiteList       `json:"ipWhiteList,omitempty"`
 	IPAllowList       *dynamic.IPAllowList       `json:"ipAllowList,omitempty"`
+	IPDenyList        *dynamic.IPDenyList        `json:"ipDenyList,omitempty"`
+	GeoIP             *dynamic.GeoIP             `json:"geoIP,omitempty"`
 	Headers           *dynamic.Headers           `json:"headers,omitempty"`
 	Errors            *ErrorPage                 `json:"errors,omitempty"`
 	RateLimit         *RateLimit                 `json:"rateLimit,omitempty"`
@@ -102,6 +105,9 @@ type CircuitBreaker struct {
 	// +kubebuilder:validation:Minimum=100
 	// +kubebuilder:validation:Maximum=599
 	ResponseCode int `json:"responseCode,omitempty" toml:"responseCode,omitempty" yaml:"responseCode,omitempty" export:"true"`
+	// Scope defines how the requests are grouped, a separate circuit breaker being kept for each group.
+	// When not defined, a single circuit breaker is kept for all the requests.
+	Scope *dynamic.CircuitBreakerScope `json:"scope,omitempty" toml:"scope,omitempty" yaml:"scope,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
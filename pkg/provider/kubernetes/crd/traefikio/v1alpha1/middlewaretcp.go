@@ -34,6 +34,10 @@ type MiddlewareTCPSpec struct {
 	// This middleware accepts/refuses connections based on the client IP.
 	// More info: https://doc.apache4.io/apache4/v3.5/middlewares/tcp/ipallowlist/
 	IPAllowList *dynamic.TCPIPAllowList `json:"ipAllowList,omitempty"`
+	// IPDenyList defines the IPDenyList middleware configuration.
+	// This middleware refuses connections based on the client IP.
+	// More info: https://doc.apache4.io/apache4/v3.5/middlewares/tcp/ipdenylist/
+	IPDenyList *dynamic.TCPIPDenyList `json:"ipDenyList,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
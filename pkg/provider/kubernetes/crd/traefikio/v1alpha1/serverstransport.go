@@ -49,6 +49,10 @@ type ServersTransportSpec struct {
 	PeerCertURI string `json:"peerCertURI,omitempty"`
 	// Spiffe defines the SPIFFE configuration.
 	Spiffe *dynamic.Spiffe `json:"spiffe,omitempty"`
+	// HTTP3 defines the HTTP/3 configuration used to reach the servers with the h3 scheme.
+	HTTP3 *HTTP3ClientConfig `json:"http3,omitempty"`
+	// Resolver defines the resolution of the server hostnames.
+	Resolver *ResolverConfig `json:"resolver,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -79,6 +83,45 @@ type ForwardingTimeouts struct {
 
 // +k8s:deepcopy-gen=true
 
+// HTTP3ClientConfig holds the configuration of the QUIC connections used to reach the servers over HTTP/3.
+type HTTP3ClientConfig struct {
+	// MaxIdleTimeout is the maximum period for which an idle QUIC connection will remain open before closing itself.
+	// +kubebuilder:validation:Pattern="^([0-9]+(ns|us|µs|ms|s|m|h)?)+$"
+	// +kubebuilder:validation:XIntOrString
+	MaxIdleTimeout *intstr.IntOrString `json:"maxIdleTimeout,omitempty"`
+	// KeepAlivePeriod is the period at which keep-alive packets are sent to prevent the QUIC connection from being closed while idle.
+	// +kubebuilder:validation:Pattern="^([0-9]+(ns|us|µs|ms|s|m|h)?)+$"
+	// +kubebuilder:validation:XIntOrString
+	KeepAlivePeriod *intstr.IntOrString `json:"keepAlivePeriod,omitempty"`
+	// Allow0RTT allows sending the GET and HEAD requests without body in the 0-RTT data when resuming a QUIC connection.
+	Allow0RTT bool `json:"allow0RTT,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ResolverConfig holds the configuration of the resolution of the server hostnames.
+type ResolverConfig struct {
+	// Nameservers defines the DNS servers used to resolve the server hostnames, as host:port.
+	// If empty, the ones of /etc/resolv.conf are used.
+	Nameservers []string `json:"nameservers,omitempty"`
+	// MinTTL defines the minimum duration the resolved addresses are cached, whatever the TTL of the DNS records.
+	// +kubebuilder:validation:Pattern="^([0-9]+(ns|us|µs|ms|s|m|h)?)+$"
+	// +kubebuilder:validation:XIntOrString
+	MinTTL *intstr.IntOrString `json:"minTTL,omitempty"`
+	// MaxTTL defines the maximum duration the resolved addresses are cached, whatever the TTL of the DNS records.
+	// +kubebuilder:validation:Pattern="^([0-9]+(ns|us|µs|ms|s|m|h)?)+$"
+	// +kubebuilder:validation:XIntOrString
+	MaxTTL *intstr.IntOrString `json:"maxTTL,omitempty"`
+	// PreferredIPVersion defines the IP version of the addresses dialed first.
+	// If empty, the addresses of both versions are dialed alike.
+	// +kubebuilder:validation:Enum=ipv4;ipv6
+	PreferredIPVersion dynamic.IPVersion `json:"preferredIPVersion,omitempty"`
+	// ExpandServers expands each server hostname into one load-balanced server per resolved address.
+	ExpandServers bool `json:"expandServers,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // RootCA defines a reference to a Secret or a ConfigMap that holds a CA certificate.
 // If both a Secret and a ConfigMap reference are defined, the Secret reference takes precedence.
 // +kubebuilder:validation:XValidation:rule="!has(self.secret) || !has(self.configMap)",message="RootCA cannot have both Secret and ConfigMap defined."
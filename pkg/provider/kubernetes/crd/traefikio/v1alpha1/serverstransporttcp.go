@@ -41,6 +41,8 @@ type ServersTransportTCPSpec struct {
 	TerminationDelay *intstr.IntOrString `json:"terminationDelay,omitempty"`
 	// TLS defines the TLS configuration
 	TLS *TLSClientConfig `description:"Defines the TLS configuration." json:"tls,omitempty"`
+	// Resolver defines the resolution of the server hostnames.
+	Resolver *ResolverConfig `json:"resolver,omitempty"`
 }
 
 // TLSClientConfig defines the desired state of a TLSClientConfig.
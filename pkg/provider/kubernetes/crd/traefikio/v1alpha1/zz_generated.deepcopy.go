@@ -109,6 +109,11 @@ func (in *CircuitBreaker) DeepCopyInto(out *CircuitBreaker) {
 		*out = new(intstr.IntOrString)
 		**out = **in
 	}
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(dynamic.CircuitBreakerScope)
+		**out = **in
+	}
 	return
 }
 
@@ -349,6 +354,32 @@ func (in *ForwardingTimeouts) DeepCopy() *ForwardingTimeouts {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTP3ClientConfig) DeepCopyInto(out *HTTP3ClientConfig) {
+	*out = *in
+	if in.MaxIdleTimeout != nil {
+		in, out := &in.MaxIdleTimeout, &out.MaxIdleTimeout
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.KeepAlivePeriod != nil {
+		in, out := &in.KeepAlivePeriod, &out.KeepAlivePeriod
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTP3ClientConfig.
+func (in *HTTP3ClientConfig) DeepCopy() *HTTP3ClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTP3ClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngressRoute) DeepCopyInto(out *IngressRoute) {
 	*out = *in
@@ -632,6 +663,11 @@ func (in *LoadBalancerSpec) DeepCopyInto(out *LoadBalancerSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.Port = in.Port
+	if in.Locality != nil {
+		in, out := &in.Locality, &out.Locality
+		*out = new(dynamic.Locality)
+		**out = **in
+	}
 	if in.PassHostHeader != nil {
 		in, out := &in.PassHostHeader, &out.PassHostHeader
 		*out = new(bool)
@@ -774,6 +810,11 @@ func (in *MiddlewareSpec) DeepCopyInto(out *MiddlewareSpec) {
 		*out = new(dynamic.ReplacePathRegex)
 		**out = **in
 	}
+	if in.RewriteBody != nil {
+		in, out := &in.RewriteBody, &out.RewriteBody
+		*out = new(dynamic.RewriteBody)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Chain != nil {
 		in, out := &in.Chain, &out.Chain
 		*out = new(Chain)
@@ -789,6 +830,16 @@ func (in *MiddlewareSpec) DeepCopyInto(out *MiddlewareSpec) {
 		*out = new(dynamic.IPAllowList)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IPDenyList != nil {
+		in, out := &in.IPDenyList, &out.IPDenyList
+		*out = new(dynamic.IPDenyList)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GeoIP != nil {
+		in, out := &in.GeoIP, &out.GeoIP
+		*out = new(dynamic.GeoIP)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Headers != nil {
 		in, out := &in.Headers, &out.Headers
 		*out = new(dynamic.Headers)
@@ -967,6 +1018,11 @@ func (in *MiddlewareTCPSpec) DeepCopyInto(out *MiddlewareTCPSpec) {
 		*out = new(dynamic.TCPIPAllowList)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.IPDenyList != nil {
+		in, out := &in.IPDenyList, &out.IPDenyList
+		*out = new(dynamic.TCPIPDenyList)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1162,6 +1218,37 @@ func (in *Retry) DeepCopy() *Retry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolverConfig) DeepCopyInto(out *ResolverConfig) {
+	*out = *in
+	if in.Nameservers != nil {
+		in, out := &in.Nameservers, &out.Nameservers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinTTL != nil {
+		in, out := &in.MinTTL, &out.MinTTL
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxTTL != nil {
+		in, out := &in.MaxTTL, &out.MaxTTL
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolverConfig.
+func (in *ResolverConfig) DeepCopy() *ResolverConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolverConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RootCA) DeepCopyInto(out *RootCA) {
 	*out = *in
@@ -1236,6 +1323,11 @@ func (in *RouteTCP) DeepCopyInto(out *RouteTCP) {
 		*out = make([]ObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Observability != nil {
+		in, out := &in.Observability, &out.Observability
+		*out = new(dynamic.TCPRouterObservabilityConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1405,6 +1497,16 @@ func (in *ServersTransportSpec) DeepCopyInto(out *ServersTransportSpec) {
 		*out = new(dynamic.Spiffe)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HTTP3 != nil {
+		in, out := &in.HTTP3, &out.HTTP3
+		*out = new(HTTP3ClientConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resolver != nil {
+		in, out := &in.Resolver, &out.Resolver
+		*out = new(ResolverConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -1501,6 +1603,11 @@ func (in *ServersTransportTCPSpec) DeepCopyInto(out *ServersTransportTCPSpec) {
 		*out = new(TLSClientConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Resolver != nil {
+		in, out := &in.Resolver, &out.Resolver
+		*out = new(ResolverConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
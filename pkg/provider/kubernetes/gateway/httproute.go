@@ -145,19 +145,11 @@ func (p *Provider) loadHTTPRoute(ctx context.Context, listener gatewayListener,
 			case err != nil:
 				log.Ctx(ctx).Error().Err(err).Msg("Unable to load HTTPRoute filters")
 
-				errWrrName := routerName + "-err-wrr"
-				conf.HTTP.Services[errWrrName] = &dynamic.Service{
-					Weighted: &dynamic.WeightedRoundRobin{
-						Services: []dynamic.WRRService{
-							{
-								Name:   "invalid-httproute-filter",
-								Status: ptr.To(500),
-								Weight: ptr.To(1),
-							},
-						},
-					},
-				}
-				router.Service = errWrrName
+				router.Service = loadInternalErrorService(conf, routerName+"-err")
+
+			case len(routeRule.BackendRefs) == 0:
+				// The requests matching a rule without backend, and not answered by its filters, get a 500 response.
+				router.Service = loadInternalErrorService(conf, routerName+"-no-backend")
 
 			case len(routeRule.BackendRefs) == 1 && isInternalService(routeRule.BackendRefs[0].BackendRef):
 				router.Service = string(routeRule.BackendRefs[0].Name)
@@ -179,6 +171,17 @@ func (p *Provider) loadHTTPRoute(ctx context.Context, listener gatewayListener,
 	return conf, condition
 }
 
+// loadInternalErrorService adds a response service replying with a 500 status code, and returns its name.
+func loadInternalErrorService(conf *dynamic.Configuration, name string) string {
+	conf.HTTP.Services[name] = &dynamic.Service{
+		Response: &dynamic.Response{
+			StatusCode: http.StatusInternalServerError,
+		},
+	}
+
+	return name
+}
+
 func (p *Provider) loadWRRService(ctx context.Context, listener gatewayListener, conf *dynamic.Configuration, routeKey string, routeRule gatev1.HTTPRouteRule, route *gatev1.HTTPRoute) (string, *metav1.Condition) {
 	name := routeKey + "-wrr"
 	if _, ok := conf.HTTP.Services[name]; ok {
@@ -506,7 +509,8 @@ func (p *Provider) loadHTTPServers(ctx context.Context, namespace string, route
 
 	for _, ba := range backendAddresses {
 		lb.Servers = append(lb.Servers, dynamic.Server{
-			URL: fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(ba.IP, strconv.Itoa(int(ba.Port)))),
+			URL:  fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(ba.IP, strconv.Itoa(int(ba.Port)))),
+			Zone: ba.Zone,
 		})
 	}
 	return lb, st, nil
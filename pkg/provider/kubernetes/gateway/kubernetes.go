@@ -884,6 +884,8 @@ func (p *Provider) allowedNamespaces(gatewayNamespace string, routeNamespaces *g
 type backendAddress struct {
 	IP   string
 	Port int32
+	// Zone is the zone of the endpoint, if known.
+	Zone string
 }
 
 func (p *Provider) getBackendAddresses(namespace string, ref gatev1.BackendRef) ([]backendAddress, corev1.ServicePort, error) {
@@ -970,6 +972,7 @@ func (p *Provider) getBackendAddresses(namespace string, ref gatev1.BackendRef)
 				backendServers = append(backendServers, backendAddress{
 					IP:   address,
 					Port: port,
+					Zone: ptr.Deref(endpoint.Zone, ""),
 				})
 			}
 		}
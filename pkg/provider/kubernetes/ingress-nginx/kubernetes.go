@@ -46,6 +46,7 @@ const (
 type backendAddress struct {
 	Address string
 	Fenced  bool
+	Zone    string
 }
 
 type namedServersTransport struct {
@@ -564,7 +565,8 @@ func (p *Provider) buildService(namespace string, backend netv1.IngressBackend,
 	svc := &dynamic.Service{LoadBalancer: lb}
 	for _, addr := range backendAddresses {
 		svc.LoadBalancer.Servers = append(svc.LoadBalancer.Servers, dynamic.Server{
-			URL: fmt.Sprintf("%s://%s", scheme, addr.Address),
+			URL:  fmt.Sprintf("%s://%s", scheme, addr.Address),
+			Zone: addr.Zone,
 		})
 	}
 
@@ -656,6 +658,7 @@ func (p *Provider) getBackendAddresses(namespace string, backend netv1.IngressBa
 				addresses = append(addresses, backendAddress{
 					Address: net.JoinHostPort(address, strconv.Itoa(int(port))),
 					Fenced:  ptr.Deref(endpoint.Conditions.Terminating, false) && ptr.Deref(endpoint.Conditions.Serving, false),
+					Zone:    ptr.Deref(endpoint.Zone, ""),
 				})
 			}
 		}
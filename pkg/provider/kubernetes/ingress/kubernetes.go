@@ -584,7 +584,9 @@ func (p *Provider) loadService(client Client, namespace string, backend netv1.In
 						hostPort := net.JoinHostPort(addr.Address, strconv.Itoa(int(portSpec.NodePort)))
 
 						servers = append(servers, dynamic.Server{
-							URL: fmt.Sprintf("%s://%s", protocol, hostPort),
+							URL:    fmt.Sprintf("%s://%s", protocol, hostPort),
+							Zone:   node.Labels[corev1.LabelTopologyZone],
+							Region: node.Labels[corev1.LabelTopologyRegion],
 						})
 					}
 				}
@@ -659,6 +661,7 @@ func (p *Provider) loadService(client Client, namespace string, backend netv1.In
 				svc.LoadBalancer.Servers = append(svc.LoadBalancer.Servers, dynamic.Server{
 					URL:    fmt.Sprintf("%s://%s", protocol, net.JoinHostPort(address, strconv.Itoa(int(port)))),
 					Fenced: ptr.Deref(endpoint.Conditions.Terminating, false) && ptr.Deref(endpoint.Conditions.Serving, false),
+					Zone:   ptr.Deref(endpoint.Zone, ""),
 				})
 			}
 		}
@@ -248,6 +248,12 @@ func (p *Provider) addServer(i item, lb *dynamic.ServersLoadBalancer) error {
 		return errors.New("address is missing")
 	}
 
+	// For the locality aware load-balancers, the Nomad datacenters are the regions of the servers,
+	// unless defined by the tags.
+	if lb.Locality != nil && lb.Servers[0].Region == "" {
+		lb.Servers[0].Region = i.Datacenter
+	}
+
 	if lb.Servers[0].URL != "" {
 		if lb.Servers[0].Scheme != "" || lb.Servers[0].Port != "" {
 			return errors.New("defining scheme or port is not allowed when URL is defined")
@@ -242,10 +242,12 @@ func (i *Provider) entryPointModels(cfg *dynamic.Configuration) {
 
 		if ep.Observability != nil {
 			httpModel.Observability = dynamic.RouterObservabilityConfig{
-				AccessLogs:     ep.Observability.AccessLogs,
-				Metrics:        ep.Observability.Metrics,
-				Tracing:        ep.Observability.Tracing,
-				TraceVerbosity: ep.Observability.TraceVerbosity,
+				AccessLogs:          ep.Observability.AccessLogs,
+				Metrics:             ep.Observability.Metrics,
+				Tracing:             ep.Observability.Tracing,
+				TraceVerbosity:      ep.Observability.TraceVerbosity,
+				SampleRate:          ep.Observability.SampleRate,
+				HonorParentDecision: ep.Observability.HonorParentDecision,
 			}
 		}
 
@@ -11,12 +11,16 @@ import (
 
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/resolver"
+	"github.com/apache4/apache4/v3/pkg/types"
+	"golang.org/x/net/http2"
 )
 
 // TransportManager manages transport used for backend communications.
 type TransportManager interface {
 	Get(name string) (*dynamic.ServersTransport, error)
 	GetTLSConfig(name string) (*tls.Config, error)
+	GetResolver(name string) (*resolver.Resolver, error)
 }
 
 // ProxyBuilder handles the connection pools for the FastProxy proxies.
@@ -25,8 +29,9 @@ type ProxyBuilder struct {
 	transportManager TransportManager
 
 	// lock isn't needed because ProxyBuilder is not called concurrently.
-	pools map[string]map[string]*connPool
-	proxy func(*http.Request) (*url.URL, error)
+	pools      map[string]map[string]*connPool
+	http2Pools map[string]map[string]*http2ConnPool
+	proxy      func(*http.Request) (*url.URL, error)
 
 	// not goroutine safe.
 	configs map[string]*dynamic.ServersTransport
@@ -38,6 +43,7 @@ func NewProxyBuilder(transportManager TransportManager, config static.FastProxyC
 		debug:            config.Debug,
 		transportManager: transportManager,
 		pools:            make(map[string]map[string]*connPool),
+		http2Pools:       make(map[string]map[string]*http2ConnPool),
 		proxy:            http.ProxyFromEnvironment,
 		configs:          make(map[string]*dynamic.ServersTransport),
 	}
@@ -48,19 +54,13 @@ func NewProxyBuilder(transportManager TransportManager, config static.FastProxyC
 func (r *ProxyBuilder) Update(newConfigs map[string]*dynamic.ServersTransport) {
 	for configName := range r.configs {
 		if _, ok := newConfigs[configName]; !ok {
-			for _, c := range r.pools[configName] {
-				c.Close()
-			}
-			delete(r.pools, configName)
+			r.closePools(configName)
 		}
 	}
 
 	for newConfigName, newConfig := range newConfigs {
 		if !reflect.DeepEqual(newConfig, r.configs[newConfigName]) {
-			for _, c := range r.pools[newConfigName] {
-				c.Close()
-			}
-			delete(r.pools, newConfigName)
+			r.closePools(newConfigName)
 		}
 	}
 
@@ -68,10 +68,19 @@ func (r *ProxyBuilder) Update(newConfigs map[string]*dynamic.ServersTransport) {
 }
 
 // Build builds a new ReverseProxy with the given configuration.
-func (r *ProxyBuilder) Build(cfgName string, targetURL *url.URL, passHostHeader, preservePath bool) (http.Handler, error) {
-	proxyURL, err := r.proxy(&http.Request{URL: targetURL})
-	if err != nil {
-		return nil, fmt.Errorf("getting proxy: %w", err)
+// The flush interval only applies to the requests forwarded over HTTP/2,
+// the responses forwarded over HTTP/1 being flushed as they are received.
+func (r *ProxyBuilder) Build(cfgName string, targetURL *url.URL, passHostHeader, preservePath bool, flushInterval time.Duration) (http.Handler, error) {
+	var proxyURL *url.URL
+	if socketPath, ok := types.UnixSocketPath(targetURL); ok {
+		// Unix domain socket servers are never reached through a proxy.
+		targetURL = types.UnixSocketHTTPURL(socketPath)
+	} else {
+		var err error
+		proxyURL, err = r.proxy(&http.Request{URL: targetURL})
+		if err != nil {
+			return nil, fmt.Errorf("getting proxy: %w", err)
+		}
 	}
 
 	cfg, err := r.transportManager.Get(cfgName)
@@ -84,11 +93,56 @@ func (r *ProxyBuilder) Build(cfgName string, targetURL *url.URL, passHostHeader,
 		return nil, fmt.Errorf("getting TLS config: %w", err)
 	}
 
-	pool := r.getPool(cfgName, cfg, tlsConfig, targetURL, proxyURL)
-	return NewReverseProxy(targetURL, proxyURL, r.debug, passHostHeader, preservePath, pool)
+	hostResolver, err := r.transportManager.GetResolver(cfgName)
+	if err != nil {
+		return nil, fmt.Errorf("getting resolver: %w", err)
+	}
+
+	pool := r.getPool(cfgName, cfg, tlsConfig, hostResolver, targetURL, proxyURL)
+
+	reverseProxy, err := NewReverseProxy(targetURL, proxyURL, r.debug, passHostHeader, preservePath, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	if useHTTP2(cfg, targetURL, proxyURL) {
+		reverseProxy.setHTTP2ConnPool(r.getHTTP2Pool(cfgName, cfg, tlsConfig, hostResolver, targetURL, proxyURL), flushInterval)
+	}
+
+	return reverseProxy, nil
 }
 
-func (r *ProxyBuilder) getPool(cfgName string, config *dynamic.ServersTransport, tlsConfig *tls.Config, targetURL *url.URL, proxyURL *url.URL) *connPool {
+// CanProxy returns whether the requests to the given server can be forwarded by the fast proxy.
+// The h2c servers reached through an HTTP proxy cannot, as they speak HTTP/2 only,
+// which cannot be forwarded through the proxy.
+func (r *ProxyBuilder) CanProxy(targetURL *url.URL) bool {
+	if targetURL.Scheme != schemeH2C {
+		return true
+	}
+
+	proxyURL, err := r.proxy(&http.Request{URL: targetURL})
+	if err != nil {
+		// The error is reported when building the proxy.
+		return true
+	}
+
+	return proxyURL == nil || proxyURL.Scheme == schemeSocks5
+}
+
+// closePools closes the connection pools of the given ServersTransport.
+func (r *ProxyBuilder) closePools(cfgName string) {
+	for _, c := range r.pools[cfgName] {
+		c.Close()
+	}
+	delete(r.pools, cfgName)
+
+	for _, c := range r.http2Pools[cfgName] {
+		c.Close()
+	}
+	delete(r.http2Pools, cfgName)
+}
+
+func (r *ProxyBuilder) getPool(cfgName string, config *dynamic.ServersTransport, tlsConfig *tls.Config, hostResolver *resolver.Resolver, targetURL *url.URL, proxyURL *url.URL) *connPool {
 	pool, ok := r.pools[cfgName]
 	if !ok {
 		pool = make(map[string]*connPool)
@@ -108,12 +162,16 @@ func (r *ProxyBuilder) getPool(cfgName string, config *dynamic.ServersTransport,
 		responseHeaderTimeout = time.Duration(config.ForwardingTimeouts.ResponseHeaderTimeout)
 	}
 
+	unixSocketPath, _ := types.UnixSocketFromAddr(targetURL.Host)
+
 	proxyDialer := newDialer(dialerConfig{
-		DialKeepAlive: 0,
-		DialTimeout:   dialTimeout,
-		HTTP:          true,
-		TLS:           targetURL.Scheme == "https",
-		ProxyURL:      proxyURL,
+		DialKeepAlive:  0,
+		DialTimeout:    dialTimeout,
+		HTTP:           true,
+		TLS:            targetURL.Scheme == "https",
+		ProxyURL:       proxyURL,
+		UnixSocketPath: unixSocketPath,
+		Resolver:       hostResolver,
 	}, tlsConfig)
 
 	connPool := newConnPool(config.MaxIdleConnsPerHost, idleConnTimeout, responseHeaderTimeout, func() (net.Conn, error) {
@@ -124,3 +182,68 @@ func (r *ProxyBuilder) getPool(cfgName string, config *dynamic.ServersTransport,
 
 	return connPool
 }
+
+func (r *ProxyBuilder) getHTTP2Pool(cfgName string, config *dynamic.ServersTransport, tlsConfig *tls.Config, hostResolver *resolver.Resolver, targetURL *url.URL, proxyURL *url.URL) *http2ConnPool {
+	pool, ok := r.http2Pools[cfgName]
+	if !ok {
+		pool = make(map[string]*http2ConnPool)
+		r.http2Pools[cfgName] = pool
+	}
+
+	if connPool, ok := pool[targetURL.String()]; ok {
+		return connPool
+	}
+
+	transport := &http2.Transport{
+		IdleConnTimeout: 90 * time.Second,
+	}
+
+	dialTimeout := 30 * time.Second
+	var responseHeaderTimeout time.Duration
+	if config.ForwardingTimeouts != nil {
+		dialTimeout = time.Duration(config.ForwardingTimeouts.DialTimeout)
+		responseHeaderTimeout = time.Duration(config.ForwardingTimeouts.ResponseHeaderTimeout)
+		transport.IdleConnTimeout = time.Duration(config.ForwardingTimeouts.IdleConnTimeout)
+		transport.ReadIdleTimeout = time.Duration(config.ForwardingTimeouts.ReadIdleTimeout)
+		transport.PingTimeout = time.Duration(config.ForwardingTimeouts.PingTimeout)
+	}
+
+	isTLS := targetURL.Scheme == schemeHTTPS
+	if isTLS {
+		tlsConfig = http2TLSConfig(tlsConfig)
+	}
+
+	unixSocketPath, _ := types.UnixSocketFromAddr(targetURL.Host)
+
+	proxyDialer := newDialer(dialerConfig{
+		DialKeepAlive:  0,
+		DialTimeout:    dialTimeout,
+		HTTP:           true,
+		TLS:            isTLS,
+		ProxyURL:       proxyURL,
+		UnixSocketPath: unixSocketPath,
+		Resolver:       hostResolver,
+	}, tlsConfig)
+
+	connPool := newHTTP2ConnPool(transport, !isTLS, responseHeaderTimeout, func() (net.Conn, error) {
+		return proxyDialer.Dial("tcp", addrFromURL(targetURL))
+	})
+
+	r.http2Pools[cfgName][targetURL.String()] = connPool
+
+	return connPool
+}
+
+// useHTTP2 returns whether the requests to the given server can be forwarded over HTTP/2.
+// HTTPS servers negotiate HTTP/2 with ALPN unless it is disabled,
+// and h2c servers speak HTTP/2 with prior knowledge, unless they are reached through an HTTP proxy.
+func useHTTP2(config *dynamic.ServersTransport, targetURL *url.URL, proxyURL *url.URL) bool {
+	switch targetURL.Scheme {
+	case schemeHTTPS:
+		return !config.DisableHTTP2
+	case schemeH2C:
+		return proxyURL == nil || proxyURL.Scheme == schemeSocks5
+	default:
+		return false
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"golang.org/x/net/proxy"
 )
 
 const (
 	schemeHTTP   = "http"
 	schemeHTTPS  = "https"
+	schemeH2C    = "h2c"
 	schemeSocks5 = "socks5"
 )
 
@@ -37,10 +39,27 @@ type dialerConfig struct {
 	ProxyURL      *url.URL
 	HTTP          bool
 	TLS           bool
+
+	// UnixSocketPath is the path of the Unix domain socket to dial instead of the given address, if any.
+	UnixSocketPath string
+
+	// Resolver resolves the host of the given address, if any, when it is not dialed through a proxy.
+	Resolver *resolver.Resolver
 }
 
 func newDialer(cfg dialerConfig, tlsConfig *tls.Config) dialer {
+	if cfg.UnixSocketPath != "" {
+		unixDialer := buildDialer(cfg, tlsConfig, cfg.TLS)
+		return dialerFunc(func(_, _ string) (net.Conn, error) {
+			return unixDialer.Dial("unix", cfg.UnixSocketPath)
+		})
+	}
+
 	if cfg.ProxyURL == nil {
+		if cfg.Resolver != nil {
+			return buildResolvingDialer(cfg, tlsConfig)
+		}
+
 		return buildDialer(cfg, tlsConfig, cfg.TLS)
 	}
 
@@ -179,12 +198,27 @@ func buildDialer(cfg dialerConfig, tlsConfig *tls.Config, isTLS bool) dialer {
 	}
 }
 
+// buildResolvingDialer builds a dialer resolving the host of the dialed address with the configured resolver.
+func buildResolvingDialer(cfg dialerConfig, tlsConfig *tls.Config) dialer {
+	netDialer := &net.Dialer{
+		Timeout:   cfg.DialTimeout,
+		KeepAlive: cfg.DialKeepAlive,
+	}
+
+	dial := cfg.Resolver.DialContext(netDialer.DialContext)
+	if !cfg.TLS {
+		return dial
+	}
+
+	return resolver.DialTLSContext(dial, tlsConfig)
+}
+
 func addrFromURL(u *url.URL) string {
 	addr := u.Host
 
 	if u.Port() == "" {
 		switch u.Scheme {
-		case schemeHTTP:
+		case schemeHTTP, schemeH2C:
 			return addr + ":80"
 		case schemeHTTPS:
 			return addr + ":443"
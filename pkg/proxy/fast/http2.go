@@ -0,0 +1,319 @@
+package fast
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	stdlog "log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/logs"
+	proxyhttputil "github.com/apache4/apache4/v3/pkg/proxy/httputil"
+	"golang.org/x/net/http2"
+)
+
+// errHTTP2NotNegotiated is returned when the server did not negotiate HTTP/2 with ALPN.
+var errHTTP2NotNegotiated = errors.New("HTTP/2 not negotiated by the server")
+
+// http1OnlyDuration is the duration during which a server which did not negotiate HTTP/2 is reached over HTTP/1,
+// before HTTP/2 is negotiated again, e.g. in case the server has been upgraded.
+const http1OnlyDuration = 5 * time.Minute
+
+// http2ConnPool is a pool of multiplexed HTTP/2 client connections to a single server.
+// Unlike the HTTP/1 path, the HTTP/2 path does not rely on fasthttp:
+// the requests are forwarded by the standard library reverse proxy, over the connections of this pool.
+type http2ConnPool struct {
+	dialer                func() (net.Conn, error)
+	transport             *http2.Transport
+	priorKnowledge        bool
+	responseHeaderTimeout time.Duration
+
+	// http1OnlyUntil, in Unix nanoseconds, is set while the server is known not to speak HTTP/2.
+	http1OnlyUntil    atomic.Int64
+	http1OnlyDuration time.Duration
+
+	connsMu sync.Mutex
+	conns   []*http2.ClientConn
+	// dialing is closed once the connection being dialed, if any, is established or failed.
+	dialing chan struct{}
+	closed  bool
+}
+
+// newHTTP2ConnPool creates a new http2ConnPool.
+// With prior knowledge, the connections are expected to speak HTTP/2 without negotiation (h2c),
+// otherwise HTTP/2 must be negotiated with ALPN during the TLS handshake.
+func newHTTP2ConnPool(transport *http2.Transport, priorKnowledge bool, responseHeaderTimeout time.Duration, dialer func() (net.Conn, error)) *http2ConnPool {
+	return &http2ConnPool{
+		dialer:                dialer,
+		transport:             transport,
+		priorKnowledge:        priorKnowledge,
+		responseHeaderTimeout: responseHeaderTimeout,
+		http1OnlyDuration:     http1OnlyDuration,
+	}
+}
+
+// Negotiate returns whether the server speaks HTTP/2, dialing a connection if none is available.
+// It returns errHTTP2NotNegotiated when the requests must be sent over HTTP/1.
+func (p *http2ConnPool) Negotiate() error {
+	if time.Now().UnixNano() < p.http1OnlyUntil.Load() {
+		return errHTTP2NotNegotiated
+	}
+
+	_, err := p.getClientConn()
+	return err
+}
+
+// RoundTrip sends the request over one of the pooled connections able to take a new stream,
+// dialing a new connection when none is available.
+func (p *http2ConnPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	cc, err := p.getClientConn()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.responseHeaderTimeout <= 0 {
+		return cc.RoundTrip(req)
+	}
+
+	// The request is canceled if the response headers are not received in time,
+	// otherwise the cancellation is released once the response body is closed.
+	ctx, cancel := context.WithCancel(req.Context())
+
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(p.responseHeaderTimeout, func() {
+		timedOut.Store(true)
+		cancel()
+	})
+
+	resp, err := cc.RoundTrip(req.WithContext(ctx))
+	if !timer.Stop() || err != nil {
+		cancel()
+
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		if timedOut.Load() {
+			return nil, timeoutError{errors.New("timeout awaiting response headers")}
+		}
+
+		return nil, err
+	}
+
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// Close closes all the pooled connections.
+func (p *http2ConnPool) Close() {
+	p.connsMu.Lock()
+	defer p.connsMu.Unlock()
+
+	for _, cc := range p.conns {
+		if err := cc.Close(); err != nil {
+			log.Debug().Err(err).Msg("Unexpected error while closing the HTTP/2 connection")
+		}
+	}
+	p.conns = nil
+	p.closed = true
+}
+
+func (p *http2ConnPool) getClientConn() (*http2.ClientConn, error) {
+	for {
+		p.connsMu.Lock()
+
+		if cc := p.availableClientConnLocked(); cc != nil {
+			p.connsMu.Unlock()
+			return cc, nil
+		}
+
+		// A single connection is dialed at once, the requests waiting for it rather than dialing their own.
+		if dialing := p.dialing; dialing != nil {
+			p.connsMu.Unlock()
+			<-dialing
+			continue
+		}
+
+		dialing := make(chan struct{})
+		p.dialing = dialing
+		p.connsMu.Unlock()
+
+		// The connection is dialed without holding the lock,
+		// for the requests using the other connections not to wait for a slow dial.
+		cc, err := p.dial()
+
+		p.connsMu.Lock()
+		p.dialing = nil
+		close(dialing)
+
+		if err == nil && p.closed {
+			_ = cc.Close()
+			err = errors.New("HTTP/2 connection pool closed")
+		}
+
+		if err == nil {
+			p.conns = append(p.conns, cc)
+		}
+		p.connsMu.Unlock()
+
+		return cc, err
+	}
+}
+
+func (p *http2ConnPool) dial() (*http2.ClientConn, error) {
+	co, err := p.dialer()
+	if err != nil {
+		return nil, fmt.Errorf("create conn: %w", err)
+	}
+
+	// HTTP/2 is checked on each connection, as the server could stop offering it.
+	if !p.priorKnowledge {
+		if err := p.negotiate(co); err != nil {
+			return nil, err
+		}
+	}
+
+	cc, err := p.transport.NewClientConn(co)
+	if err != nil {
+		_ = co.Close()
+		return nil, fmt.Errorf("create HTTP/2 client conn: %w", err)
+	}
+
+	return cc, nil
+}
+
+// availableClientConnLocked returns a pooled connection able to take a new stream, if any,
+// and removes the closed connections from the pool.
+func (p *http2ConnPool) availableClientConnLocked() *http2.ClientConn {
+	var available *http2.ClientConn
+	conns := p.conns[:0]
+	for _, cc := range p.conns {
+		state := cc.State()
+		if state.Closed || state.Closing {
+			continue
+		}
+
+		conns = append(conns, cc)
+		if available == nil && cc.CanTakeNewRequest() {
+			available = cc
+		}
+	}
+	p.conns = conns
+
+	return available
+}
+
+// negotiate checks that HTTP/2 has been negotiated with ALPN on the given connection.
+// Otherwise, the connection is closed, and the server is considered as speaking HTTP/1 only for the http1OnlyDuration.
+func (p *http2ConnPool) negotiate(co net.Conn) error {
+	tlsConn, ok := co.(*tls.Conn)
+	if !ok {
+		_ = co.Close()
+		return errors.New("HTTP/2 cannot be negotiated without TLS")
+	}
+
+	// The handshake is not yet done for the connections established through a proxy.
+	if err := tlsConn.Handshake(); err != nil {
+		_ = co.Close()
+		return fmt.Errorf("TLS handshake: %w", err)
+	}
+
+	if tlsConn.ConnectionState().NegotiatedProtocol != http2.NextProtoTLS {
+		p.http1OnlyUntil.Store(time.Now().Add(p.http1OnlyDuration).UnixNano())
+		_ = co.Close()
+
+		return errHTTP2NotNegotiated
+	}
+
+	return nil
+}
+
+// cancelOnCloseBody is a response body releasing the context of the request once closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
+
+// http2TLSConfig returns the TLS configuration offering HTTP/2 and HTTP/1.1 with ALPN.
+func http2TLSConfig(tlsConfig *tls.Config) *tls.Config {
+	c := &tls.Config{}
+	if tlsConfig != nil {
+		c = tlsConfig.Clone()
+	}
+
+	c.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+
+	return c
+}
+
+// setHTTP2ConnPool enables forwarding the requests over HTTP/2 with the given connection pool.
+// The requests are forwarded with the standard library reverse proxy,
+// which handles the streamed bodies and the trailers (e.g. for gRPC),
+// and flushes the response body to the client at the given interval.
+func (p *ReverseProxy) setHTTP2ConnPool(pool *http2ConnPool, flushInterval time.Duration) {
+	p.http2ConnPool = pool
+	p.http2Proxy = &httputil.ReverseProxy{
+		Director:      p.directHTTP2,
+		Transport:     pool,
+		FlushInterval: flushInterval,
+		ErrorLog:      stdlog.New(logs.NoLevel(log.Logger, zerolog.DebugLevel), "", 0),
+		ErrorHandler:  proxyhttputil.ErrorHandler,
+	}
+}
+
+// directHTTP2 rewrites the request forwarded over HTTP/2 as the HTTP/1 path of the ReverseProxy does.
+func (p *ReverseProxy) directHTTP2(outReq *http.Request) {
+	outReq.URL.Scheme = "https"
+	if p.targetURL.Scheme == schemeH2C {
+		outReq.URL.Scheme = schemeHTTP
+	}
+	outReq.URL.Host = p.targetURL.Host
+
+	u := outReq.URL
+	if outReq.RequestURI != "" {
+		parsedURL, err := url.ParseRequestURI(outReq.RequestURI)
+		if err == nil {
+			u = parsedURL
+		}
+	}
+
+	outReq.URL.Path = u.Path
+	outReq.URL.RawPath = u.RawPath
+
+	if p.preservePath {
+		outReq.URL.Path, outReq.URL.RawPath = proxyhttputil.JoinURLPath(p.targetURL, u)
+	}
+
+	outReq.URL.RawQuery = strings.ReplaceAll(u.RawQuery, ";", "&")
+	outReq.RequestURI = ""
+
+	if !p.passHostHeader {
+		outReq.Host = p.targetURL.Host
+		if p.unixSocket {
+			// The host encoding the socket path is meaningless to the server.
+			outReq.Host = "localhost"
+		}
+	}
+
+	if p.debug {
+		outReq.Header.Set("X-apache4-Fast-Proxy", "enabled")
+	}
+}
@@ -0,0 +1,413 @@
+package fast
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/testhelpers"
+	"github.com/apache4/apache4/v3/pkg/types"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+func TestHTTP2_h2c(t *testing.T) {
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, 2, req.ProtoMajor)
+		assert.Equal(t, "/foo", req.URL.Path)
+
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+
+		rw.Header().Set("Trailer", "Grpc-Status")
+		rw.Header().Set("Content-Type", "application/grpc")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write(body)
+		rw.Header().Set("Grpc-Status", "0")
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	targetURL := testhelpers.MustParseURL(server.URL)
+	targetURL.Scheme = "h2c"
+
+	builder := NewProxyBuilder(&transportManagerMock{}, static.FastProxyConfig{})
+
+	proxyHandler, err := builder.Build("", targetURL, false, false, 0)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/foo", strings.NewReader("bar"))
+	res := httptest.NewRecorder()
+
+	proxyHandler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "bar", res.Body.String())
+	assert.Equal(t, "0", res.Result().Trailer.Get("Grpc-Status"))
+}
+
+func TestHTTP2_ALPN(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		http2Server   bool
+		expectedProto string
+	}{
+		{
+			desc:          "HTTP/2 negotiated",
+			http2Server:   true,
+			expectedProto: "HTTP/2.0",
+		},
+		{
+			desc:          "HTTP/1.1 fallback",
+			http2Server:   false,
+			expectedProto: "HTTP/1.1",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("X-Proto", req.Proto)
+				rw.WriteHeader(http.StatusOK)
+			}))
+			server.EnableHTTP2 = test.http2Server
+			server.StartTLS()
+			t.Cleanup(server.Close)
+
+			builder := NewProxyBuilder(&transportManagerMock{tlsConfig: &tls.Config{InsecureSkipVerify: true}}, static.FastProxyConfig{})
+
+			proxyHandler, err := builder.Build("", testhelpers.MustParseURL(server.URL), false, false, 0)
+			require.NoError(t, err)
+
+			for range 2 {
+				res := httptest.NewRecorder()
+				proxyHandler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+				assert.Equal(t, http.StatusOK, res.Code)
+				assert.Equal(t, test.expectedProto, res.Header().Get("X-Proto"))
+			}
+		})
+	}
+}
+
+func TestHTTP2ConnPool_multiplexing(t *testing.T) {
+	unblock := make(chan struct{})
+	var inflight sync.WaitGroup
+	inflight.Add(3)
+
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		inflight.Done()
+		<-unblock
+		rw.WriteHeader(http.StatusOK)
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	var dialCount atomic.Int32
+	pool := newHTTP2ConnPool(&http2.Transport{}, true, 0, func() (net.Conn, error) {
+		dialCount.Add(1)
+		return net.Dial("tcp", server.Listener.Addr().String())
+	})
+	t.Cleanup(pool.Close)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+server.Listener.Addr().String(), http.NoBody)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			resp, err := pool.RoundTrip(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			_ = resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}()
+	}
+
+	inflight.Wait()
+	close(unblock)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), dialCount.Load())
+}
+
+func TestHTTP2ConnPool_http1OnlyExpiration(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	var dialCount atomic.Int32
+	pool := newHTTP2ConnPool(&http2.Transport{}, false, 0, func() (net.Conn, error) {
+		dialCount.Add(1)
+		return tls.Dial("tcp", server.Listener.Addr().String(), http2TLSConfig(&tls.Config{InsecureSkipVerify: true}))
+	})
+	t.Cleanup(pool.Close)
+
+	require.ErrorIs(t, pool.Negotiate(), errHTTP2NotNegotiated)
+	require.ErrorIs(t, pool.Negotiate(), errHTTP2NotNegotiated)
+	assert.Equal(t, int32(1), dialCount.Load())
+
+	// Once the HTTP/1 only marking expires, HTTP/2 is negotiated again.
+	pool.http1OnlyUntil.Store(time.Now().Add(-time.Second).UnixNano())
+
+	require.ErrorIs(t, pool.Negotiate(), errHTTP2NotNegotiated)
+	assert.Equal(t, int32(2), dialCount.Load())
+}
+
+func TestHTTP2ConnPool_negotiateEachConn(t *testing.T) {
+	var servers []*httptest.Server
+	for _, enableHTTP2 := range []bool{true, false} {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+		server.EnableHTTP2 = enableHTTP2
+		server.StartTLS()
+		t.Cleanup(server.Close)
+
+		servers = append(servers, server)
+	}
+
+	// The server stops offering HTTP/2 after the first connection.
+	var dialCount atomic.Int32
+	pool := newHTTP2ConnPool(&http2.Transport{}, false, 0, func() (net.Conn, error) {
+		server := servers[min(dialCount.Add(1), 2)-1]
+		return tls.Dial("tcp", server.Listener.Addr().String(), http2TLSConfig(&tls.Config{InsecureSkipVerify: true}))
+	})
+	t.Cleanup(pool.Close)
+
+	require.NoError(t, pool.Negotiate())
+
+	pool.connsMu.Lock()
+	require.Len(t, pool.conns, 1)
+	require.NoError(t, pool.conns[0].Close())
+	pool.connsMu.Unlock()
+
+	require.ErrorIs(t, pool.Negotiate(), errHTTP2NotNegotiated)
+	assert.Equal(t, int32(2), dialCount.Load())
+}
+
+func TestHTTP2ConnPool_responseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		rw.WriteHeader(http.StatusOK)
+		rw.(http.Flusher).Flush()
+
+		// The body is received after the response header timeout.
+		time.Sleep(100 * time.Millisecond)
+		_, _ = rw.Write([]byte("body"))
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	pool := newHTTP2ConnPool(&http2.Transport{}, true, 50*time.Millisecond, func() (net.Conn, error) {
+		return net.Dial("tcp", server.Listener.Addr().String())
+	})
+	t.Cleanup(pool.Close)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/slow", http.NoBody)
+	require.NoError(t, err)
+
+	_, err = pool.RoundTrip(req)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+
+	req, err = http.NewRequest(http.MethodGet, server.URL+"/fast", http.NoBody)
+	require.NoError(t, err)
+
+	resp, err := pool.RoundTrip(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, "body", string(body))
+}
+
+func TestHTTP2_flushInterval(t *testing.T) {
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	targetURL := testhelpers.MustParseURL(server.URL)
+	targetURL.Scheme = "h2c"
+
+	builder := NewProxyBuilder(&transportManagerMock{}, static.FastProxyConfig{})
+
+	proxyHandler, err := builder.Build("", targetURL, false, false, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	reverseProxy, ok := proxyHandler.(*ReverseProxy)
+	require.True(t, ok)
+	require.NotNil(t, reverseProxy.http2Proxy)
+
+	assert.Equal(t, 100*time.Millisecond, reverseProxy.http2Proxy.FlushInterval)
+}
+
+func TestDirectHTTP2_unixSocket(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		passHostHeader bool
+		expectedHost   string
+	}{
+		{
+			desc:         "without passHostHeader",
+			expectedHost: "localhost",
+		},
+		{
+			desc:           "with passHostHeader",
+			passHostHeader: true,
+			expectedHost:   "example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			targetURL := types.UnixSocketHTTPURL("/var/run/app.sock")
+			targetURL.Scheme = schemeH2C
+
+			reverseProxy, err := NewReverseProxy(targetURL, nil, false, test.passHostHeader, false, nil)
+			require.NoError(t, err)
+
+			outReq := httptest.NewRequest(http.MethodGet, "http://example.com/foo", http.NoBody)
+			reverseProxy.directHTTP2(outReq)
+
+			assert.Equal(t, targetURL.Host, outReq.URL.Host)
+			assert.Equal(t, test.expectedHost, outReq.Host)
+			assert.Equal(t, "/foo", outReq.URL.Path)
+		})
+	}
+}
+
+func TestUseHTTP2(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    dynamic.ServersTransport
+		targetURL string
+		proxyURL  string
+		expected  bool
+	}{
+		{
+			desc:      "http",
+			targetURL: "http://127.0.0.1",
+		},
+		{
+			desc:      "https",
+			targetURL: "https://127.0.0.1",
+			expected:  true,
+		},
+		{
+			desc:      "https with DisableHTTP2",
+			config:    dynamic.ServersTransport{DisableHTTP2: true},
+			targetURL: "https://127.0.0.1",
+		},
+		{
+			desc:      "h2c",
+			targetURL: "h2c://127.0.0.1",
+			expected:  true,
+		},
+		{
+			desc:      "h2c through an HTTP proxy",
+			targetURL: "h2c://127.0.0.1",
+			proxyURL:  "http://proxy:8080",
+		},
+		{
+			desc:      "h2c through a SOCKS5 proxy",
+			targetURL: "h2c://127.0.0.1",
+			proxyURL:  "socks5://proxy:1080",
+			expected:  true,
+		},
+		{
+			desc:      "Unix domain socket",
+			targetURL: types.UnixSocketHTTPURL("/var/run/app.sock").String(),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var proxyURL *url.URL
+			if test.proxyURL != "" {
+				proxyURL = testhelpers.MustParseURL(test.proxyURL)
+			}
+
+			assert.Equal(t, test.expected, useHTTP2(&test.config, testhelpers.MustParseURL(test.targetURL), proxyURL))
+		})
+	}
+}
+
+func TestProxyBuilder_CanProxy(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		targetURL string
+		proxyURL  string
+		expected  bool
+	}{
+		{
+			desc:      "http through an HTTP proxy",
+			targetURL: "http://127.0.0.1",
+			proxyURL:  "http://proxy:8080",
+			expected:  true,
+		},
+		{
+			desc:      "h2c",
+			targetURL: "h2c://127.0.0.1",
+			expected:  true,
+		},
+		{
+			desc:      "h2c through an HTTP proxy",
+			targetURL: "h2c://127.0.0.1",
+			proxyURL:  "http://proxy:8080",
+		},
+		{
+			desc:      "h2c through a SOCKS5 proxy",
+			targetURL: "h2c://127.0.0.1",
+			proxyURL:  "socks5://proxy:1080",
+			expected:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			builder := NewProxyBuilder(&transportManagerMock{}, static.FastProxyConfig{})
+			builder.proxy = func(*http.Request) (*url.URL, error) {
+				if test.proxyURL == "" {
+					return nil, nil
+				}
+				return testhelpers.MustParseURL(test.proxyURL), nil
+			}
+
+			assert.Equal(t, test.expected, builder.CanProxy(testhelpers.MustParseURL(test.targetURL)))
+		})
+	}
+}
@@ -4,17 +4,20 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"net/http/httputil"
 	"net/url"
 	"strings"
 	"sync"
 
 	"github.com/rs/zerolog/log"
 	proxyhttputil "github.com/apache4/apache4/v3/pkg/proxy/httputil"
+	"github.com/apache4/apache4/v3/pkg/types"
 	"github.com/valyala/fasthttp"
 	"golang.org/x/net/http/httpguts"
 )
@@ -104,12 +107,21 @@ type ReverseProxy struct {
 	proxyAuth string
 
 	targetURL      *url.URL
+	unixSocket     bool
 	passHostHeader bool
 	preservePath   bool
+
+	// http2ConnPool and http2Proxy are set when the server can be reached over HTTP/2.
+	http2ConnPool *http2ConnPool
+	http2Proxy    *httputil.ReverseProxy
 }
 
 // NewReverseProxy creates a new ReverseProxy.
 func NewReverseProxy(targetURL, proxyURL *url.URL, debug, passHostHeader, preservePath bool, connPool *connPool) (*ReverseProxy, error) {
+	if socketPath, ok := types.UnixSocketPath(targetURL); ok {
+		targetURL = types.UnixSocketHTTPURL(socketPath)
+	}
+
 	var proxyAuth string
 	if proxyURL != nil && proxyURL.User != nil && targetURL.Scheme == "http" {
 		username := proxyURL.User.Username()
@@ -122,12 +134,27 @@ func NewReverseProxy(targetURL, proxyURL *url.URL, debug, passHostHeader, preser
 		passHostHeader: passHostHeader,
 		preservePath:   preservePath,
 		targetURL:      targetURL,
+		unixSocket:     types.IsUnixSocketHost(targetURL.Host),
 		proxyAuth:      proxyAuth,
 		connPool:       connPool,
 	}, nil
 }
 
 func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// The connection upgrades (e.g. WebSocket) are only handled over HTTP/1.
+	if p.http2ConnPool != nil && upgradeType(req.Header) == "" {
+		err := p.http2ConnPool.Negotiate()
+		if err == nil {
+			p.http2Proxy.ServeHTTP(rw, req)
+			return
+		}
+
+		if !errors.Is(err, errHTTP2NotNegotiated) {
+			proxyhttputil.ErrorHandler(rw, req, err)
+			return
+		}
+	}
+
 	if req.Body != nil {
 		defer req.Body.Close()
 	}
@@ -199,8 +226,14 @@ func (p *ReverseProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	u2.RawQuery = strings.ReplaceAll(u.RawQuery, ";", "&")
 
-	outReq.SetHost(u2.Host)
-	outReq.Header.SetHost(u2.Host)
+	host := u2.Host
+	if p.unixSocket {
+		// The host encoding the socket path is meaningless to the server.
+		host = "localhost"
+	}
+
+	outReq.SetHost(host)
+	outReq.Header.SetHost(host)
 
 	if p.passHostHeader {
 		outReq.Header.SetHost(req.Host)
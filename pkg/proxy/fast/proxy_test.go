@@ -11,6 +11,7 @@ import (
 	"net/http/httptest"
 	"net/http/httputil"
 	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/testhelpers"
 )
 
@@ -189,26 +191,30 @@ func TestProxyFromEnvironment(t *testing.T) {
 
 				proxyURL = fmt.Sprintf("socks5://%s", ln.Addr())
 
-				go func() {
-					conn, err := ln.Accept()
-					require.NoError(t, err)
+				t.Cleanup(func() { _ = ln.Close() })
 
-					proxyCalled = true
+				conf := &socks5.Config{}
+				if test.auth != nil {
+					conf.Credentials = socks5.StaticCredentials{test.auth.user: test.auth.password}
+				}
 
-					conf := &socks5.Config{}
-					if test.auth != nil {
-						conf.Credentials = socks5.StaticCredentials{test.auth.user: test.auth.password}
-					}
+				server, err := socks5.New(conf)
+				require.NoError(t, err)
 
-					server, err := socks5.New(conf)
-					require.NoError(t, err)
+				// The proxy can be dialed several times, e.g. to negotiate HTTP/2 with an HTTPS backend.
+				go func() {
+					for {
+						conn, err := ln.Accept()
+						if err != nil {
+							return
+						}
 
-					// We are not checking the error, because ServeConn is blocked until the client or the backend
-					// connection is closed which, in some cases, raises a connection reset by peer error.
-					_ = server.ServeConn(conn)
+						proxyCalled = true
 
-					err = ln.Close()
-					require.NoError(t, err)
+						// We are not checking the error, because ServeConn is blocked until the client or the backend
+						// connection is closed which, in some cases, raises a connection reset by peer error.
+						go func() { _ = server.ServeConn(conn) }()
+					}
 				}()
 
 			case proxyHTTP:
@@ -247,7 +253,7 @@ func TestProxyFromEnvironment(t *testing.T) {
 				return u, nil
 			}
 
-			reverseProxy, err := builder.Build("foo", testhelpers.MustParseURL(backendServer.URL), false, false)
+			reverseProxy, err := builder.Build("foo", testhelpers.MustParseURL(backendServer.URL), false, false, 0)
 			require.NoError(t, err)
 
 			reverseProxyServer := httptest.NewServer(reverseProxy)
@@ -287,7 +293,7 @@ func TestPreservePath(t *testing.T) {
 	serverURL, err := url.JoinPath(server.URL, "base")
 	require.NoError(t, err)
 
-	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(serverURL), true, true)
+	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(serverURL), true, true, 0)
 	require.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/foo%2Fbar", http.NoBody)
@@ -299,6 +305,42 @@ func TestPreservePath(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.Code)
 }
 
+func TestUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "backend.sock")
+
+	listener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := &httptest.Server{
+		Listener: listener,
+		Config: &http.Server{Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("X-Host", req.Host)
+			rw.Header().Set("X-Path", req.URL.Path)
+			rw.WriteHeader(http.StatusOK)
+		})},
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	builder := NewProxyBuilder(&transportManagerMock{}, static.FastProxyConfig{})
+	// Unix domain socket servers must never be reached through a proxy.
+	builder.proxy = func(_ *http.Request) (*url.URL, error) {
+		return url.Parse("http://127.0.0.1:1")
+	}
+
+	proxyHandler, err := builder.Build("", testhelpers.MustParseURL("unix://"+socketPath), false, false, 0)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/foo", http.NoBody)
+	res := httptest.NewRecorder()
+
+	proxyHandler.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "localhost", res.Header().Get("X-Host"))
+	assert.Equal(t, "/foo", res.Header().Get("X-Path"))
+}
+
 func TestHeadRequest(t *testing.T) {
 	var callCount int
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -315,7 +357,7 @@ func TestHeadRequest(t *testing.T) {
 	serverURL, err := url.JoinPath(server.URL)
 	require.NoError(t, err)
 
-	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(serverURL), true, true)
+	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(serverURL), true, true, 0)
 	require.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodHead, "/", http.NoBody)
@@ -355,7 +397,7 @@ func TestNoContentLength(t *testing.T) {
 
 	serverURL := "http://" + backendListener.Addr().String()
 
-	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(serverURL), true, true)
+	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(serverURL), true, true, 0)
 	require.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
@@ -383,7 +425,7 @@ func TestTransferEncodingChunked(t *testing.T) {
 
 	builder := NewProxyBuilder(&transportManagerMock{}, static.FastProxyConfig{})
 
-	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(backendServer.URL), true, true)
+	proxyHandler, err := builder.Build("", testhelpers.MustParseURL(backendServer.URL), true, true, 0)
 	require.NoError(t, err)
 
 	proxyServer := httptest.NewServer(proxyHandler)
@@ -417,3 +459,7 @@ func (r *transportManagerMock) GetTLSConfig(_ string) (*tls.Config, error) {
 func (r *transportManagerMock) Get(_ string) (*dynamic.ServersTransport, error) {
 	return &dynamic.ServersTransport{}, nil
 }
+
+func (r *transportManagerMock) GetResolver(_ string) (*resolver.Resolver, error) {
+	return nil, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/metrics"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 )
 
 // TransportManager manages transport used for backend communications.
@@ -16,6 +17,7 @@ type TransportManager interface {
 	Get(name string) (*dynamic.ServersTransport, error)
 	GetRoundTripper(name string) (http.RoundTripper, error)
 	GetTLSConfig(name string) (*tls.Config, error)
+	GetResolver(name string) (*resolver.Resolver, error)
 }
 
 // ProxyBuilder handles the http.RoundTripper for httputil reverse proxies.
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/testhelpers"
 )
 
@@ -54,3 +55,7 @@ func (t *transportManagerMock) GetTLSConfig(_ string) (*tls.Config, error) {
 func (t *transportManagerMock) Get(_ string) (*dynamic.ServersTransport, error) {
 	panic("implement me")
 }
+
+func (t *transportManagerMock) GetResolver(_ string) (*resolver.Resolver, error) {
+	panic("implement me")
+}
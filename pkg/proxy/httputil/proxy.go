@@ -16,6 +16,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/types"
 	"golang.org/x/net/http/httpguts"
 )
 
@@ -39,6 +40,13 @@ func buildSingleHostProxy(target *url.URL, passHostHeader bool, preservePath boo
 }
 
 func directorBuilder(target *url.URL, passHostHeader bool, preservePath bool) func(req *http.Request) {
+	// Unix domain socket servers are reached with an HTTP URL encoding the socket path,
+	// which is decoded by the transport dialer.
+	socketPath, unixSocket := types.UnixSocketPath(target)
+	if unixSocket {
+		target = types.UnixSocketHTTPURL(socketPath)
+	}
+
 	return func(outReq *http.Request) {
 		outReq.URL.Scheme = target.Scheme
 		outReq.URL.Host = target.Host
@@ -69,6 +77,9 @@ func directorBuilder(target *url.URL, passHostHeader bool, preservePath bool) fu
 		// Do not pass client Host header unless option PassHostHeader is set.
 		if !passHostHeader {
 			outReq.Host = outReq.URL.Host
+			if unixSocket {
+				outReq.Host = "localhost"
+			}
 		}
 
 		if isWebSocketUpgrade(outReq) {
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/apache4/apache4/v3/pkg/testhelpers"
+	"github.com/apache4/apache4/v3/pkg/types"
 )
 
 func Test_directorBuilder(t *testing.T) {
@@ -104,6 +105,28 @@ func Test_directorBuilder(t *testing.T) {
 	}
 }
 
+func Test_directorBuilder_unixSocket(t *testing.T) {
+	target := testhelpers.MustParseURL("unix:///var/run/app.sock")
+
+	director := directorBuilder(target, false, true)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/foo?bar=baz", http.NoBody)
+	director(req)
+
+	assert.Equal(t, "http", req.URL.Scheme)
+	assert.True(t, types.IsUnixSocketHost(req.URL.Host))
+	assert.Equal(t, "localhost", req.Host)
+	assert.Equal(t, "/foo", req.URL.Path)
+	assert.Equal(t, "bar=baz", req.URL.RawQuery)
+
+	director = directorBuilder(target, true, false)
+
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/foo", http.NoBody)
+	director(req)
+
+	assert.Equal(t, "example.com", req.Host)
+}
+
 func Test_isTLSConfigError(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -2,7 +2,6 @@ package proxy
 
 import (
 	"crypto/tls"
-	"fmt"
 	"net/http"
 	"net/url"
 	"time"
@@ -10,7 +9,7 @@ import (
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/static"
 	"github.com/apache4/apache4/v3/pkg/proxy/fast"
-	"github.com/apache4/apache4/v3/pkg/proxy/httputil"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/server/service"
 )
 
@@ -19,15 +18,16 @@ type TransportManager interface {
 	Get(name string) (*dynamic.ServersTransport, error)
 	GetRoundTripper(name string) (http.RoundTripper, error)
 	GetTLSConfig(name string) (*tls.Config, error)
+	GetResolver(name string) (*resolver.Resolver, error)
 }
 
-// SmartBuilder is a proxy builder which returns a fast proxy or httputil proxy corresponding
-// to the ServersTransport configuration.
+// SmartBuilder is a proxy builder which returns a fast proxy,
+// speaking HTTP/1.1 or HTTP/2 depending on the server scheme, the ALPN negotiation and the ServersTransport configuration.
+// The servers reached over HTTP/3, and the h2c servers reached through an HTTP proxy,
+// are proxied with the given fallback proxy builder.
 type SmartBuilder struct {
 	fastProxyBuilder *fast.ProxyBuilder
 	proxyBuilder     service.ProxyBuilder
-
-	transportManager httputil.TransportManager
 }
 
 // NewSmartBuilder creates and returns a new SmartBuilder instance.
@@ -35,7 +35,6 @@ func NewSmartBuilder(transportManager TransportManager, proxyBuilder service.Pro
 	return &SmartBuilder{
 		fastProxyBuilder: fast.NewProxyBuilder(transportManager, fastProxyConfig),
 		proxyBuilder:     proxyBuilder,
-		transportManager: transportManager,
 	}
 }
 
@@ -46,16 +45,11 @@ func (b *SmartBuilder) Update(newConfigs map[string]*dynamic.ServersTransport) {
 
 // Build builds an HTTP proxy for the given URL using the ServersTransport with the given name.
 func (b *SmartBuilder) Build(configName string, targetURL *url.URL, passHostHeader, preservePath bool, flushInterval time.Duration) (http.Handler, error) {
-	serversTransport, err := b.transportManager.Get(configName)
-	if err != nil {
-		return nil, fmt.Errorf("getting ServersTransport: %w", err)
-	}
-
-	// The fast proxy implementation cannot handle HTTP/2 requests for now.
-	// For the https scheme we cannot guess if the backend communication will use HTTP2,
-	// thus we check if HTTP/2 is disabled to use the fast proxy implementation when this is possible.
-	if targetURL.Scheme == "h2c" || (targetURL.Scheme == "https" && !serversTransport.DisableHTTP2) {
+	// The fast proxy implementation cannot handle HTTP/3 requests,
+	// nor the h2c requests to forward through an HTTP proxy.
+	if targetURL.Scheme == "h3" || !b.fastProxyBuilder.CanProxy(targetURL) {
 		return b.proxyBuilder.Build(configName, targetURL, passHostHeader, preservePath, flushInterval)
 	}
-	return b.fastProxyBuilder.Build(configName, targetURL, passHostHeader, preservePath)
+
+	return b.fastProxyBuilder.Build(configName, targetURL, passHostHeader, preservePath, flushInterval)
 }
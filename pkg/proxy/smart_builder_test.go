@@ -1,12 +1,15 @@
 package proxy
 
 import (
+	"crypto/tls"
 	"encoding/pem"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
@@ -14,6 +17,7 @@ import (
 	"github.com/apache4/apache4/v3/pkg/proxy/httputil"
 	"github.com/apache4/apache4/v3/pkg/server/service"
 	"github.com/apache4/apache4/v3/pkg/testhelpers"
+	"github.com/apache4/apache4/v3/pkg/tls/generate"
 	"github.com/apache4/apache4/v3/pkg/types"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -25,32 +29,41 @@ func TestSmartBuilder_Build(t *testing.T) {
 		serversTransport dynamic.ServersTransport
 		fastProxyConfig  static.FastProxyConfig
 		https            bool
+		http2Server      bool
 		h2c              bool
-		wantFastProxy    bool
+		expectedProto    int
 	}{
 		{
 			desc:            "fastproxy",
 			fastProxyConfig: static.FastProxyConfig{Debug: true},
-			wantFastProxy:   true,
+			expectedProto:   1,
 		},
 		{
-			desc:            "fastproxy with https and without DisableHTTP2",
+			desc:            "fastproxy with https and without DisableHTTP2 on an HTTP/1 server",
 			https:           true,
 			fastProxyConfig: static.FastProxyConfig{Debug: true},
-			wantFastProxy:   false,
+			expectedProto:   1,
+		},
+		{
+			desc:            "fastproxy with https and without DisableHTTP2 on an HTTP/2 server",
+			https:           true,
+			http2Server:     true,
+			fastProxyConfig: static.FastProxyConfig{Debug: true},
+			expectedProto:   2,
 		},
 		{
 			desc:             "fastproxy with https and DisableHTTP2",
 			https:            true,
+			http2Server:      true,
 			serversTransport: dynamic.ServersTransport{DisableHTTP2: true},
 			fastProxyConfig:  static.FastProxyConfig{Debug: true},
-			wantFastProxy:    true,
+			expectedProto:    1,
 		},
 		{
 			desc:            "fastproxy with h2c",
 			h2c:             true,
 			fastProxyConfig: static.FastProxyConfig{Debug: true},
-			wantFastProxy:   false,
+			expectedProto:   2,
 		},
 	}
 
@@ -61,18 +74,15 @@ func TestSmartBuilder_Build(t *testing.T) {
 			var callCount int
 			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				callCount++
-				if test.wantFastProxy {
-					assert.Contains(t, r.Header, "X-apache4-Fast-Proxy")
-				} else {
-					assert.NotContains(t, r.Header, "X-apache4-Fast-Proxy")
-				}
+				assert.Contains(t, r.Header, "X-apache4-Fast-Proxy")
+				assert.Equal(t, test.expectedProto, r.ProtoMajor)
 			})
 
 			var server *httptest.Server
 
 			if test.https {
 				server = httptest.NewUnstartedServer(handler)
-				server.EnableHTTP2 = false
+				server.EnableHTTP2 = test.http2Server
 				server.StartTLS()
 
 				certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
@@ -111,3 +121,39 @@ func TestSmartBuilder_Build(t *testing.T) {
 		})
 	}
 }
+
+func TestSmartBuilder_Build_h3(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+
+	cert, err := generate.DefaultCertificate()
+	require.NoError(t, err)
+
+	server := &http3.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.NotContains(t, r.Header, "X-apache4-Fast-Proxy")
+			assert.Equal(t, 3, r.ProtoMajor)
+		}),
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: []tls.Certificate{*cert}}),
+	}
+	go func() { _ = server.Serve(conn) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	serversTransports := map[string]*dynamic.ServersTransport{
+		"test": {InsecureSkipVerify: true},
+	}
+
+	transportManager := service.NewTransportManager(nil)
+	transportManager.Update(serversTransports)
+
+	httpProxyBuilder := httputil.NewProxyBuilder(transportManager, nil)
+	proxyBuilder := NewSmartBuilder(transportManager, httpProxyBuilder, static.FastProxyConfig{Debug: true})
+
+	proxyHandler, err := proxyBuilder.Build("test", testhelpers.MustParseURL("h3://"+conn.LocalAddr().String()), false, false, time.Second)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	proxyHandler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
@@ -0,0 +1,400 @@
+package resolver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+const (
+	defaultResolvConfig = "/etc/resolv.conf"
+	defaultDNSPort      = "53"
+	exchangeTimeout     = 5 * time.Second
+)
+
+// DialContextFunc dials a connection to the given address.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Dial dials a connection to the given address, implementing proxy.Dialer.
+func (f DialContextFunc) Dial(network, addr string) (net.Conn, error) {
+	return f(context.Background(), network, addr)
+}
+
+// Resolver resolves the hostnames of the servers into IP addresses,
+// caching them for the TTL of their DNS records.
+type Resolver struct {
+	client             *dns.Client
+	nameservers        []string
+	minTTL             time.Duration
+	maxTTL             time.Duration
+	preferredIPVersion dynamic.IPVersion
+	expandServers      bool
+
+	// clientConfig is the resolver configuration file the nameservers are read from, if any,
+	// whose search domains complete the hostnames which are not fully qualified.
+	clientConfig *dns.ClientConfig
+
+	cacheMu sync.Mutex
+	cache   map[string]*cacheEntry
+
+	// next spreads the connections across the resolved addresses.
+	next atomic.Uint32
+}
+
+type cacheEntry struct {
+	addrs     []netip.Addr
+	expiresAt time.Time
+}
+
+// New creates a new Resolver.
+func New(config *dynamic.ResolverConfig) (*Resolver, error) {
+	switch config.PreferredIPVersion {
+	case "", dynamic.IPVersion4, dynamic.IPVersion6:
+	default:
+		return nil, fmt.Errorf("unsupported preferred IP version %q", config.PreferredIPVersion)
+	}
+
+	if config.MaxTTL > 0 && config.MinTTL > config.MaxTTL {
+		return nil, fmt.Errorf("minimum TTL %s is greater than maximum TTL %s", config.MinTTL, config.MaxTTL)
+	}
+
+	var nameservers []string
+	var clientConfig *dns.ClientConfig
+	for _, nameserver := range config.Nameservers {
+		if _, _, err := net.SplitHostPort(nameserver); err != nil {
+			nameserver = net.JoinHostPort(nameserver, defaultDNSPort)
+		}
+		nameservers = append(nameservers, nameserver)
+	}
+
+	if len(nameservers) == 0 {
+		var err error
+		clientConfig, err = dns.ClientConfigFromFile(defaultResolvConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resolver configuration file: %s: %w", defaultResolvConfig, err)
+		}
+
+		for _, server := range clientConfig.Servers {
+			nameservers = append(nameservers, net.JoinHostPort(server, clientConfig.Port))
+		}
+	}
+
+	if len(nameservers) == 0 {
+		return nil, errors.New("no nameserver defined")
+	}
+
+	return &Resolver{
+		client:             &dns.Client{Timeout: exchangeTimeout},
+		nameservers:        nameservers,
+		minTTL:             time.Duration(config.MinTTL),
+		maxTTL:             time.Duration(config.MaxTTL),
+		preferredIPVersion: config.PreferredIPVersion,
+		expandServers:      config.ExpandServers,
+		clientConfig:       clientConfig,
+		cache:              make(map[string]*cacheEntry),
+	}, nil
+}
+
+// ExpandServers returns whether the server hostnames are expanded into one server per resolved address.
+func (r *Resolver) ExpandServers() bool {
+	return r.expandServers
+}
+
+// LookupAddrs returns the IP addresses of the given host, the ones of the preferred IP version first.
+// The addresses are cached for the TTL of their DNS records,
+// and the expired ones are still returned when the host cannot be resolved anymore.
+func (r *Resolver) LookupAddrs(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+
+	now := time.Now()
+
+	r.cacheMu.Lock()
+	entry := r.cache[host]
+	r.cacheMu.Unlock()
+
+	if entry != nil && now.Before(entry.expiresAt) {
+		return entry.addrs, nil
+	}
+
+	addrs, ttl, err := r.lookup(ctx, host)
+	if err != nil {
+		if entry != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("Using expired addresses of host %q", host)
+			return entry.addrs, nil
+		}
+
+		return nil, err
+	}
+
+	r.cacheMu.Lock()
+	r.cache[host] = &cacheEntry{addrs: addrs, expiresAt: now.Add(r.clampTTL(ttl))}
+	r.cacheMu.Unlock()
+
+	return addrs, nil
+}
+
+// ServerAddrs returns the IP addresses of the given host the servers are expanded into,
+// which are the ones of the preferred IP version, if any.
+func (r *Resolver) ServerAddrs(ctx context.Context, host string) ([]netip.Addr, error) {
+	addrs, err := r.LookupAddrs(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	preferred, _ := r.splitPreferred(addrs)
+	if len(preferred) > 0 {
+		return preferred, nil
+	}
+
+	return addrs, nil
+}
+
+// DialContext returns a DialContextFunc resolving the host of the address,
+// and dialing its IP addresses with the given function until a connection is established.
+// The connections are spread across the addresses, the ones of the preferred IP version being dialed first.
+func (r *Resolver) DialContext(dial DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+
+		addrs, err := r.LookupAddrs(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs = filterNetwork(network, addrs)
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("no %s address found for host %q", network, host)
+		}
+
+		var errs []error
+		for _, ipAddr := range r.spread(addrs) {
+			conn, err := dial(ctx, network, net.JoinHostPort(ipAddr.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+
+			errs = append(errs, err)
+		}
+
+		return nil, errors.Join(errs...)
+	}
+}
+
+// DialTLSContext returns a DialContextFunc establishing a TLS connection over the connection dialed with the given function.
+// When the TLS configuration does not define a server name, the host of the dialed address is used,
+// so that the server certificate is verified against the hostname rather than the resolved address.
+func DialTLSContext(dial DialContextFunc, config *tls.Config) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig := config
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+
+		if tlsConfig.ServerName == "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				host = addr
+			}
+
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.ServerName = host
+		}
+
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+
+		return tlsConn, nil
+	}
+}
+
+// lookup queries the A and AAAA records of the host, and returns their addresses with their lowest TTL.
+// The names the host is completed into are queried in turn, until one of them has addresses.
+func (r *Resolver) lookup(ctx context.Context, host string) ([]netip.Addr, time.Duration, error) {
+	var errs []error
+	for _, name := range r.names(host) {
+		addrs, ttl, err := r.lookupName(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		if len(addrs) > 0 {
+			preferred, others := r.splitPreferred(addrs)
+
+			return append(preferred, others...), ttl, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, 0, fmt.Errorf("resolving host %q: %w", host, errors.Join(errs...))
+	}
+
+	return nil, 0, fmt.Errorf("no address found for host %q", host)
+}
+
+// lookupName queries the A and AAAA records of the fully qualified name,
+// and returns their addresses with their lowest TTL.
+func (r *Resolver) lookupName(ctx context.Context, name string) ([]netip.Addr, time.Duration, error) {
+	var addrs []netip.Addr
+	var ttl uint32
+	var errs []error
+
+	for _, qType := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		answer, err := r.exchange(ctx, name, qType)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, rr := range answer {
+			var ip net.IP
+			switch record := rr.(type) {
+			case *dns.A:
+				ip = record.A
+			case *dns.AAAA:
+				ip = record.AAAA
+			default:
+				continue
+			}
+
+			addr, ok := netip.AddrFromSlice(ip)
+			if !ok {
+				continue
+			}
+
+			if len(addrs) == 0 || rr.Header().Ttl < ttl {
+				ttl = rr.Header().Ttl
+			}
+			addrs = append(addrs, addr.Unmap())
+		}
+	}
+
+	return addrs, time.Duration(ttl) * time.Second, errors.Join(errs...)
+}
+
+// names returns the fully qualified names to query for the host.
+// When the nameservers are read from the resolver configuration file,
+// the hostnames which are not fully qualified are completed with its search domains,
+// e.g. for the short names of the Kubernetes services.
+func (r *Resolver) names(host string) []string {
+	if r.clientConfig == nil {
+		return []string{dns.Fqdn(host)}
+	}
+
+	return r.clientConfig.NameList(host)
+}
+
+// exchange queries the records of the given type for the fully qualified name,
+// asking the nameservers in turn until one of them answers.
+func (r *Resolver) exchange(ctx context.Context, name string, qType uint16) ([]dns.RR, error) {
+	msg := &dns.Msg{}
+	msg.SetQuestion(name, qType)
+
+	var errs []error
+	for _, nameserver := range r.nameservers {
+		resp, _, err := r.client.ExchangeContext(ctx, msg, nameserver)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("exchange error for server %s: %w", nameserver, err))
+			continue
+		}
+
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+			return resp.Answer, nil
+		case dns.RcodeNameError:
+			// The name does not exist, which the other nameservers would confirm.
+			return nil, nil
+		default:
+			errs = append(errs, fmt.Errorf("server %s answered %s", nameserver, dns.RcodeToString[resp.Rcode]))
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+func (r *Resolver) clampTTL(ttl time.Duration) time.Duration {
+	if ttl < r.minTTL {
+		return r.minTTL
+	}
+
+	if r.maxTTL > 0 && ttl > r.maxTTL {
+		return r.maxTTL
+	}
+
+	return ttl
+}
+
+// splitPreferred splits the addresses of the preferred IP version from the others.
+// All the addresses are returned as preferred when no IP version is preferred.
+func (r *Resolver) splitPreferred(addrs []netip.Addr) ([]netip.Addr, []netip.Addr) {
+	if r.preferredIPVersion == "" {
+		return addrs, nil
+	}
+
+	var preferred, others []netip.Addr
+	for _, addr := range addrs {
+		if addr.Is4() == (r.preferredIPVersion == dynamic.IPVersion4) {
+			preferred = append(preferred, addr)
+			continue
+		}
+
+		others = append(others, addr)
+	}
+
+	return preferred, others
+}
+
+// spread returns the addresses in the order they are dialed,
+// rotated from one connection to the next to spread the connections across them.
+func (r *Resolver) spread(addrs []netip.Addr) []netip.Addr {
+	preferred, others := r.splitPreferred(addrs)
+
+	next := int(r.next.Add(1))
+
+	return append(rotate(preferred, next), rotate(others, next)...)
+}
+
+func rotate(addrs []netip.Addr, n int) []netip.Addr {
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	n %= len(addrs)
+
+	return append(slices.Clone(addrs[n:]), addrs[:n]...)
+}
+
+// filterNetwork returns the addresses which can be dialed on the given network.
+func filterNetwork(network string, addrs []netip.Addr) []netip.Addr {
+	switch network {
+	case "tcp4", "udp4":
+		return slices.DeleteFunc(slices.Clone(addrs), func(addr netip.Addr) bool { return !addr.Is4() })
+	case "tcp6", "udp6":
+		return slices.DeleteFunc(slices.Clone(addrs), func(addr netip.Addr) bool { return !addr.Is6() })
+	default:
+		return addrs
+	}
+}
@@ -0,0 +1,328 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+type fakeNameserver struct {
+	addr    string
+	records map[uint16][]string
+	ttl     uint32
+	fail    atomic.Bool
+	queries atomic.Int32
+}
+
+func startNameserver(t *testing.T, ttl uint32, records map[uint16][]string) *fakeNameserver {
+	t.Helper()
+
+	ns := &fakeNameserver{records: records, ttl: ttl}
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ns.addr = conn.LocalAddr().String()
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		PacketConn:        conn,
+		Handler:           ns,
+		NotifyStartedFunc: func() { close(started) },
+	}
+
+	go func() { _ = server.ActivateAndServe() }()
+	t.Cleanup(func() { _ = server.Shutdown() })
+
+	<-started
+
+	return ns
+}
+
+func (n *fakeNameserver) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	n.queries.Add(1)
+
+	resp := &dns.Msg{}
+	resp.SetReply(req)
+
+	if n.fail.Load() {
+		resp.Rcode = dns.RcodeServerFailure
+		_ = w.WriteMsg(resp)
+		return
+	}
+
+	question := req.Question[0]
+	if question.Name != "example.com." {
+		resp.Rcode = dns.RcodeNameError
+		_ = w.WriteMsg(resp)
+		return
+	}
+
+	for _, ip := range n.records[question.Qtype] {
+		header := dns.RR_Header{Name: question.Name, Rrtype: question.Qtype, Class: dns.ClassINET, Ttl: n.ttl}
+
+		switch question.Qtype {
+		case dns.TypeA:
+			resp.Answer = append(resp.Answer, &dns.A{Hdr: header, A: net.ParseIP(ip)})
+		case dns.TypeAAAA:
+			resp.Answer = append(resp.Answer, &dns.AAAA{Hdr: header, AAAA: net.ParseIP(ip)})
+		}
+	}
+
+	_ = w.WriteMsg(resp)
+}
+
+func TestResolver_LookupAddrs(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		host               string
+		preferredIPVersion dynamic.IPVersion
+		expected           []string
+		expectedErr        bool
+	}{
+		{
+			desc:     "IP address",
+			host:     "10.0.0.1",
+			expected: []string{"10.0.0.1"},
+		},
+		{
+			desc:     "IPv4 and IPv6 addresses",
+			host:     "example.com",
+			expected: []string{"10.0.0.1", "10.0.0.2", "fd00::1"},
+		},
+		{
+			desc:               "IPv6 preferred",
+			host:               "example.com",
+			preferredIPVersion: dynamic.IPVersion6,
+			expected:           []string{"fd00::1", "10.0.0.1", "10.0.0.2"},
+		},
+		{
+			desc:        "unknown host",
+			host:        "unknown.com",
+			expectedErr: true,
+		},
+	}
+
+	ns := startNameserver(t, 60, map[uint16][]string{
+		dns.TypeA:    {"10.0.0.1", "10.0.0.2"},
+		dns.TypeAAAA: {"fd00::1"},
+	})
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resolver, err := New(&dynamic.ResolverConfig{
+				Nameservers:        []string{ns.addr},
+				PreferredIPVersion: test.preferredIPVersion,
+			})
+			require.NoError(t, err)
+
+			addrs, err := resolver.LookupAddrs(t.Context(), test.host)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, addr := range addrs {
+				got = append(got, addr.String())
+			}
+			assert.Equal(t, test.expected, got)
+		})
+	}
+}
+
+func TestResolver_LookupAddrs_cache(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		ttl             uint32
+		minTTL          time.Duration
+		maxTTL          time.Duration
+		expectedQueries int32
+	}{
+		{
+			desc:            "cached for the record TTL",
+			ttl:             60,
+			expectedQueries: 2,
+		},
+		{
+			desc:            "expired record",
+			ttl:             0,
+			expectedQueries: 4,
+		},
+		{
+			desc:            "record TTL raised to the minimum TTL",
+			ttl:             0,
+			minTTL:          time.Minute,
+			expectedQueries: 2,
+		},
+		{
+			desc:            "record TTL lowered to the maximum TTL",
+			ttl:             60,
+			maxTTL:          time.Nanosecond,
+			expectedQueries: 4,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ns := startNameserver(t, test.ttl, map[uint16][]string{dns.TypeA: {"10.0.0.1"}})
+
+			resolver, err := New(&dynamic.ResolverConfig{
+				Nameservers: []string{ns.addr},
+				MinTTL:      ptypes.Duration(test.minTTL),
+				MaxTTL:      ptypes.Duration(test.maxTTL),
+			})
+			require.NoError(t, err)
+
+			for range 2 {
+				_, err = resolver.LookupAddrs(t.Context(), "example.com")
+				require.NoError(t, err)
+			}
+
+			// A and AAAA queries are sent for each lookup.
+			assert.Equal(t, test.expectedQueries, ns.queries.Load())
+		})
+	}
+}
+
+func TestResolver_LookupAddrs_expired(t *testing.T) {
+	ns := startNameserver(t, 0, map[uint16][]string{dns.TypeA: {"10.0.0.1"}})
+
+	resolver, err := New(&dynamic.ResolverConfig{Nameservers: []string{ns.addr}})
+	require.NoError(t, err)
+
+	_, err = resolver.LookupAddrs(t.Context(), "example.com")
+	require.NoError(t, err)
+
+	ns.fail.Store(true)
+
+	addrs, err := resolver.LookupAddrs(t.Context(), "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.1")}, addrs)
+}
+
+func TestResolver_LookupAddrs_searchDomains(t *testing.T) {
+	ns := startNameserver(t, 60, map[uint16][]string{dns.TypeA: {"10.0.0.1"}})
+
+	resolver, err := New(&dynamic.ResolverConfig{Nameservers: []string{ns.addr}})
+	require.NoError(t, err)
+
+	// As if the nameservers were read from the resolver configuration file.
+	resolver.clientConfig = &dns.ClientConfig{Search: []string{"svc.local", "com"}, Ndots: 1}
+
+	addrs, err := resolver.LookupAddrs(t.Context(), "example")
+	require.NoError(t, err)
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.1")}, addrs)
+
+	_, err = resolver.LookupAddrs(t.Context(), "unknown")
+	require.Error(t, err)
+}
+
+func TestResolver_ServerAddrs(t *testing.T) {
+	ns := startNameserver(t, 60, map[uint16][]string{
+		dns.TypeA:    {"10.0.0.1", "10.0.0.2"},
+		dns.TypeAAAA: {"fd00::1"},
+	})
+
+	resolver, err := New(&dynamic.ResolverConfig{
+		Nameservers:        []string{ns.addr},
+		PreferredIPVersion: dynamic.IPVersion4,
+	})
+	require.NoError(t, err)
+
+	addrs, err := resolver.ServerAddrs(t.Context(), "example.com")
+	require.NoError(t, err)
+
+	assert.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")}, addrs)
+}
+
+func TestResolver_DialContext(t *testing.T) {
+	ns := startNameserver(t, 60, map[uint16][]string{
+		dns.TypeA:    {"127.0.0.1", "127.0.0.2"},
+		dns.TypeAAAA: {"::1"},
+	})
+
+	resolver, err := New(&dynamic.ResolverConfig{
+		Nameservers:        []string{ns.addr},
+		PreferredIPVersion: dynamic.IPVersion4,
+	})
+	require.NoError(t, err)
+
+	var dialed []string
+	dial := resolver.DialContext(func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, errors.New("unreachable")
+	})
+
+	_, err = dial(t.Context(), "tcp", "example.com:8080")
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{"127.0.0.1:8080", "127.0.0.2:8080"}, dialed[:2])
+	assert.Equal(t, "[::1]:8080", dialed[2])
+
+	first := dialed[0]
+
+	dialed = nil
+	_, err = dial(t.Context(), "tcp4", "example.com:8080")
+	require.Error(t, err)
+	require.Len(t, dialed, 2)
+
+	// The connections are spread across the addresses.
+	assert.NotEqual(t, first, dialed[0])
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.ResolverConfig
+		expectedErr bool
+	}{
+		{
+			desc:   "nameserver without port",
+			config: dynamic.ResolverConfig{Nameservers: []string{"10.0.0.1"}},
+		},
+		{
+			desc:        "unsupported IP version",
+			config:      dynamic.ResolverConfig{Nameservers: []string{"10.0.0.1"}, PreferredIPVersion: "ipv5"},
+			expectedErr: true,
+		},
+		{
+			desc: "minimum TTL greater than maximum TTL",
+			config: dynamic.ResolverConfig{
+				Nameservers: []string{"10.0.0.1"},
+				MinTTL:      ptypes.Duration(time.Hour),
+				MaxTTL:      ptypes.Duration(time.Minute),
+			},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resolver, err := New(&test.config)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"10.0.0.1:53"}, resolver.nameservers)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package resolver
+
+import (
+	"context"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// watchInterval is the interval at which the watched hosts are checked,
+	// their addresses being resolved again only once their cached TTL expires.
+	watchInterval = time.Second
+	// changeDelay is the delay between the detection of a change and its notification,
+	// for the changes happening meanwhile, e.g. during a rolling update, to be notified at once.
+	changeDelay = 5 * time.Second
+	// minChangeInterval is the minimum interval between two notifications of changes for the same name.
+	minChangeInterval = 30 * time.Second
+)
+
+// ServersWatcher watches the hosts the servers of services have been expanded into,
+// to detect when they resolve to other addresses, and the servers of the services must be expanded again.
+type ServersWatcher struct {
+	interval          time.Duration
+	changeDelay       time.Duration
+	minChangeInterval time.Duration
+
+	hostsMu sync.Mutex
+	// hosts holds the watched hosts, keyed by service name and host.
+	hosts map[string]map[string]watchedHost
+}
+
+type watchedHost struct {
+	resolver *Resolver
+	addrs    []netip.Addr
+}
+
+// NewServersWatcher creates a new ServersWatcher.
+func NewServersWatcher() *ServersWatcher {
+	return &ServersWatcher{
+		interval:          watchInterval,
+		changeDelay:       changeDelay,
+		minChangeInterval: minChangeInterval,
+		hosts:             make(map[string]map[string]watchedHost),
+	}
+}
+
+// Watch watches the given host, which the servers of the given service have been expanded into the given addresses of.
+// Watching a host again for the same service replaces its addresses.
+func (w *ServersWatcher) Watch(name string, resolver *Resolver, host string, addrs []netip.Addr) {
+	w.hostsMu.Lock()
+	defer w.hostsMu.Unlock()
+
+	if w.hosts[name] == nil {
+		w.hosts[name] = make(map[string]watchedHost)
+	}
+
+	w.hosts[name][host] = watchedHost{resolver: resolver, addrs: sortedAddrs(addrs)}
+}
+
+// Watched reports whether hosts are watched for the given service.
+func (w *ServersWatcher) Watched(name string) bool {
+	w.hostsMu.Lock()
+	defer w.hostsMu.Unlock()
+
+	return len(w.hosts[name]) > 0
+}
+
+// Run checks the watched hosts until the context is done,
+// and calls onChange with the name of the services one of the hosts of which resolves to other addresses.
+// A change is notified once it has lasted for the change delay,
+// and at most once per minimum change interval for a given service.
+func (w *ServersWatcher) Run(ctx context.Context, onChange func(name string)) {
+	w.hostsMu.Lock()
+	empty := len(w.hosts) == 0
+	w.hostsMu.Unlock()
+
+	if empty {
+		return
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// detectedAt holds when the pending change of each service has been detected.
+	detectedAt := make(map[string]time.Time)
+	notifiedAt := make(map[string]time.Time)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed := w.changed(ctx)
+
+			for name := range detectedAt {
+				if !slices.Contains(changed, name) {
+					delete(detectedAt, name)
+				}
+			}
+
+			for _, name := range changed {
+				now := time.Now()
+				if _, ok := detectedAt[name]; !ok {
+					detectedAt[name] = now
+				}
+
+				if now.Sub(detectedAt[name]) < w.changeDelay || now.Sub(notifiedAt[name]) < w.minChangeInterval {
+					continue
+				}
+
+				delete(detectedAt, name)
+				notifiedAt[name] = now
+
+				log.Ctx(ctx).Debug().Msgf("Hosts of service %q resolve to other addresses, expanding its servers again", name)
+				onChange(name)
+			}
+		}
+	}
+}
+
+// changed returns the name of the services one of the hosts of which resolves to other addresses.
+func (w *ServersWatcher) changed(ctx context.Context) []string {
+	type check struct {
+		name string
+		host string
+		watchedHost
+	}
+
+	var checks []check
+
+	w.hostsMu.Lock()
+	for name, hosts := range w.hosts {
+		for host, watched := range hosts {
+			checks = append(checks, check{name: name, host: host, watchedHost: watched})
+		}
+	}
+	w.hostsMu.Unlock()
+
+	var changed []string
+	for _, c := range checks {
+		if slices.Contains(changed, c.name) {
+			continue
+		}
+
+		addrs, err := c.resolver.ServerAddrs(ctx, c.host)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("Failed to resolve host %q", c.host)
+			continue
+		}
+
+		if !slices.Equal(c.addrs, sortedAddrs(addrs)) {
+			changed = append(changed, c.name)
+		}
+	}
+
+	slices.Sort(changed)
+
+	return changed
+}
+
+// sortedAddrs returns a sorted copy of the addresses,
+// for the order of the DNS answers not to be taken as a change.
+func sortedAddrs(addrs []netip.Addr) []netip.Addr {
+	sorted := slices.Clone(addrs)
+	slices.SortFunc(sorted, func(a, b netip.Addr) int { return a.Compare(b) })
+
+	return sorted
+}
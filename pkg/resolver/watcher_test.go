@@ -0,0 +1,101 @@
+package resolver
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestServersWatcher_Run(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		watchedAddrs      []string
+		changeDelay       time.Duration
+		minChangeInterval time.Duration
+		expectedChanges   int
+	}{
+		{
+			desc:         "same addresses",
+			watchedAddrs: []string{"10.0.0.2", "10.0.0.1"},
+		},
+		{
+			desc:              "other addresses notified at most once per interval",
+			watchedAddrs:      []string{"10.0.0.1"},
+			minChangeInterval: time.Hour,
+			expectedChanges:   1,
+		},
+		{
+			desc:         "other addresses notified after the change delay",
+			watchedAddrs: []string{"10.0.0.1"},
+			changeDelay:  time.Hour,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ns := startNameserver(t, 0, map[uint16][]string{dns.TypeA: {"10.0.0.1", "10.0.0.2"}})
+
+			resolver, err := New(&dynamic.ResolverConfig{Nameservers: []string{ns.addr}})
+			require.NoError(t, err)
+
+			var addrs []netip.Addr
+			for _, addr := range test.watchedAddrs {
+				addrs = append(addrs, netip.MustParseAddr(addr))
+			}
+
+			watcher := NewServersWatcher()
+			watcher.interval = 10 * time.Millisecond
+			watcher.changeDelay = test.changeDelay
+			watcher.minChangeInterval = test.minChangeInterval
+			watcher.Watch("foo@file", resolver, "example.com", addrs)
+
+			assert.True(t, watcher.Watched("foo@file"))
+			assert.False(t, watcher.Watched("bar@file"))
+
+			ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+			defer cancel()
+
+			var changes []string
+			watcher.Run(ctx, func(name string) { changes = append(changes, name) })
+
+			assert.Len(t, changes, test.expectedChanges)
+			for _, name := range changes {
+				assert.Equal(t, "foo@file", name)
+			}
+			assert.Positive(t, ns.queries.Load())
+		})
+	}
+}
+
+func TestServersWatcher_Watch(t *testing.T) {
+	ns := startNameserver(t, 0, map[uint16][]string{dns.TypeA: {"10.0.0.1", "10.0.0.2"}})
+
+	resolver, err := New(&dynamic.ResolverConfig{Nameservers: []string{ns.addr}})
+	require.NoError(t, err)
+
+	watcher := NewServersWatcher()
+	watcher.interval = 10 * time.Millisecond
+	watcher.changeDelay = 0
+	watcher.minChangeInterval = 0
+	watcher.Watch("foo@file", resolver, "example.com", nil)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+
+	// Watching the host again with its new addresses, as when the servers are expanded again, ends the change.
+	var changes int
+	watcher.Run(ctx, func(name string) {
+		changes++
+		watcher.Watch(name, resolver, "example.com", []netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.1")})
+	})
+
+	assert.Equal(t, 1, changes)
+}
@@ -118,7 +118,9 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 					continue
 				}
 
-				conf.TLS.Certificates = append(conf.TLS.Certificates, cert)
+				certAndStores := *cert
+				certAndStores.Source = pvd
+				conf.TLS.Certificates = append(conf.TLS.Certificates, &certAndStores)
 			}
 
 			for key, store := range configuration.TLS.Stores {
@@ -213,6 +215,14 @@ func applyModel(cfg dynamic.Configuration) dynamic.Configuration {
 						cp.Observability.TraceVerbosity = m.Observability.TraceVerbosity
 					}
 
+					if cp.Observability.SampleRate == nil {
+						cp.Observability.SampleRate = m.Observability.SampleRate
+					}
+
+					if cp.Observability.HonorParentDecision == nil {
+						cp.Observability.HonorParentDecision = m.Observability.HonorParentDecision
+					}
+
 					rtName := name
 					if len(eps) > 1 {
 						rtName = epName + "-" + name
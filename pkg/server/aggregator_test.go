@@ -157,6 +157,7 @@ func Test_mergeConfiguration_tlsCertificates(t *testing.T) {
 			expected: []*tls.CertAndStores{{
 				Certificate: tls.Certificate{CertFile: "foo", KeyFile: "bar"},
 				Stores:      []string{tlsalpn01.ACMETLS1Protocol},
+				Source:      "tlsalpn.acme",
 			}},
 		},
 	}
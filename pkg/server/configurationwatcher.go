@@ -25,6 +25,8 @@ type ConfigurationWatcher struct {
 
 	newConfigs chan dynamic.Configurations
 
+	refresh chan struct{}
+
 	requiredProvider       string
 	configurationListeners []func(dynamic.Configuration)
 
@@ -42,6 +44,7 @@ func NewConfigurationWatcher(
 		providerAggregator:  pvd,
 		allProvidersConfigs: make(chan dynamic.Message, 100),
 		newConfigs:          make(chan dynamic.Configurations),
+		refresh:             make(chan struct{}, 1),
 		routinesPool:        routinesPool,
 		defaultEntryPoints:  defaultEntryPoints,
 		requiredProvider:    requiredProvider,
@@ -69,6 +72,15 @@ func (c *ConfigurationWatcher) AddListener(listener func(dynamic.Configuration))
 	c.configurationListeners = append(c.configurationListeners, listener)
 }
 
+// Refresh applies the current configuration again to the listeners,
+// e.g. to rebuild the routers when a local plugin has been rebuilt.
+func (c *ConfigurationWatcher) Refresh() {
+	select {
+	case c.refresh <- struct{}{}:
+	default:
+	}
+}
+
 func (c *ConfigurationWatcher) startProviderAggregator() {
 	log.Info().Msgf("Starting provider aggregator %T", c.providerAggregator)
 
@@ -165,18 +177,29 @@ func (c *ConfigurationWatcher) applyConfigurations(ctx context.Context) {
 				continue
 			}
 
-			conf := mergeConfiguration(newConfigs.DeepCopy(), c.defaultEntryPoints)
-			conf = applyModel(conf)
+			c.notifyListeners(newConfigs)
+
+			lastConfigurations = newConfigs
 
-			for _, listener := range c.configurationListeners {
-				listener(conf)
+		case <-c.refresh:
+			if lastConfigurations == nil {
+				continue
 			}
 
-			lastConfigurations = newConfigs
+			c.notifyListeners(lastConfigurations)
 		}
 	}
 }
 
+func (c *ConfigurationWatcher) notifyListeners(configurations dynamic.Configurations) {
+	conf := mergeConfiguration(configurations.DeepCopy(), c.defaultEntryPoints)
+	conf = applyModel(conf)
+
+	for _, listener := range c.configurationListeners {
+		listener(conf)
+	}
+}
+
 func logConfiguration(logger zerolog.Logger, configMsg dynamic.Message) {
 	if logger.GetLevel() > zerolog.DebugLevel {
 		return
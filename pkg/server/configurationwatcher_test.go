@@ -404,6 +404,50 @@ func TestListenProvidersSkipsSameConfigurationForProvider(t *testing.T) {
 	assert.Equal(t, 1, configurationReloads, "Same configuration should not be published multiple times")
 }
 
+func TestRefreshAppliesSameConfiguration(t *testing.T) {
+	routinesPool := safe.NewPool(t.Context())
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{{
+			ProviderName: "mock",
+			Configuration: &dynamic.Configuration{
+				HTTP: th.BuildConfiguration(
+					th.WithRouters(th.WithRouter("foo", th.WithEntryPoints("ep"))),
+					th.WithLoadBalancerServices(th.WithService("bar")),
+				),
+			},
+		}},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+
+	reloads := make(chan dynamic.Configuration, 2)
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		reloads <- conf
+	})
+
+	watcher.Start()
+
+	t.Cleanup(watcher.Stop)
+	t.Cleanup(routinesPool.Stop)
+
+	var first dynamic.Configuration
+	select {
+	case first = <-reloads:
+	case <-time.After(time.Second):
+		t.Fatal("the configuration has not been applied")
+	}
+
+	watcher.Refresh()
+
+	select {
+	case conf := <-reloads:
+		assert.Equal(t, first, conf)
+	case <-time.After(time.Second):
+		t.Fatal("the configuration has not been refreshed")
+	}
+}
+
 func TestListenProvidersDoesNotSkipFlappingConfiguration(t *testing.T) {
 	routinesPool := safe.NewPool(t.Context())
 
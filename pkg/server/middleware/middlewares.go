@@ -12,6 +12,7 @@ import (
 	"github.com/containous/alice"
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares/addprefix"
 	"github.com/apache4/apache4/v3/pkg/middlewares/auth"
 	"github.com/apache4/apache4/v3/pkg/middlewares/buffering"
@@ -23,10 +24,12 @@ import (
 	"github.com/apache4/apache4/v3/pkg/middlewares/gatewayapi/headermodifier"
 	gapiredirect "github.com/apache4/apache4/v3/pkg/middlewares/gatewayapi/redirect"
 	"github.com/apache4/apache4/v3/pkg/middlewares/gatewayapi/urlrewrite"
+	"github.com/apache4/apache4/v3/pkg/middlewares/geoip"
 	"github.com/apache4/apache4/v3/pkg/middlewares/grpcweb"
 	"github.com/apache4/apache4/v3/pkg/middlewares/headers"
 	"github.com/apache4/apache4/v3/pkg/middlewares/inflightreq"
 	"github.com/apache4/apache4/v3/pkg/middlewares/ipallowlist"
+	"github.com/apache4/apache4/v3/pkg/middlewares/ipdenylist"
 	"github.com/apache4/apache4/v3/pkg/middlewares/ipwhitelist"
 	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
 	"github.com/apache4/apache4/v3/pkg/middlewares/passtlsclientcert"
@@ -35,6 +38,7 @@ import (
 	"github.com/apache4/apache4/v3/pkg/middlewares/replacepath"
 	"github.com/apache4/apache4/v3/pkg/middlewares/replacepathregex"
 	"github.com/apache4/apache4/v3/pkg/middlewares/retry"
+	"github.com/apache4/apache4/v3/pkg/middlewares/rewritebody"
 	"github.com/apache4/apache4/v3/pkg/middlewares/stripprefix"
 	"github.com/apache4/apache4/v3/pkg/middlewares/stripprefixregex"
 	"github.com/apache4/apache4/v3/pkg/server/provider"
@@ -48,9 +52,10 @@ const (
 
 // Builder the middleware builder.
 type Builder struct {
-	configs        map[string]*runtime.MiddlewareInfo
-	pluginBuilder  PluginsBuilder
-	serviceBuilder serviceBuilder
+	configs         map[string]*runtime.MiddlewareInfo
+	pluginBuilder   PluginsBuilder
+	serviceBuilder  serviceBuilder
+	metricsRegistry metrics.Registry
 }
 
 type serviceBuilder interface {
@@ -58,8 +63,8 @@ type serviceBuilder interface {
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder) *Builder {
-	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder}
+func NewBuilder(configs map[string]*runtime.MiddlewareInfo, serviceBuilder serviceBuilder, pluginBuilder PluginsBuilder, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, serviceBuilder: serviceBuilder, pluginBuilder: pluginBuilder, metricsRegistry: metricsRegistry}
 }
 
 // BuildChain creates a middleware chain.
@@ -168,7 +173,7 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, middlewareName)
+			return circuitbreaker.New(ctx, next, *config.CircuitBreaker, b.metricsRegistry, config, middlewareName)
 		}
 	}
 
@@ -222,6 +227,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// GeoIP
+	if config.GeoIP != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return geoip.New(ctx, next, *config.GeoIP, middlewareName)
+		}
+	}
+
 	// GrpcWeb
 	if config.GrpcWeb != nil {
 		if middleware != nil {
@@ -261,7 +276,17 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 			return nil, badConf
 		}
 		middleware = func(next http.Handler) (http.Handler, error) {
-			return ipallowlist.New(ctx, next, *config.IPAllowList, middlewareName)
+			return ipallowlist.New(ctx, next, *config.IPAllowList, b.metricsRegistry, config, middlewareName)
+		}
+	}
+
+	// IPDenyList
+	if config.IPDenyList != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return ipdenylist.New(ctx, next, *config.IPDenyList, b.metricsRegistry, config, middlewareName)
 		}
 	}
 
@@ -335,6 +360,16 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// RewriteBody
+	if config.RewriteBody != nil {
+		if middleware != nil {
+			return nil, badConf
+		}
+		middleware = func(next http.Handler) (http.Handler, error) {
+			return rewritebody.New(ctx, next, *config.RewriteBody, middlewareName)
+		}
+	}
+
 	// Retry
 	if config.Retry != nil {
 		if middleware != nil {
@@ -126,7 +126,16 @@ func (o *ObservabilityMgr) RotateAccessLogs() error {
 }
 
 func (o *ObservabilityMgr) observabilityContextHandler(next http.Handler, internal bool, config dynamic.RouterObservabilityConfig) http.Handler {
-	return observability.WithObservabilityHandler(next, observability.Observability{
+	// The sampling rule is used by the tracing sampler when the entry point span starts.
+	samplingRule := tracing.SamplingRule{
+		Rate:                config.SampleRate,
+		HonorParentDecision: config.HonorParentDecision,
+	}
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(rw, req.WithContext(tracing.WithSamplingRule(req.Context(), samplingRule)))
+	})
+
+	return observability.WithObservabilityHandler(handler, observability.Observability{
 		AccessLogsEnabled:      o.shouldAccessLog(internal, config),
 		MetricsEnabled:         o.shouldMeter(internal, config),
 		SemConvMetricsEnabled:  o.shouldMeterSemConv(internal, config),
@@ -3,13 +3,16 @@ package tcpmiddleware
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"slices"
 	"strings"
 
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares/tcp/inflightconn"
 	"github.com/apache4/apache4/v3/pkg/middlewares/tcp/ipallowlist"
+	"github.com/apache4/apache4/v3/pkg/middlewares/tcp/ipdenylist"
 	"github.com/apache4/apache4/v3/pkg/middlewares/tcp/ipwhitelist"
 	"github.com/apache4/apache4/v3/pkg/server/provider"
 	"github.com/apache4/apache4/v3/pkg/tcp"
@@ -23,12 +26,14 @@ const (
 
 // Builder the middleware builder.
 type Builder struct {
-	configs map[string]*runtime.TCPMiddlewareInfo
+	configs         map[string]*runtime.TCPMiddlewareInfo
+	pluginBuilder   PluginsBuilder
+	metricsRegistry metrics.Registry
 }
 
 // NewBuilder creates a new Builder.
-func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo) *Builder {
-	return &Builder{configs: configs}
+func NewBuilder(configs map[string]*runtime.TCPMiddlewareInfo, pluginBuilder PluginsBuilder, metricsRegistry metrics.Registry) *Builder {
+	return &Builder{configs: configs, pluginBuilder: pluginBuilder, metricsRegistry: metricsRegistry}
 }
 
 // BuildChain creates a middleware chain.
@@ -109,7 +114,31 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 	// IPAllowList
 	if config.IPAllowList != nil {
 		middleware = func(next tcp.Handler) (tcp.Handler, error) {
-			return ipallowlist.New(ctx, next, *config.IPAllowList, middlewareName)
+			return ipallowlist.New(ctx, next, *config.IPAllowList, b.metricsRegistry, config, middlewareName)
+		}
+	}
+
+	// IPDenyList
+	if config.IPDenyList != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ipdenylist.New(ctx, next, *config.IPDenyList, b.metricsRegistry, config, middlewareName)
+		}
+	}
+
+	// Plugin
+	if config.Plugin != nil && b.pluginBuilder != nil && !reflect.ValueOf(b.pluginBuilder).IsNil() { // Using "reflect" because "b.pluginBuilder" is an interface.
+		pluginType, rawPluginConfig, err := findPluginConfig(config.Plugin)
+		if err != nil {
+			return nil, fmt.Errorf("plugin: %w", err)
+		}
+
+		plug, err := b.pluginBuilder.BuildTCP(pluginType, rawPluginConfig, middlewareName)
+		if err != nil {
+			return nil, fmt.Errorf("plugin: %w", err)
+		}
+
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return plug(ctx, next)
 		}
 	}
 
@@ -0,0 +1,33 @@
+package tcpmiddleware
+
+import (
+	"errors"
+
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/plugins"
+)
+
+// PluginsBuilder the TCP plugin's builder interface.
+type PluginsBuilder interface {
+	BuildTCP(pName string, config map[string]interface{}, middlewareName string) (plugins.TCPConstructor, error)
+}
+
+func findPluginConfig(rawConfig map[string]dynamic.PluginConf) (string, map[string]interface{}, error) {
+	if len(rawConfig) != 1 {
+		return "", nil, errors.New("invalid configuration: no configuration or too many plugin definition")
+	}
+
+	var pluginType string
+	var rawPluginConfig map[string]interface{}
+
+	for pType, pConfig := range rawConfig {
+		pluginType = pType
+		rawPluginConfig = pConfig
+	}
+
+	if pluginType == "" {
+		return "", nil, errors.New("missing plugin type")
+	}
+
+	return pluginType, rawPluginConfig, nil
+}
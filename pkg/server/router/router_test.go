@@ -18,6 +18,7 @@ import (
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
 	"github.com/apache4/apache4/v3/pkg/middlewares/requestdecorator"
 	httpmuxer "github.com/apache4/apache4/v3/pkg/muxer/http"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/server/middleware"
 	"github.com/apache4/apache4/v3/pkg/server/service"
 	"github.com/apache4/apache4/v3/pkg/testhelpers"
@@ -324,7 +325,7 @@ func TestRouterManager_Get(t *testing.T) {
 			transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 			serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, proxyBuilderMock{})
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 			tlsManager := apache4tls.NewManager(nil)
 
 			parser, err := httpmuxer.NewSyntaxParser()
@@ -711,7 +712,7 @@ func TestRuntimeConfiguration(t *testing.T) {
 			transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 			serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, proxyBuilderMock{})
-			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+			middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 			tlsManager := apache4tls.NewManager(nil)
 			tlsManager.UpdateConfigs(t.Context(), nil, test.tlsOptions, nil)
 
@@ -793,7 +794,7 @@ func TestProviderOnMiddlewares(t *testing.T) {
 	transportManager.Update(map[string]*dynamic.ServersTransport{"default@internal": {}})
 
 	serviceManager := service.NewManager(rtConf.Services, nil, nil, transportManager, nil)
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 	tlsManager := apache4tls.NewManager(nil)
 
 	parser, err := httpmuxer.NewSyntaxParser()
@@ -825,6 +826,10 @@ func (s staticTransportManager) Get(_ string) (*dynamic.ServersTransport, error)
 	panic("implement me")
 }
 
+func (s staticTransportManager) GetResolver(_ string) (*resolver.Resolver, error) {
+	panic("implement me")
+}
+
 type staticTransport struct {
 	res *http.Response
 }
@@ -872,7 +877,7 @@ func BenchmarkRouterServe(b *testing.B) {
 	})
 
 	serviceManager := service.NewManager(rtConf.Services, nil, nil, staticTransportManager{res}, nil)
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, nil, nil)
 	tlsManager := apache4tls.NewManager(nil)
 
 	parser, err := httpmuxer.NewSyntaxParser()
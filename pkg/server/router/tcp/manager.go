@@ -316,6 +316,8 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 				logger.Error().Err(err).Send()
 				continue
 			}
+
+			handler = newRouterObservability(routerName, routerConfig.Service, routerTracing(routerConfig), handler)
 		}
 
 		if routerConfig.TLS == nil {
@@ -397,6 +399,9 @@ func (m *Manager) addTCPHandlers(ctx context.Context, configs map[string]*runtim
 			Config: tlsConf,
 		}
 
+		// The router observability wraps the TLS handler for the handshake to be traced.
+		handler = newRouterObservability(routerName, routerConfig.Service, routerTracing(routerConfig), handler)
+
 		logger.Debug().Msgf("Adding TLS route for %q", routerConfig.Rule)
 
 		if err := router.muxerTCPTLS.AddRoute(routerConfig.Rule, routerConfig.RuleSyntax, routerConfig.Priority, handler); err != nil {
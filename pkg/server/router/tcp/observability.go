@@ -0,0 +1,58 @@
+package tcp
+
+import (
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/tcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// routerObservability traces the connections handled by a TCP router,
+// starting the span of the connection if it is not started yet.
+type routerObservability struct {
+	router  string
+	service string
+	tracing *bool
+	next    tcp.Handler
+}
+
+func newRouterObservability(router, service string, tracing *bool, next tcp.Handler) tcp.Handler {
+	return &routerObservability{
+		router:  router,
+		service: service,
+		tracing: tracing,
+		next:    next,
+	}
+}
+
+func (r *routerObservability) ServeTCP(conn tcp.WriteCloser) {
+	observedConn := tcp.ObservedConnFrom(conn)
+	if observedConn == nil || !observedConn.TracingEnabled(r.tracing) {
+		r.next.ServeTCP(conn)
+		return
+	}
+
+	observedConn.Start()
+	defer observedConn.End()
+
+	ctx, span := tcp.StartSpan(conn, "Router", trace.WithSpanKind(trace.SpanKindInternal))
+	if span == nil {
+		r.next.ServeTCP(conn)
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(attribute.String("apache4.router.name", r.router))
+	span.SetAttributes(attribute.String("apache4.service.name", r.service))
+
+	r.next.ServeTCP(tcp.WithContext(conn, ctx))
+}
+
+// routerTracing returns the tracing option of the router, nil if it is not set.
+func routerTracing(router *runtime.TCPRouterInfo) *bool {
+	if router.Observability == nil {
+		return nil
+	}
+
+	return router.Observability.Tracing
+}
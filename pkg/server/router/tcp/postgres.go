@@ -9,7 +9,6 @@ import (
 	"sync"
 
 	"github.com/rs/zerolog/log"
-	tcpmuxer "github.com/apache4/apache4/v3/pkg/muxer/tcp"
 	"github.com/apache4/apache4/v3/pkg/tcp"
 )
 
@@ -70,7 +69,7 @@ func (r *Router) servePostgres(conn tcp.WriteCloser) {
 		return
 	}
 
-	connData, err := tcpmuxer.NewConnData(hello.serverName, conn, hello.protos)
+	connData, err := r.newConnData(hello.serverName, conn, hello.protos)
 	if err != nil {
 		log.Error().Err(err).Msg("Error while reading TCP connection data")
 		conn.Close()
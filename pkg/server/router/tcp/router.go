@@ -13,6 +13,7 @@ import (
 
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	tcpmuxer "github.com/apache4/apache4/v3/pkg/muxer/tcp"
 	"github.com/apache4/apache4/v3/pkg/tcp"
 )
@@ -46,6 +47,9 @@ type Router struct {
 	// hostHTTPTLSConfig contains TLS configs keyed by SNI.
 	// A nil config is the hint to set up a brokenTLSRouter.
 	hostHTTPTLSConfig map[string]*tls.Config // TLS configs keyed by SNI
+
+	// geoIPDatabase gives the country of the clients to the ClientCountry matchers.
+	geoIPDatabase *geoip.Database
 }
 
 // NewRouter returns a new TCP router.
@@ -91,7 +95,7 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 	// we would block forever on clientHelloInfo,
 	// which is why we want to detect and handle that case first and foremost.
 	if r.muxerTCP.HasRoutes() && !r.muxerTCPTLS.HasRoutes() && !r.muxerHTTPS.HasRoutes() {
-		connData, err := tcpmuxer.NewConnData("", conn, nil)
+		connData, err := r.newConnData("", conn, nil)
 		if err != nil {
 			log.Error().Err(err).Msg("Error while reading TCP connection data")
 			conn.Close()
@@ -145,7 +149,7 @@ func (r *Router) ServeTCP(conn tcp.WriteCloser) {
 		log.Error().Err(err).Msg("Error while setting deadline")
 	}
 
-	connData, err := tcpmuxer.NewConnData(hello.serverName, conn, hello.protos)
+	connData, err := r.newConnData(hello.serverName, conn, hello.protos)
 	if err != nil {
 		log.Error().Err(err).Msg("Error while reading TCP connection data")
 		conn.Close()
@@ -261,6 +265,39 @@ func (r *Router) GetHTTPSHandler() http.Handler {
 	return r.httpsHandler
 }
 
+// SetGeoIPDatabase sets the database giving the country of the clients.
+func (r *Router) SetGeoIPDatabase(db *geoip.Database) {
+	r.geoIPDatabase = db
+}
+
+// newConnData builds the connection data, including the country of the client when a GeoIP database is set.
+func (r *Router) newConnData(serverName string, conn tcp.WriteCloser, alpnProtos []string) (tcpmuxer.ConnData, error) {
+	// The routing data are recorded for the connection to be traced with them.
+	if observedConn := tcp.ObservedConnFrom(conn); observedConn != nil {
+		observedConn.SetRoutingData(serverName, alpnProtos)
+	}
+
+	connData, err := tcpmuxer.NewConnData(serverName, conn, alpnProtos)
+	if err != nil || r.geoIPDatabase == nil {
+		return connData, err
+	}
+
+	// The clients of a unix socket have no remote IP.
+	if _, ok := conn.RemoteAddr().(*net.UnixAddr); ok {
+		return connData, nil
+	}
+
+	record, err := r.geoIPDatabase.LookupHost(conn.RemoteAddr().String())
+	if err != nil {
+		log.Debug().Err(err).Msg("Unable to look up the client country")
+		return connData, nil
+	}
+
+	connData.SetClientCountry(record.Country)
+
+	return connData, nil
+}
+
 // SetHTTPForwarder sets the tcp handler that will forward the connections to an http handler.
 func (r *Router) SetHTTPForwarder(handler tcp.Handler) {
 	r.httpForwarder = handler
@@ -336,6 +373,11 @@ type Conn struct {
 	tcp.WriteCloser
 }
 
+// NetConn returns the underlying connection, without the peeked bytes.
+func (c *Conn) NetConn() net.Conn {
+	return c.WriteCloser
+}
+
 // Read reads bytes from the connection (using the buffer prior to actually reading).
 func (c *Conn) Read(p []byte) (n int, err error) {
 	if len(c.Peeked) > 0 {
@@ -21,6 +21,12 @@ import (
 	"github.com/apache4/apache4/v3/pkg/udp"
 )
 
+// PluginsBuilder the builder of the HTTP and TCP middleware plugins.
+type PluginsBuilder interface {
+	middleware.PluginsBuilder
+	tcpmiddleware.PluginsBuilder
+}
+
 // RouterFactory the factory of TCP/UDP routers.
 type RouterFactory struct {
 	entryPointsTCP  []string
@@ -29,7 +35,7 @@ type RouterFactory struct {
 
 	managerFactory *service.ManagerFactory
 
-	pluginBuilder middleware.PluginsBuilder
+	pluginBuilder PluginsBuilder
 
 	observabilityMgr *middleware.ObservabilityMgr
 	tlsManager       *tls.Manager
@@ -43,7 +49,7 @@ type RouterFactory struct {
 
 // NewRouterFactory creates a new RouterFactory.
 func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *service.ManagerFactory, tlsManager *tls.Manager,
-	observabilityMgr *middleware.ObservabilityMgr, pluginBuilder middleware.PluginsBuilder, dialerManager *tcp.DialerManager,
+	observabilityMgr *middleware.ObservabilityMgr, pluginBuilder PluginsBuilder, dialerManager *tcp.DialerManager,
 ) (*RouterFactory, error) {
 	handlesTLSChallenge := false
 	for _, resolver := range staticConfiguration.CertificatesResolvers {
@@ -101,7 +107,7 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	// HTTP
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder, f.observabilityMgr.MetricsRegistry())
 
 	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, f.observabilityMgr, f.tlsManager, f.parser)
 
@@ -113,7 +119,7 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	// TCP
 	svcTCPManager := tcpsvc.NewManager(rtConf, f.dialerManager)
 
-	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares)
+	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares, f.pluginBuilder, f.observabilityMgr.MetricsRegistry())
 
 	rtTCPManager := tcprouter.NewManager(rtConf, svcTCPManager, middlewaresTCPBuilder, handlersNonTLS, handlersTLS, f.tlsManager)
 	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
@@ -129,6 +135,9 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager)
 	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
 
+	serviceManager.WatchServers(ctx)
+	svcTCPManager.WatchServers(ctx)
+
 	rtConf.PopulateUsedBy()
 
 	return routersTCP, routersUDP
@@ -21,6 +21,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/geoip"
 	"github.com/apache4/apache4/v3/pkg/ip"
 	"github.com/apache4/apache4/v3/pkg/logs"
 	"github.com/apache4/apache4/v3/pkg/metrics"
@@ -28,11 +29,14 @@ import (
 	"github.com/apache4/apache4/v3/pkg/middlewares/contenttype"
 	"github.com/apache4/apache4/v3/pkg/middlewares/forwardedheaders"
 	"github.com/apache4/apache4/v3/pkg/middlewares/requestdecorator"
+	"github.com/apache4/apache4/v3/pkg/middlewares/requestid"
 	"github.com/apache4/apache4/v3/pkg/safe"
 	tcprouter "github.com/apache4/apache4/v3/pkg/server/router/tcp"
 	"github.com/apache4/apache4/v3/pkg/server/service"
 	"github.com/apache4/apache4/v3/pkg/tcp"
+	"github.com/apache4/apache4/v3/pkg/tracing"
 	"github.com/apache4/apache4/v3/pkg/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type key string
@@ -87,7 +91,7 @@ func (h *httpForwarder) Accept() (net.Conn, error) {
 type TCPEntryPoints map[string]*TCPEntryPoint
 
 // NewTCPEntryPoints creates a new TCPEntryPoints.
-func NewTCPEntryPoints(entryPointsConfig static.EntryPoints, hostResolverConfig *types.HostResolverConfig, metricsRegistry metrics.Registry) (TCPEntryPoints, error) {
+func NewTCPEntryPoints(entryPointsConfig static.EntryPoints, hostResolverConfig *types.HostResolverConfig, geoIPDatabase *geoip.Database, metricsRegistry metrics.Registry, tracer *tracing.Tracer) (TCPEntryPoints, error) {
 	if os.Getenv(debugConnectionEnv) != "" {
 		expvar.Publish("clientConnectionStates", expvar.Func(func() any {
 			return clientConnectionStates
@@ -101,7 +105,7 @@ func NewTCPEntryPoints(entryPointsConfig static.EntryPoints, hostResolverConfig
 			return nil, fmt.Errorf("error while building entryPoint %s: %w", entryPointName, err)
 		}
 
-		if protocol != "tcp" {
+		if protocol != "tcp" && protocol != "unix" {
 			continue
 		}
 
@@ -111,10 +115,20 @@ func NewTCPEntryPoints(entryPointsConfig static.EntryPoints, hostResolverConfig
 			OpenConnectionsGauge().
 			With("entrypoint", entryPointName, "protocol", "TCP")
 
-		serverEntryPointsTCP[entryPointName], err = NewTCPEntryPoint(ctx, entryPointName, config, hostResolverConfig, openConnectionsGauge)
+		http2AbuseCounter := metricsRegistry.
+			HTTP2AbuseClosedConnectionsCounter().
+			With("entrypoint", entryPointName)
+
+		serverEntryPointsTCP[entryPointName], err = NewTCPEntryPoint(ctx, entryPointName, config, hostResolverConfig, geoIPDatabase, openConnectionsGauge, http2AbuseCounter)
 		if err != nil {
 			return nil, fmt.Errorf("error while building entryPoint %s: %w", entryPointName, err)
 		}
+
+		// The connections are observed as soon as tracing is enabled, to be traced by the TCP routers enabling it.
+		if tracer != nil {
+			serverEntryPointsTCP[entryPointName].tracer = tracer
+			serverEntryPointsTCP[entryPointName].connectionTracing = config.Observability.ConnectionTracingEnabled()
+		}
 	}
 	return serverEntryPointsTCP, nil
 }
@@ -162,12 +176,16 @@ type TCPEntryPoint struct {
 	tracker                *connectionTracker
 	httpServer             *httpServer
 	httpsServer            *httpServer
+	geoIPDatabase          *geoip.Database
+	name                   string
+	tracer                 trace.Tracer
+	connectionTracing      bool
 
 	http3Server *http3server
 }
 
 // NewTCPEntryPoint creates a new TCPEntryPoint.
-func NewTCPEntryPoint(ctx context.Context, name string, config *static.EntryPoint, hostResolverConfig *types.HostResolverConfig, openConnectionsGauge gokitmetrics.Gauge) (*TCPEntryPoint, error) {
+func NewTCPEntryPoint(ctx context.Context, name string, config *static.EntryPoint, hostResolverConfig *types.HostResolverConfig, geoIPDatabase *geoip.Database, openConnectionsGauge gokitmetrics.Gauge, http2AbuseCounter gokitmetrics.Counter) (*TCPEntryPoint, error) {
 	tracker := newConnectionTracker(openConnectionsGauge)
 
 	listener, err := buildListener(ctx, name, config)
@@ -180,16 +198,18 @@ func NewTCPEntryPoint(ctx context.Context, name string, config *static.EntryPoin
 		return nil, fmt.Errorf("error preparing tcp router: %w", err)
 	}
 
+	rt.SetGeoIPDatabase(geoIPDatabase)
+
 	reqDecorator := requestdecorator.New(hostResolverConfig)
 
-	httpServer, err := createHTTPServer(ctx, listener, config, true, reqDecorator)
+	httpServer, err := createHTTPServer(ctx, listener, config, true, reqDecorator, geoIPDatabase, http2AbuseCounter)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing http server: %w", err)
 	}
 
 	rt.SetHTTPForwarder(httpServer.Forwarder)
 
-	httpsServer, err := createHTTPServer(ctx, listener, config, false, reqDecorator)
+	httpsServer, err := createHTTPServer(ctx, listener, config, false, reqDecorator, geoIPDatabase, http2AbuseCounter)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing https server: %w", err)
 	}
@@ -211,6 +231,8 @@ func NewTCPEntryPoint(ctx context.Context, name string, config *static.EntryPoin
 		tracker:                tracker,
 		httpServer:             httpServer,
 		httpsServer:            httpsServer,
+		geoIPDatabase:          geoIPDatabase,
+		name:                   name,
 		http3Server:            h3Server,
 	}, nil
 }
@@ -268,7 +290,12 @@ func (e *TCPEntryPoint) Start(ctx context.Context) {
 				}
 			}
 
-			e.switcher.ServeTCP(newTrackedConnection(writeCloser, e.tracker))
+			var trackedConn tcp.WriteCloser = newTrackedConnection(writeCloser, e.tracker)
+			if e.tracer != nil {
+				trackedConn = tcp.NewObservedConn(trackedConn, e.tracer, e.name, e.connectionTracing)
+			}
+
+			e.switcher.ServeTCP(trackedConn)
 		})
 	}
 }
@@ -346,6 +373,8 @@ func (e *TCPEntryPoint) Shutdown(ctx context.Context) {
 
 // SwitchRouter switches the TCP router handler.
 func (e *TCPEntryPoint) SwitchRouter(rt *tcprouter.Router) {
+	rt.SetGeoIPDatabase(e.geoIPDatabase)
+
 	rt.SetHTTPForwarder(e.httpServer.Forwarder)
 
 	httpHandler := rt.GetHTTPHandler()
@@ -387,13 +416,17 @@ func (c *writeCloserWrapper) CloseWrite() error {
 func writeCloser(conn net.Conn) (tcp.WriteCloser, error) {
 	switch typedConn := conn.(type) {
 	case *proxyproto.Conn:
-		underlying, ok := typedConn.TCPConn()
-		if !ok {
-			return nil, errors.New("underlying connection is not a tcp connection")
+		if underlying, ok := typedConn.TCPConn(); ok {
+			return &writeCloserWrapper{writeCloser: underlying, Conn: typedConn}, nil
 		}
-		return &writeCloserWrapper{writeCloser: underlying, Conn: typedConn}, nil
+		if underlying, ok := typedConn.UnixConn(); ok {
+			return &writeCloserWrapper{writeCloser: underlying, Conn: typedConn}, nil
+		}
+		return nil, errors.New("underlying connection is neither a tcp nor a unix connection")
 	case *net.TCPConn:
 		return typedConn, nil
+	case *net.UnixConn:
+		return typedConn, nil
 	default:
 		return nil, fmt.Errorf("unknown connection type %T", typedConn)
 	}
@@ -444,6 +477,12 @@ func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoi
 	}
 
 	proxyListener.Policy = func(upstream net.Addr) (proxyproto.Policy, error) {
+		if _, ok := upstream.(*net.UnixAddr); ok {
+			// The clients of a unix socket have no IP address to check against the trusted IPs.
+			log.Ctx(ctx).Debug().Msg("Unix socket clients are not trusted without the insecure option, ignoring ProxyProtocol Headers")
+			return proxyproto.IGNORE, nil
+		}
+
 		ipAddr, ok := upstream.(*net.TCPAddr)
 		if !ok {
 			return proxyproto.REJECT, fmt.Errorf("type error %v", upstream)
@@ -473,6 +512,13 @@ func buildListener(ctx context.Context, name string, config *static.EntryPoint)
 		}
 	}
 
+	if protocol, _ := config.GetProtocol(); listener == nil && protocol == "unix" {
+		listener, err = buildUnixListener(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if listener == nil {
 		listenConfig := newListenConfig(config)
 
@@ -488,7 +534,9 @@ func buildListener(ctx context.Context, name string, config *static.EntryPoint)
 		}
 	}
 
-	listener = tcpKeepAliveListener{listener.(*net.TCPListener)}
+	if tcpListener, ok := listener.(*net.TCPListener); ok {
+		listener = tcpKeepAliveListener{tcpListener}
+	}
 
 	if config.ProxyProtocol != nil {
 		listener, err = buildProxyProtocolListener(ctx, config, listener)
@@ -592,14 +640,14 @@ type httpServer struct {
 	Switcher  *middlewares.HTTPHandlerSwitcher
 }
 
-func createHTTPServer(ctx context.Context, ln net.Listener, configuration *static.EntryPoint, withH2c bool, reqDecorator *requestdecorator.RequestDecorator) (*httpServer, error) {
-	if configuration.HTTP2.MaxConcurrentStreams < 0 {
-		return nil, errors.New("max concurrent streams value must be greater than or equal to zero")
+func createHTTPServer(ctx context.Context, ln net.Listener, configuration *static.EntryPoint, withH2c bool, reqDecorator *requestdecorator.RequestDecorator, geoIPDatabase *geoip.Database, http2AbuseCounter gokitmetrics.Counter) (*httpServer, error) {
+	if err := validateHTTP2Config(configuration.HTTP2); err != nil {
+		return nil, err
 	}
 
 	httpSwitcher := middlewares.NewHandlerSwitcher(http.NotFoundHandler())
 
-	next, err := alice.New(requestdecorator.WrapHandler(reqDecorator)).Then(httpSwitcher)
+	next, err := alice.New(requestdecorator.WrapHandler(reqDecorator), geoip.WrapHandler(geoIPDatabase)).Then(httpSwitcher)
 	if err != nil {
 		return nil, err
 	}
@@ -614,6 +662,16 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		return nil, err
 	}
 
+	if reqID := configuration.HTTP.RequestID; reqID != nil {
+		// Unless restricted to the forwarded headers trusted IPs, the incoming request IDs are kept.
+		insecure := !reqID.TrustedIPsOnly || configuration.ForwardedHeaders.Insecure
+
+		handler, err = requestid.New(reqID.HeaderName, reqID.Format, insecure, configuration.ForwardedHeaders.TrustedIPs, handler)
+		if err != nil {
+			return nil, fmt.Errorf("creating request ID handler: %w", err)
+		}
+	}
+
 	debugConnection := os.Getenv(debugConnectionEnv) != ""
 	if debugConnection || (configuration.Transport != nil && (configuration.Transport.KeepAliveMaxTime > 0 || configuration.Transport.KeepAliveMaxRequests > 0)) {
 		handler = newKeepAliveMiddleware(handler, configuration.Transport.KeepAliveMaxRequests, configuration.Transport.KeepAliveMaxTime)
@@ -644,6 +702,10 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 
 	handler = denyFragment(handler)
 
+	if protocol, _ := configuration.GetProtocol(); protocol == "unix" {
+		handler = clearUnixRemoteAddr(handler)
+	}
+
 	serverHTTP := &http.Server{
 		Protocols:      &protocols,
 		Handler:        handler,
@@ -652,9 +714,7 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		WriteTimeout:   time.Duration(configuration.Transport.RespondingTimeouts.WriteTimeout),
 		IdleTimeout:    time.Duration(configuration.Transport.RespondingTimeouts.IdleTimeout),
 		MaxHeaderBytes: configuration.HTTP.MaxHeaderBytes,
-		HTTP2: &http.HTTP2Config{
-			MaxConcurrentStreams: int(configuration.HTTP2.MaxConcurrentStreams),
-		},
+		HTTP2:          newHTTP2Config(configuration.HTTP2),
 	}
 	if debugConnection || (configuration.Transport != nil && (configuration.Transport.KeepAliveMaxTime > 0 || configuration.Transport.KeepAliveMaxRequests > 0)) {
 		serverHTTP.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
@@ -688,9 +748,13 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		return ctx
 	}
 
+	if err := configureHTTP2(ctx, serverHTTP, configuration.HTTP2, withH2c, http2AbuseCounter); err != nil {
+		return nil, err
+	}
+
 	listener := newHTTPForwarder(ln)
 	go func() {
-		err := serverHTTP.Serve(listener)
+		err := serverHTTP.Serve(newH2cAbuseListener(ctx, listener, configuration.HTTP2, withH2c, http2AbuseCounter))
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Ctx(ctx).Error().Err(err).Msg("Error while starting server")
 		}
@@ -0,0 +1,336 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/static"
+	"golang.org/x/net/http2"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// nextProtoUnencryptedHTTP2 is the TLSNextProto key used by the net/http package to pass off the h2c connections.
+	nextProtoUnencryptedHTTP2 = "unencrypted_http2"
+
+	http2FrameHeaderLen = 9
+	http2MinFrameSize   = 1 << 14
+	http2MaxFrameSize   = 1<<24 - 1
+)
+
+const (
+	http2AbuseReasonResetStream  = "reset_stream"
+	http2AbuseReasonControlFrame = "control_frame"
+)
+
+var errHTTP2Abuse = errors.New("connection closed for HTTP/2 abuse")
+
+func validateHTTP2Config(config *static.HTTP2Config) error {
+	if config.MaxConcurrentStreams < 0 {
+		return errors.New("max concurrent streams value must be greater than or equal to zero")
+	}
+
+	if config.MaxHeaderListSize < 0 {
+		return errors.New("max header list size value must be greater than or equal to zero")
+	}
+
+	if config.MaxFrameSize != 0 && (config.MaxFrameSize < http2MinFrameSize || config.MaxFrameSize > http2MaxFrameSize) {
+		return fmt.Errorf("max frame size value must be between %d and %d", http2MinFrameSize, http2MaxFrameSize)
+	}
+
+	if config.InitialStreamWindowSize < 0 {
+		return errors.New("initial stream window size value must be greater than or equal to zero")
+	}
+
+	if config.InitialConnectionWindowSize < 0 {
+		return errors.New("initial connection window size value must be greater than or equal to zero")
+	}
+
+	if config.IdleTimeout < 0 {
+		return errors.New("idle timeout value must be greater than or equal to zero")
+	}
+
+	if config.MaxResetStreamRate < 0 {
+		return errors.New("max reset stream rate value must be greater than or equal to zero")
+	}
+
+	if config.MaxControlFrameRate < 0 {
+		return errors.New("max control frame rate value must be greater than or equal to zero")
+	}
+
+	return nil
+}
+
+// newHTTP2Config returns the standard library HTTP/2 configuration of the server.
+func newHTTP2Config(config *static.HTTP2Config) *http.HTTP2Config {
+	return &http.HTTP2Config{
+		MaxConcurrentStreams:          int(config.MaxConcurrentStreams),
+		MaxReadFrameSize:              int(config.MaxFrameSize),
+		MaxReceiveBufferPerStream:     int(config.InitialStreamWindowSize),
+		MaxReceiveBufferPerConnection: int(config.InitialConnectionWindowSize),
+	}
+}
+
+// hasHTTP2AbuseLimits reports whether the HTTP/2 connections must be inspected for abuse.
+func hasHTTP2AbuseLimits(config *static.HTTP2Config) bool {
+	return config.MaxResetStreamRate > 0 || config.MaxControlFrameRate > 0
+}
+
+// configureHTTP2 sets up the HTTP/2 settings the standard library does not provide,
+// i.e. the max header list size, the idle timeout and the abuse protection of the TLS (h2) connections.
+// Otherwise, the HTTP/2 connections are served by the standard library, with the HTTP2 and Protocols server fields.
+// It must be called once all the other server fields are set.
+func configureHTTP2(ctx context.Context, serverHTTP *http.Server, config *static.HTTP2Config, withH2c bool, abuseCounter gokitmetrics.Counter) error {
+	if config.MaxHeaderListSize == 0 && config.IdleTimeout == 0 && !hasHTTP2AbuseLimits(config) {
+		return nil
+	}
+
+	h2Server := &http2.Server{
+		IdleTimeout: time.Duration(config.IdleTimeout),
+	}
+
+	// ConfigureServer reads the settings from the HTTP2 server field,
+	// and registers the graceful shutdown of the HTTP/2 connections on the server.
+	if err := http2.ConfigureServer(serverHTTP, h2Server); err != nil {
+		return fmt.Errorf("configuring HTTP/2: %w", err)
+	}
+
+	// The HTTP/2 max header list size is read from the HTTP/1 server configuration.
+	baseConfig := serverHTTP
+	if config.MaxHeaderListSize > 0 {
+		baseConfig = &http.Server{
+			ErrorLog:       serverHTTP.ErrorLog,
+			ConnState:      serverHTTP.ConnState,
+			ReadTimeout:    serverHTTP.ReadTimeout,
+			WriteTimeout:   serverHTTP.WriteTimeout,
+			IdleTimeout:    serverHTTP.IdleTimeout,
+			MaxHeaderBytes: int(config.MaxHeaderListSize),
+		}
+	}
+
+	serveH2c := serverHTTP.TLSNextProto[nextProtoUnencryptedHTTP2]
+	delete(serverHTTP.TLSNextProto, nextProtoUnencryptedHTTP2)
+	if withH2c && serveH2c != nil {
+		// The h2c connections are inspected for abuse by the listener, see newH2cAbuseListener.
+		serverHTTP.TLSNextProto[nextProtoUnencryptedHTTP2] = func(_ *http.Server, c *tls.Conn, h http.Handler) {
+			serveH2c(baseConfig, c, h)
+		}
+	}
+
+	serverHTTP.TLSNextProto[http2.NextProtoTLS] = func(_ *http.Server, c *tls.Conn, h http.Handler) {
+		// The net/http package passes down the per-connection base context through the handler.
+		var connCtx context.Context
+		if bc, ok := h.(interface{ BaseContext() context.Context }); ok {
+			connCtx = bc.BaseContext()
+		}
+
+		h2Server.ServeConn(newHTTP2AbuseConn(ctx, c, config, abuseCounter), &http2.ServeConnOpts{
+			Context:    connCtx,
+			Handler:    h,
+			BaseConfig: baseConfig,
+		})
+	}
+
+	return nil
+}
+
+// h2cAbuseListener wraps the cleartext connections accepted by the server,
+// for the h2c connections to be inspected for abuse.
+type h2cAbuseListener struct {
+	net.Listener
+
+	ctx          context.Context
+	config       *static.HTTP2Config
+	abuseCounter gokitmetrics.Counter
+}
+
+// newH2cAbuseListener returns the given listener as is when the h2c connections must not be inspected.
+func newH2cAbuseListener(ctx context.Context, ln net.Listener, config *static.HTTP2Config, withH2c bool, abuseCounter gokitmetrics.Counter) net.Listener {
+	if !withH2c || !hasHTTP2AbuseLimits(config) {
+		return ln
+	}
+
+	return &h2cAbuseListener{
+		Listener:     ln,
+		ctx:          ctx,
+		config:       config,
+		abuseCounter: abuseCounter,
+	}
+}
+
+func (l *h2cAbuseListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	// The TLS connections are inspected once HTTP/2 has been negotiated.
+	if _, ok := conn.(*tls.Conn); ok {
+		return conn, nil
+	}
+
+	return newHTTP2AbuseConn(l.ctx, conn, l.config, l.abuseCounter), nil
+}
+
+// newHTTP2AbuseConn wraps the given connection to close it when the client sends
+// RST_STREAM or control frames faster than allowed by the configuration.
+// The frames are only inspected once the HTTP/2 client preface has been read,
+// and the connection is returned as is when no limit is configured.
+func newHTTP2AbuseConn(ctx context.Context, conn net.Conn, config *static.HTTP2Config, abuseCounter gokitmetrics.Counter) net.Conn {
+	if !hasHTTP2AbuseLimits(config) {
+		return conn
+	}
+
+	c := &http2AbuseConn{
+		Conn:         conn,
+		ctx:          ctx,
+		abuseCounter: abuseCounter,
+	}
+
+	if config.MaxResetStreamRate > 0 {
+		c.resetStreamLimiter = rate.NewLimiter(rate.Limit(config.MaxResetStreamRate), config.MaxResetStreamRate)
+	}
+
+	if config.MaxControlFrameRate > 0 {
+		c.controlFrameLimiter = rate.NewLimiter(rate.Limit(config.MaxControlFrameRate), config.MaxControlFrameRate)
+	}
+
+	// The TLS connection state is used by the HTTP/2 server to check the negotiated TLS version and cipher suite.
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		return &http2AbuseTLSConn{http2AbuseConn: c, tlsConn: tlsConn}
+	}
+
+	return c
+}
+
+// http2AbuseConn inspects the frame headers read from the client connection.
+// The reads are done by the single reading goroutine of the HTTP/2 server connection.
+type http2AbuseConn struct {
+	net.Conn
+
+	ctx          context.Context
+	abuseCounter gokitmetrics.Counter
+
+	resetStreamLimiter  *rate.Limiter
+	controlFrameLimiter *rate.Limiter
+
+	// prefaceRead is the length of the client preface read so far,
+	// and notHTTP2 is set when the connection does not start with it, e.g. for HTTP/1 connections.
+	prefaceRead int
+	notHTTP2    bool
+
+	header           [http2FrameHeaderLen]byte
+	headerLen        int
+	payloadRemaining int
+
+	abuseOnce sync.Once
+}
+
+func (c *http2AbuseConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 && !c.notHTTP2 {
+		if reason := c.inspect(p[:n]); reason != "" {
+			c.closeForAbuse(reason)
+			return 0, errHTTP2Abuse
+		}
+	}
+
+	return n, err
+}
+
+// inspect walks through the given bytes read from the connection,
+// and returns the abuse reason as soon as a frame exceeds its allowed rate.
+func (c *http2AbuseConn) inspect(p []byte) string {
+	for len(p) > 0 {
+		switch {
+		case c.prefaceRead < len(http2.ClientPreface):
+			n := min(len(http2.ClientPreface)-c.prefaceRead, len(p))
+			if string(p[:n]) != http2.ClientPreface[c.prefaceRead:c.prefaceRead+n] {
+				c.notHTTP2 = true
+				return ""
+			}
+
+			c.prefaceRead += n
+			p = p[n:]
+
+		case c.payloadRemaining > 0:
+			skipped := min(c.payloadRemaining, len(p))
+			c.payloadRemaining -= skipped
+			p = p[skipped:]
+
+		default:
+			copied := copy(c.header[c.headerLen:], p)
+			c.headerLen += copied
+			p = p[copied:]
+
+			if c.headerLen < http2FrameHeaderLen {
+				continue
+			}
+
+			c.headerLen = 0
+			c.payloadRemaining = int(c.header[0])<<16 | int(c.header[1])<<8 | int(c.header[2])
+
+			if reason := c.checkFrame(http2.FrameType(c.header[3]), http2.Flags(c.header[4])); reason != "" {
+				return reason
+			}
+		}
+	}
+
+	return ""
+}
+
+func (c *http2AbuseConn) checkFrame(frameType http2.FrameType, flags http2.Flags) string {
+	switch frameType {
+	case http2.FrameRSTStream:
+		if c.resetStreamLimiter != nil && !c.resetStreamLimiter.Allow() {
+			return http2AbuseReasonResetStream
+		}
+
+	case http2.FramePing, http2.FrameSettings, http2.FramePriority:
+		// The PING and SETTINGS acknowledgments are answers to the frames sent by the server.
+		if (frameType == http2.FramePing && flags.Has(http2.FlagPingAck)) ||
+			(frameType == http2.FrameSettings && flags.Has(http2.FlagSettingsAck)) {
+			return ""
+		}
+
+		if c.controlFrameLimiter != nil && !c.controlFrameLimiter.Allow() {
+			return http2AbuseReasonControlFrame
+		}
+	}
+
+	return ""
+}
+
+func (c *http2AbuseConn) closeForAbuse(reason string) {
+	c.abuseOnce.Do(func() {
+		log.Ctx(c.ctx).Debug().
+			Str("remoteAddr", c.RemoteAddr().String()).
+			Str("reason", reason).
+			Msg("Closing HTTP/2 connection for abuse")
+
+		if c.abuseCounter != nil {
+			c.abuseCounter.With("reason", reason).Add(1)
+		}
+
+		_ = c.Conn.Close()
+	})
+}
+
+// http2AbuseTLSConn is an http2AbuseConn exposing the state of the underlying TLS connection.
+type http2AbuseTLSConn struct {
+	*http2AbuseConn
+
+	tlsConn *tls.Conn
+}
+
+func (c *http2AbuseTLSConn) ConnectionState() tls.ConnectionState {
+	return c.tlsConn.ConnectionState()
+}
@@ -0,0 +1,374 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/config/static"
+	tcprouter "github.com/apache4/apache4/v3/pkg/server/router/tcp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+func TestValidateHTTP2Config(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    static.HTTP2Config
+		expectErr bool
+	}{
+		{
+			desc:   "defaults",
+			config: static.HTTP2Config{MaxConcurrentStreams: 250},
+		},
+		{
+			desc: "all settings",
+			config: static.HTTP2Config{
+				MaxConcurrentStreams:        100,
+				MaxHeaderListSize:           16384,
+				MaxFrameSize:                16384,
+				InitialStreamWindowSize:     65535,
+				InitialConnectionWindowSize: 1 << 20,
+				IdleTimeout:                 ptypes.Duration(time.Minute),
+				MaxResetStreamRate:          100,
+				MaxControlFrameRate:         100,
+			},
+		},
+		{
+			desc:      "negative max concurrent streams",
+			config:    static.HTTP2Config{MaxConcurrentStreams: -1},
+			expectErr: true,
+		},
+		{
+			desc:      "negative max header list size",
+			config:    static.HTTP2Config{MaxHeaderListSize: -1},
+			expectErr: true,
+		},
+		{
+			desc:      "max frame size too small",
+			config:    static.HTTP2Config{MaxFrameSize: 1024},
+			expectErr: true,
+		},
+		{
+			desc:      "max frame size too large",
+			config:    static.HTTP2Config{MaxFrameSize: 1 << 24},
+			expectErr: true,
+		},
+		{
+			desc:      "negative initial stream window size",
+			config:    static.HTTP2Config{InitialStreamWindowSize: -1},
+			expectErr: true,
+		},
+		{
+			desc:      "negative initial connection window size",
+			config:    static.HTTP2Config{InitialConnectionWindowSize: -1},
+			expectErr: true,
+		},
+		{
+			desc:      "negative idle timeout",
+			config:    static.HTTP2Config{IdleTimeout: ptypes.Duration(-time.Second)},
+			expectErr: true,
+		},
+		{
+			desc:      "negative max reset stream rate",
+			config:    static.HTTP2Config{MaxResetStreamRate: -1},
+			expectErr: true,
+		},
+		{
+			desc:      "negative max control frame rate",
+			config:    static.HTTP2Config{MaxControlFrameRate: -1},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateHTTP2Config(&test.config)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestHTTP2AbuseConn(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         static.HTTP2Config
+		http1          bool
+		writeFrames    func(fr *http2.Framer)
+		expectedReason string
+	}{
+		{
+			desc:   "no limit",
+			config: static.HTTP2Config{},
+			writeFrames: func(fr *http2.Framer) {
+				for i := range 10 {
+					_ = fr.WriteRSTStream(uint32(2*i+1), http2.ErrCodeCancel)
+				}
+			},
+		},
+		{
+			desc:   "reset streams under the limit",
+			config: static.HTTP2Config{MaxResetStreamRate: 5},
+			writeFrames: func(fr *http2.Framer) {
+				for i := range 5 {
+					_ = fr.WriteData(uint32(2*i+1), false, bytes.Repeat([]byte("a"), 100))
+					_ = fr.WriteRSTStream(uint32(2*i+1), http2.ErrCodeCancel)
+				}
+			},
+		},
+		{
+			desc:   "reset streams over the limit",
+			config: static.HTTP2Config{MaxResetStreamRate: 5},
+			writeFrames: func(fr *http2.Framer) {
+				for i := range 10 {
+					_ = fr.WriteData(uint32(2*i+1), false, bytes.Repeat([]byte("a"), 100))
+					_ = fr.WriteRSTStream(uint32(2*i+1), http2.ErrCodeCancel)
+				}
+			},
+			expectedReason: http2AbuseReasonResetStream,
+		},
+		{
+			desc:   "HTTP/1 connection",
+			config: static.HTTP2Config{MaxResetStreamRate: 5},
+			http1:  true,
+			writeFrames: func(fr *http2.Framer) {
+				for i := range 10 {
+					_ = fr.WriteRSTStream(uint32(2*i+1), http2.ErrCodeCancel)
+				}
+			},
+		},
+		{
+			desc:   "ping flood",
+			config: static.HTTP2Config{MaxControlFrameRate: 5},
+			writeFrames: func(fr *http2.Framer) {
+				for range 10 {
+					_ = fr.WritePing(false, [8]byte{})
+				}
+			},
+			expectedReason: http2AbuseReasonControlFrame,
+		},
+		{
+			desc:   "settings and priority flood",
+			config: static.HTTP2Config{MaxControlFrameRate: 5},
+			writeFrames: func(fr *http2.Framer) {
+				for i := range 5 {
+					_ = fr.WriteSettings()
+					_ = fr.WritePriority(uint32(2*i+1), http2.PriorityParam{Weight: 1})
+				}
+			},
+			expectedReason: http2AbuseReasonControlFrame,
+		},
+		{
+			desc:   "acknowledgments are not limited",
+			config: static.HTTP2Config{MaxControlFrameRate: 5},
+			writeFrames: func(fr *http2.Framer) {
+				for range 10 {
+					_ = fr.WritePing(true, [8]byte{})
+					_ = fr.WriteSettingsAck()
+				}
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var data bytes.Buffer
+			if test.http1 {
+				data.WriteString("POST / HTTP/1.1\r\nHost: foo.com\r\n\r\n")
+			} else {
+				data.WriteString(http2.ClientPreface)
+			}
+			test.writeFrames(http2.NewFramer(&data, nil))
+
+			clientConn, serverConn := net.Pipe()
+			t.Cleanup(func() { _ = clientConn.Close() })
+
+			go func() {
+				_, _ = clientConn.Write(data.Bytes())
+				_ = clientConn.Close()
+			}()
+
+			counter := &collectingCounter{}
+			conn := newHTTP2AbuseConn(t.Context(), serverConn, &test.config, counter)
+
+			// Read small chunks to check the frame headers spanning several reads.
+			buf := make([]byte, 5)
+			var err error
+			for err == nil {
+				_, err = conn.Read(buf)
+			}
+
+			if test.expectedReason == "" {
+				assert.ErrorIs(t, err, io.EOF)
+				assert.Zero(t, counter.value())
+				return
+			}
+
+			assert.ErrorIs(t, err, errHTTP2Abuse)
+			assert.InDelta(t, 1, counter.value(), 0)
+			assert.Equal(t, []string{"reason", test.expectedReason}, counter.labels())
+		})
+	}
+}
+
+func TestHTTP2AbuseH2c(t *testing.T) {
+	epConfig := &static.EntryPointsTransport{}
+	epConfig.SetDefaults()
+
+	counter := &collectingCounter{}
+
+	entryPoint, err := NewTCPEntryPoint(t.Context(), "", &static.EntryPoint{
+		Address:          "127.0.0.1:0",
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		HTTP2:            &static.HTTP2Config{MaxResetStreamRate: 5},
+	}, nil, nil, nil, counter)
+	require.NoError(t, err)
+
+	router, err := tcprouter.NewRouter()
+	require.NoError(t, err)
+
+	router.SetHTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	conn, err := startEntrypoint(t, entryPoint, router)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = conn.Write([]byte(http2.ClientPreface))
+	require.NoError(t, err)
+
+	var headerBlock bytes.Buffer
+	enc := hpack.NewEncoder(&headerBlock)
+	_ = enc.WriteField(hpack.HeaderField{Name: ":method", Value: http.MethodGet})
+	_ = enc.WriteField(hpack.HeaderField{Name: ":scheme", Value: "http"})
+	_ = enc.WriteField(hpack.HeaderField{Name: ":authority", Value: "foo.com"})
+	_ = enc.WriteField(hpack.HeaderField{Name: ":path", Value: "/"})
+
+	fr := http2.NewFramer(conn, conn)
+	require.NoError(t, fr.WriteSettings())
+
+	// Rapid reset: the streams are canceled right after being opened.
+	for i := range 20 {
+		// The writes fail once the connection has been closed by the server.
+		_ = fr.WriteHeaders(http2.HeadersFrameParam{
+			StreamID:      uint32(2*i + 1),
+			BlockFragment: headerBlock.Bytes(),
+			EndStream:     true,
+			EndHeaders:    true,
+		})
+		_ = fr.WriteRSTStream(uint32(2*i+1), http2.ErrCodeCancel)
+	}
+
+	// The connection is either closed or reset by the server.
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.Copy(io.Discard, conn)
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		require.False(t, netErr.Timeout(), "connection not closed by the server")
+	}
+
+	assert.InDelta(t, 1, counter.value(), 0)
+	assert.Equal(t, []string{"reason", http2AbuseReasonResetStream}, counter.labels())
+}
+
+func TestConfigureHTTP2(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        static.HTTP2Config
+		withH2c       bool
+		expectedProto []string
+	}{
+		{
+			desc:   "standard library HTTP/2 server",
+			config: static.HTTP2Config{MaxConcurrentStreams: 10},
+		},
+		{
+			desc:          "with abuse limits",
+			config:        static.HTTP2Config{MaxResetStreamRate: 5},
+			expectedProto: []string{http2.NextProtoTLS},
+		},
+		{
+			desc:          "with abuse limits and h2c",
+			config:        static.HTTP2Config{MaxResetStreamRate: 5},
+			withH2c:       true,
+			expectedProto: []string{http2.NextProtoTLS, nextProtoUnencryptedHTTP2},
+		},
+		{
+			desc:          "with max header list size and h2c",
+			config:        static.HTTP2Config{MaxHeaderListSize: 1024},
+			withH2c:       true,
+			expectedProto: []string{http2.NextProtoTLS, nextProtoUnencryptedHTTP2},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			serverHTTP := &http.Server{HTTP2: newHTTP2Config(&test.config)}
+
+			err := configureHTTP2(t.Context(), serverHTTP, &test.config, test.withH2c, nil)
+			require.NoError(t, err)
+
+			assert.ElementsMatch(t, test.expectedProto, slices.Collect(maps.Keys(serverHTTP.TLSNextProto)))
+		})
+	}
+}
+
+// collectingCounter is a gokitmetrics.Counter keeping track of its value and last label values.
+type collectingCounter struct {
+	mu              sync.Mutex
+	counterValue    float64
+	lastLabelValues []string
+}
+
+func (c *collectingCounter) With(labelValues ...string) gokitmetrics.Counter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastLabelValues = labelValues
+	return c
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.counterValue += delta
+}
+
+func (c *collectingCounter) value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.counterValue
+}
+
+func (c *collectingCounter) labels() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lastLabelValues
+}
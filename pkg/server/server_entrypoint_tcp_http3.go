@@ -33,6 +33,10 @@ func newHTTP3Server(ctx context.Context, name string, config *static.EntryPoint,
 		return nil, nil
 	}
 
+	if protocol, _ := config.GetProtocol(); protocol == "unix" {
+		return nil, errors.New("HTTP/3 is not supported on unix socket entry points")
+	}
+
 	if config.HTTP3.AdvertisedPort < 0 {
 		return nil, errors.New("advertised port must be greater than or equal to zero")
 	}
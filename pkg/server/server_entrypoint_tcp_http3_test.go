@@ -94,7 +94,7 @@ func TestHTTP3AdvertisedPort(t *testing.T) {
 		HTTP3: &static.HTTP3Config{
 			AdvertisedPort: 8080,
 		},
-	}, nil, nil)
+	}, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	router, err := tcprouter.NewRouter()
@@ -156,7 +156,7 @@ func TestHTTP30RTT(t *testing.T) {
 		ForwardedHeaders: &static.ForwardedHeaders{},
 		HTTP2:            &static.HTTP2Config{},
 		HTTP3:            &static.HTTP3Config{},
-	}, nil, nil)
+	}, nil, nil, nil, nil)
 	require.NoError(t, err)
 
 	router, err := tcprouter.NewRouter()
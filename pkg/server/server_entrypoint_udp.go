@@ -9,14 +9,16 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/static"
 	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/tracing"
 	"github.com/apache4/apache4/v3/pkg/udp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // UDPEntryPoints maps UDP entry points by their names.
 type UDPEntryPoints map[string]*UDPEntryPoint
 
 // NewUDPEntryPoints returns all the UDP entry points, keyed by name.
-func NewUDPEntryPoints(config static.EntryPoints) (UDPEntryPoints, error) {
+func NewUDPEntryPoints(config static.EntryPoints, tracer *tracing.Tracer) (UDPEntryPoints, error) {
 	entryPoints := make(UDPEntryPoints)
 	for entryPointName, entryPoint := range config {
 		protocol, err := entryPoint.GetProtocol()
@@ -32,6 +34,10 @@ func NewUDPEntryPoints(config static.EntryPoints) (UDPEntryPoints, error) {
 		if err != nil {
 			return nil, fmt.Errorf("error while building entryPoint %s: %w", entryPointName, err)
 		}
+
+		if tracer != nil && entryPoint.Observability.ConnectionTracingEnabled() {
+			ep.tracer = tracer
+		}
 		entryPoints[entryPointName] = ep
 	}
 	return entryPoints, nil
@@ -82,6 +88,9 @@ type UDPEntryPoint struct {
 	listener               *udp.Listener
 	switcher               *udp.HandlerSwitcher
 	transportConfiguration *static.EntryPointsTransport
+	name                   string
+	// tracer traces the sessions, when the connection tracing is enabled.
+	tracer trace.Tracer
 }
 
 // NewUDPEntryPoint returns a UDP entry point.
@@ -111,7 +120,7 @@ func NewUDPEntryPoint(config *static.EntryPoint, name string) (*UDPEntryPoint, e
 		}
 	}
 
-	return &UDPEntryPoint{listener: listener, switcher: &udp.HandlerSwitcher{}, transportConfiguration: config.Transport}, nil
+	return &UDPEntryPoint{listener: listener, switcher: &udp.HandlerSwitcher{}, transportConfiguration: config.Transport, name: name}, nil
 }
 
 // Start commences the listening for ep.
@@ -124,6 +133,10 @@ func (ep *UDPEntryPoint) Start(ctx context.Context) {
 			return
 		}
 
+		if ep.tracer != nil {
+			conn.Observe(ep.tracer, ep.name)
+		}
+
 		go ep.switcher.ServeUDP(conn)
 	}
 }
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/static"
+)
+
+// buildUnixListener listens on the Unix domain socket path of the entry point,
+// and applies the configured file mode and owner to the socket.
+func buildUnixListener(ctx context.Context, config *static.EntryPoint) (net.Listener, error) {
+	path := config.GetAddress()
+
+	mode, err := config.UnixSocket.FileMode()
+	if err != nil {
+		return nil, err
+	}
+
+	uid, gid, err := lookupUnixSocketOwner(config.UnixSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := removeStaleUnixSocket(ctx, path); err != nil {
+		return nil, err
+	}
+
+	var listenConfig net.ListenConfig
+	listener, err := listenConfig.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening listener: %w", err)
+	}
+
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("setting unix socket mode: %w", err)
+		}
+	}
+
+	if uid != -1 || gid != -1 {
+		if err := os.Chown(path, uid, gid); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("setting unix socket owner: %w", err)
+		}
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Listening on unix socket %s", path)
+
+	return listener, nil
+}
+
+// clearUnixRemoteAddr clears the remote address of the requests received from unix socket clients,
+// which have no IP address, unless it was set from the ProxyProtocol header.
+func clearUnixRemoteAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if _, _, err := net.SplitHostPort(req.RemoteAddr); err != nil {
+			req.RemoteAddr = ""
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// removeStaleUnixSocket removes the socket file left behind by a previous instance, if any.
+// It fails if the path is not a socket, or if the socket is still in use.
+func removeStaleUnixSocket(ctx context.Context, path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("checking unix socket path: %w", err)
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return fmt.Errorf("unix socket path %s already exists and is not a socket", path)
+	}
+
+	dialer := net.Dialer{Timeout: time.Second}
+	if conn, err := dialer.DialContext(ctx, "unix", path); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("unix socket %s is already in use", path)
+	}
+
+	log.Ctx(ctx).Debug().Msgf("Removing stale unix socket %s", path)
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("removing stale unix socket: %w", err)
+	}
+
+	return nil
+}
+
+// lookupUnixSocketOwner returns the user and group IDs the socket must belong to,
+// -1 meaning that the ID must be kept unchanged.
+func lookupUnixSocketOwner(config *static.UnixSocketConfig) (int, int, error) {
+	if config == nil {
+		return -1, -1, nil
+	}
+
+	uid, err := lookupID(config.Owner, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("looking up unix socket owner: %w", err)
+	}
+
+	gid, err := lookupID(config.Group, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("looking up unix socket group: %w", err)
+	}
+
+	return uid, gid, nil
+}
+
+// lookupID returns the numeric ID of the given name, which can already be a numeric ID,
+// or -1 when no name is given.
+func lookupID(name string, lookup func(name string) (string, error)) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(id)
+}
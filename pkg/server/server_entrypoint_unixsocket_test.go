@@ -0,0 +1,155 @@
+//go:build !windows
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/static"
+	tcprouter "github.com/apache4/apache4/v3/pkg/server/router/tcp"
+	"github.com/apache4/apache4/v3/pkg/tcp"
+)
+
+func TestUnixSocketEntryPoint_HTTP(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "ep.sock")
+
+	entryPoint := newUnixSocketEntryPoint(t, socketPath, &static.UnixSocketConfig{Mode: "0600"})
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	router, err := tcprouter.NewRouter()
+	require.NoError(t, err)
+
+	router.SetHTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Remote-Addr", req.RemoteAddr)
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	go entryPoint.Start(t.Context())
+	entryPoint.SwitchRouter(router)
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}}
+
+	resp, err := client.Get("http://localhost/")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get("X-Remote-Addr"))
+}
+
+func TestUnixSocketEntryPoint_TCP(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "ep.sock")
+
+	entryPoint := newUnixSocketEntryPoint(t, socketPath, nil)
+
+	router, err := tcprouter.NewRouter()
+	require.NoError(t, err)
+
+	err = router.AddTCPRoute("HostSNI(`*`)", 0, tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		defer func() { _ = conn.Close() }()
+
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("echo " + line))
+	}))
+	require.NoError(t, err)
+
+	go entryPoint.Start(t.Context())
+	entryPoint.SwitchRouter(router)
+
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = conn.Write([]byte("ping\n"))
+	require.NoError(t, err)
+
+	resp, err := io.ReadAll(conn)
+	require.NoError(t, err)
+
+	assert.Equal(t, "echo ping\n", string(resp))
+}
+
+func TestBuildUnixListener_staleSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "ep.sock")
+
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: socketPath, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	listener, err := buildUnixListener(t.Context(), &static.EntryPoint{Address: "unix:" + socketPath})
+	require.NoError(t, err)
+
+	// The socket is now in use.
+	_, err = buildUnixListener(t.Context(), &static.EntryPoint{Address: "unix:" + socketPath})
+	require.Error(t, err)
+
+	require.NoError(t, listener.Close())
+
+	_, err = os.Stat(socketPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestBuildUnixListener_notASocket(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "ep.sock")
+	require.NoError(t, os.WriteFile(filePath, []byte("foo"), 0o600))
+
+	_, err := buildUnixListener(t.Context(), &static.EntryPoint{Address: "unix:" + filePath})
+	require.Error(t, err)
+}
+
+func TestLookupUnixSocketOwner(t *testing.T) {
+	uid, gid, err := lookupUnixSocketOwner(nil)
+	require.NoError(t, err)
+	assert.Equal(t, -1, uid)
+	assert.Equal(t, -1, gid)
+
+	uid, gid, err = lookupUnixSocketOwner(&static.UnixSocketConfig{Owner: strconv.Itoa(os.Getuid())})
+	require.NoError(t, err)
+	assert.Equal(t, os.Getuid(), uid)
+	assert.Equal(t, -1, gid)
+
+	_, _, err = lookupUnixSocketOwner(&static.UnixSocketConfig{Group: "apache4-unknown-group"})
+	require.Error(t, err)
+}
+
+func newUnixSocketEntryPoint(t *testing.T, socketPath string, config *static.UnixSocketConfig) *TCPEntryPoint {
+	t.Helper()
+
+	epConfig := &static.EntryPointsTransport{}
+	epConfig.SetDefaults()
+
+	entryPoint, err := NewTCPEntryPoint(t.Context(), "", &static.EntryPoint{
+		Address:          "unix:" + socketPath,
+		Transport:        epConfig,
+		ForwardedHeaders: &static.ForwardedHeaders{},
+		HTTP2:            &static.HTTP2Config{},
+		UnixSocket:       config,
+	}, nil, nil, nil, nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { entryPoint.Shutdown(context.Background()) })
+
+	return entryPoint
+}
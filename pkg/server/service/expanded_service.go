@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/runtime"
+	"github.com/apache4/apache4/v3/pkg/healthcheck"
+)
+
+// expandedService is a load-balancer service whose servers have been expanded into the addresses of their hosts,
+// and whose load-balancer is built again when these addresses change.
+type expandedService struct {
+	switcher *balancerSwitcher
+	// build builds the load-balancer of the service again.
+	build func() (serverBalancer, *healthcheck.ServiceHealthChecker, []string, error)
+	info  *runtime.ServiceInfo
+
+	// serverURLs holds the URLs of the servers of the current load-balancer.
+	serverURLs        []string
+	cancelHealthCheck context.CancelFunc
+}
+
+// balancerSwitcher forwards the requests to the current load-balancer of a service,
+// and reports its status changes to the parents of the service, across the load-balancer replacements.
+type balancerSwitcher struct {
+	mu       sync.RWMutex
+	balancer serverBalancer
+	updaters []func(up bool)
+	// up is the last status reported to the updaters.
+	up bool
+}
+
+func newBalancerSwitcher(balancer serverBalancer) *balancerSwitcher {
+	return &balancerSwitcher{balancer: balancer, up: true}
+}
+
+func (s *balancerSwitcher) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	balancer := s.balancer
+	s.mu.RUnlock()
+
+	balancer.ServeHTTP(rw, req)
+}
+
+// RegisterStatusUpdater adds fn to the list of hooks that are run when the status of the current load-balancer changes.
+func (s *balancerSwitcher) RegisterStatusUpdater(fn func(up bool)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.updaters) == 0 {
+		if err := s.balancer.RegisterStatusUpdater(s.statusUpdater(s.balancer)); err != nil {
+			return err
+		}
+	}
+
+	s.updaters = append(s.updaters, fn)
+
+	return nil
+}
+
+// Switch replaces the current load-balancer with the given one,
+// which is up when it has servers, as they are considered up at creation.
+func (s *balancerSwitcher) Switch(balancer serverBalancer, up bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.balancer = balancer
+
+	if len(s.updaters) == 0 {
+		return
+	}
+
+	if err := balancer.RegisterStatusUpdater(s.statusUpdater(balancer)); err != nil {
+		// Should never happen, as the load-balancers of a service are built from the same configuration.
+		log.Error().Err(err).Msg("Unable to register the status updater of the load-balancer")
+	}
+
+	s.setStatus(up)
+}
+
+// statusUpdater returns the hook reporting the status changes of the given load-balancer, as long as it is the current one.
+func (s *balancerSwitcher) statusUpdater(balancer serverBalancer) func(up bool) {
+	return func(up bool) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.balancer != balancer {
+			return
+		}
+
+		s.setStatus(up)
+	}
+}
+
+// setStatus reports the given status to the updaters, if it changed.
+// It must be called with the mu lock held.
+func (s *balancerSwitcher) setStatus(up bool) {
+	if s.up == up {
+		return
+	}
+
+	s.up = up
+	for _, fn := range s.updaters {
+		fn(up)
+	}
+}
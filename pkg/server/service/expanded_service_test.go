@@ -0,0 +1,59 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer/wrr"
+)
+
+func TestBalancerSwitcher(t *testing.T) {
+	newBalancer := func(server string) *wrr.Balancer {
+		balancer := wrr.New(nil, true, nil)
+		balancer.AddServer(server, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", server)
+		}), dynamic.Server{})
+
+		return balancer
+	}
+
+	oldBalancer := newBalancer("old")
+	switcher := newBalancerSwitcher(oldBalancer)
+
+	var statuses []bool
+	err := switcher.RegisterStatusUpdater(func(up bool) { statuses = append(statuses, up) })
+	require.NoError(t, err)
+
+	oldBalancer.SetStatus(t.Context(), "old", false)
+	assert.Equal(t, []bool{false}, statuses)
+
+	replacement := newBalancer("new")
+	switcher.Switch(replacement, true)
+
+	// The load-balancer being up, as its servers at creation, is reported.
+	assert.Equal(t, []bool{false, true}, statuses)
+
+	recorder := httptest.NewRecorder()
+	switcher.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	assert.Equal(t, "new", recorder.Header().Get("server"))
+
+	// The status changes of the replaced load-balancer are not reported anymore.
+	oldBalancer.SetStatus(t.Context(), "old", true)
+	assert.Equal(t, []bool{false, true}, statuses)
+
+	replacement.SetStatus(t.Context(), "new", false)
+	assert.Equal(t, []bool{false, true, false}, statuses)
+}
+
+func TestBalancerSwitcher_withoutHealthCheck(t *testing.T) {
+	switcher := newBalancerSwitcher(wrr.New(nil, false, nil))
+
+	err := switcher.RegisterStatusUpdater(func(up bool) {})
+	require.Error(t, err)
+
+	switcher.Switch(wrr.New(nil, false, nil), true)
+}
@@ -0,0 +1,358 @@
+package files
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+// precompressedEncodings are the encodings of the precompressed sidecar files, in order of preference.
+var precompressedEncodings = []struct {
+	name      string
+	extension string
+}{
+	{name: "br", extension: ".br"},
+	{name: "zstd", extension: ".zst"},
+	{name: "gzip", extension: ".gz"},
+}
+
+// Files is an http.Handler serving the files of a local directory.
+type Files struct {
+	root             string
+	indexFiles       []string
+	spaFallback      bool
+	precompressed    bool
+	directoryListing bool
+}
+
+// New creates a new Files handler.
+func New(config dynamic.Files) (*Files, error) {
+	if config.Root == "" {
+		return nil, errors.New("root directory is required")
+	}
+
+	root, err := filepath.Abs(config.Root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root directory %q: %w", config.Root, err)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("checking root directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("root %q is not a directory", root)
+	}
+
+	for _, index := range config.IndexFiles {
+		if index == "" || strings.ContainsAny(index, `/\`) {
+			return nil, fmt.Errorf("invalid index file %q: must be a file name", index)
+		}
+	}
+
+	return &Files{
+		root:             root,
+		indexFiles:       config.IndexFiles,
+		spaFallback:      config.SPAFallback,
+		precompressed:    config.Precompressed,
+		directoryListing: config.DirectoryListing,
+	}, nil
+}
+
+func (f *Files) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		rw.Header().Set("Allow", "GET, HEAD")
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The root is opened for each request, so that the files cannot be accessed outside of it,
+	// even through symbolic links, and that no file descriptor outlives the configuration.
+	root, err := os.OpenRoot(f.root)
+	if err != nil {
+		log.Ctx(req.Context()).Error().Err(err).Msg("Error while opening root directory")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = root.Close() }()
+
+	upath := path.Clean("/" + req.URL.Path)
+	name := strings.TrimPrefix(upath, "/")
+	if name == "" {
+		name = "."
+	}
+
+	info, err := root.Stat(name)
+	if err != nil {
+		// The single-page applications route the requests to the missing files in the browser.
+		if isNotExist(err) && f.spaFallback {
+			if !f.serveIndex(rw, req, root, ".") {
+				http.NotFound(rw, req)
+			}
+			return
+		}
+
+		f.serveError(rw, req, err)
+		return
+	}
+
+	if info.IsDir() {
+		// The relative links of the directory content require the trailing slash.
+		if !strings.HasSuffix(req.URL.Path, "/") {
+			redirectToDirectory(rw, req, upath)
+			return
+		}
+
+		f.serveDirectory(rw, req, root, name)
+		return
+	}
+
+	f.serveFile(rw, req, root, name, info)
+}
+
+// serveDirectory serves the first existing index file of the directory,
+// or its content when the directory listing is enabled.
+func (f *Files) serveDirectory(rw http.ResponseWriter, req *http.Request, root *os.Root, dir string) {
+	if f.serveIndex(rw, req, root, dir) {
+		return
+	}
+
+	if !f.directoryListing {
+		http.NotFound(rw, req)
+		return
+	}
+
+	listDirectory(rw, req, root, dir)
+}
+
+// serveIndex serves the first existing index file of the directory, and returns whether one was found.
+func (f *Files) serveIndex(rw http.ResponseWriter, req *http.Request, root *os.Root, dir string) bool {
+	for _, index := range f.indexFiles {
+		name := path.Join(dir, index)
+
+		info, err := root.Stat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		f.serveFile(rw, req, root, name, info)
+		return true
+	}
+
+	return false
+}
+
+// serveFile serves a regular file, or its precompressed sidecar file when the client accepts its encoding.
+// The range requests and conditional requests are handled by http.ServeContent.
+func (f *Files) serveFile(rw http.ResponseWriter, req *http.Request, root *os.Root, name string, info fs.FileInfo) {
+	if !info.Mode().IsRegular() {
+		http.NotFound(rw, req)
+		return
+	}
+
+	file, err := root.Open(name)
+	if err != nil {
+		f.serveError(rw, req, err)
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	content := io.ReadSeeker(file)
+	contentInfo := info
+
+	if f.precompressed {
+		rw.Header().Add("Vary", "Accept-Encoding")
+
+		if encoding, sidecar, sidecarInfo := openPrecompressed(root, name, req.Header.Get("Accept-Encoding")); sidecar != nil {
+			defer func() { _ = sidecar.Close() }()
+
+			// The content type is the one of the original file, not the one of its encoding.
+			if err := setContentType(rw, name, file); err != nil {
+				f.serveError(rw, req, err)
+				return
+			}
+
+			rw.Header().Set("Content-Encoding", encoding)
+			content = sidecar
+			contentInfo = sidecarInfo
+		}
+	}
+
+	if rw.Header().Get("Etag") == "" {
+		rw.Header().Set("Etag", etag(contentInfo, rw.Header().Get("Content-Encoding")))
+	}
+
+	http.ServeContent(rw, req, name, contentInfo.ModTime(), content)
+}
+
+func (f *Files) serveError(rw http.ResponseWriter, req *http.Request, err error) {
+	switch {
+	case isNotExist(err):
+		http.NotFound(rw, req)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		// The paths escaping the root, through symbolic links, are reported as not found.
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) && strings.Contains(pathErr.Err.Error(), "escapes from parent") {
+			http.NotFound(rw, req)
+			return
+		}
+
+		log.Ctx(req.Context()).Error().Err(err).Msg("Error while serving file")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+// isNotExist returns whether the error reports a missing file,
+// including through a path going through a regular file, as net/http.FileServer does.
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR)
+}
+
+// openPrecompressed opens the precompressed sidecar file of the given file, in the preferred encoding accepted by the client.
+func openPrecompressed(root *os.Root, name, acceptEncoding string) (string, *os.File, fs.FileInfo) {
+	accepted := acceptedEncodings(acceptEncoding)
+
+	for _, encoding := range precompressedEncodings {
+		accept, ok := accepted[encoding.name]
+		if !ok {
+			accept = accepted["*"]
+		}
+		if !accept {
+			continue
+		}
+
+		sidecar, err := root.Open(name + encoding.extension)
+		if err != nil {
+			continue
+		}
+
+		info, err := sidecar.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			_ = sidecar.Close()
+			continue
+		}
+
+		return encoding.name, sidecar, info
+	}
+
+	return "", nil, nil
+}
+
+// acceptedEncodings returns whether the encodings listed in the Accept-Encoding header are accepted by the client,
+// the encodings with a zero quality value being explicitly refused.
+func acceptedEncodings(acceptEncoding string) map[string]bool {
+	encodings := make(map[string]bool)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		encoding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if encoding == "" {
+			continue
+		}
+
+		accepted := true
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			value, err := strconv.ParseFloat(q, 64)
+			accepted = err == nil && value > 0
+		}
+
+		encodings[encoding] = accepted
+	}
+
+	return encodings
+}
+
+// setContentType sets the Content-Type header from the extension of the file,
+// or from its first bytes when the extension is unknown.
+func setContentType(rw http.ResponseWriter, name string, file io.ReadSeeker) error {
+	if rw.Header().Get("Content-Type") != "" {
+		return nil
+	}
+
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		rw.Header().Set("Content-Type", ctype)
+		return nil
+	}
+
+	var buf [512]byte
+	n, err := io.ReadFull(file, buf[:])
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+
+	rw.Header().Set("Content-Type", http.DetectContentType(buf[:n]))
+	return nil
+}
+
+// etag returns a strong entity tag derived from the modification time and the size of the file,
+// and from the encoding of the served content.
+func etag(info fs.FileInfo, encoding string) string {
+	tag := strconv.FormatInt(info.ModTime().UnixNano(), 16) + "-" + strconv.FormatInt(info.Size(), 16)
+	if encoding != "" {
+		tag += "-" + encoding
+	}
+
+	return `"` + tag + `"`
+}
+
+func redirectToDirectory(rw http.ResponseWriter, req *http.Request, upath string) {
+	target := path.Base(upath) + "/"
+	if upath == "/" {
+		target = "./"
+	}
+
+	target = (&url.URL{Path: target}).EscapedPath()
+	if req.URL.RawQuery != "" {
+		target += "?" + req.URL.RawQuery
+	}
+
+	rw.Header().Set("Location", target)
+	rw.WriteHeader(http.StatusMovedPermanently)
+}
+
+// listDirectory writes an HTML page listing the content of the directory.
+func listDirectory(rw http.ResponseWriter, req *http.Request, root *os.Root, dir string) {
+	entries, err := fs.ReadDir(root.FS(), dir)
+	if err != nil {
+		log.Ctx(req.Context()).Error().Err(err).Msg("Error while reading directory")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// fs.ReadDir returns the entries sorted by name.
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+
+		link := (&url.URL{Path: name}).EscapedPath()
+		fmt.Fprintf(&b, "<a href=\"./%s\">%s</a>\n", html.EscapeString(link), html.EscapeString(name))
+	}
+	b.WriteString("</pre>\n")
+
+	_, _ = io.WriteString(rw, b.String())
+}
@@ -0,0 +1,309 @@
+package files
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "file.txt", "foo")
+
+	testCases := []struct {
+		desc        string
+		config      dynamic.Files
+		expectedErr bool
+	}{
+		{
+			desc:   "valid root",
+			config: dynamic.Files{Root: dir, IndexFiles: []string{"index.html"}},
+		},
+		{
+			desc:        "missing root",
+			config:      dynamic.Files{},
+			expectedErr: true,
+		},
+		{
+			desc:        "nonexistent root",
+			config:      dynamic.Files{Root: filepath.Join(dir, "missing")},
+			expectedErr: true,
+		},
+		{
+			desc:        "root is a file",
+			config:      dynamic.Files{Root: filepath.Join(dir, "file.txt")},
+			expectedErr: true,
+		},
+		{
+			desc:        "index file with a path",
+			config:      dynamic.Files{Root: dir, IndexFiles: []string{"../index.html"}},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.config)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.html", "root index")
+	writeFile(t, dir, "app.js", "console.log('foo')")
+	writeFile(t, dir, "app.js.br", "brotli")
+	writeFile(t, dir, "app.js.gz", "gzip")
+	writeFile(t, dir, "sub/index.html", "sub index")
+	writeFile(t, dir, "list/a b.txt", "a")
+	writeFile(t, dir, "list/<b>.txt", "b")
+	writeFile(t, dir, "list/dir/file.txt", "c")
+
+	outside := t.TempDir()
+	writeFile(t, outside, "secret.txt", "secret")
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(dir, "link.txt")))
+
+	testCases := []struct {
+		desc             string
+		config           dynamic.Files
+		method           string
+		path             string
+		headers          map[string]string
+		expectedStatus   int
+		expectedBody     string
+		expectedHeaders  map[string]string
+		expectedContains []string
+	}{
+		{
+			desc:           "file",
+			path:           "/app.js",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "console.log('foo')",
+			expectedHeaders: map[string]string{
+				"Content-Type":     "text/javascript; charset=utf-8",
+				"Content-Encoding": "",
+			},
+		},
+		{
+			desc:           "root index",
+			path:           "/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "root index",
+		},
+		{
+			desc:           "directory index",
+			path:           "/sub/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "sub index",
+		},
+		{
+			desc:           "directory without trailing slash",
+			path:           "/sub?foo=bar",
+			expectedStatus: http.StatusMovedPermanently,
+			expectedHeaders: map[string]string{
+				"Location": "sub/?foo=bar",
+			},
+		},
+		{
+			desc:           "path traversal",
+			path:           "/../../../etc/passwd",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "symbolic link escaping the root",
+			path:           "/link.txt",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "missing file",
+			path:           "/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "path through a file",
+			path:           "/app.js/foo",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "SPA fallback for a path through a file",
+			config:         dynamic.Files{SPAFallback: true},
+			path:           "/index.html/foo",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "root index",
+		},
+		{
+			desc:           "SPA fallback",
+			config:         dynamic.Files{SPAFallback: true},
+			path:           "/some/client/route",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "root index",
+		},
+		{
+			desc:           "directory without index",
+			path:           "/list/",
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "directory listing",
+			config:         dynamic.Files{DirectoryListing: true},
+			path:           "/list/",
+			expectedStatus: http.StatusOK,
+			expectedContains: []string{
+				`<a href="./a%20b.txt">a b.txt</a>`,
+				`<a href="./%3Cb%3E.txt">&lt;b&gt;.txt</a>`,
+				`<a href="./dir/">dir/</a>`,
+			},
+		},
+		{
+			desc:           "precompressed brotli",
+			config:         dynamic.Files{Precompressed: true},
+			path:           "/app.js",
+			headers:        map[string]string{"Accept-Encoding": "gzip, br"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "brotli",
+			expectedHeaders: map[string]string{
+				"Content-Type":     "text/javascript; charset=utf-8",
+				"Content-Encoding": "br",
+				"Vary":             "Accept-Encoding",
+			},
+		},
+		{
+			desc:           "precompressed gzip",
+			config:         dynamic.Files{Precompressed: true},
+			path:           "/app.js",
+			headers:        map[string]string{"Accept-Encoding": "gzip, br;q=0"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "gzip",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "gzip",
+			},
+		},
+		{
+			desc:           "precompressed without accepted encoding",
+			config:         dynamic.Files{Precompressed: true},
+			path:           "/app.js",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "console.log('foo')",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "",
+				"Vary":             "Accept-Encoding",
+			},
+		},
+		{
+			desc:           "precompressed disabled",
+			path:           "/app.js",
+			headers:        map[string]string{"Accept-Encoding": "br"},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "console.log('foo')",
+			expectedHeaders: map[string]string{
+				"Content-Encoding": "",
+			},
+		},
+		{
+			desc:           "range request",
+			path:           "/app.js",
+			headers:        map[string]string{"Range": "bytes=0-6"},
+			expectedStatus: http.StatusPartialContent,
+			expectedBody:   "console",
+		},
+		{
+			desc:           "HEAD request",
+			method:         http.MethodHead,
+			path:           "/app.js",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "unsupported method",
+			method:         http.MethodPost,
+			path:           "/app.js",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedHeaders: map[string]string{
+				"Allow": "GET, HEAD",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := test.config
+			config.Root = dir
+			config.IndexFiles = []string{"index.html"}
+
+			handler, err := New(config)
+			require.NoError(t, err)
+
+			method := http.MethodGet
+			if test.method != "" {
+				method = test.method
+			}
+
+			req := httptest.NewRequest(method, "http://localhost"+test.path, nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+			for _, expected := range test.expectedContains {
+				assert.Contains(t, recorder.Body.String(), expected)
+			}
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name), name)
+			}
+		})
+	}
+}
+
+func TestFiles_conditionalRequest(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "file.txt", "foo")
+
+	handler, err := New(dynamic.Files{Root: dir})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://localhost/file.txt", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	etag := recorder.Header().Get("Etag")
+	require.NotEmpty(t, etag)
+	require.NotEmpty(t, recorder.Header().Get("Last-Modified"))
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost/file.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	filename := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(filename), 0o755))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o644))
+}
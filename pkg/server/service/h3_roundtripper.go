@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+// schemeH3 is the scheme of the server URLs reached over HTTP/3.
+const schemeH3 = "h3"
+
+// h3TransportWrapper is the round tripper registered for the h3 scheme,
+// sending the requests over HTTP/3 to the servers.
+type h3TransportWrapper struct {
+	*http3.Transport
+
+	allow0RTT bool
+}
+
+// newH3TransportWrapper creates an h3TransportWrapper sharing the given TLS configuration.
+func newH3TransportWrapper(cfg *dynamic.HTTP3ClientConfig, tlsConfig *tls.Config, dialTimeout time.Duration) *h3TransportWrapper {
+	if cfg == nil {
+		cfg = &dynamic.HTTP3ClientConfig{}
+		cfg.SetDefaults()
+	}
+
+	h3TLSConfig := &tls.Config{}
+	if tlsConfig != nil {
+		h3TLSConfig = tlsConfig.Clone()
+	}
+
+	if cfg.Allow0RTT {
+		// The session tickets are required to resume the connections with 0-RTT.
+		h3TLSConfig.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+
+	return &h3TransportWrapper{
+		Transport: &http3.Transport{
+			TLSClientConfig: h3TLSConfig,
+			QUICConfig: &quic.Config{
+				MaxIdleTimeout:  time.Duration(cfg.MaxIdleTimeout),
+				KeepAlivePeriod: time.Duration(cfg.KeepAlivePeriod),
+			},
+			Dial: h3Dial(cfg.Allow0RTT, dialTimeout),
+		},
+		allow0RTT: cfg.Allow0RTT,
+	}
+}
+
+func (t *h3TransportWrapper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The request belongs to the caller, and must not be modified.
+	outReq := *req
+	outURL := *req.URL
+	outURL.Scheme = "https"
+	outReq.URL = &outURL
+
+	if !t.allow0RTT || !isReplayable(req) {
+		return t.Transport.RoundTrip(&outReq)
+	}
+
+	// The request is sent in the 0-RTT data only when its method is explicitly marked as such,
+	// as these data can be replayed by an attacker.
+	switch req.Method {
+	case http.MethodGet:
+		outReq.Method = http3.MethodGet0RTT
+	case http.MethodHead:
+		outReq.Method = http3.MethodHead0RTT
+	}
+
+	return t.Transport.RoundTrip(&outReq)
+}
+
+// isReplayable reports whether the given request can be safely sent in the 0-RTT data.
+func isReplayable(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		(req.Body == nil || req.Body == http.NoBody)
+}
+
+// h3Dial returns the function dialing the QUIC connections,
+// which are only established before the end of the handshake when 0-RTT is allowed.
+func h3Dial(allow0RTT bool, dialTimeout time.Duration) func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+	return func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (*quic.Conn, error) {
+		if dialTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, dialTimeout)
+			defer cancel()
+		}
+
+		if allow0RTT {
+			return quic.DialAddrEarly(ctx, addr, tlsCfg, cfg)
+		}
+
+		return quic.DialAddr(ctx, addr, tlsCfg, cfg)
+	}
+}
@@ -0,0 +1,99 @@
+package loadbalancer
+
+import (
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+// Locality tiers of the servers, from the most preferred to the least preferred.
+const (
+	// TierZone is the tier of the servers located in the same zone as apache4.
+	TierZone = iota
+	// TierRegion is the tier of the servers located in the same region as apache4, but not in the same zone.
+	TierRegion
+	// TierAny is the tier of all the other servers.
+	TierAny
+
+	tierCount
+)
+
+// Locality is the location of apache4 or of a server.
+type Locality struct {
+	Zone   string
+	Region string
+}
+
+// LocalityPreference makes a load-balancer prefer the servers closest to apache4,
+// as long as enough of them are healthy, spilling over to the next tier otherwise.
+type LocalityPreference struct {
+	local             Locality
+	minHealthyServers int
+	minHealthyPercent int
+}
+
+// NewLocalityPreference creates a LocalityPreference for an apache4 instance located in local.
+// It returns nil when the apache4 locality is unknown, in which case all the servers are treated equally.
+func NewLocalityPreference(local Locality, config *dynamic.Locality) *LocalityPreference {
+	if local.Zone == "" && local.Region == "" {
+		return nil
+	}
+
+	preference := &LocalityPreference{
+		local:             local,
+		minHealthyServers: 1,
+	}
+
+	if config != nil {
+		preference.minHealthyServers = max(config.MinHealthyServers, 1)
+		preference.minHealthyPercent = config.MinHealthyPercent
+	}
+
+	return preference
+}
+
+// Tier returns the locality tier of the given server.
+func (p *LocalityPreference) Tier(server dynamic.Server) int {
+	switch {
+	case p == nil:
+		return TierAny
+	case p.local.Zone != "" && server.Zone == p.local.Zone:
+		return TierZone
+	case p.local.Region != "" && server.Region == p.local.Region:
+		return TierRegion
+	default:
+		return TierAny
+	}
+}
+
+// MaxTier returns the least preferred tier the servers are picked from.
+// A tier is kept, along with the more preferred ones, when it has enough available servers.
+func (p *LocalityPreference) MaxTier(counts LocalityCounts) int {
+	if p == nil {
+		return TierAny
+	}
+
+	var servers, available int
+	for tier := TierZone; tier < TierAny; tier++ {
+		servers += counts.servers[tier]
+		available += counts.available[tier]
+
+		if available >= p.minHealthyServers && available*100 >= p.minHealthyPercent*servers {
+			return tier
+		}
+	}
+
+	return TierAny
+}
+
+// LocalityCounts holds the number of servers, and of available servers, per locality tier.
+type LocalityCounts struct {
+	servers   [tierCount]int
+	available [tierCount]int
+}
+
+// Add counts a server of the given tier.
+func (c *LocalityCounts) Add(tier int, available bool) {
+	c.servers[tier]++
+	if available {
+		c.available[tier]++
+	}
+}
@@ -0,0 +1,178 @@
+package loadbalancer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestNewLocalityPreference(t *testing.T) {
+	assert.Nil(t, NewLocalityPreference(Locality{}, &dynamic.Locality{MinHealthyServers: 2}))
+	assert.NotNil(t, NewLocalityPreference(Locality{Zone: "a"}, nil))
+	assert.NotNil(t, NewLocalityPreference(Locality{Region: "eu"}, nil))
+}
+
+func TestLocalityPreference_Tier(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		local    Locality
+		server   dynamic.Server
+		expected int
+	}{
+		{
+			desc:     "same zone",
+			local:    Locality{Zone: "a", Region: "eu"},
+			server:   dynamic.Server{Zone: "a", Region: "eu"},
+			expected: TierZone,
+		},
+		{
+			desc:     "same zone without region",
+			local:    Locality{Zone: "a", Region: "eu"},
+			server:   dynamic.Server{Zone: "a"},
+			expected: TierZone,
+		},
+		{
+			desc:     "same region",
+			local:    Locality{Zone: "a", Region: "eu"},
+			server:   dynamic.Server{Zone: "b", Region: "eu"},
+			expected: TierRegion,
+		},
+		{
+			desc:     "other region",
+			local:    Locality{Zone: "a", Region: "eu"},
+			server:   dynamic.Server{Zone: "c", Region: "us"},
+			expected: TierAny,
+		},
+		{
+			desc:     "unknown server locality",
+			local:    Locality{Zone: "a", Region: "eu"},
+			server:   dynamic.Server{},
+			expected: TierAny,
+		},
+		{
+			desc:     "empty local zone does not match empty server zone",
+			local:    Locality{Region: "eu"},
+			server:   dynamic.Server{Region: "us"},
+			expected: TierAny,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			preference := NewLocalityPreference(test.local, nil)
+			assert.Equal(t, test.expected, preference.Tier(test.server))
+		})
+	}
+}
+
+func TestLocalityPreference_Tier_nil(t *testing.T) {
+	var preference *LocalityPreference
+
+	assert.Equal(t, TierAny, preference.Tier(dynamic.Server{Zone: "a"}))
+	assert.Equal(t, TierAny, preference.MaxTier(LocalityCounts{}))
+}
+
+func TestLocalityPreference_MaxTier(t *testing.T) {
+	type server struct {
+		tier      int
+		available bool
+	}
+
+	testCases := []struct {
+		desc     string
+		config   *dynamic.Locality
+		servers  []server
+		expected int
+	}{
+		{
+			desc:     "no servers",
+			expected: TierAny,
+		},
+		{
+			desc: "healthy server in the zone",
+			servers: []server{
+				{tier: TierZone, available: true},
+				{tier: TierRegion, available: true},
+				{tier: TierAny, available: true},
+			},
+			expected: TierZone,
+		},
+		{
+			desc: "unhealthy server in the zone",
+			servers: []server{
+				{tier: TierZone, available: false},
+				{tier: TierRegion, available: true},
+				{tier: TierAny, available: true},
+			},
+			expected: TierRegion,
+		},
+		{
+			desc: "no healthy server in the region",
+			servers: []server{
+				{tier: TierZone, available: false},
+				{tier: TierRegion, available: false},
+				{tier: TierAny, available: true},
+			},
+			expected: TierAny,
+		},
+		{
+			desc:   "not enough healthy servers in the zone",
+			config: &dynamic.Locality{MinHealthyServers: 2},
+			servers: []server{
+				{tier: TierZone, available: true},
+				{tier: TierZone, available: false},
+				{tier: TierRegion, available: true},
+			},
+			expected: TierRegion,
+		},
+		{
+			desc:   "not enough healthy servers in the region",
+			config: &dynamic.Locality{MinHealthyServers: 3},
+			servers: []server{
+				{tier: TierZone, available: true},
+				{tier: TierRegion, available: true},
+				{tier: TierAny, available: true},
+			},
+			expected: TierAny,
+		},
+		{
+			desc:   "healthy percentage reached in the zone",
+			config: &dynamic.Locality{MinHealthyPercent: 50},
+			servers: []server{
+				{tier: TierZone, available: true},
+				{tier: TierZone, available: false},
+				{tier: TierRegion, available: true},
+			},
+			expected: TierZone,
+		},
+		{
+			desc:   "healthy percentage not reached in the zone",
+			config: &dynamic.Locality{MinHealthyPercent: 70},
+			servers: []server{
+				{tier: TierZone, available: true},
+				{tier: TierZone, available: false},
+				{tier: TierZone, available: false},
+				{tier: TierRegion, available: true},
+				{tier: TierRegion, available: true},
+			},
+			expected: TierAny,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var counts LocalityCounts
+			for _, s := range test.servers {
+				counts.Add(s.tier, s.available)
+			}
+
+			preference := NewLocalityPreference(Locality{Zone: "a", Region: "eu"}, test.config)
+			assert.Equal(t, test.expected, preference.MaxTier(counts))
+		})
+	}
+}
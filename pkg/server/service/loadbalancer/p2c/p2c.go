@@ -19,6 +19,8 @@ type namedHandler struct {
 
 	// name is the handler name.
 	name string
+	// tier is the locality tier of the server.
+	tier int
 	// inflight is the number of inflight requests.
 	// It is used to implement the "power-of-two-random-choices" algorithm.
 	inflight atomic.Int64
@@ -60,16 +62,23 @@ type Balancer struct {
 
 	sticky *loadbalancer.Sticky
 
+	// locality is the preference for the servers closest to apache4, nil when all the servers are treated equally.
+	locality *loadbalancer.LocalityPreference
+	// maxTier is the least preferred locality tier the servers are currently picked from.
+	maxTier int
+
 	randMu sync.Mutex
 	rand   rnd
 }
 
 // New creates a new power-of-two-random-choices load balancer.
-func New(stickyConfig *dynamic.Sticky, wantsHealthCheck bool) *Balancer {
+func New(stickyConfig *dynamic.Sticky, wantsHealthCheck bool, locality *loadbalancer.LocalityPreference) *Balancer {
 	balancer := &Balancer{
 		status:           make(map[string]struct{}),
 		fenced:           make(map[string]struct{}),
 		wantsHealthCheck: wantsHealthCheck,
+		locality:         locality,
+		maxTier:          loadbalancer.TierAny,
 		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 	if stickyConfig != nil && stickyConfig.Cookie != nil {
@@ -100,6 +109,11 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 		delete(b.status, childName)
 	}
 
+	if maxTier := b.computeMaxTier(); maxTier != b.maxTier {
+		log.Ctx(ctx).Debug().Msgf("Picking the servers up to the locality tier %d", maxTier)
+		b.maxTier = maxTier
+	}
+
 	upAfter := len(b.status) > 0
 	status = "DOWN"
 	if upAfter {
@@ -139,6 +153,9 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 	b.handlersMu.RLock()
 	var healthy []*namedHandler
 	for _, h := range b.handlers {
+		if h.tier > b.maxTier {
+			continue
+		}
 		if _, ok := b.status[h.name]; ok {
 			if _, fenced := b.fenced[h.name]; !fenced {
 				healthy = append(healthy, h)
@@ -220,7 +237,7 @@ func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 // AddServer adds a handler with a server.
 func (b *Balancer) AddServer(name string, handler http.Handler, server dynamic.Server) {
-	h := &namedHandler{Handler: handler, name: name}
+	h := &namedHandler{Handler: handler, name: name, tier: b.locality.Tier(server)}
 
 	b.handlersMu.Lock()
 	b.handlers = append(b.handlers, h)
@@ -228,9 +245,27 @@ func (b *Balancer) AddServer(name string, handler http.Handler, server dynamic.S
 	if server.Fenced {
 		b.fenced[name] = struct{}{}
 	}
+	b.maxTier = b.computeMaxTier()
 	b.handlersMu.Unlock()
 
 	if b.sticky != nil {
 		b.sticky.AddHandler(name, h)
 	}
 }
+
+// computeMaxTier returns the least preferred locality tier the servers should be picked from.
+// It must be called with the handlersMu lock held.
+func (b *Balancer) computeMaxTier() int {
+	if b.locality == nil {
+		return loadbalancer.TierAny
+	}
+
+	var counts loadbalancer.LocalityCounts
+	for _, h := range b.handlers {
+		_, up := b.status[h.name]
+		_, fenced := b.fenced[h.name]
+		counts.Add(h.tier, up && !fenced)
+	}
+
+	return b.locality.MaxTier(counts)
+}
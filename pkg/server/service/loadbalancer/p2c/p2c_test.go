@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer"
 )
 
 func TestP2C(t *testing.T) {
@@ -48,7 +49,7 @@ func TestP2C(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			t.Parallel()
 
-			balancer := New(nil, false)
+			balancer := New(nil, false, nil)
 			balancer.rand = test.rand
 
 			for _, h := range test.handlers {
@@ -74,7 +75,7 @@ func TestSticky(t *testing.T) {
 			MaxAge:   42,
 			Path:     func(v string) *string { return &v }("/foo"),
 		},
-	}, false)
+	}, false, nil)
 	balancer.rand = &mockRand{vals: []int{1, 0}}
 
 	balancer.AddServer("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -117,7 +118,7 @@ func TestSticky(t *testing.T) {
 func TestSticky_Fallback(t *testing.T) {
 	balancer := New(&dynamic.Sticky{
 		Cookie: &dynamic.Cookie{Name: "test"},
-	}, false)
+	}, false, nil)
 	balancer.rand = &mockRand{vals: []int{1, 0}}
 
 	balancer.AddServer("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -146,7 +147,7 @@ func TestSticky_Fallback(t *testing.T) {
 
 // TestSticky_Fenced checks that fenced node receive traffic if their sticky cookie matches.
 func TestSticky_Fenced(t *testing.T) {
-	balancer := New(&dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "test"}}, false)
+	balancer := New(&dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "test"}}, false, nil)
 	balancer.rand = &mockRand{vals: []int{1, 0, 1, 0}}
 
 	balancer.AddServer("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
@@ -184,7 +185,7 @@ func TestSticky_Fenced(t *testing.T) {
 }
 
 func TestBalancerPropagate(t *testing.T) {
-	balancer := New(nil, true)
+	balancer := New(nil, true, nil)
 
 	balancer.AddServer("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -233,7 +234,7 @@ func TestBalancerPropagate(t *testing.T) {
 }
 
 func TestBalancerAllServersFenced(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.AddServer("test", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), dynamic.Server{Fenced: true})
 	balancer.AddServer("test2", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), dynamic.Server{Fenced: true})
@@ -244,6 +245,54 @@ func TestBalancerAllServersFenced(t *testing.T) {
 	assert.Equal(t, http.StatusServiceUnavailable, recorder.Result().StatusCode)
 }
 
+func TestBalancerLocality(t *testing.T) {
+	balancer := New(nil, false, loadbalancer.NewLocalityPreference(loadbalancer.Locality{Zone: "a", Region: "eu"}, nil))
+
+	for _, server := range []dynamic.Server{
+		{URL: "first", Zone: "a", Region: "eu"},
+		{URL: "second", Zone: "b", Region: "eu"},
+		{URL: "third", Zone: "c", Region: "us"},
+	} {
+		balancer.AddServer(server.URL, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", server.URL)
+			rw.WriteHeader(http.StatusOK)
+		}), server)
+	}
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+
+	// the server in the same zone is down, the one in the same region takes over.
+	balancer.SetStatus(t.Context(), "first", false)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["second"])
+
+	// no server left in the region, the remaining one takes over.
+	balancer.SetStatus(t.Context(), "second", false)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["third"])
+
+	// the server in the same zone is back up.
+	balancer.SetStatus(t.Context(), "first", true)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+}
+
 type responseRecorder struct {
 	*httptest.ResponseRecorder
 	save     map[string]int
@@ -17,6 +17,8 @@ type namedHandler struct {
 	name     string
 	weight   float64
 	deadline float64
+	// tier is the locality tier of the server.
+	tier int
 }
 
 // Balancer is a WeightedRoundRobin load balancer based on Earliest Deadline First (EDF).
@@ -44,15 +46,22 @@ type Balancer struct {
 
 	sticky *loadbalancer.Sticky
 
+	// locality is the preference for the servers closest to apache4, nil when all the servers are treated equally.
+	locality *loadbalancer.LocalityPreference
+	// maxTier is the least preferred locality tier the servers are currently picked from.
+	maxTier int
+
 	curDeadline float64
 }
 
 // New creates a new load balancer.
-func New(sticky *dynamic.Sticky, wantsHealthCheck bool) *Balancer {
+func New(sticky *dynamic.Sticky, wantsHealthCheck bool, locality *loadbalancer.LocalityPreference) *Balancer {
 	balancer := &Balancer{
 		status:           make(map[string]struct{}),
 		fenced:           make(map[string]struct{}),
 		wantsHealthCheck: wantsHealthCheck,
+		locality:         locality,
+		maxTier:          loadbalancer.TierAny,
 	}
 	if sticky != nil && sticky.Cookie != nil {
 		balancer.sticky = loadbalancer.NewSticky(*sticky.Cookie)
@@ -113,6 +122,11 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 		delete(b.status, childName)
 	}
 
+	if maxTier := b.computeMaxTier(); maxTier != b.maxTier {
+		log.Ctx(ctx).Debug().Msgf("Picking the servers up to the locality tier %d", maxTier)
+		b.maxTier = maxTier
+	}
+
 	upAfter := len(b.status) > 0
 	status = "DOWN"
 	if upAfter {
@@ -164,6 +178,10 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 		handler.deadline += 1 / handler.weight
 
 		heap.Push(b, handler)
+		if handler.tier > b.maxTier {
+			// do not select a handler farther than the current locality tier.
+			continue
+		}
 		if _, ok := b.status[handler.name]; ok {
 			if _, ok := b.fenced[handler.name]; !ok {
 				// do not select a fenced handler.
@@ -219,12 +237,16 @@ func (b *Balancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 // AddServer adds a handler with a server.
 func (b *Balancer) AddServer(name string, handler http.Handler, server dynamic.Server) {
-	b.Add(name, handler, server.Weight, server.Fenced)
+	b.add(name, handler, server.Weight, server.Fenced, b.locality.Tier(server))
 }
 
 // Add adds a handler.
 // A handler with a non-positive weight is ignored.
 func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bool) {
+	b.add(name, handler, weight, fenced, loadbalancer.TierAny)
+}
+
+func (b *Balancer) add(name string, handler http.Handler, weight *int, fenced bool, tier int) {
 	w := 1
 	if weight != nil {
 		w = *weight
@@ -234,7 +256,7 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 		return
 	}
 
-	h := &namedHandler{Handler: handler, name: name, weight: float64(w)}
+	h := &namedHandler{Handler: handler, name: name, weight: float64(w), tier: tier}
 
 	b.handlersMu.Lock()
 	h.deadline = b.curDeadline + 1/h.weight
@@ -243,9 +265,27 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int, fenced bo
 	if fenced {
 		b.fenced[name] = struct{}{}
 	}
+	b.maxTier = b.computeMaxTier()
 	b.handlersMu.Unlock()
 
 	if b.sticky != nil {
 		b.sticky.AddHandler(name, handler)
 	}
 }
+
+// computeMaxTier returns the least preferred locality tier the servers should be picked from.
+// It must be called with the handlersMu lock held.
+func (b *Balancer) computeMaxTier() int {
+	if b.locality == nil {
+		return loadbalancer.TierAny
+	}
+
+	var counts loadbalancer.LocalityCounts
+	for _, h := range b.handlers {
+		_, up := b.status[h.name]
+		_, fenced := b.fenced[h.name]
+		counts.Add(h.tier, up && !fenced)
+	}
+
+	return b.locality.MaxTier(counts)
+}
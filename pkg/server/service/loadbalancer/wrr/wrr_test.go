@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer"
 )
 
 type key string
@@ -17,7 +18,7 @@ const serviceName key = "serviceName"
 func pointer[T any](v T) *T { return &v }
 
 func TestBalancer(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -39,7 +40,7 @@ func TestBalancer(t *testing.T) {
 }
 
 func TestBalancerNoService(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	recorder := httptest.NewRecorder()
 	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
@@ -48,7 +49,7 @@ func TestBalancerNoService(t *testing.T) {
 }
 
 func TestBalancerOneServerZeroWeight(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -66,7 +67,7 @@ func TestBalancerOneServerZeroWeight(t *testing.T) {
 }
 
 func TestBalancerNoServiceUp(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusInternalServerError)
@@ -86,7 +87,7 @@ func TestBalancerNoServiceUp(t *testing.T) {
 }
 
 func TestBalancerOneServerDown(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -107,7 +108,7 @@ func TestBalancerOneServerDown(t *testing.T) {
 }
 
 func TestBalancerDownThenUp(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -136,7 +137,7 @@ func TestBalancerDownThenUp(t *testing.T) {
 }
 
 func TestBalancerPropagate(t *testing.T) {
-	balancer1 := New(nil, true)
+	balancer1 := New(nil, true, nil)
 
 	balancer1.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -147,7 +148,7 @@ func TestBalancerPropagate(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	}), pointer(1), false)
 
-	balancer2 := New(nil, true)
+	balancer2 := New(nil, true, nil)
 	balancer2.Add("third", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "third")
 		rw.WriteHeader(http.StatusOK)
@@ -157,7 +158,7 @@ func TestBalancerPropagate(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	}), pointer(1), false)
 
-	topBalancer := New(nil, true)
+	topBalancer := New(nil, true, nil)
 	topBalancer.Add("balancer1", balancer1, pointer(1), false)
 	_ = balancer1.RegisterStatusUpdater(func(up bool) {
 		topBalancer.SetStatus(context.WithValue(t.Context(), serviceName, "top"), "balancer1", up)
@@ -209,7 +210,7 @@ func TestBalancerPropagate(t *testing.T) {
 }
 
 func TestBalancerAllServersZeroWeight(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("test", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), pointer(0), false)
 	balancer.Add("test2", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), pointer(0), false)
@@ -221,7 +222,7 @@ func TestBalancerAllServersZeroWeight(t *testing.T) {
 }
 
 func TestBalancerAllServersFenced(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("test", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), pointer(1), true)
 	balancer.Add("test2", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), pointer(1), true)
@@ -243,7 +244,7 @@ func TestSticky(t *testing.T) {
 			MaxAge:   42,
 			Path:     func(v string) *string { return &v }("/foo"),
 		},
-	}, false)
+	}, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -286,7 +287,7 @@ func TestSticky(t *testing.T) {
 func TestSticky_Fallback(t *testing.T) {
 	balancer := New(&dynamic.Sticky{
 		Cookie: &dynamic.Cookie{Name: "test"},
-	}, false)
+	}, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -314,7 +315,7 @@ func TestSticky_Fallback(t *testing.T) {
 
 // TestSticky_Fenced checks that fenced node receive traffic if their sticky cookie matches.
 func TestSticky_Fenced(t *testing.T) {
-	balancer := New(&dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "test"}}, false)
+	balancer := New(&dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "test"}}, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "first")
@@ -353,7 +354,7 @@ func TestSticky_Fenced(t *testing.T) {
 // TestBalancerBias makes sure that the WRR algorithm spreads elements evenly right from the start,
 // and that it does not "over-favor" the high-weighted ones with a biased start-up regime.
 func TestBalancerBias(t *testing.T) {
-	balancer := New(nil, false)
+	balancer := New(nil, false, nil)
 
 	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.Header().Set("server", "A")
@@ -376,6 +377,54 @@ func TestBalancerBias(t *testing.T) {
 	assert.Equal(t, wantSequence, recorder.sequence)
 }
 
+func TestBalancerLocality(t *testing.T) {
+	balancer := New(nil, false, loadbalancer.NewLocalityPreference(loadbalancer.Locality{Zone: "a", Region: "eu"}, nil))
+
+	for _, server := range []dynamic.Server{
+		{URL: "first", Zone: "a", Region: "eu"},
+		{URL: "second", Zone: "b", Region: "eu"},
+		{URL: "third", Zone: "c", Region: "us"},
+	} {
+		balancer.AddServer(server.URL, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", server.URL)
+			rw.WriteHeader(http.StatusOK)
+		}), server)
+	}
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+
+	// the server in the same zone is down, the one in the same region takes over.
+	balancer.SetStatus(context.WithValue(t.Context(), serviceName, "parent"), "first", false)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["second"])
+
+	// no server left in the region, the remaining one takes over.
+	balancer.SetStatus(context.WithValue(t.Context(), serviceName, "parent"), "second", false)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["third"])
+
+	// the server in the same zone is back up.
+	balancer.SetStatus(context.WithValue(t.Context(), serviceName, "parent"), "first", true)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for range 3 {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+}
+
 type responseRecorder struct {
 	*httptest.ResponseRecorder
 	save     map[string]int
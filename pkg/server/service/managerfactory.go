@@ -12,6 +12,8 @@ import (
 	"github.com/apache4/apache4/v3/pkg/metrics"
 	"github.com/apache4/apache4/v3/pkg/safe"
 	"github.com/apache4/apache4/v3/pkg/server/middleware"
+	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer"
+	"github.com/apache4/apache4/v3/pkg/tls"
 )
 
 // ManagerFactory a factory of service manager.
@@ -29,10 +31,12 @@ type ManagerFactory struct {
 	acmeHTTPHandler  http.Handler
 
 	routinesPool *safe.Pool
+
+	locality loadbalancer.Locality
 }
 
 // NewManagerFactory creates a new ManagerFactory.
-func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *safe.Pool, observabilityMgr *middleware.ObservabilityMgr, transportManager *TransportManager, proxyBuilder ProxyBuilder, acmeHTTPHandler http.Handler) *ManagerFactory {
+func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *safe.Pool, observabilityMgr *middleware.ObservabilityMgr, transportManager *TransportManager, proxyBuilder ProxyBuilder, acmeHTTPHandler http.Handler, tlsManager *tls.Manager) *ManagerFactory {
 	factory := &ManagerFactory{
 		observabilityMgr: observabilityMgr,
 		routinesPool:     routinesPool,
@@ -42,7 +46,7 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 	}
 
 	if staticConfiguration.API != nil {
-		apiRouterBuilder := api.NewBuilder(staticConfiguration)
+		apiRouterBuilder := api.NewBuilder(staticConfiguration, tlsManager)
 
 		if staticConfiguration.API.Dashboard {
 			factory.dashboardHandler = dashboard.Handler{BasePath: staticConfiguration.API.BasePath}
@@ -59,6 +63,13 @@ func NewManagerFactory(staticConfiguration static.Configuration, routinesPool *s
 		}
 	}
 
+	if staticConfiguration.Locality != nil {
+		factory.locality = loadbalancer.Locality{
+			Zone:   staticConfiguration.Locality.Zone,
+			Region: staticConfiguration.Locality.Region,
+		}
+	}
+
 	if staticConfiguration.Providers != nil && staticConfiguration.Providers.Rest != nil {
 		factory.restHandler = staticConfiguration.Providers.Rest.CreateRouter()
 	}
@@ -85,5 +96,9 @@ func (f *ManagerFactory) Build(configuration *runtime.Configuration) *Manager {
 	}
 
 	internalHandlers := NewInternalHandlers(apiHandler, f.restHandler, f.metricsHandler, f.pingHandler, f.dashboardHandler, f.acmeHTTPHandler)
-	return NewManager(configuration.Services, f.observabilityMgr, f.routinesPool, f.transportManager, f.proxyBuilder, internalHandlers)
+
+	manager := NewManager(configuration.Services, f.observabilityMgr, f.routinesPool, f.transportManager, f.proxyBuilder, internalHandlers)
+	manager.locality = f.locality
+
+	return manager
 }
@@ -0,0 +1,141 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"text/template"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+// bodyTemplate is the template of the body, implemented by both text/template and html/template.
+type bodyTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// templateData is the data the body template is executed with.
+type templateData struct {
+	Host string
+	Path string
+}
+
+// Response is an http.Handler replying to the requests with a fixed response.
+type Response struct {
+	statusCode int
+	headers    http.Header
+	body       []byte
+	template   bodyTemplate
+}
+
+// New creates a new Response handler.
+func New(config dynamic.Response) (*Response, error) {
+	if config.StatusCode < 200 || config.StatusCode > 599 {
+		return nil, fmt.Errorf("invalid status code %d: must be between 200 and 599", config.StatusCode)
+	}
+
+	if config.Body != "" && config.BodyFile != "" {
+		return nil, errors.New("body and bodyFile options are mutually exclusive")
+	}
+
+	headers := make(http.Header, len(config.Headers))
+	for name, value := range config.Headers {
+		headers.Set(name, value)
+	}
+
+	body := []byte(config.Body)
+	if config.BodyFile != "" {
+		var err error
+		body, err = os.ReadFile(config.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading body file: %w", err)
+		}
+	}
+
+	if len(body) > 0 && !bodyAllowed(config.StatusCode) {
+		return nil, fmt.Errorf("a response with status code %d cannot have a body", config.StatusCode)
+	}
+
+	r := &Response{
+		statusCode: config.StatusCode,
+		headers:    headers,
+		body:       body,
+	}
+
+	if !config.Template {
+		return r, nil
+	}
+
+	// The request values are inserted as is in the other bodies,
+	// which must not be sniffed by the browsers as HTML.
+	if headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	if headers.Get("X-Content-Type-Options") == "" {
+		headers.Set("X-Content-Type-Options", "nosniff")
+	}
+
+	var err error
+	r.template, err = parseTemplate(string(body), headers.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("parsing body template: %w", err)
+	}
+
+	return r, nil
+}
+
+func (r *Response) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	body := r.body
+
+	if r.template != nil {
+		var buf bytes.Buffer
+		if err := r.template.Execute(&buf, templateData{Host: req.Host, Path: req.URL.Path}); err != nil {
+			log.Ctx(req.Context()).Error().Err(err).Msg("Error while executing body template")
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		body = buf.Bytes()
+	}
+
+	for name, values := range r.headers {
+		rw.Header()[name] = slices.Clone(values)
+	}
+
+	if bodyAllowed(r.statusCode) {
+		rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	}
+
+	rw.WriteHeader(r.statusCode)
+
+	if req.Method == http.MethodHead {
+		return
+	}
+
+	if _, err := rw.Write(body); err != nil {
+		log.Ctx(req.Context()).Debug().Err(err).Msg("Error while writing response body")
+	}
+}
+
+// parseTemplate parses the body template.
+// The HTML bodies are parsed with html/template, so that the request values are escaped in their context.
+func parseTemplate(body, contentType string) (bodyTemplate, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == "text/html" {
+		return htmltemplate.New("body").Parse(body)
+	}
+
+	return template.New("body").Parse(body)
+}
+
+// bodyAllowed returns whether a response with the given status code can have a body.
+func bodyAllowed(statusCode int) bool {
+	return statusCode != http.StatusNoContent && statusCode != http.StatusNotModified
+}
@@ -0,0 +1,196 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.Response
+		expectedErr bool
+	}{
+		{
+			desc:   "body",
+			config: dynamic.Response{StatusCode: http.StatusOK, Body: "foo"},
+		},
+		{
+			desc:   "template",
+			config: dynamic.Response{StatusCode: http.StatusOK, Body: "{{ .Host }}", Template: true},
+		},
+		{
+			desc:        "invalid status code",
+			config:      dynamic.Response{StatusCode: 42},
+			expectedErr: true,
+		},
+		{
+			desc:        "informational status code",
+			config:      dynamic.Response{StatusCode: http.StatusContinue},
+			expectedErr: true,
+		},
+		{
+			desc:        "body and body file",
+			config:      dynamic.Response{StatusCode: http.StatusOK, Body: "foo", BodyFile: "foo.txt"},
+			expectedErr: true,
+		},
+		{
+			desc:        "missing body file",
+			config:      dynamic.Response{StatusCode: http.StatusOK, BodyFile: filepath.Join(t.TempDir(), "missing.txt")},
+			expectedErr: true,
+		},
+		{
+			desc:        "body with no content status code",
+			config:      dynamic.Response{StatusCode: http.StatusNoContent, Body: "foo"},
+			expectedErr: true,
+		},
+		{
+			desc:        "invalid template",
+			config:      dynamic.Response{StatusCode: http.StatusOK, Body: "{{ .Host", Template: true},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(test.config)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestResponse(t *testing.T) {
+	bodyFile := filepath.Join(t.TempDir(), "robots.txt")
+	require.NoError(t, os.WriteFile(bodyFile, []byte("User-agent: *\nDisallow: /\n"), 0o644))
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.Response
+		method          string
+		path            string
+		expectedStatus  int
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			desc: "status code and body",
+			config: dynamic.Response{
+				StatusCode: http.StatusGone,
+				Headers:    map[string]string{"Content-Type": "text/plain"},
+				Body:       "gone",
+			},
+			expectedStatus: http.StatusGone,
+			expectedBody:   "gone",
+			expectedHeaders: map[string]string{
+				"Content-Type":   "text/plain",
+				"Content-Length": "4",
+			},
+		},
+		{
+			desc:           "body file",
+			config:         dynamic.Response{StatusCode: http.StatusOK, BodyFile: bodyFile},
+			path:           "/robots.txt",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "User-agent: *\nDisallow: /\n",
+		},
+		{
+			desc:           "no body",
+			config:         dynamic.Response{StatusCode: http.StatusNoContent},
+			expectedStatus: http.StatusNoContent,
+			expectedHeaders: map[string]string{
+				"Content-Length": "",
+			},
+		},
+		{
+			desc: "text template",
+			config: dynamic.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       "{{ .Host }}{{ .Path }} is under maintenance",
+				Template:   true,
+			},
+			path:           "/foo/<b>",
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "example.com/foo/<b> is under maintenance",
+			expectedHeaders: map[string]string{
+				"Content-Type":           "text/plain; charset=utf-8",
+				"X-Content-Type-Options": "nosniff",
+			},
+		},
+		{
+			desc: "HTML template",
+			config: dynamic.Response{
+				StatusCode: http.StatusNotFound,
+				Headers:    map[string]string{"Content-Type": "text/html; charset=utf-8"},
+				Body:       "<p>{{ .Path }} not found</p>",
+				Template:   true,
+			},
+			path:           "/foo/<b>",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   "<p>/foo/&lt;b&gt; not found</p>",
+			expectedHeaders: map[string]string{
+				"Content-Type":           "text/html; charset=utf-8",
+				"X-Content-Type-Options": "nosniff",
+			},
+		},
+		{
+			desc: "template not enabled",
+			config: dynamic.Response{
+				StatusCode: http.StatusOK,
+				Body:       "{{ .Host }}",
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{{ .Host }}",
+		},
+		{
+			desc: "HEAD request",
+			config: dynamic.Response{
+				StatusCode: http.StatusOK,
+				Body:       "foo",
+			},
+			method:         http.MethodHead,
+			expectedStatus: http.StatusOK,
+			expectedHeaders: map[string]string{
+				"Content-Length": "3",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := New(test.config)
+			require.NoError(t, err)
+
+			method := http.MethodGet
+			if test.method != "" {
+				method = test.method
+			}
+
+			req := httptest.NewRequest(method, "http://example.com", nil)
+			req.URL.Path = test.path
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedBody, recorder.Body.String())
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(name), name)
+			}
+		})
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,18 +21,24 @@ import (
 	"github.com/apache4/apache4/v3/pkg/healthcheck"
 	"github.com/apache4/apache4/v3/pkg/logs"
 	"github.com/apache4/apache4/v3/pkg/middlewares/accesslog"
+	"github.com/apache4/apache4/v3/pkg/middlewares/circuitbreaker"
 	metricsMiddle "github.com/apache4/apache4/v3/pkg/middlewares/metrics"
 	"github.com/apache4/apache4/v3/pkg/middlewares/observability"
 	"github.com/apache4/apache4/v3/pkg/middlewares/retry"
 	"github.com/apache4/apache4/v3/pkg/proxy/httputil"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/safe"
 	"github.com/apache4/apache4/v3/pkg/server/cookie"
 	"github.com/apache4/apache4/v3/pkg/server/middleware"
 	"github.com/apache4/apache4/v3/pkg/server/provider"
+	"github.com/apache4/apache4/v3/pkg/server/service/files"
+	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer"
 	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer/failover"
 	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer/mirror"
 	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer/p2c"
 	"github.com/apache4/apache4/v3/pkg/server/service/loadbalancer/wrr"
+	"github.com/apache4/apache4/v3/pkg/server/service/response"
+	"github.com/apache4/apache4/v3/pkg/types"
 	"google.golang.org/grpc/status"
 )
 
@@ -56,7 +64,13 @@ type Manager struct {
 	services       map[string]http.Handler
 	configs        map[string]*runtime.ServiceInfo
 	healthCheckers map[string]*healthcheck.ServiceHealthChecker
-	rand           *rand.Rand // For the initial shuffling of load-balancers.
+	serversWatcher *resolver.ServersWatcher
+	// expandedServices holds the load-balancer services whose servers have been expanded into the addresses of their hosts.
+	expandedServices map[string]*expandedService
+	rand             *rand.Rand // For the initial shuffling of load-balancers.
+
+	// locality is the location of apache4, for the load-balancers to prefer the servers located nearby.
+	locality loadbalancer.Locality
 }
 
 // NewManager creates a new Manager.
@@ -70,6 +84,8 @@ func NewManager(configs map[string]*runtime.ServiceInfo, observabilityMgr *middl
 		services:         make(map[string]http.Handler),
 		configs:          configs,
 		healthCheckers:   make(map[string]*healthcheck.ServiceHealthChecker),
+		serversWatcher:   resolver.NewServersWatcher(),
+		expandedServices: make(map[string]*expandedService),
 		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
@@ -151,6 +167,24 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 			conf.AddError(err, true)
 			return nil, err
 		}
+	case conf.Files != nil:
+		log.Ctx(ctx).Debug().Str("root", conf.Files.Root).Msg("Creating files service")
+
+		var err error
+		lb, err = files.New(*conf.Files)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
+	case conf.Response != nil:
+		log.Ctx(ctx).Debug().Int("statusCode", conf.Response.StatusCode).Msg("Creating response service")
+
+		var err error
+		lb, err = response.New(*conf.Response)
+		if err != nil {
+			conf.AddError(err, true)
+			return nil, err
+		}
 	default:
 		sErr := fmt.Errorf("the service %q does not have any type defined", serviceName)
 		conf.AddError(sErr, true)
@@ -245,7 +279,7 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 		config.Sticky.Cookie.Name = cookie.GetName(config.Sticky.Cookie.Name, serviceName)
 	}
 
-	balancer := wrr.New(config.Sticky, config.HealthCheck != nil)
+	balancer := wrr.New(config.Sticky, config.HealthCheck != nil, nil)
 	for _, service := range shuffle(config.Services, m.rand) {
 		serviceHandler, err := m.getServiceHandler(ctx, service)
 		if err != nil {
@@ -308,6 +342,7 @@ func (m *Manager) getServiceHandler(ctx context.Context, service dynamic.WRRServ
 type serverBalancer interface {
 	http.Handler
 	healthcheck.StatusSetter
+	healthcheck.StatusUpdater
 
 	AddServer(name string, handler http.Handler, server dynamic.Server)
 }
@@ -315,6 +350,46 @@ type serverBalancer interface {
 func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName string, info *runtime.ServiceInfo) (http.Handler, error) {
 	service := info.LoadBalancer
 
+	if len(service.ServersTransport) > 0 {
+		service.ServersTransport = provider.GetQualifiedName(ctx, service.ServersTransport)
+	}
+
+	if service.Sticky != nil && service.Sticky.Cookie != nil {
+		service.Sticky.Cookie.Name = cookie.GetName(service.Sticky.Cookie.Name, serviceName)
+	}
+
+	lb, healthChecker, serverURLs, err := m.buildLoadBalancer(ctx, serviceName, info)
+	if err != nil {
+		return nil, err
+	}
+
+	if healthChecker != nil {
+		m.healthCheckers[serviceName] = healthChecker
+	}
+
+	if !m.serversWatcher.Watched(serviceName) {
+		return lb, nil
+	}
+
+	// The load-balancer is built again, and replaced, when the hosts of the servers resolve to other addresses.
+	switcher := newBalancerSwitcher(lb)
+	m.expandedServices[serviceName] = &expandedService{
+		switcher: switcher,
+		build: func() (serverBalancer, *healthcheck.ServiceHealthChecker, []string, error) {
+			return m.buildLoadBalancer(ctx, serviceName, info)
+		},
+		info:       info,
+		serverURLs: serverURLs,
+	}
+
+	return switcher, nil
+}
+
+// buildLoadBalancer builds the load-balancer of the given service, and its health checker if any,
+// and returns the URLs of its servers.
+func (m *Manager) buildLoadBalancer(ctx context.Context, serviceName string, info *runtime.ServiceInfo) (serverBalancer, *healthcheck.ServiceHealthChecker, []string, error) {
+	service := info.LoadBalancer
+
 	logger := log.Ctx(ctx)
 	logger.Debug().Msg("Creating load-balancer")
 
@@ -324,38 +399,38 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		flushInterval = time.Duration(service.ResponseForwarding.FlushInterval)
 	}
 
-	if len(service.ServersTransport) > 0 {
-		service.ServersTransport = provider.GetQualifiedName(ctx, service.ServersTransport)
-	}
-
-	if service.Sticky != nil && service.Sticky.Cookie != nil {
-		service.Sticky.Cookie.Name = cookie.GetName(service.Sticky.Cookie.Name, serviceName)
-	}
-
 	// We make sure that the PassHostHeader value is defined to avoid panics.
 	passHostHeader := dynamic.DefaultPassHostHeader
 	if service.PassHostHeader != nil {
 		passHostHeader = *service.PassHostHeader
 	}
 
+	locality := loadbalancer.NewLocalityPreference(m.locality, service.Locality)
+
 	var lb serverBalancer
 	switch service.Strategy {
 	// Here we are handling the empty value to comply with providers that are not applying defaults (e.g. REST provider)
 	// TODO: remove this when all providers apply default values.
 	case dynamic.BalancerStrategyWRR, "":
-		lb = wrr.New(service.Sticky, service.HealthCheck != nil)
+		lb = wrr.New(service.Sticky, service.HealthCheck != nil, locality)
 	case dynamic.BalancerStrategyP2C:
-		lb = p2c.New(service.Sticky, service.HealthCheck != nil)
+		lb = p2c.New(service.Sticky, service.HealthCheck != nil, locality)
 	default:
-		return nil, fmt.Errorf("unsupported load-balancer strategy %q", service.Strategy)
+		return nil, nil, nil, fmt.Errorf("unsupported load-balancer strategy %q", service.Strategy)
 	}
 
 	healthCheckTargets := make(map[string]*url.URL)
+	var serverURLs []string
+
+	servers, err := m.expandServers(ctx, serviceName, service.ServersTransport, shuffle(service.Servers, m.rand))
+	if err != nil {
+		return nil, nil, nil, err
+	}
 
-	for i, server := range shuffle(service.Servers, m.rand) {
+	for i, server := range servers {
 		target, err := url.Parse(server.URL)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing server URL %s: %w", server.URL, err)
+			return nil, nil, nil, fmt.Errorf("error parsing server URL %s: %w", server.URL, err)
 		}
 
 		logger.Debug().Int(logs.ServerIndex, i).Str("URL", server.URL).
@@ -365,7 +440,7 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 
 		proxy, err := m.proxyBuilder.Build(service.ServersTransport, target, passHostHeader, server.PreservePath, flushInterval)
 		if err != nil {
-			return nil, fmt.Errorf("error building proxy for server URL %s: %w", server.URL, err)
+			return nil, nil, nil, fmt.Errorf("error building proxy for server URL %s: %w", server.URL, err)
 		}
 
 		// The retry wrapping must be done just before the proxy handler,
@@ -373,9 +448,16 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		// middlewares in the chain.
 		proxy = retry.WrapHandler(proxy)
 
+		// The server scoped circuit breakers of the request keep a breaker per server URL.
+		proxy = circuitbreaker.WrapServerHandler(proxy, server.URL)
+
 		// Access logs, metrics, and tracing middlewares are idempotent if the associated signal is disabled.
 		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceURL, target.String(), nil)
-		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceAddr, target.Host, nil)
+		serviceAddr := target.Host
+		if socketPath, ok := types.UnixSocketPath(target); ok {
+			serviceAddr = socketPath
+		}
+		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceAddr, serviceAddr, nil)
 		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceName, qualifiedSvcName, accesslog.AddServiceFields)
 
 		metricsHandler := metricsMiddle.ServiceMetricsHandler(ctx, m.observabilityMgr.MetricsRegistry(), qualifiedSvcName)
@@ -385,7 +467,7 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 			Append(metricsHandler).
 			Then(proxy)
 		if err != nil {
-			return nil, fmt.Errorf("error wrapping metrics handler: %w", err)
+			return nil, nil, nil, fmt.Errorf("error wrapping metrics handler: %w", err)
 		}
 
 		proxy = observability.NewService(ctx, qualifiedSvcName, proxy)
@@ -394,17 +476,19 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 
 		// servers are considered UP by default.
 		info.UpdateServerStatus(target.String(), runtime.StatusUp)
+		serverURLs = append(serverURLs, target.String())
 
 		healthCheckTargets[server.URL] = target
 	}
 
+	var healthChecker *healthcheck.ServiceHealthChecker
 	if service.HealthCheck != nil {
 		roundTripper, err := m.transportManager.GetRoundTripper(service.ServersTransport)
 		if err != nil {
-			return nil, fmt.Errorf("getting RoundTripper: %w", err)
+			return nil, nil, nil, fmt.Errorf("getting RoundTripper: %w", err)
 		}
 
-		m.healthCheckers[serviceName] = healthcheck.NewServiceHealthChecker(
+		healthChecker = healthcheck.NewServiceHealthChecker(
 			ctx,
 			m.observabilityMgr.MetricsRegistry(),
 			service.HealthCheck,
@@ -416,15 +500,125 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		)
 	}
 
-	return lb, nil
+	return lb, healthChecker, serverURLs, nil
 }
 
 // LaunchHealthCheck launches the health checks.
 func (m *Manager) LaunchHealthCheck(ctx context.Context) {
 	for serviceName, hc := range m.healthCheckers {
 		logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
-		go hc.Launch(logger.WithContext(ctx))
+
+		hcCtx := ctx
+		if expanded, ok := m.expandedServices[serviceName]; ok {
+			// The health check of an expanded service is stopped when its load-balancer is replaced.
+			hcCtx, expanded.cancelHealthCheck = context.WithCancel(ctx)
+		}
+
+		go hc.Launch(logger.WithContext(hcCtx))
+	}
+}
+
+// WatchServers watches, until the context is done, the hosts the servers have been expanded into,
+// and builds again the load-balancer of the services whose hosts resolve to other addresses.
+func (m *Manager) WatchServers(ctx context.Context) {
+	go m.serversWatcher.Run(ctx, func(serviceName string) {
+		m.updateServers(ctx, serviceName)
+	})
+}
+
+// updateServers builds again the load-balancer of the given expanded service,
+// and replaces the current one with it, along with its health check.
+func (m *Manager) updateServers(ctx context.Context, serviceName string) {
+	expanded, ok := m.expandedServices[serviceName]
+	if !ok {
+		return
+	}
+
+	logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+
+	lb, healthChecker, serverURLs, err := expanded.build()
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to build the load-balancer again, keeping the current one")
+		return
+	}
+
+	if expanded.cancelHealthCheck != nil {
+		expanded.cancelHealthCheck()
+		expanded.cancelHealthCheck = nil
+	}
+
+	if healthChecker != nil {
+		var hcCtx context.Context
+		hcCtx, expanded.cancelHealthCheck = context.WithCancel(ctx)
+		go healthChecker.Launch(logger.WithContext(hcCtx))
+	}
+
+	expanded.switcher.Switch(lb, len(serverURLs) > 0)
+
+	for _, serverURL := range expanded.serverURLs {
+		if !slices.Contains(serverURLs, serverURL) {
+			expanded.info.RemoveServerStatus(serverURL)
+		}
+	}
+	expanded.serverURLs = serverURLs
+
+	logger.Info().Strs("servers", serverURLs).Msg("Load-balancer updated with the new addresses of the servers")
+}
+
+// expandServers expands each server with a hostname into one server per resolved address,
+// when the resolver of the ServersTransport expands the servers.
+// The servers which cannot be resolved are skipped, until their host resolves.
+func (m *Manager) expandServers(ctx context.Context, serviceName, serversTransport string, servers []dynamic.Server) ([]dynamic.Server, error) {
+	if len(servers) == 0 {
+		return servers, nil
 	}
+
+	hostResolver, err := m.transportManager.GetResolver(serversTransport)
+	if err != nil {
+		return nil, fmt.Errorf("getting resolver: %w", err)
+	}
+
+	if hostResolver == nil || !hostResolver.ExpandServers() {
+		return servers, nil
+	}
+
+	var expanded []dynamic.Server
+	for _, server := range servers {
+		target, err := url.Parse(server.URL)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing server URL %s: %w", server.URL, err)
+		}
+
+		if _, ok := types.UnixSocketPath(target); ok {
+			expanded = append(expanded, server)
+			continue
+		}
+
+		addrs, err := hostResolver.ServerAddrs(ctx, target.Hostname())
+
+		m.serversWatcher.Watch(serviceName, hostResolver, target.Hostname(), addrs)
+
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("URL", server.URL).Msg("Failed to resolve server")
+			continue
+		}
+
+		for _, addr := range addrs {
+			serverTarget := *target
+			serverTarget.Host = addr.String()
+			if port := target.Port(); port != "" {
+				serverTarget.Host = net.JoinHostPort(addr.String(), port)
+			} else if addr.Is6() {
+				serverTarget.Host = "[" + addr.String() + "]"
+			}
+
+			expandedServer := server
+			expandedServer.URL = serverTarget.String()
+			expanded = append(expanded, expandedServer)
+		}
+	}
+
+	return expanded, nil
 }
 
 func shuffle[T any](values []T, r *rand.Rand) []T {
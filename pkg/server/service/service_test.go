@@ -16,6 +16,7 @@ import (
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
 	"github.com/apache4/apache4/v3/pkg/proxy/httputil"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/server/provider"
 	"github.com/apache4/apache4/v3/pkg/testhelpers"
 )
@@ -25,6 +26,7 @@ func pointer[T any](v T) *T { return &v }
 func TestGetLoadBalancer(t *testing.T) {
 	sm := Manager{
 		transportManager: &transportManagerMock{},
+		serversWatcher:   resolver.NewServersWatcher(),
 	}
 
 	testCases := []struct {
@@ -611,3 +613,7 @@ func (t transportManagerMock) GetTLSConfig(_ string) (*tls.Config, error) {
 func (t transportManagerMock) Get(_ string) (*dynamic.ServersTransport, error) {
 	return &dynamic.ServersTransport{}, nil
 }
+
+func (t transportManagerMock) GetResolver(_ string) (*resolver.Resolver, error) {
+	return nil, nil
+}
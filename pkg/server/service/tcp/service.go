@@ -11,24 +11,39 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/runtime"
 	"github.com/apache4/apache4/v3/pkg/logs"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	"github.com/apache4/apache4/v3/pkg/server/provider"
 	"github.com/apache4/apache4/v3/pkg/tcp"
+	"github.com/apache4/apache4/v3/pkg/types"
 	"golang.org/x/net/proxy"
 )
 
 // Manager is the TCPHandlers factory.
 type Manager struct {
-	dialerManager *tcp.DialerManager
-	configs       map[string]*runtime.TCPServiceInfo
-	rand          *rand.Rand // For the initial shuffling of load-balancers.
+	dialerManager  *tcp.DialerManager
+	configs        map[string]*runtime.TCPServiceInfo
+	serversWatcher *resolver.ServersWatcher
+	// expandedServices holds the load-balancers of the services whose servers have been expanded into the addresses of their hosts,
+	// with the function building them again.
+	expandedServices map[string][]expandedService
+	rand             *rand.Rand // For the initial shuffling of load-balancers.
+}
+
+// expandedService is a load-balancer whose servers have been expanded into the addresses of their hosts,
+// and which is built again when these addresses change.
+type expandedService struct {
+	switcher *tcp.HandlerSwitcher
+	build    func() (tcp.Handler, error)
 }
 
 // NewManager creates a new manager.
 func NewManager(conf *runtime.Configuration, dialerManager *tcp.DialerManager) *Manager {
 	return &Manager{
-		dialerManager: dialerManager,
-		configs:       conf.TCPServices,
-		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		dialerManager:    dialerManager,
+		configs:          conf.TCPServices,
+		serversWatcher:   resolver.NewServersWatcher(),
+		expandedServices: make(map[string][]expandedService),
+		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
 
@@ -52,8 +67,6 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 
 	switch {
 	case conf.LoadBalancer != nil:
-		loadBalancer := tcp.NewWRRLoadBalancer()
-
 		if conf.LoadBalancer.TerminationDelay != nil {
 			log.Ctx(ctx).Warn().Msgf("Service %q load balancer uses `TerminationDelay`, but this option is deprecated, please use ServersTransport configuration instead.", serviceName)
 		}
@@ -62,40 +75,28 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 			conf.LoadBalancer.ServersTransport = provider.GetQualifiedName(ctx, conf.LoadBalancer.ServersTransport)
 		}
 
-		for index, server := range shuffle(conf.LoadBalancer.Servers, m.rand) {
-			srvLogger := logger.With().
-				Int(logs.ServerIndex, index).
-				Str("serverAddress", server.Address).Logger()
-
-			if _, _, err := net.SplitHostPort(server.Address); err != nil {
-				srvLogger.Error().Err(err).Msg("Failed to split host port")
-				continue
-			}
-
-			dialer, err := m.dialerManager.Get(conf.LoadBalancer.ServersTransport, server.TLS)
-			if err != nil {
-				return nil, err
-			}
-
-			// Handle TerminationDelay deprecated option.
-			if conf.LoadBalancer.ServersTransport == "" && conf.LoadBalancer.TerminationDelay != nil {
-				dialer = &dialerWrapper{
-					Dialer:           dialer,
-					terminationDelay: time.Duration(*conf.LoadBalancer.TerminationDelay),
-				}
-			}
+		build := func() (tcp.Handler, error) {
+			return m.buildLoadBalancer(logger.WithContext(ctx), serviceQualifiedName, conf)
+		}
 
-			handler, err := tcp.NewProxy(server.Address, conf.LoadBalancer.ProxyProtocol, dialer)
-			if err != nil {
-				srvLogger.Error().Err(err).Msg("Failed to create server")
-				continue
-			}
+		loadBalancer, err := build()
+		if err != nil {
+			return nil, err
+		}
 
-			loadBalancer.AddServer(handler)
-			logger.Debug().Msg("Creating TCP server")
+		if !m.serversWatcher.Watched(serviceQualifiedName) {
+			return loadBalancer, nil
 		}
 
-		return loadBalancer, nil
+		// The load-balancer is built again, and replaced, when the hosts of the servers resolve to other addresses.
+		switcher := &tcp.HandlerSwitcher{}
+		switcher.Switch(loadBalancer)
+		m.expandedServices[serviceQualifiedName] = append(m.expandedServices[serviceQualifiedName], expandedService{
+			switcher: switcher,
+			build:    build,
+		})
+
+		return switcher, nil
 
 	case conf.Weighted != nil:
 		loadBalancer := tcp.NewWRRLoadBalancer()
@@ -119,6 +120,121 @@ func (m *Manager) BuildTCP(rootCtx context.Context, serviceName string) (tcp.Han
 	}
 }
 
+// WatchServers watches, until the context is done, the hosts the servers have been expanded into,
+// and builds again the load-balancers of the services whose hosts resolve to other addresses.
+func (m *Manager) WatchServers(ctx context.Context) {
+	go m.serversWatcher.Run(ctx, func(serviceName string) {
+		m.updateServers(ctx, serviceName)
+	})
+}
+
+// updateServers builds again the load-balancers of the given expanded service, and replaces the current ones with them.
+func (m *Manager) updateServers(ctx context.Context, serviceName string) {
+	logger := log.Ctx(ctx).With().Str(logs.ServiceName, serviceName).Logger()
+
+	for _, expanded := range m.expandedServices[serviceName] {
+		loadBalancer, err := expanded.build()
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to build the load-balancer again, keeping the current one")
+			continue
+		}
+
+		expanded.switcher.Switch(loadBalancer)
+	}
+
+	logger.Info().Msg("Load-balancer updated with the new addresses of the servers")
+}
+
+// buildLoadBalancer builds the load-balancer of the given service.
+func (m *Manager) buildLoadBalancer(ctx context.Context, serviceName string, conf *runtime.TCPServiceInfo) (*tcp.WRRLoadBalancer, error) {
+	logger := log.Ctx(ctx)
+
+	loadBalancer := tcp.NewWRRLoadBalancer()
+
+	for index, server := range shuffle(conf.LoadBalancer.Servers, m.rand) {
+		srvLogger := logger.With().
+			Int(logs.ServerIndex, index).
+			Str("serverAddress", server.Address).Logger()
+
+		if _, ok := types.UnixSocketAddressPath(server.Address); !ok {
+			if _, _, err := net.SplitHostPort(server.Address); err != nil {
+				srvLogger.Error().Err(err).Msg("Failed to split host port")
+				continue
+			}
+		}
+
+		dialer, err := m.dialerManager.Get(conf.LoadBalancer.ServersTransport, server.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		// Handle TerminationDelay deprecated option.
+		if conf.LoadBalancer.ServersTransport == "" && conf.LoadBalancer.TerminationDelay != nil {
+			dialer = &dialerWrapper{
+				Dialer:           dialer,
+				terminationDelay: time.Duration(*conf.LoadBalancer.TerminationDelay),
+			}
+		}
+
+		addresses, err := m.serverAddresses(ctx, serviceName, conf.LoadBalancer.ServersTransport, server.Address)
+		if err != nil {
+			srvLogger.Error().Err(err).Msg("Failed to resolve server address")
+			continue
+		}
+
+		for _, address := range addresses {
+			handler, err := tcp.NewProxy(address, conf.LoadBalancer.ProxyProtocol, dialer)
+			if err != nil {
+				srvLogger.Error().Err(err).Msg("Failed to create server")
+				continue
+			}
+
+			loadBalancer.AddServer(handler)
+			logger.Debug().Str("address", address).Msg("Creating TCP server")
+		}
+	}
+
+	return loadBalancer, nil
+}
+
+// serverAddresses returns the addresses of the servers the given server address is expanded into,
+// which is the server address itself unless the resolver of the ServersTransport expands the servers.
+// The host of an expanded server is watched for the given service, even when it cannot be resolved yet.
+func (m *Manager) serverAddresses(ctx context.Context, serviceName, serversTransport, address string) ([]string, error) {
+	if _, ok := types.UnixSocketAddressPath(address); ok {
+		return []string{address}, nil
+	}
+
+	hostResolver, err := m.dialerManager.GetResolver(serversTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	if hostResolver == nil || !hostResolver.ExpandServers() {
+		return []string{address}, nil
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := hostResolver.ServerAddrs(ctx, host)
+
+	m.serversWatcher.Watch(serviceName, hostResolver, host, addrs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	for _, addr := range addrs {
+		addresses = append(addresses, net.JoinHostPort(addr.String(), port))
+	}
+
+	return addresses, nil
+}
+
 func shuffle[T any](values []T, r *rand.Rand) []T {
 	shuffled := make([]T, len(values))
 	copy(shuffled, values)
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/url"
 	"reflect"
 	"slices"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	apache4tls "github.com/apache4/apache4/v3/pkg/tls"
 	"github.com/apache4/apache4/v3/pkg/types"
 )
@@ -36,6 +38,9 @@ type TransportManager struct {
 	roundTrippers map[string]http.RoundTripper
 	configs       map[string]*dynamic.ServersTransport
 	tlsConfigs    map[string]*tls.Config
+	resolvers     map[string]*resolver.Resolver
+	// h3Transports are closed with their configuration, as they hold QUIC connections.
+	h3Transports map[string]*h3TransportWrapper
 
 	spiffeX509Source SpiffeX509Source
 }
@@ -46,6 +51,8 @@ func NewTransportManager(spiffeX509Source SpiffeX509Source) *TransportManager {
 		roundTrippers:    make(map[string]http.RoundTripper),
 		configs:          make(map[string]*dynamic.ServersTransport),
 		tlsConfigs:       make(map[string]*tls.Config),
+		resolvers:        make(map[string]*resolver.Resolver),
+		h3Transports:     make(map[string]*h3TransportWrapper),
 		spiffeX509Source: spiffeX509Source,
 	}
 }
@@ -61,6 +68,8 @@ func (t *TransportManager) Update(newConfigs map[string]*dynamic.ServersTranspor
 			delete(t.configs, configName)
 			delete(t.roundTrippers, configName)
 			delete(t.tlsConfigs, configName)
+			delete(t.resolvers, configName)
+			t.closeH3Transport(configName)
 			continue
 		}
 
@@ -68,6 +77,8 @@ func (t *TransportManager) Update(newConfigs map[string]*dynamic.ServersTranspor
 			continue
 		}
 
+		t.closeH3Transport(configName)
+
 		var err error
 
 		var tlsConfig *tls.Config
@@ -76,7 +87,13 @@ func (t *TransportManager) Update(newConfigs map[string]*dynamic.ServersTranspor
 		}
 		t.tlsConfigs[configName] = tlsConfig
 
-		t.roundTrippers[configName], err = t.createRoundTripper(newConfig, tlsConfig)
+		var hostResolver *resolver.Resolver
+		if hostResolver, err = createResolver(newConfig); err != nil {
+			log.Error().Err(err).Msgf("Could not configure HTTP Transport %s resolver, fallback on default resolution", configName)
+		}
+		t.resolvers[configName] = hostResolver
+
+		t.roundTrippers[configName], err = t.createRoundTripper(configName, newConfig, tlsConfig, hostResolver)
 		if err != nil {
 			log.Error().Err(err).Msgf("Could not configure HTTP Transport %s, fallback on default transport", configName)
 			t.roundTrippers[configName] = http.DefaultTransport
@@ -96,7 +113,13 @@ func (t *TransportManager) Update(newConfigs map[string]*dynamic.ServersTranspor
 		}
 		t.tlsConfigs[newConfigName] = tlsConfig
 
-		t.roundTrippers[newConfigName], err = t.createRoundTripper(newConfig, tlsConfig)
+		var hostResolver *resolver.Resolver
+		if hostResolver, err = createResolver(newConfig); err != nil {
+			log.Error().Err(err).Msgf("Could not configure HTTP Transport %s resolver, fallback on default resolution", newConfigName)
+		}
+		t.resolvers[newConfigName] = hostResolver
+
+		t.roundTrippers[newConfigName], err = t.createRoundTripper(newConfigName, newConfig, tlsConfig, hostResolver)
 		if err != nil {
 			log.Error().Err(err).Msgf("Could not configure HTTP Transport %s, fallback on default transport", newConfigName)
 			t.roundTrippers[newConfigName] = http.DefaultTransport
@@ -106,6 +129,19 @@ func (t *TransportManager) Update(newConfigs map[string]*dynamic.ServersTranspor
 	t.configs = newConfigs
 }
 
+// closeH3Transport closes the HTTP/3 transport of the given configuration, and its QUIC connections.
+func (t *TransportManager) closeH3Transport(configName string) {
+	h3Transport, ok := t.h3Transports[configName]
+	if !ok {
+		return
+	}
+
+	if err := h3Transport.Close(); err != nil {
+		log.Debug().Err(err).Msgf("Unexpected error while closing the HTTP/3 transport %s", configName)
+	}
+	delete(t.h3Transports, configName)
+}
+
 // GetRoundTripper gets a roundtripper corresponding to the given transport name.
 func (t *TransportManager) GetRoundTripper(name string) (http.RoundTripper, error) {
 	if len(name) == 0 {
@@ -154,6 +190,31 @@ func (t *TransportManager) GetTLSConfig(name string) (*tls.Config, error) {
 	return nil, fmt.Errorf("tls config not found %s", name)
 }
 
+// GetResolver gets the resolver of the server hostnames corresponding to the given transport name.
+// The resolver is nil when the transport does not define one.
+func (t *TransportManager) GetResolver(name string) (*resolver.Resolver, error) {
+	if len(name) == 0 {
+		name = "default@internal"
+	}
+
+	t.rtLock.RLock()
+	defer t.rtLock.RUnlock()
+
+	if _, ok := t.configs[name]; !ok {
+		return nil, fmt.Errorf("servers transport not found %s", name)
+	}
+
+	return t.resolvers[name], nil
+}
+
+func createResolver(cfg *dynamic.ServersTransport) (*resolver.Resolver, error) {
+	if cfg.Resolver == nil {
+		return nil, nil
+	}
+
+	return resolver.New(cfg.Resolver)
+}
+
 func (t *TransportManager) createTLSConfig(cfg *dynamic.ServersTransport) (*tls.Config, error) {
 	var config *tls.Config
 	if cfg.Spiffe != nil {
@@ -195,7 +256,7 @@ func (t *TransportManager) createTLSConfig(cfg *dynamic.ServersTransport) (*tls.
 // For the settings that can't be configured in apache4 it uses the default http.Transport settings.
 // An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost in apache4 at this point in time.
 // Setting this value to the default of 100 could lead to confusing behavior and backwards compatibility issues.
-func (t *TransportManager) createRoundTripper(cfg *dynamic.ServersTransport, tlsConfig *tls.Config) (http.RoundTripper, error) {
+func (t *TransportManager) createRoundTripper(configName string, cfg *dynamic.ServersTransport, tlsConfig *tls.Config, hostResolver *resolver.Resolver) (http.RoundTripper, error) {
 	if cfg == nil {
 		return nil, errors.New("no transport configuration given")
 	}
@@ -209,9 +270,14 @@ func (t *TransportManager) createRoundTripper(cfg *dynamic.ServersTransport, tls
 		dialer.Timeout = time.Duration(cfg.ForwardingTimeouts.DialTimeout)
 	}
 
+	dialContext := resolver.DialContextFunc(dialer.DialContext)
+	if hostResolver != nil {
+		dialContext = hostResolver.DialContext(dialContext)
+	}
+
 	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
+		Proxy:                 proxyFromEnvironment,
+		DialContext:           unixSocketDialContext(dialer, dialContext),
 		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
@@ -226,6 +292,11 @@ func (t *TransportManager) createRoundTripper(cfg *dynamic.ServersTransport, tls
 		transport.IdleConnTimeout = time.Duration(cfg.ForwardingTimeouts.IdleConnTimeout)
 	}
 
+	// The servers with the h3 scheme are reached over HTTP/3, with the same TLS configuration.
+	h3Transport := newH3TransportWrapper(cfg.HTTP3, tlsConfig, dialer.Timeout)
+	t.h3Transports[configName] = h3Transport
+	transport.RegisterProtocol(schemeH3, h3Transport)
+
 	// Return directly HTTP/1.1 transport when HTTP/2 is disabled
 	if cfg.DisableHTTP2 {
 		return &kerberosRoundTripper{
@@ -248,6 +319,27 @@ func (t *TransportManager) createRoundTripper(cfg *dynamic.ServersTransport, tls
 	}, nil
 }
 
+// unixSocketDialContext returns a DialContext function dialing the Unix domain socket encoded in the address host, if any,
+// and falling back to the given dial function otherwise.
+func unixSocketDialContext(dialer *net.Dialer, dialContext resolver.DialContextFunc) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if path, ok := types.UnixSocketFromAddr(addr); ok {
+			return dialer.DialContext(ctx, types.UnixSocketScheme, path)
+		}
+
+		return dialContext(ctx, network, addr)
+	}
+}
+
+// proxyFromEnvironment is http.ProxyFromEnvironment, except that Unix domain socket servers are never reached through a proxy.
+func proxyFromEnvironment(req *http.Request) (*url.URL, error) {
+	if types.IsUnixSocketHost(req.URL.Host) {
+		return nil, nil
+	}
+
+	return http.ProxyFromEnvironment(req)
+}
+
 type stickyRoundTripper struct {
 	RoundTripper http.RoundTripper
 }
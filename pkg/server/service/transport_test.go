@@ -6,21 +6,26 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"io"
 	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/apache4/paerser/types"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
 	apache4tls "github.com/apache4/apache4/v3/pkg/tls"
 	"github.com/apache4/apache4/v3/pkg/types"
@@ -435,6 +440,160 @@ func TestDisableHTTP2(t *testing.T) {
 	}
 }
 
+func TestHTTP3(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config *dynamic.HTTP3ClientConfig
+	}{
+		{
+			desc: "default configuration",
+		},
+		{
+			desc: "0-RTT allowed",
+			config: &dynamic.HTTP3ClientConfig{
+				MaxIdleTimeout:  ptypes.Duration(10 * time.Second),
+				KeepAlivePeriod: ptypes.Duration(time.Second),
+				Allow0RTT:       true,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert, err := tls.X509KeyPair(LocalhostCert, LocalhostKey)
+			require.NoError(t, err)
+
+			clientPool := x509.NewCertPool()
+			clientPool.AppendCertsFromPEM(mTLSCert)
+
+			conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+			require.NoError(t, err)
+
+			srv := &http3.Server{
+				Handler: http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					rw.Header().Set("X-Method", req.Method)
+					rw.WriteHeader(http.StatusOK)
+				}),
+				TLSConfig: http3.ConfigureTLSConfig(&tls.Config{
+					Certificates: []tls.Certificate{cert},
+					ClientAuth:   tls.RequireAndVerifyClientCert,
+					ClientCAs:    clientPool,
+				}),
+				QUICConfig: &quic.Config{Allow0RTT: true},
+			}
+			go func() { _ = srv.Serve(conn) }()
+			t.Cleanup(func() { _ = srv.Close() })
+
+			transportManager := NewTransportManager(nil)
+
+			dynamicConf := map[string]*dynamic.ServersTransport{
+				"test": {
+					ServerName: "example.com",
+					RootCAs:    []types.FileOrContent{types.FileOrContent(LocalhostCert)},
+					Certificates: apache4tls.Certificates{
+						apache4tls.Certificate{
+							CertFile: types.FileOrContent(mTLSCert),
+							KeyFile:  types.FileOrContent(mTLSKey),
+						},
+					},
+					HTTP3: test.config,
+				},
+			}
+
+			transportManager.Update(dynamicConf)
+
+			tr, err := transportManager.GetRoundTripper("test")
+			require.NoError(t, err)
+
+			client := http.Client{Transport: tr}
+
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				req, err := http.NewRequest(method, "h3://"+conn.LocalAddr().String(), http.NoBody)
+				require.NoError(t, err)
+
+				resp, err := client.Do(req)
+				require.NoError(t, err)
+				_ = resp.Body.Close()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "HTTP/3.0", resp.Proto)
+				assert.Equal(t, method, resp.Header.Get("X-Method"))
+				assert.Equal(t, "h3", req.URL.Scheme)
+			}
+		})
+	}
+}
+
+func TestTransportManager_Update_h3Transports(t *testing.T) {
+	transportManager := NewTransportManager(nil)
+
+	transportManager.Update(map[string]*dynamic.ServersTransport{
+		"foo": {},
+		"bar": {},
+	})
+	require.Len(t, transportManager.h3Transports, 2)
+
+	foo := transportManager.h3Transports["foo"]
+	bar := transportManager.h3Transports["bar"]
+
+	transportManager.Update(map[string]*dynamic.ServersTransport{
+		"foo": {},
+		"bar": {ServerName: "example.com"},
+	})
+	require.Len(t, transportManager.h3Transports, 2)
+
+	// The unchanged transport is kept, and the changed one is replaced.
+	assert.Same(t, foo, transportManager.h3Transports["foo"])
+	assert.NotSame(t, bar, transportManager.h3Transports["bar"])
+
+	transportManager.Update(map[string]*dynamic.ServersTransport{})
+	assert.Empty(t, transportManager.h3Transports)
+}
+
+func TestIsReplayable(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		method   string
+		body     io.Reader
+		expected bool
+	}{
+		{
+			desc:     "GET without body",
+			method:   http.MethodGet,
+			expected: true,
+		},
+		{
+			desc:     "HEAD without body",
+			method:   http.MethodHead,
+			expected: true,
+		},
+		{
+			desc:   "GET with body",
+			method: http.MethodGet,
+			body:   strings.NewReader("foo"),
+		},
+		{
+			desc:   "POST without body",
+			method: http.MethodPost,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(test.method, "h3://127.0.0.1", test.body)
+			if test.body == nil {
+				req.Body = http.NoBody
+			}
+
+			assert.Equal(t, test.expected, isReplayable(req))
+		})
+	}
+}
+
 // fakeSpiffePKI simulates a SPIFFE aware PKI and allows generating multiple valid SVIDs.
 type fakeSpiffePKI struct {
 	caPrivateKey *rsa.PrivateKey
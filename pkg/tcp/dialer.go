@@ -15,6 +15,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/resolver"
 	apache4tls "github.com/apache4/apache4/v3/pkg/tls"
 	"github.com/apache4/apache4/v3/pkg/types"
 	"golang.org/x/net/proxy"
@@ -46,6 +47,7 @@ type DialerManager struct {
 	rtLock           sync.RWMutex
 	dialers          map[string]Dialer
 	dialersTLS       map[string]Dialer
+	resolvers        map[string]*resolver.Resolver
 	spiffeX509Source SpiffeX509Source
 }
 
@@ -54,6 +56,7 @@ func NewDialerManager(spiffeX509Source SpiffeX509Source) *DialerManager {
 	return &DialerManager{
 		dialers:          make(map[string]Dialer),
 		dialersTLS:       make(map[string]Dialer),
+		resolvers:        make(map[string]*resolver.Resolver),
 		spiffeX509Source: spiffeX509Source,
 	}
 }
@@ -65,6 +68,7 @@ func (d *DialerManager) Update(configs map[string]*dynamic.TCPServersTransport)
 
 	d.dialers = make(map[string]Dialer)
 	d.dialersTLS = make(map[string]Dialer)
+	d.resolvers = make(map[string]*resolver.Resolver)
 	for configName, config := range configs {
 		if err := d.createDialers(configName, config); err != nil {
 			log.Debug().
@@ -99,6 +103,23 @@ func (d *DialerManager) Get(name string, tls bool) (Dialer, error) {
 	return nil, fmt.Errorf("TCP dialer not found %s", name)
 }
 
+// GetResolver gets the resolver of the server hostnames of the given dialer name.
+// The resolver is nil when the dialer does not define one.
+func (d *DialerManager) GetResolver(name string) (*resolver.Resolver, error) {
+	if len(name) == 0 {
+		name = "default@internal"
+	}
+
+	d.rtLock.RLock()
+	defer d.rtLock.RUnlock()
+
+	if _, ok := d.dialers[name]; !ok {
+		return nil, fmt.Errorf("TCP dialer not found %s", name)
+	}
+
+	return d.resolvers[name], nil
+}
+
 // createDialers creates the dialers according to the TCPServersTransport configuration.
 func (d *DialerManager) createDialers(name string, cfg *dynamic.TCPServersTransport) error {
 	if cfg == nil {
@@ -146,6 +167,21 @@ func (d *DialerManager) createDialers(name string, cfg *dynamic.TCPServersTransp
 		}
 	}
 
+	if cfg.Resolver != nil {
+		hostResolver, err := resolver.New(cfg.Resolver)
+		if err != nil {
+			return fmt.Errorf("unable to create resolver: %w", err)
+		}
+
+		dial := hostResolver.DialContext(dialer.DialContext)
+
+		d.resolvers[name] = hostResolver
+		d.dialers[name] = tcpDialer{dial, time.Duration(cfg.TerminationDelay)}
+		d.dialersTLS[name] = tcpDialer{resolver.DialTLSContext(dial, tlsConfig), time.Duration(cfg.TerminationDelay)}
+
+		return nil
+	}
+
 	tlsDialer := &tls.Dialer{
 		NetDialer: dialer,
 		Config:    tlsConfig,
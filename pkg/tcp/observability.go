@@ -0,0 +1,278 @@
+package tcp
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer giving the apache4 tracer from the span of a connection.
+const tracerName = "github.com/apache4/apache4"
+
+// ObservedConn is a connection accepted by an entry point, which can be traced.
+// Its span is only started when the connection is handled by a TCP router with tracing enabled,
+// so that the connections forwarded to the HTTP servers are not traced,
+// and is ended once the router is done with the connection, recording the bytes transferred.
+type ObservedConn struct {
+	WriteCloser
+
+	tracer         trace.Tracer
+	entryPointName string
+	// tracing defines whether the connection is traced when the TCP router handling it does not define it.
+	tracing    bool
+	acceptedAt time.Time
+
+	mu         sync.Mutex
+	ctx        context.Context
+	span       trace.Span
+	ended      bool
+	serverName string
+	alpnProtos []string
+
+	bytesReceived atomic.Int64
+	bytesSent     atomic.Int64
+}
+
+// NewObservedConn creates a new ObservedConn.
+func NewObservedConn(conn WriteCloser, tracer trace.Tracer, entryPointName string, tracing bool) *ObservedConn {
+	return &ObservedConn{
+		WriteCloser:    conn,
+		tracer:         tracer,
+		entryPointName: entryPointName,
+		tracing:        tracing,
+		acceptedAt:     time.Now(),
+	}
+}
+
+// TracingEnabled returns whether the connection is traced, according to the tracing option of the TCP router handling it.
+func (c *ObservedConn) TracingEnabled(routerTracing *bool) bool {
+	if c.tracer == nil {
+		return false
+	}
+
+	if routerTracing != nil {
+		return *routerTracing
+	}
+
+	return c.tracing
+}
+
+// SetRoutingData records the server name and the ALPN protocols sent by the client, used to route the connection.
+func (c *ObservedConn) SetRoutingData(serverName string, alpnProtos []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.serverName = serverName
+	c.alpnProtos = alpnProtos
+}
+
+// Start starts the span of the connection, from the time it was accepted, if not already started,
+// and returns the context holding it.
+func (c *ObservedConn) Start() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.span != nil || c.ended || c.tracer == nil {
+		return c.context()
+	}
+
+	c.ctx, c.span = c.tracer.Start(context.Background(), "EntryPoint", trace.WithSpanKind(trace.SpanKindServer), trace.WithTimestamp(c.acceptedAt))
+
+	c.span.SetAttributes(attribute.String("entry_point", c.entryPointName))
+	c.span.SetAttributes(semconv.NetworkTransportTCP)
+	setClientAttributes(c.span, c.RemoteAddr())
+
+	if c.serverName != "" {
+		c.span.SetAttributes(semconv.TLSClientServerName(c.serverName))
+	}
+	if len(c.alpnProtos) > 0 {
+		c.span.SetAttributes(attribute.StringSlice("tls.client.alpn_protocols", c.alpnProtos))
+	}
+
+	return c.ctx
+}
+
+// Context returns the context holding the span of the connection,
+// or an empty context if the connection is not traced.
+func (c *ObservedConn) Context() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.context()
+}
+
+func (c *ObservedConn) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+// Read reads data from the connection, counting the bytes received.
+func (c *ObservedConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	c.bytesReceived.Add(int64(n))
+
+	return n, err
+}
+
+// Write writes data to the connection, counting the bytes sent.
+func (c *ObservedConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	c.bytesSent.Add(int64(n))
+
+	return n, err
+}
+
+// End ends the span of the connection, recording the bytes transferred.
+func (c *ObservedConn) End() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ended {
+		return
+	}
+	c.ended = true
+
+	if c.span == nil {
+		return
+	}
+
+	c.span.SetAttributes(attribute.Int64("apache4.connection.received_bytes", c.bytesReceived.Load()))
+	c.span.SetAttributes(attribute.Int64("apache4.connection.sent_bytes", c.bytesSent.Load()))
+	c.span.End()
+}
+
+// ObservedConnFrom returns the ObservedConn underlying the given connection, if any.
+func ObservedConnFrom(conn net.Conn) *ObservedConn {
+	for conn != nil {
+		switch c := conn.(type) {
+		case *ObservedConn:
+			return c
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// ContextFromConn returns the tracing context of the given connection,
+// or an empty context if the connection is not traced.
+func ContextFromConn(conn net.Conn) context.Context {
+	for conn != nil {
+		switch c := conn.(type) {
+		case interface{ Context() context.Context }:
+			return c.Context()
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return context.Background()
+		}
+	}
+
+	return context.Background()
+}
+
+// WithContext returns the connection holding the given tracing context,
+// for the handlers to start their spans from it.
+func WithContext(conn WriteCloser, ctx context.Context) WriteCloser {
+	return &contextConn{WriteCloser: conn, ctx: ctx}
+}
+
+type contextConn struct {
+	WriteCloser
+
+	ctx context.Context
+}
+
+func (c *contextConn) Context() context.Context {
+	return c.ctx
+}
+
+// NetConn returns the underlying connection.
+func (c *contextConn) NetConn() net.Conn {
+	return c.WriteCloser
+}
+
+// StartSpan starts a span from the tracing context of the connection, if the connection is traced.
+// The returned span is nil otherwise.
+func StartSpan(conn net.Conn, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx := ContextFromConn(conn)
+
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, nil
+	}
+
+	return parent.TracerProvider().Tracer(tracerName).Start(ctx, spanName, opts...)
+}
+
+// endSpan ends the span, setting its status from the given error.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// setClientAttributes sets the attributes of the client connected from the given address.
+func setClientAttributes(span trace.Span, addr net.Addr) {
+	if addr == nil {
+		return
+	}
+
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		span.SetAttributes(semconv.ClientAddress(addr.String()))
+		return
+	}
+
+	intPort, _ := strconv.Atoi(port)
+	span.SetAttributes(semconv.ClientAddress(host))
+	span.SetAttributes(semconv.ClientPort(intPort))
+
+	setNetworkPeerAttributes(span, addr)
+}
+
+// setServerAttributes sets the attributes of the server dialed at the given address.
+func setServerAttributes(span trace.Span, address string) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		span.SetAttributes(semconv.ServerAddress(address))
+		return
+	}
+
+	intPort, _ := strconv.Atoi(port)
+	span.SetAttributes(semconv.ServerAddress(host))
+	span.SetAttributes(semconv.ServerPort(intPort))
+}
+
+// setNetworkPeerAttributes sets the attributes of the peer of a connection.
+func setNetworkPeerAttributes(span trace.Span, addr net.Addr) {
+	if addr == nil {
+		return
+	}
+
+	host, port, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return
+	}
+
+	intPort, _ := strconv.Atoi(port)
+	span.SetAttributes(semconv.NetworkPeerAddress(host))
+	span.SetAttributes(semconv.NetworkPeerPort(intPort))
+}
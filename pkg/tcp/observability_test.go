@@ -0,0 +1,198 @@
+package tcp
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/utils/ptr"
+)
+
+func TestObservedConn_TracingEnabled(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		noTracer      bool
+		tracing       bool
+		routerTracing *bool
+		expected      bool
+	}{
+		{
+			desc:     "entry point default",
+			tracing:  true,
+			expected: true,
+		},
+		{
+			desc:          "router enables tracing",
+			routerTracing: ptr.To(true),
+			expected:      true,
+		},
+		{
+			desc:          "router disables tracing",
+			tracing:       true,
+			routerTracing: ptr.To(false),
+		},
+		{
+			desc:          "no tracer",
+			noTracer:      true,
+			tracing:       true,
+			routerTracing: ptr.To(true),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var tracer trace.Tracer
+			if !test.noTracer {
+				tracer = sdktrace.NewTracerProvider().Tracer("test")
+			}
+
+			conn := NewObservedConn(&fakeConn{}, tracer, "foo", test.tracing)
+
+			assert.Equal(t, test.expected, conn.TracingEnabled(test.routerTracing))
+		})
+	}
+}
+
+func TestObservedConn(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+
+	serverConn, clientConn := tcpConnPair(t)
+
+	conn := NewObservedConn(serverConn, tracer, "foo", true)
+	conn.SetRoutingData("example.com", []string{"h2"})
+
+	// The connection is not traced until started.
+	_, span := StartSpan(conn, "Router")
+	assert.Nil(t, span)
+
+	_, err := clientConn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("pong!"))
+	require.NoError(t, err)
+
+	ctx := conn.Start()
+	assert.Equal(t, ctx, ContextFromConn(conn))
+
+	_, span = StartSpan(WithContext(conn, ctx), "Router")
+	require.NotNil(t, span)
+	span.End()
+
+	assert.Same(t, conn, ObservedConnFrom(WithContext(conn, ctx)))
+
+	conn.End()
+	conn.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "Router", spans[0].Name)
+	assert.Equal(t, "EntryPoint", spans[1].Name)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+
+	assert.Equal(t, trace.SpanKindServer, spans[1].SpanKind)
+	assert.Contains(t, spans[1].Attributes, attribute.String("entry_point", "foo"))
+	assert.Contains(t, spans[1].Attributes, attribute.String("tls.client.server_name", "example.com"))
+	assert.Contains(t, spans[1].Attributes, attribute.StringSlice("tls.client.alpn_protocols", []string{"h2"}))
+	assert.Contains(t, spans[1].Attributes, attribute.Int64("apache4.connection.received_bytes", 4))
+	assert.Contains(t, spans[1].Attributes, attribute.Int64("apache4.connection.sent_bytes", 5))
+
+	// The span is not started again once the connection is ended.
+	conn.Start()
+	conn.End()
+	assert.Len(t, exporter.GetSpans(), 2)
+}
+
+func TestProxy_tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)).Tracer("test")
+
+	backendListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = backendListener.Close() })
+
+	go func() {
+		conn, err := backendListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	proxy, err := NewProxy(backendListener.Addr().String(), nil, tcpDialer{&net.Dialer{}, 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	serverConn, clientConn := tcpConnPair(t)
+
+	conn := NewObservedConn(serverConn, tracer, "foo", true)
+	ctx := conn.Start()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		proxy.ServeTCP(WithContext(conn, ctx))
+	}()
+
+	_, err = clientConn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	err = clientConn.CloseWrite()
+	require.NoError(t, err)
+
+	resp, err := io.ReadAll(clientConn)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(resp))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("proxy did not return")
+	}
+
+	conn.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "Dial", spans[0].Name)
+	assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+	assert.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	assert.Contains(t, spans[0].Attributes, attribute.String("server.address", "127.0.0.1"))
+
+	assert.Contains(t, spans[1].Attributes, attribute.Int64("apache4.connection.received_bytes", 4))
+	assert.Contains(t, spans[1].Attributes, attribute.Int64("apache4.connection.sent_bytes", 4))
+}
+
+func tcpConnPair(t *testing.T) (*net.TCPConn, *net.TCPConn) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	clientConn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientConn.Close() })
+
+	serverConn, err := listener.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverConn.Close() })
+
+	return serverConn.(*net.TCPConn), clientConn.(*net.TCPConn)
+}
@@ -11,11 +11,16 @@ import (
 	"github.com/pires/go-proxyproto"
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/dynamic"
+	"github.com/apache4/apache4/v3/pkg/types"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Proxy forwards a TCP request to a TCP service.
 type Proxy struct {
 	address       string
+	network       string
+	dialAddress   string
 	proxyProtocol *dynamic.ProxyProtocol
 	dialer        Dialer
 }
@@ -26,8 +31,15 @@ func NewProxy(address string, proxyProtocol *dynamic.ProxyProtocol, dialer Diale
 		return nil, fmt.Errorf("unknown proxyProtocol version: %d", proxyProtocol.Version)
 	}
 
+	network, dialAddress := "tcp", address
+	if socketPath, ok := types.UnixSocketAddressPath(address); ok {
+		network, dialAddress = types.UnixSocketScheme, socketPath
+	}
+
 	return &Proxy{
 		address:       address,
+		network:       network,
+		dialAddress:   dialAddress,
 		proxyProtocol: proxyProtocol,
 		dialer:        dialer,
 	}, nil
@@ -43,7 +55,7 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	// needed because of e.g. server.trackedConnection
 	defer conn.Close()
 
-	connBackend, err := p.dialBackend()
+	connBackend, err := p.dialBackend(conn)
 	if err != nil {
 		log.Error().Err(err).Msg("Error while dialing backend")
 		return
@@ -79,8 +91,22 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	<-errChan
 }
 
-func (p *Proxy) dialBackend() (WriteCloser, error) {
-	conn, err := p.dialer.Dial("tcp", p.address)
+func (p *Proxy) dialBackend(clientConn WriteCloser) (WriteCloser, error) {
+	_, span := StartSpan(clientConn, "Dial", trace.WithSpanKind(trace.SpanKindClient))
+	if span != nil {
+		span.SetAttributes(semconv.NetworkTransportKey.String(p.network))
+		setServerAttributes(span, p.dialAddress)
+	}
+
+	conn, err := p.dialer.Dial(p.network, p.dialAddress)
+
+	if span != nil {
+		if err == nil {
+			setNetworkPeerAttributes(span, conn.RemoteAddr())
+		}
+		endSpan(span, err)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -88,6 +89,40 @@ func TestCloseWrite(t *testing.T) {
 	require.Equal(t, "PONG", buffer.String())
 }
 
+func TestProxy_unixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "backend.sock")
+
+	backendListener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	go fakeRedis(t, backendListener)
+
+	proxy, err := NewProxy("unix://"+socketPath, nil, tcpDialer{&net.Dialer{}, 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	proxyListener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+
+	go func() {
+		conn, err := proxyListener.Accept()
+		require.NoError(t, err)
+		proxy.ServeTCP(conn.(*net.TCPConn))
+	}()
+
+	conn, err := net.Dial("tcp", proxyListener.Addr().String())
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("ping\n"))
+	require.NoError(t, err)
+
+	err = conn.(*net.TCPConn).CloseWrite()
+	require.NoError(t, err)
+
+	resp, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", string(resp))
+}
+
 func TestProxyProtocol(t *testing.T) {
 	testCases := []struct {
 		desc    string
@@ -2,6 +2,10 @@ package tcp
 
 import (
 	"crypto/tls"
+
+	"github.com/rs/zerolog/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TLSHandler handles TLS connections.
@@ -12,5 +16,30 @@ type TLSHandler struct {
 
 // ServeTCP terminates the TLS connection.
 func (t *TLSHandler) ServeTCP(conn WriteCloser) {
-	t.Next.ServeTCP(tls.Server(conn, t.Config))
+	tlsConn := tls.Server(conn, t.Config)
+
+	// When the connection is traced, the handshake is done right away to be traced on its own,
+	// instead of during the first read.
+	if ctx, span := StartSpan(conn, "TLS", trace.WithSpanKind(trace.SpanKindInternal)); span != nil {
+		err := tlsConn.HandshakeContext(ctx)
+		if err == nil {
+			state := tlsConn.ConnectionState()
+			span.SetAttributes(semconv.TLSProtocolVersion(tls.VersionName(state.Version)))
+			span.SetAttributes(semconv.TLSCipher(tls.CipherSuiteName(state.CipherSuite)))
+			span.SetAttributes(semconv.TLSResumed(state.DidResume))
+			if state.NegotiatedProtocol != "" {
+				span.SetAttributes(semconv.TLSNextProtocol(state.NegotiatedProtocol))
+			}
+		}
+
+		endSpan(span, err)
+
+		if err != nil {
+			log.Debug().Err(err).Msg("Error during the TLS handshake")
+			_ = tlsConn.Close()
+			return
+		}
+	}
+
+	t.Next.ServeTCP(tlsConn)
 }
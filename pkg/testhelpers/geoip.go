@@ -0,0 +1,158 @@
+package testhelpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Elements of the MaxMind DB format, as described in https://maxmind.github.io/MaxMind-DB/.
+const (
+	mmdbDataSectionSeparatorSize = 16
+
+	mmdbTypeString = 2
+	mmdbTypeUint32 = 6
+	mmdbTypeMap    = 7
+)
+
+// WriteGeoIPDatabase writes a MaxMind DB file holding the given records, keyed by network.
+// The records hold strings, uint32 and maps of them.
+func WriteGeoIPDatabase(t *testing.T, path string, recordSize int, records map[string]map[string]any) {
+	t.Helper()
+
+	type node struct {
+		// children are node indexes, or -1 for no data, or -2-i for the i-th data.
+		children [2]int
+	}
+
+	nodes := []node{{children: [2]int{-1, -1}}}
+
+	var data bytes.Buffer
+	var dataOffsets []int
+
+	networks := make([]string, 0, len(records))
+	for network := range records {
+		networks = append(networks, network)
+	}
+	slices.Sort(networks)
+
+	for _, network := range networks {
+		prefix := netip.MustParsePrefix(network)
+
+		addr := prefix.Addr().As16()
+		bits := prefix.Bits()
+		if prefix.Addr().Is4() {
+			// The IPv4 networks are stored in the ::/96 subtree.
+			addr = [16]byte{}
+			copy(addr[12:], prefix.Addr().AsSlice())
+			bits += 96
+		}
+
+		dataOffsets = append(dataOffsets, data.Len())
+		encodeMMDBValue(t, &data, records[network])
+
+		current := 0
+		for i := range bits {
+			bit := (addr[i/8] >> (7 - i%8)) & 1
+
+			if i == bits-1 {
+				nodes[current].children[bit] = -2 - (len(dataOffsets) - 1)
+				break
+			}
+
+			next := nodes[current].children[bit]
+			if next < 0 {
+				nodes = append(nodes, node{children: [2]int{-1, -1}})
+				next = len(nodes) - 1
+				nodes[current].children[bit] = next
+			}
+			current = next
+		}
+	}
+
+	nodeCount := len(nodes)
+
+	var file bytes.Buffer
+	for _, n := range nodes {
+		var records [2]uint32
+		for i, child := range n.children {
+			switch {
+			case child == -1:
+				records[i] = uint32(nodeCount)
+			case child < -1:
+				records[i] = uint32(nodeCount + mmdbDataSectionSeparatorSize + dataOffsets[-2-child])
+			default:
+				records[i] = uint32(child)
+			}
+		}
+
+		switch recordSize {
+		case 24:
+			file.Write([]byte{byte(records[0] >> 16), byte(records[0] >> 8), byte(records[0])})
+			file.Write([]byte{byte(records[1] >> 16), byte(records[1] >> 8), byte(records[1])})
+		case 28:
+			file.Write([]byte{byte(records[0] >> 16), byte(records[0] >> 8), byte(records[0])})
+			file.WriteByte(byte(records[0]>>24)<<4 | byte(records[1]>>24)&0x0F)
+			file.Write([]byte{byte(records[1] >> 16), byte(records[1] >> 8), byte(records[1])})
+		default:
+			file.Write(binary.BigEndian.AppendUint32(nil, records[0]))
+			file.Write(binary.BigEndian.AppendUint32(nil, records[1]))
+		}
+	}
+
+	file.Write(make([]byte, mmdbDataSectionSeparatorSize))
+	file.Write(data.Bytes())
+	file.WriteString("\xAB\xCD\xEFMaxMind.com")
+	encodeMMDBValue(t, &file, map[string]any{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint32(recordSize),
+		"ip_version":                  uint32(6),
+		"binary_format_major_version": uint32(2),
+		"database_type":               "Test",
+	})
+
+	err := os.WriteFile(path, file.Bytes(), 0o644)
+	require.NoError(t, err)
+}
+
+func encodeMMDBValue(t *testing.T, buffer *bytes.Buffer, value any) {
+	t.Helper()
+
+	switch v := value.(type) {
+	case string:
+		require.Less(t, len(v), 285)
+		if len(v) < 29 {
+			buffer.WriteByte(mmdbTypeString<<5 | byte(len(v)))
+		} else {
+			buffer.Write([]byte{mmdbTypeString<<5 | 29, byte(len(v) - 29)})
+		}
+		buffer.WriteString(v)
+
+	case uint32:
+		buffer.WriteByte(mmdbTypeUint32<<5 | 4)
+		buffer.Write(binary.BigEndian.AppendUint32(nil, v))
+
+	case map[string]any:
+		require.Less(t, len(v), 29)
+		buffer.WriteByte(mmdbTypeMap<<5 | byte(len(v)))
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			encodeMMDBValue(t, buffer, k)
+			encodeMMDBValue(t, buffer, v[k])
+		}
+
+	default:
+		t.Fatalf("unsupported value type %T", value)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/patrickmn/go-cache"
@@ -17,6 +18,17 @@ import (
 type CertificateData struct {
 	Hash        string
 	Certificate *tls.Certificate
+	// Source is the origin of the certificate, i.e. the name of the provider defining it,
+	// or how the default certificate of a store is obtained.
+	Source string
+}
+
+// CertificateResolver resolves, during the TLS handshake, a certificate for a server name
+// that none of the certificates of a store matches.
+type CertificateResolver interface {
+	// ResolveCertificate returns the certificate to serve for the given ClientHello,
+	// or nil if the resolver is not able to provide one.
+	ResolveCertificate(clientHello *tls.ClientHelloInfo) (*tls.Certificate, error)
 }
 
 // CertificateStore store for dynamic certificates.
@@ -26,6 +38,8 @@ type CertificateStore struct {
 	CertCache          *cache.Cache
 
 	ocspStapler *ocspStapler
+	// onDemandResolvers is swapped as a whole, as resolvers can be registered while handshakes are served.
+	onDemandResolvers atomic.Pointer[[]CertificateResolver]
 }
 
 // NewCertificateStore create a store for dynamic certificates.
@@ -99,6 +113,26 @@ func (c *CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo)
 		return certificateData.Certificate
 	}
 
+	if certificateData := c.matchCertificate(serverName); certificateData != nil {
+		// cache best match
+		c.CertCache.SetDefault(serverName, certificateData)
+
+		if c.ocspStapler != nil && certificateData.Hash != "" {
+			if staple, ok := c.ocspStapler.GetStaple(certificateData.Hash); ok {
+				// We are updating the OCSPStaple of the certificate without any synchronization
+				// as this should not cause any issue.
+				certificateData.Certificate.OCSPStaple = staple
+			}
+		}
+
+		return certificateData.Certificate
+	}
+
+	return c.resolveOnDemand(clientHello)
+}
+
+// matchCertificate returns the dynamic certificate best matching the given server name, if any.
+func (c *CertificateStore) matchCertificate(serverName string) *CertificateData {
 	matchedCerts := map[string]*CertificateData{}
 	if c.DynamicCerts != nil && c.DynamicCerts.Get() != nil {
 		for domains, cert := range c.DynamicCerts.Get().(map[string]*CertificateData) {
@@ -110,32 +144,47 @@ func (c *CertificateStore) GetBestCertificate(clientHello *tls.ClientHelloInfo)
 		}
 	}
 
-	if len(matchedCerts) > 0 {
-		// sort map by keys
-		keys := make([]string, 0, len(matchedCerts))
-		for k := range matchedCerts {
-			keys = append(keys, k)
-		}
-		sort.Strings(keys)
+	if len(matchedCerts) == 0 {
+		return nil
+	}
 
-		// cache best match
-		certificateData := matchedCerts[keys[len(keys)-1]]
-		c.CertCache.SetDefault(serverName, certificateData)
+	// sort map by keys
+	keys := make([]string, 0, len(matchedCerts))
+	for k := range matchedCerts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
 
-		if c.ocspStapler != nil && certificateData.Hash != "" {
-			if staple, ok := c.ocspStapler.GetStaple(certificateData.Hash); ok {
-				// We are updating the OCSPStaple of the certificate without any synchronization
-				// as this should not cause any issue.
-				certificateData.Certificate.OCSPStaple = staple
-			}
+	return matchedCerts[keys[len(keys)-1]]
+}
+
+// resolveOnDemand asks the on-demand resolvers, in order, for a certificate matching the ClientHello.
+func (c *CertificateStore) resolveOnDemand(clientHello *tls.ClientHelloInfo) *tls.Certificate {
+	resolvers := c.onDemandResolvers.Load()
+	if resolvers == nil {
+		return nil
+	}
+
+	for _, resolver := range *resolvers {
+		cert, err := resolver.ResolveCertificate(clientHello)
+		if err != nil {
+			log.Debug().Err(err).Str("serverName", clientHello.ServerName).Msg("Unable to resolve certificate on demand")
+			continue
 		}
 
-		return certificateData.Certificate
+		if cert != nil {
+			return cert
+		}
 	}
 
 	return nil
 }
 
+// setOnDemandResolvers replaces the resolvers queried when no certificate of the store matches.
+func (c *CertificateStore) setOnDemandResolvers(resolvers []CertificateResolver) {
+	c.onDemandResolvers.Store(&resolvers)
+}
+
 // GetCertificate returns the first certificate matching all the given domains.
 func (c *CertificateStore) GetCertificate(domains []string) *CertificateData {
 	if c == nil {
@@ -2,6 +2,7 @@ package tls
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -88,6 +89,72 @@ func TestGetBestCertificate(t *testing.T) {
 	}
 }
 
+func TestGetBestCertificate_onDemand(t *testing.T) {
+	snitestCom, err := loadTestCert("snitest.com", false)
+	require.NoError(t, err)
+
+	snitestOrg, err := loadTestCert("snitest.org", false)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc          string
+		domainToCheck string
+		resolvers     []CertificateResolver
+		expectedCert  *tls.Certificate
+	}{
+		{
+			desc:          "matching dynamic certificate takes precedence",
+			domainToCheck: "snitest.com",
+			resolvers:     []CertificateResolver{staticResolver{cert: snitestOrg}},
+			expectedCert:  snitestCom,
+		},
+		{
+			desc:          "no on-demand resolver",
+			domainToCheck: "snitest.org",
+		},
+		{
+			desc:          "on-demand resolver",
+			domainToCheck: "snitest.org",
+			resolvers:     []CertificateResolver{staticResolver{cert: snitestOrg}},
+			expectedCert:  snitestOrg,
+		},
+		{
+			desc:          "falls through failing resolvers",
+			domainToCheck: "snitest.org",
+			resolvers: []CertificateResolver{
+				staticResolver{err: errors.New("not allowed")},
+				staticResolver{},
+				staticResolver{cert: snitestOrg},
+			},
+			expectedCert: snitestOrg,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			store := &CertificateStore{
+				DynamicCerts: safe.New(map[string]*CertificateData{"snitest.com": {Certificate: snitestCom}}),
+				CertCache:    cache.New(1*time.Hour, 10*time.Minute),
+			}
+			store.setOnDemandResolvers(test.resolvers)
+
+			actual := store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: test.domainToCheck})
+			assert.Equal(t, test.expectedCert, actual)
+		})
+	}
+}
+
+type staticResolver struct {
+	cert *tls.Certificate
+	err  error
+}
+
+func (s staticResolver) ResolveCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return s.cert, s.err
+}
+
 func loadTestCert(certName string, uppercase bool) (*tls.Certificate, error) {
 	replacement := "wildcard"
 	if uppercase {
@@ -0,0 +1,129 @@
+package tls
+
+import (
+	"crypto/x509"
+	"maps"
+	"strings"
+)
+
+const (
+	// CertificateSourceGenerated is the source of the default certificate generated by apache4.
+	CertificateSourceGenerated = "generated"
+	// CertificateSourceStore is the source of the default certificate defined by a TLS store.
+	CertificateSourceStore = "store"
+)
+
+// OCSP staple statuses of a certificate.
+const (
+	OCSPStapled    = "stapled"
+	OCSPNotStapled = "notStapled"
+)
+
+// CertificateInfo describes a certificate loaded in a TLS store.
+type CertificateInfo struct {
+	Store       string
+	Source      string
+	Default     bool
+	Certificate *x509.Certificate
+	// OCSPStaple is the OCSP staple status, empty when OCSP stapling does not apply to the certificate.
+	OCSPStaple string
+}
+
+// GetCertificatesInfo returns the certificates of all the TLS stores, including their default certificates.
+func (m *Manager) GetCertificatesInfo() []CertificateInfo {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	var infos []CertificateInfo
+	for storeName, store := range m.stores {
+		if store == nil {
+			continue
+		}
+
+		if store.DynamicCerts != nil && store.DynamicCerts.Get() != nil {
+			for _, cert := range store.DynamicCerts.Get().(map[string]*CertificateData) {
+				if info, ok := m.newCertificateInfo(storeName, cert, false); ok {
+					infos = append(infos, info)
+				}
+			}
+		}
+
+		if store.DefaultCertificate != nil {
+			if info, ok := m.newCertificateInfo(storeName, store.DefaultCertificate, true); ok {
+				infos = append(infos, info)
+			}
+		}
+	}
+
+	return infos
+}
+
+// MatchCertificate returns the certificate served by the given TLS store for the given server name,
+// without resolving a certificate on demand.
+func (m *Manager) MatchCertificate(storeName, serverName string) *x509.Certificate {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	store := m.getStore(storeName)
+	if store == nil {
+		return nil
+	}
+
+	certificateData := store.matchCertificate(strings.ToLower(strings.TrimSpace(serverName)))
+	if certificateData == nil {
+		certificateData = store.DefaultCertificate
+	}
+
+	if certificateData == nil || certificateData.Certificate == nil {
+		return nil
+	}
+
+	return certificateData.Certificate.Leaf
+}
+
+// GetOptions returns the TLS options, keyed by name.
+func (m *Manager) GetOptions() map[string]Options {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return maps.Clone(m.configs)
+}
+
+// GetStores returns the configuration of the TLS stores, keyed by name.
+func (m *Manager) GetStores() map[string]Store {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return maps.Clone(m.storesConfig)
+}
+
+func (m *Manager) newCertificateInfo(storeName string, cert *CertificateData, isDefault bool) (CertificateInfo, bool) {
+	if cert.Certificate == nil || len(cert.Certificate.Certificate) == 0 {
+		return CertificateInfo{}, false
+	}
+
+	leaf := cert.Certificate.Leaf
+	if leaf == nil {
+		var err error
+		leaf, err = x509.ParseCertificate(cert.Certificate.Certificate[0])
+		if err != nil {
+			return CertificateInfo{}, false
+		}
+	}
+
+	info := CertificateInfo{
+		Store:       storeName,
+		Source:      cert.Source,
+		Default:     isDefault,
+		Certificate: leaf,
+	}
+
+	if m.ocspStapler != nil && cert.Hash != "" {
+		info.OCSPStaple = OCSPNotStapled
+		if staple, ok := m.ocspStapler.GetStaple(cert.Hash); ok && len(staple) > 0 {
+			info.OCSPStaple = OCSPStapled
+		}
+	}
+
+	return info, true
+}
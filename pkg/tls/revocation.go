@@ -0,0 +1,437 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/patrickmn/go-cache"
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/safe"
+	"golang.org/x/crypto/ocsp"
+)
+
+const (
+	revocationModeSoftFail = "SoftFail"
+	revocationModeHardFail = "HardFail"
+
+	defaultCRLRefreshInterval = time.Hour
+	defaultOCSPCacheDuration  = time.Hour
+
+	// ocspTimeout is the timeout of OCSP requests, which are sent during the TLS handshake.
+	ocspTimeout = 5 * time.Second
+
+	// maxCRLSize is the maximum size of a downloaded certificate revocation list.
+	maxCRLSize = 64 << 20
+)
+
+type revocationStatus int
+
+const (
+	revocationStatusUnknown revocationStatus = iota
+	revocationStatusGood
+	revocationStatusRevoked
+)
+
+func (s revocationStatus) String() string {
+	switch s {
+	case revocationStatusGood:
+		return "good"
+	case revocationStatusRevoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
+
+// crlEntry is a parsed certificate revocation list, with its revoked serial numbers indexed.
+type crlEntry struct {
+	list    *x509.RevocationList
+	revoked map[string]struct{}
+}
+
+func newCRLEntry(data []byte) (*crlEntry, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+		}
+		data = block.Bytes
+	}
+
+	list, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate revocation list: %w", err)
+	}
+
+	revoked := make(map[string]struct{}, len(list.RevokedCertificateEntries))
+	for _, entry := range list.RevokedCertificateEntries {
+		revoked[entry.SerialNumber.String()] = struct{}{}
+	}
+
+	return &crlEntry{list: list, revoked: revoked}, nil
+}
+
+// revocationChecker checks the revocation status of the client certificates,
+// against certificate revocation lists and the OCSP responders of the certificates.
+type revocationChecker struct {
+	optionName      string
+	config          RevocationCheck
+	hardFail        bool
+	refreshInterval time.Duration
+	client          *http.Client
+	failures        gokitmetrics.Counter
+
+	staticCRLs []*crlEntry
+
+	remoteCRLsMu sync.RWMutex
+	remoteCRLs   map[string]*crlEntry
+	lastRefresh  time.Time
+	refreshing   atomic.Bool
+
+	ocspCache *cache.Cache
+}
+
+func newRevocationChecker(optionName string, config RevocationCheck, failures gokitmetrics.Counter) (*revocationChecker, error) {
+	if len(config.CRLFiles) == 0 && len(config.CRLURLs) == 0 && !config.OCSP {
+		return nil, errors.New("no certificate revocation list nor OCSP check defined")
+	}
+
+	checker := &revocationChecker{
+		optionName:      optionName,
+		config:          config,
+		refreshInterval: defaultCRLRefreshInterval,
+		client:          &http.Client{Timeout: 30 * time.Second},
+		failures:        failures,
+		remoteCRLs:      make(map[string]*crlEntry),
+		ocspCache:       cache.New(defaultOCSPCacheDuration, 10*time.Minute),
+	}
+
+	switch config.Mode {
+	case "", revocationModeSoftFail:
+	case revocationModeHardFail:
+		checker.hardFail = true
+	default:
+		return nil, fmt.Errorf("unknown revocation check mode %q", config.Mode)
+	}
+
+	if config.CRLRefreshInterval > 0 {
+		checker.refreshInterval = time.Duration(config.CRLRefreshInterval)
+	}
+
+	for _, crlFile := range config.CRLFiles {
+		data, err := crlFile.Read()
+		if err != nil {
+			return nil, fmt.Errorf("reading certificate revocation list: %w", err)
+		}
+
+		entry, err := newCRLEntry(data)
+		if err != nil {
+			if crlFile.IsPath() {
+				return nil, fmt.Errorf("invalid certificate revocation list %s: %w", crlFile, err)
+			}
+			return nil, fmt.Errorf("invalid certificate revocation list content: %w", err)
+		}
+
+		checker.staticCRLs = append(checker.staticCRLs, entry)
+	}
+
+	// Triggers the first download of the certificate revocation lists URLs.
+	checker.refreshCRLsIfNeeded()
+
+	return checker, nil
+}
+
+// verifyPeerCertificate is used as the tls.Config VerifyPeerCertificate callback.
+// It is called, after the standard verification, with the chains built from the ClientAuth CAs.
+func (r *revocationChecker) verifyPeerCertificate(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	// Without verified chains, the issuer of the certificate is unknown, hence its revocation status cannot be checked.
+	if len(verifiedChains) == 0 {
+		return nil
+	}
+
+	r.refreshCRLsIfNeeded()
+
+	chain := verifiedChains[0]
+	for i := 0; i < len(chain)-1; i++ {
+		// The intermediate CAs are only checked when a source of revocation status covers them,
+		// otherwise their status would always be unknown.
+		if i > 0 && !r.covers(chain[i], chain[i+1]) {
+			continue
+		}
+
+		if err := r.verify(chain[i], chain[i+1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// covers reports whether a certificate revocation list of the issuer, or an OCSP responder, can tell the certificate status.
+func (r *revocationChecker) covers(cert, issuer *x509.Certificate) bool {
+	if r.config.OCSP && len(cert.OCSPServer) > 0 {
+		return true
+	}
+
+	for _, entry := range r.crlEntries() {
+		if bytes.Equal(entry.list.RawIssuer, issuer.RawSubject) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyConnection is used as the tls.Config VerifyConnection callback,
+// to check the certificates of resumed connections, for which VerifyPeerCertificate is not called.
+func (r *revocationChecker) verifyConnection(cs tls.ConnectionState) error {
+	if !cs.DidResume {
+		return nil
+	}
+
+	return r.verifyPeerCertificate(nil, cs.VerifiedChains)
+}
+
+func (r *revocationChecker) verify(cert, issuer *x509.Certificate) error {
+	status := r.status(cert, issuer)
+
+	logger := log.With().
+		Str("tlsOption", r.optionName).
+		Str("subject", cert.Subject.String()).
+		Str("serial", cert.SerialNumber.String()).
+		Logger()
+
+	switch status {
+	case revocationStatusRevoked:
+		r.countFailure(status)
+		logger.Warn().Msg("Rejecting revoked client certificate")
+
+		return fmt.Errorf("certificate %s is revoked", cert.SerialNumber)
+
+	case revocationStatusUnknown:
+		if r.hardFail {
+			r.countFailure(status)
+			logger.Warn().Msg("Rejecting client certificate with unknown revocation status")
+
+			return fmt.Errorf("revocation status of certificate %s is unknown", cert.SerialNumber)
+		}
+
+		logger.Debug().Msg("Accepting client certificate with unknown revocation status")
+	}
+
+	return nil
+}
+
+func (r *revocationChecker) countFailure(status revocationStatus) {
+	if r.failures == nil {
+		return
+	}
+
+	r.failures.With("tls_option", r.optionName, "status", status.String()).Add(1)
+}
+
+// status returns the revocation status of the certificate.
+// A certificate revoked by a CRL is revoked, otherwise the OCSP status, if known, takes precedence over the CRLs status.
+func (r *revocationChecker) status(cert, issuer *x509.Certificate) revocationStatus {
+	status := r.crlStatus(cert, issuer)
+	if status == revocationStatusRevoked {
+		return status
+	}
+
+	if r.config.OCSP && len(cert.OCSPServer) > 0 {
+		if ocspStatus := r.ocspStatus(cert, issuer); ocspStatus != revocationStatusUnknown {
+			return ocspStatus
+		}
+	}
+
+	return status
+}
+
+// crlEntries returns the static and the downloaded certificate revocation lists.
+func (r *revocationChecker) crlEntries() []*crlEntry {
+	r.remoteCRLsMu.RLock()
+	defer r.remoteCRLsMu.RUnlock()
+
+	entries := make([]*crlEntry, 0, len(r.staticCRLs)+len(r.remoteCRLs))
+	entries = append(entries, r.staticCRLs...)
+	for _, entry := range r.remoteCRLs {
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func (r *revocationChecker) crlStatus(cert, issuer *x509.Certificate) revocationStatus {
+	status := revocationStatusUnknown
+	for _, entry := range r.crlEntries() {
+		if !bytes.Equal(entry.list.RawIssuer, issuer.RawSubject) {
+			continue
+		}
+
+		if err := entry.list.CheckSignatureFrom(issuer); err != nil {
+			log.Debug().Err(err).Str("issuer", issuer.Subject.String()).Msg("Ignoring certificate revocation list with invalid signature")
+			continue
+		}
+
+		// An outdated list cannot tell whether a certificate has been revoked since.
+		if !entry.list.NextUpdate.IsZero() && time.Now().After(entry.list.NextUpdate) {
+			log.Debug().Str("issuer", issuer.Subject.String()).Msg("Ignoring outdated certificate revocation list")
+			continue
+		}
+
+		if _, ok := entry.revoked[cert.SerialNumber.String()]; ok {
+			return revocationStatusRevoked
+		}
+
+		status = revocationStatusGood
+	}
+
+	return status
+}
+
+func (r *revocationChecker) ocspStatus(cert, issuer *x509.Certificate) revocationStatus {
+	key := hashRawCert(cert.Raw)
+	if item, ok := r.ocspCache.Get(key); ok {
+		return item.(revocationStatus)
+	}
+
+	ocspReq, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		log.Debug().Err(err).Msg("Unable to create OCSP request")
+		return revocationStatusUnknown
+	}
+
+	for _, responder := range cert.OCSPServer {
+		ocspRes, err := r.queryOCSP(responder, ocspReq, cert, issuer)
+		if err != nil {
+			log.Debug().Err(err).Str("responder", responder).Msg("Unable to obtain OCSP response")
+			continue
+		}
+
+		var status revocationStatus
+		switch ocspRes.Status {
+		case ocsp.Good:
+			status = revocationStatusGood
+		case ocsp.Revoked:
+			status = revocationStatusRevoked
+		default:
+			continue
+		}
+
+		ttl := defaultOCSPCacheDuration
+		if !ocspRes.NextUpdate.IsZero() {
+			ttl = time.Until(ocspRes.NextUpdate)
+		}
+		if ttl > 0 {
+			r.ocspCache.Set(key, status, ttl)
+		}
+
+		return status
+	}
+
+	return revocationStatusUnknown
+}
+
+func (r *revocationChecker) queryOCSP(responder string, ocspReq []byte, cert, issuer *x509.Certificate) (*ocsp.Response, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ocspTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responder, bytes.NewReader(ocspReq))
+	if err != nil {
+		return nil, fmt.Errorf("creating OCSP request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	ocspResBytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading OCSP response: %w", err)
+	}
+
+	return ocsp.ParseResponseForCert(ocspResBytes, cert, issuer)
+}
+
+// refreshCRLsIfNeeded triggers, in the background, the download of the certificate revocation lists URLs
+// if they have not been downloaded for the refresh interval.
+func (r *revocationChecker) refreshCRLsIfNeeded() {
+	if len(r.config.CRLURLs) == 0 {
+		return
+	}
+
+	r.remoteCRLsMu.RLock()
+	lastRefresh := r.lastRefresh
+	r.remoteCRLsMu.RUnlock()
+
+	if time.Since(lastRefresh) < r.refreshInterval || !r.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	safe.Go(func() {
+		defer r.refreshing.Store(false)
+
+		r.refreshCRLs(context.Background())
+	})
+}
+
+func (r *revocationChecker) refreshCRLs(ctx context.Context) {
+	for _, crlURL := range r.config.CRLURLs {
+		entry, err := r.downloadCRL(ctx, crlURL)
+		if err != nil {
+			// The previously downloaded list, if any, is kept until it becomes outdated.
+			log.Error().Err(err).Str("tlsOption", r.optionName).Str("url", crlURL).Msg("Unable to download certificate revocation list")
+			continue
+		}
+
+		r.remoteCRLsMu.Lock()
+		r.remoteCRLs[crlURL] = entry
+		r.remoteCRLsMu.Unlock()
+	}
+
+	r.remoteCRLsMu.Lock()
+	r.lastRefresh = time.Now()
+	r.remoteCRLsMu.Unlock()
+}
+
+func (r *revocationChecker) downloadCRL(ctx context.Context, crlURL string) (*crlEntry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, crlURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxCRLSize))
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	return newCRLEntry(data)
+}
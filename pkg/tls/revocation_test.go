@@ -0,0 +1,435 @@
+package tls
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/apache4/apache4/v3/pkg/types"
+	"golang.org/x/crypto/ocsp"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+// intermediate issues an intermediate CA signed by the CA.
+func (c *testCA) intermediate(t *testing.T, serial int64) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, key.Public(), c.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (c *testCA) issue(t *testing.T, serial int64, ocspServers ...string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		OCSPServer:   ocspServers,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.cert, key.Public(), c.key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func (c *testCA) crl(t *testing.T, nextUpdate time.Time, revokedSerials ...int64) []byte {
+	t.Helper()
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now().Add(-time.Hour),
+		NextUpdate: nextUpdate,
+	}
+	for _, serial := range revokedSerials {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: time.Now().Add(-time.Minute),
+		})
+	}
+
+	der, err := x509.CreateRevocationList(rand.Reader, template, c.cert, c.key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+}
+
+func TestNewRevocationChecker(t *testing.T) {
+	ca := newTestCA(t)
+
+	testCases := []struct {
+		desc      string
+		config    RevocationCheck
+		expectErr bool
+	}{
+		{
+			desc:      "nothing to check against",
+			config:    RevocationCheck{},
+			expectErr: true,
+		},
+		{
+			desc:      "unknown mode",
+			config:    RevocationCheck{OCSP: true, Mode: "foo"},
+			expectErr: true,
+		},
+		{
+			desc:      "invalid CRL",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{"foo"}},
+			expectErr: true,
+		},
+		{
+			desc:   "CRL",
+			config: RevocationCheck{CRLFiles: []types.FileOrContent{types.FileOrContent(ca.crl(t, time.Now().Add(time.Hour)))}},
+		},
+		{
+			desc:   "OCSP in hard fail mode",
+			config: RevocationCheck{OCSP: true, Mode: "HardFail"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newRevocationChecker("default", test.config, nil)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRevocationChecker_CRL(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+
+	crl := types.FileOrContent(ca.crl(t, time.Now().Add(time.Hour), 3))
+	outdatedCRL := types.FileOrContent(ca.crl(t, time.Now().Add(-time.Minute), 3))
+
+	testCases := []struct {
+		desc      string
+		config    RevocationCheck
+		cert      *x509.Certificate
+		issuer    *x509.Certificate
+		expectErr bool
+	}{
+		{
+			desc:   "not revoked",
+			config: RevocationCheck{CRLFiles: []types.FileOrContent{crl}},
+			cert:   ca.issue(t, 2),
+			issuer: ca.cert,
+		},
+		{
+			desc:      "revoked",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{crl}},
+			cert:      ca.issue(t, 3),
+			issuer:    ca.cert,
+			expectErr: true,
+		},
+		{
+			desc:      "revoked in soft fail mode",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{crl}, Mode: "SoftFail"},
+			cert:      ca.issue(t, 3),
+			issuer:    ca.cert,
+			expectErr: true,
+		},
+		{
+			desc:   "no CRL for the issuer in soft fail mode",
+			config: RevocationCheck{CRLFiles: []types.FileOrContent{crl}},
+			cert:   otherCA.issue(t, 3),
+			issuer: otherCA.cert,
+		},
+		{
+			desc:      "no CRL for the issuer in hard fail mode",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{crl}, Mode: "HardFail"},
+			cert:      otherCA.issue(t, 3),
+			issuer:    otherCA.cert,
+			expectErr: true,
+		},
+		{
+			desc:      "outdated CRL in hard fail mode",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{outdatedCRL}, Mode: "HardFail"},
+			cert:      ca.issue(t, 2),
+			issuer:    ca.cert,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checker, err := newRevocationChecker("default", test.config, nil)
+			require.NoError(t, err)
+
+			err = checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{test.cert, test.issuer}})
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRevocationChecker_IntermediateCA(t *testing.T) {
+	root := newTestCA(t)
+	intermediate := root.intermediate(t, 10)
+
+	intermediateCRL := types.FileOrContent(intermediate.crl(t, time.Now().Add(time.Hour), 3))
+	rootCRL := types.FileOrContent(root.crl(t, time.Now().Add(time.Hour), 10))
+
+	testCases := []struct {
+		desc      string
+		config    RevocationCheck
+		cert      *x509.Certificate
+		expectErr bool
+	}{
+		{
+			desc:   "no CRL for the root in hard fail mode",
+			config: RevocationCheck{CRLFiles: []types.FileOrContent{intermediateCRL}, Mode: "HardFail"},
+			cert:   intermediate.issue(t, 2),
+		},
+		{
+			desc:      "revoked client certificate",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{intermediateCRL}, Mode: "HardFail"},
+			cert:      intermediate.issue(t, 3),
+			expectErr: true,
+		},
+		{
+			desc:      "revoked intermediate CA",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{intermediateCRL, rootCRL}},
+			cert:      intermediate.issue(t, 2),
+			expectErr: true,
+		},
+		{
+			desc:      "no CRL for the intermediate CA in hard fail mode",
+			config:    RevocationCheck{CRLFiles: []types.FileOrContent{rootCRL}, Mode: "HardFail"},
+			cert:      intermediate.issue(t, 2),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			checker, err := newRevocationChecker("default", test.config, nil)
+			require.NoError(t, err)
+
+			err = checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{test.cert, intermediate.cert, root.cert}})
+			if test.expectErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRevocationChecker_failuresCount(t *testing.T) {
+	ca := newTestCA(t)
+	otherCA := newTestCA(t)
+
+	crl := types.FileOrContent(ca.crl(t, time.Now().Add(time.Hour), 3))
+
+	testCases := []struct {
+		desc          string
+		mode          string
+		cert          *x509.Certificate
+		issuer        *x509.Certificate
+		expectedCount float64
+	}{
+		{
+			desc:   "good status",
+			cert:   ca.issue(t, 2),
+			issuer: ca.cert,
+		},
+		{
+			desc:          "revoked",
+			cert:          ca.issue(t, 3),
+			issuer:        ca.cert,
+			expectedCount: 1,
+		},
+		{
+			desc:   "unknown status in soft fail mode",
+			mode:   "SoftFail",
+			cert:   otherCA.issue(t, 3),
+			issuer: otherCA.cert,
+		},
+		{
+			desc:          "unknown status in hard fail mode",
+			mode:          "HardFail",
+			cert:          otherCA.issue(t, 3),
+			issuer:        otherCA.cert,
+			expectedCount: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			counter := &countingCounter{}
+			checker, err := newRevocationChecker("default", RevocationCheck{CRLFiles: []types.FileOrContent{crl}, Mode: test.mode}, counter)
+			require.NoError(t, err)
+
+			_ = checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{test.cert, test.issuer}})
+
+			assert.InDelta(t, test.expectedCount, counter.value, 0)
+		})
+	}
+}
+
+type countingCounter struct {
+	value float64
+}
+
+func (c *countingCounter) With(_ ...string) gokitmetrics.Counter {
+	return c
+}
+
+func (c *countingCounter) Add(delta float64) {
+	c.value += delta
+}
+
+func TestRevocationChecker_CRLURL(t *testing.T) {
+	ca := newTestCA(t)
+	crl := ca.crl(t, time.Now().Add(time.Hour), 3)
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		_, _ = rw.Write(crl)
+	}))
+	t.Cleanup(server.Close)
+
+	checker, err := newRevocationChecker("default", RevocationCheck{CRLURLs: []string{server.URL}, Mode: "HardFail"}, nil)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{ca.issue(t, 2), ca.cert}}) == nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	err = checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{ca.issue(t, 3), ca.cert}})
+	assert.Error(t, err)
+}
+
+func TestRevocationChecker_OCSP(t *testing.T) {
+	ca := newTestCA(t)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests++
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		ocspReq, err := ocsp.ParseRequest(body)
+		require.NoError(t, err)
+
+		status := ocsp.Good
+		if ocspReq.SerialNumber.Int64() == 3 {
+			status = ocsp.Revoked
+		}
+
+		res, err := ocsp.CreateResponse(ca.cert, ca.cert, ocsp.Response{
+			Status:       status,
+			SerialNumber: ocspReq.SerialNumber,
+			ThisUpdate:   time.Now().Add(-time.Minute),
+			NextUpdate:   time.Now().Add(time.Hour),
+			RevokedAt:    time.Now().Add(-time.Minute),
+		}, ca.key)
+		require.NoError(t, err)
+
+		_, _ = rw.Write(res)
+	}))
+	t.Cleanup(server.Close)
+
+	checker, err := newRevocationChecker("default", RevocationCheck{OCSP: true, Mode: "HardFail"}, nil)
+	require.NoError(t, err)
+
+	good := ca.issue(t, 2, server.URL)
+	require.NoError(t, checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{good, ca.cert}}))
+	// The response is cached.
+	require.NoError(t, checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{good, ca.cert}}))
+	assert.Equal(t, 1, requests)
+
+	revoked := ca.issue(t, 3, server.URL)
+	assert.Error(t, checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{revoked, ca.cert}}))
+
+	unreachable := ca.issue(t, 4, "http://127.0.0.1:1")
+	assert.Error(t, checker.verifyPeerCertificate(nil, [][]*x509.Certificate{{unreachable, ca.cert}}))
+}
@@ -0,0 +1,257 @@
+package tls
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/rs/zerolog/log"
+	ptypes "github.com/apache4/paerser/types"
+)
+
+const (
+	sessionTicketKeySize = 32
+
+	// sessionTicketKeysRefreshInterval is the interval between two loads of the session ticket keys.
+	sessionTicketKeysRefreshInterval = 30 * time.Second
+)
+
+// SessionTicketsConfig configures the TLS session ticket keys shared by several apache4 instances.
+type SessionTicketsConfig struct {
+	KeysFile         string            `description:"File holding the session ticket keys, one base64 encoded 32 bytes key per line. The first key encrypts the new tickets, all the keys decrypt the tickets." json:"keysFile,omitempty" toml:"keysFile,omitempty" yaml:"keysFile,omitempty"`
+	KV               *SessionTicketsKV `description:"Stores and rotates the session ticket keys in a KV store." json:"kv,omitempty" toml:"kv,omitempty" yaml:"kv,omitempty" export:"true"`
+	RotationInterval ptypes.Duration   `description:"Interval between two rotations of the session ticket keys stored in the KV store." json:"rotationInterval,omitempty" toml:"rotationInterval,omitempty" yaml:"rotationInterval,omitempty" export:"true"`
+	RetainedKeys     int               `description:"Number of previous session ticket keys stored in the KV store, to decrypt the tickets issued before a rotation." json:"retainedKeys,omitempty" toml:"retainedKeys,omitempty" yaml:"retainedKeys,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (c *SessionTicketsConfig) SetDefaults() {
+	c.RotationInterval = ptypes.Duration(12 * time.Hour)
+	c.RetainedKeys = 2
+}
+
+// SessionTicketsKV defines the KV store holding the session ticket keys.
+type SessionTicketsKV struct {
+	Provider string `description:"KV provider whose store holds the session ticket keys (consul, etcd, redis or zooKeeper)." json:"provider,omitempty" toml:"provider,omitempty" yaml:"provider,omitempty" export:"true"`
+	Key      string `description:"Key holding the session ticket keys." json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (k *SessionTicketsKV) SetDefaults() {
+	k.Key = "sessionTicketKeys"
+}
+
+// kvStore is the part of a KV store used to share the session ticket keys.
+type kvStore interface {
+	Get(ctx context.Context, key string, opts *store.ReadOptions) (*store.KVPair, error)
+	AtomicPut(ctx context.Context, key string, value []byte, previous *store.KVPair, opts *store.WriteOptions) (bool, *store.KVPair, error)
+}
+
+// storedSessionTicketKeys is the representation of the session ticket keys in the KV store.
+type storedSessionTicketKeys struct {
+	Keys      [][]byte  `json:"keys"`
+	RotatedAt time.Time `json:"rotatedAt"`
+}
+
+// SessionTicketKeys holds the session ticket keys used by the TLS configurations built by the Manager.
+// The keys are loaded from a file or from a KV store, and are refreshed periodically.
+type SessionTicketKeys struct {
+	keysFile string
+
+	kvStore          kvStore
+	kvKey            string
+	rotationInterval time.Duration
+	retainedKeys     int
+
+	// keysConfig only holds the session ticket keys, and encrypts and decrypts the tickets on behalf of the TLS configurations.
+	keysConfig *tls.Config
+}
+
+// NewSessionTicketKeys creates the session ticket keys defined by the given configuration.
+// The KV store is only used, and required, when the configuration defines a KV.
+func NewSessionTicketKeys(config *SessionTicketsConfig, kvStore store.Store) (*SessionTicketKeys, error) {
+	if (config.KeysFile == "") == (config.KV == nil) {
+		return nil, errors.New("session tickets require either a keys file or a KV")
+	}
+
+	k := &SessionTicketKeys{
+		keysFile:   config.KeysFile,
+		keysConfig: &tls.Config{},
+	}
+
+	if config.KV != nil {
+		if kvStore == nil {
+			return nil, errors.New("session tickets KV store is not available")
+		}
+
+		if config.KV.Key == "" {
+			return nil, errors.New("session tickets KV key is required")
+		}
+
+		if config.RotationInterval <= 0 {
+			return nil, errors.New("session tickets rotation interval must be positive")
+		}
+
+		if config.RetainedKeys < 0 {
+			return nil, errors.New("session tickets retained keys cannot be negative")
+		}
+
+		k.kvStore = kvStore
+		k.kvKey = config.KV.Key
+		k.rotationInterval = time.Duration(config.RotationInterval)
+		k.retainedKeys = config.RetainedKeys
+	}
+
+	return k, nil
+}
+
+// Run loads the session ticket keys, then refreshes them periodically until the context is done.
+// Until the keys are loaded, the tickets are protected by keys local to this instance.
+func (k *SessionTicketKeys) Run(ctx context.Context) {
+	ticker := time.NewTicker(sessionTicketKeysRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := k.refresh(ctx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Unable to refresh the session ticket keys")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply makes the given TLS configuration encrypt and decrypt its session tickets with the shared keys.
+func (k *SessionTicketKeys) apply(conf *tls.Config) {
+	conf.WrapSession = k.keysConfig.EncryptTicket
+	conf.UnwrapSession = k.keysConfig.DecryptTicket
+}
+
+func (k *SessionTicketKeys) refresh(ctx context.Context) error {
+	var keys [][sessionTicketKeySize]byte
+	var err error
+	if k.kvStore != nil {
+		keys, err = k.rotateKVKeys(ctx)
+	} else {
+		keys, err = readSessionTicketKeysFile(k.keysFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	k.keysConfig.SetSessionTicketKeys(keys)
+
+	return nil
+}
+
+// rotateKVKeys returns the keys stored in the KV store, after having rotated them if the rotation interval has elapsed.
+// As all the instances sharing the keys attempt the rotation, the stored keys are only replaced if they were not modified in the meantime.
+func (k *SessionTicketKeys) rotateKVKeys(ctx context.Context) ([][sessionTicketKeySize]byte, error) {
+	var stored storedSessionTicketKeys
+
+	pair, err := k.kvStore.Get(ctx, k.kvKey, nil)
+	switch {
+	case errors.Is(err, store.ErrKeyNotFound):
+		pair = nil
+	case err != nil:
+		return nil, fmt.Errorf("getting session ticket keys: %w", err)
+	default:
+		if err = json.Unmarshal(pair.Value, &stored); err != nil {
+			return nil, fmt.Errorf("decoding session ticket keys: %w", err)
+		}
+	}
+
+	if pair != nil && len(stored.Keys) > 0 && time.Since(stored.RotatedAt) < k.rotationInterval {
+		return toSessionTicketKeys(stored.Keys)
+	}
+
+	newKey := make([]byte, sessionTicketKeySize)
+	if _, err = rand.Read(newKey); err != nil {
+		return nil, fmt.Errorf("generating session ticket key: %w", err)
+	}
+
+	rotated := storedSessionTicketKeys{
+		Keys:      append([][]byte{newKey}, stored.Keys...),
+		RotatedAt: time.Now(),
+	}
+	if len(rotated.Keys) > k.retainedKeys+1 {
+		rotated.Keys = rotated.Keys[:k.retainedKeys+1]
+	}
+
+	value, err := json.Marshal(rotated)
+	if err != nil {
+		return nil, fmt.Errorf("encoding session ticket keys: %w", err)
+	}
+
+	_, _, err = k.kvStore.AtomicPut(ctx, k.kvKey, value, pair, nil)
+	if errors.Is(err, store.ErrKeyModified) || errors.Is(err, store.ErrKeyExists) {
+		// Another instance rotated the keys first, they are loaded at the next refresh.
+		log.Ctx(ctx).Debug().Msg("Session ticket keys already rotated by another instance")
+
+		if pair == nil {
+			return nil, errors.New("session ticket keys not loaded yet")
+		}
+
+		return toSessionTicketKeys(stored.Keys)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storing session ticket keys: %w", err)
+	}
+
+	log.Ctx(ctx).Debug().Msg("Session ticket keys rotated")
+
+	return toSessionTicketKeys(rotated.Keys)
+}
+
+func readSessionTicketKeysFile(path string) ([][sessionTicketKeySize]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading session ticket keys file: %w", err)
+	}
+
+	var keys [][]byte
+	for line := range bytes.Lines(content) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+
+		key := make([]byte, base64.StdEncoding.DecodedLen(len(line)))
+		n, err := base64.StdEncoding.Decode(key, line)
+		if err != nil {
+			return nil, fmt.Errorf("decoding session ticket key %d: %w", len(keys)+1, err)
+		}
+
+		keys = append(keys, key[:n])
+	}
+
+	return toSessionTicketKeys(keys)
+}
+
+func toSessionTicketKeys(rawKeys [][]byte) ([][sessionTicketKeySize]byte, error) {
+	if len(rawKeys) == 0 {
+		return nil, errors.New("no session ticket key")
+	}
+
+	keys := make([][sessionTicketKeySize]byte, len(rawKeys))
+	for i, rawKey := range rawKeys {
+		if len(rawKey) != sessionTicketKeySize {
+			return nil, fmt.Errorf("session ticket key %d must be %d bytes long, got %d", i+1, sessionTicketKeySize, len(rawKey))
+		}
+
+		copy(keys[i][:], rawKey)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,254 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kvtools/valkeyrie/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/apache4/paerser/types"
+)
+
+func TestReadSessionTicketKeysFile(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", sessionTicketKeySize)))
+	key2 := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("b", sessionTicketKeySize)))
+
+	testCases := []struct {
+		desc         string
+		content      string
+		expectedKeys int
+		expectErr    bool
+	}{
+		{
+			desc:         "one key",
+			content:      key1,
+			expectedKeys: 1,
+		},
+		{
+			desc:         "several keys and blank lines",
+			content:      key1 + "\n\n" + key2 + "\n",
+			expectedKeys: 2,
+		},
+		{
+			desc:      "no key",
+			content:   "\n",
+			expectErr: true,
+		},
+		{
+			desc:      "invalid base64",
+			content:   "foo!",
+			expectErr: true,
+		},
+		{
+			desc:      "invalid key size",
+			content:   base64.StdEncoding.EncodeToString([]byte("foo")),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "keys")
+			require.NoError(t, os.WriteFile(path, []byte(test.content), 0o600))
+
+			keys, err := readSessionTicketKeysFile(path)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Len(t, keys, test.expectedKeys)
+		})
+	}
+}
+
+func TestNewSessionTicketKeys(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		config    SessionTicketsConfig
+		kvStore   store.Store
+		expectErr bool
+	}{
+		{
+			desc:   "keys file",
+			config: SessionTicketsConfig{KeysFile: "keys"},
+		},
+		{
+			desc:      "neither keys file nor KV",
+			config:    SessionTicketsConfig{},
+			expectErr: true,
+		},
+		{
+			desc:      "both keys file and KV",
+			config:    SessionTicketsConfig{KeysFile: "keys", KV: &SessionTicketsKV{Key: "keys"}},
+			expectErr: true,
+		},
+		{
+			desc:      "KV without store",
+			config:    SessionTicketsConfig{KV: &SessionTicketsKV{Key: "keys"}, RotationInterval: ptypes.Duration(time.Hour)},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewSessionTicketKeys(&test.config, test.kvStore)
+			if test.expectErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSessionTicketKeys_rotateKVKeys(t *testing.T) {
+	kvStore := &fakeKVStore{}
+
+	keys := &SessionTicketKeys{kvStore: kvStore, kvKey: "keys", rotationInterval: time.Hour, retainedKeys: 1}
+
+	first, err := keys.rotateKVKeys(t.Context())
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+
+	// The rotation interval has not elapsed yet.
+	current, err := keys.rotateKVKeys(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, first, current)
+
+	kvStore.setRotatedAt(t, time.Now().Add(-2*time.Hour))
+
+	second, err := keys.rotateKVKeys(t.Context())
+	require.NoError(t, err)
+	require.Len(t, second, 2)
+	assert.Equal(t, first[0], second[1])
+
+	kvStore.setRotatedAt(t, time.Now().Add(-2*time.Hour))
+
+	// Only the retained previous key is kept.
+	third, err := keys.rotateKVKeys(t.Context())
+	require.NoError(t, err)
+	require.Len(t, third, 2)
+	assert.Equal(t, second[0], third[1])
+}
+
+func TestSessionTicketKeys_resumption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", sessionTicketKeySize)))
+	require.NoError(t, os.WriteFile(path, []byte(key), 0o600))
+
+	newServerConfig := func() *tls.Config {
+		keys, err := NewSessionTicketKeys(&SessionTicketsConfig{KeysFile: path}, nil)
+		require.NoError(t, err)
+		require.NoError(t, keys.refresh(t.Context()))
+
+		conf := &tls.Config{Certificates: []tls.Certificate{generateTestCertificate(t)}}
+		keys.apply(conf)
+
+		return conf
+	}
+
+	clientConfig := &tls.Config{
+		ServerName:         "example.com",
+		InsecureSkipVerify: true,
+		ClientSessionCache: tls.NewLRUClientSessionCache(1),
+		MaxVersion:         tls.VersionTLS12,
+	}
+
+	assert.False(t, handshake(t, newServerConfig(), clientConfig).DidResume)
+	// Another instance, sharing the keys, resumes the session.
+	assert.True(t, handshake(t, newServerConfig(), clientConfig).DidResume)
+}
+
+func generateTestCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	ca := newTestCA(t)
+
+	return tls.Certificate{Certificate: [][]byte{ca.cert.Raw}, PrivateKey: ca.key}
+}
+
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) tls.ConnectionState {
+	t.Helper()
+
+	serverConn, clientConn := net.Pipe()
+	t.Cleanup(func() {
+		_ = serverConn.Close()
+		_ = clientConn.Close()
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		_ = tls.Server(serverConn, serverConfig).Handshake()
+	}()
+
+	client := tls.Client(clientConn, clientConfig)
+	require.NoError(t, client.Handshake())
+
+	wg.Wait()
+
+	return client.ConnectionState()
+}
+
+type fakeKVStore struct {
+	pair *store.KVPair
+}
+
+func (f *fakeKVStore) Get(_ context.Context, _ string, _ *store.ReadOptions) (*store.KVPair, error) {
+	if f.pair == nil {
+		return nil, store.ErrKeyNotFound
+	}
+
+	return f.pair, nil
+}
+
+func (f *fakeKVStore) AtomicPut(_ context.Context, key string, value []byte, previous *store.KVPair, _ *store.WriteOptions) (bool, *store.KVPair, error) {
+	switch {
+	case previous == nil && f.pair != nil:
+		return false, nil, store.ErrKeyExists
+	case previous != nil && (f.pair == nil || previous.LastIndex != f.pair.LastIndex):
+		return false, nil, store.ErrKeyModified
+	}
+
+	var index uint64
+	if f.pair != nil {
+		index = f.pair.LastIndex + 1
+	}
+
+	f.pair = &store.KVPair{Key: key, Value: value, LastIndex: index}
+
+	return true, f.pair, nil
+}
+
+func (f *fakeKVStore) setRotatedAt(t *testing.T, rotatedAt time.Time) {
+	t.Helper()
+
+	var stored storedSessionTicketKeys
+	require.NoError(t, json.Unmarshal(f.pair.Value, &stored))
+
+	stored.RotatedAt = rotatedAt
+
+	value, err := json.Marshal(stored)
+	require.NoError(t, err)
+
+	f.pair = &store.KVPair{Key: f.pair.Key, Value: value, LastIndex: f.pair.LastIndex + 1}
+}
@@ -1,6 +1,9 @@
 package tls
 
-import "github.com/apache4/apache4/v3/pkg/types"
+import (
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/types"
+)
 
 const certificateHeader = "-----BEGIN CERTIFICATE-----\n"
 
@@ -12,6 +15,25 @@ type ClientAuth struct {
 	// ClientAuthType defines the client authentication type to apply.
 	// The available values are: "NoClientCert", "RequestClientCert", "VerifyClientCertIfGiven" and "RequireAndVerifyClientCert".
 	ClientAuthType string `json:"clientAuthType,omitempty" toml:"clientAuthType,omitempty" yaml:"clientAuthType,omitempty" export:"true"`
+	// RevocationCheck defines how the revocation status of the client certificates is checked.
+	RevocationCheck *RevocationCheck `json:"revocationCheck,omitempty" toml:"revocationCheck,omitempty" yaml:"revocationCheck,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RevocationCheck defines the revocation checks of the client certificates.
+type RevocationCheck struct {
+	// CRLFiles defines the certificate revocation lists, PEM or DER encoded, to check the client certificates against.
+	CRLFiles []types.FileOrContent `json:"crlFiles,omitempty" toml:"crlFiles,omitempty" yaml:"crlFiles,omitempty"`
+	// CRLURLs defines the URLs of the certificate revocation lists, periodically downloaded, to check the client certificates against.
+	CRLURLs []string `json:"crlURLs,omitempty" toml:"crlURLs,omitempty" yaml:"crlURLs,omitempty"`
+	// CRLRefreshInterval defines the interval between two downloads of the certificate revocation lists URLs.
+	CRLRefreshInterval ptypes.Duration `json:"crlRefreshInterval,omitempty" toml:"crlRefreshInterval,omitempty" yaml:"crlRefreshInterval,omitempty" export:"true"`
+	// OCSP enables the check of the client certificates against the OCSP responders they define.
+	OCSP bool `json:"ocsp,omitempty" toml:"ocsp,omitempty" yaml:"ocsp,omitempty" export:"true"`
+	// Mode defines the behavior when the revocation status of a client certificate cannot be determined.
+	// The available values are: "SoftFail" (the default), which accepts the certificate, and "HardFail", which rejects it.
+	Mode string `json:"mode,omitempty" toml:"mode,omitempty" yaml:"mode,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -62,4 +84,7 @@ type GeneratedCert struct {
 type CertAndStores struct {
 	Certificate `yaml:",inline" export:"true"`
 	Stores      []string `json:"stores,omitempty" toml:"stores,omitempty" yaml:"stores,omitempty" export:"true"`
+
+	// Source is the name of the provider defining the certificate, set when the configurations are merged.
+	Source string `json:"-" toml:"-" yaml:"-" label:"-" file:"-" kv:"-"`
 }
@@ -7,11 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"reflect"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
 
+	gokitmetrics "github.com/go-kit/kit/metrics"
 	"github.com/go-acme/lego/v4/challenge/dns01"
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/rs/zerolog/log"
@@ -62,6 +64,17 @@ type Manager struct {
 	// It would likely have been a Configuration listener but this implies that certs are re-parsed.
 	// But this would probably have impact on resource consumption.
 	ocspStapler *ocspStapler
+
+	// onDemandResolvers are the resolvers, keyed by name, queried during the TLS handshake
+	// when no certificate of the default store matches the requested server name.
+	onDemandResolvers map[string]CertificateResolver
+
+	// revocationCheckers are the client certificates revocation checkers, keyed by TLS options name.
+	revocationCheckers        map[string]*revocationChecker
+	revocationFailuresCounter gokitmetrics.Counter
+
+	// sessionTicketKeys, when set, are the session ticket keys shared with other instances.
+	sessionTicketKeys *SessionTicketKeys
 }
 
 // NewManager creates a new Manager.
@@ -71,6 +84,8 @@ func NewManager(ocspConfig *OCSPConfig) *Manager {
 		configs: map[string]Options{
 			"default": DefaultTLSOptions,
 		},
+		onDemandResolvers:  map[string]CertificateResolver{},
+		revocationCheckers: map[string]*revocationChecker{},
 	}
 
 	if ocspConfig != nil {
@@ -86,6 +101,38 @@ func (m *Manager) Run(ctx context.Context) {
 	}
 }
 
+// SetRevocationFailuresCounter sets the counter of client certificates rejected as revoked,
+// or, in HardFail mode, whose revocation status cannot be determined.
+func (m *Manager) SetRevocationFailuresCounter(counter gokitmetrics.Counter) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.revocationFailuresCounter = counter
+}
+
+// SetSessionTicketKeys sets the session ticket keys used by the TLS configurations,
+// instead of the keys generated by each instance.
+func (m *Manager) SetSessionTicketKeys(keys *SessionTicketKeys) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.sessionTicketKeys = keys
+}
+
+// AddOnDemandResolver registers a resolver to query when no certificate of the default store
+// matches the server name of a TLS handshake.
+// Resolvers are queried in the alphabetical order of their names.
+func (m *Manager) AddOnDemandResolver(name string, resolver CertificateResolver) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.onDemandResolvers[name] = resolver
+
+	if st, ok := m.stores[DefaultTLSStoreName]; ok {
+		st.setOnDemandResolvers(m.sortedOnDemandResolvers())
+	}
+}
+
 // UpdateConfigs updates the TLS* configuration options.
 // It initializes the default TLS store, and the TLS store for the ACME challenges.
 func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, configs map[string]Options, certs []*CertAndStores) {
@@ -100,6 +147,8 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 		}
 	}
 
+	m.updateRevocationCheckers(ctx)
+
 	m.storesConfig = stores
 	m.certs = certs
 
@@ -160,6 +209,7 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 		certData := &CertificateData{
 			Certificate: &cert,
 			Hash:        certHash,
+			Source:      conf.Source,
 		}
 
 		for _, store := range conf.Stores {
@@ -181,6 +231,10 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 			st.DynamicCerts.Set(certs)
 		}
 
+		if storeName == DefaultTLSStoreName {
+			st.setOnDemandResolvers(m.sortedOnDemandResolvers())
+		}
+
 		// a default cert for the ACME store does not make any sense, so generating one is a waste.
 		if storeName == tlsalpn01.ACMETLS1Protocol {
 			continue
@@ -202,6 +256,33 @@ func (m *Manager) UpdateConfigs(ctx context.Context, stores map[string]Store, co
 	}
 }
 
+// updateRevocationCheckers creates the revocation checkers of the TLS options,
+// and keeps the existing ones, with their downloaded lists and cached responses, when their configuration is unchanged.
+func (m *Manager) updateRevocationCheckers(ctx context.Context) {
+	checkers := make(map[string]*revocationChecker)
+	for optionName, option := range m.configs {
+		revocationCheck := option.ClientAuth.RevocationCheck
+		if revocationCheck == nil {
+			continue
+		}
+
+		if checker, ok := m.revocationCheckers[optionName]; ok && reflect.DeepEqual(checker.config, *revocationCheck) {
+			checkers[optionName] = checker
+			continue
+		}
+
+		checker, err := newRevocationChecker(optionName, *revocationCheck, m.revocationFailuresCounter)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msgf("Unable to create the client certificates revocation checker of TLSOption %q", optionName)
+			continue
+		}
+
+		checkers[optionName] = checker
+	}
+
+	m.revocationCheckers = checkers
+}
+
 // sanitizeDomains sanitizes the domain definition Main and SANS,
 // and returns them as a slice.
 // This func apply the same sanitization as the ACME provider do before resolving certificates.
@@ -232,12 +313,24 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 		return nil, fmt.Errorf("unknown TLS options: %s", configName)
 	}
 
+	var checker *revocationChecker
+	if config.ClientAuth.RevocationCheck != nil {
+		checker, ok = m.revocationCheckers[configName]
+		if !ok {
+			return nil, fmt.Errorf("invalid client certificates revocation check for TLS options: %s", configName)
+		}
+	}
+
 	sniStrict = config.SniStrict
-	tlsConfig, err := buildTLSConfig(config)
+	tlsConfig, err := buildTLSConfig(config, checker)
 	if err != nil {
 		return nil, fmt.Errorf("building TLS config: %w", err)
 	}
 
+	if m.sessionTicketKeys != nil && !config.DisableSessionTickets {
+		m.sessionTicketKeys.apply(tlsConfig)
+	}
+
 	store := m.getStore(storeName)
 	if store == nil {
 		err = fmt.Errorf("TLS store %s not found", storeName)
@@ -333,6 +426,22 @@ func (m *Manager) GetServerCertificates() []*x509.Certificate {
 	return certificates
 }
 
+// sortedOnDemandResolvers returns the registered on-demand resolvers sorted by name.
+func (m *Manager) sortedOnDemandResolvers() []CertificateResolver {
+	names := make([]string, 0, len(m.onDemandResolvers))
+	for name := range m.onDemandResolvers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	resolvers := make([]CertificateResolver, 0, len(names))
+	for _, name := range names {
+		resolvers = append(resolvers, m.onDemandResolvers[name])
+	}
+
+	return resolvers
+}
+
 // getStore returns the store found for storeName, or nil otherwise.
 func (m *Manager) getStore(storeName string) *CertificateStore {
 	st, ok := m.stores[storeName]
@@ -367,6 +476,7 @@ func (m *Manager) getDefaultCertificate(ctx context.Context, tlsStore Store, st
 
 	defaultCertificate := &CertificateData{
 		Certificate: defaultCert,
+		Source:      CertificateSourceGenerated,
 	}
 
 	if tlsStore.DefaultGeneratedCert != nil && tlsStore.DefaultGeneratedCert.Domain != nil && tlsStore.DefaultGeneratedCert.Resolver != "" {
@@ -423,11 +533,13 @@ func (m *Manager) buildDefaultCertificate(defaultCertificate *Certificate) (*Cer
 	return &CertificateData{
 		Certificate: &cert,
 		Hash:        certHash,
+		Source:      CertificateSourceStore,
 	}, nil
 }
 
 // creates a TLS config that allows terminating HTTPS for multiple domains using SNI.
-func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
+// The revocation checker, if any, checks the revocation status of the verified client certificates.
+func buildTLSConfig(tlsOption Options, revocationChecker *revocationChecker) (*tls.Config, error) {
 	conf := &tls.Config{
 		NextProtos:             tlsOption.ALPNProtocols,
 		SessionTicketsDisabled: tlsOption.DisableSessionTickets,
@@ -475,6 +587,15 @@ func buildTLSConfig(tlsOption Options) (*tls.Config, error) {
 		}
 	}
 
+	if revocationChecker != nil {
+		if conf.ClientCAs == nil {
+			return nil, errors.New("invalid revocationCheck: CAFiles is required")
+		}
+
+		conf.VerifyPeerCertificate = revocationChecker.verifyPeerCertificate
+		conf.VerifyConnection = revocationChecker.verifyConnection
+	}
+
 	// Set the minimum TLS version if set in the config
 	if minConst, exists := MinVersion[tlsOption.MinVersion]; exists {
 		conf.MinVersion = minConst
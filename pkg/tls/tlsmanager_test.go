@@ -6,6 +6,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -455,3 +456,30 @@ func TestManager_Get_DefaultValues(t *testing.T) {
 		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
 	}, config.CipherSuites)
 }
+
+func TestManager_AddOnDemandResolver_concurrentHandshakes(t *testing.T) {
+	tlsManager := NewManager(nil)
+	tlsManager.UpdateConfigs(t.Context(), map[string]Store{}, map[string]Options{}, nil)
+
+	store := tlsManager.GetStore(DefaultTLSStoreName)
+	require.NotNil(t, store)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := range 100 {
+			tlsManager.AddOnDemandResolver(fmt.Sprintf("resolver-%d", i), staticResolver{})
+		}
+	}()
+
+	for range 100 {
+		store.GetBestCertificate(&tls.ClientHelloInfo{ServerName: "unknown.localhost"})
+	}
+
+	<-done
+
+	resolvers := store.onDemandResolvers.Load()
+	require.NotNil(t, resolvers)
+	assert.Len(t, *resolvers, 100)
+}
@@ -63,6 +63,11 @@ func (in *ClientAuth) DeepCopyInto(out *ClientAuth) {
 		*out = make([]types.FileOrContent, len(*in))
 		copy(*out, *in)
 	}
+	if in.RevocationCheck != nil {
+		in, out := &in.RevocationCheck, &out.RevocationCheck
+		*out = new(RevocationCheck)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -134,6 +139,32 @@ func (in *Options) DeepCopy() *Options {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevocationCheck) DeepCopyInto(out *RevocationCheck) {
+	*out = *in
+	if in.CRLFiles != nil {
+		in, out := &in.CRLFiles, &out.CRLFiles
+		*out = make([]types.FileOrContent, len(*in))
+		copy(*out, *in)
+	}
+	if in.CRLURLs != nil {
+		in, out := &in.CRLURLs, &out.CRLURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevocationCheck.
+func (in *RevocationCheck) DeepCopy() *RevocationCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(RevocationCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Store) DeepCopyInto(out *Store) {
 	*out = *in
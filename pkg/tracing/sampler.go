@@ -0,0 +1,257 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/apache4/apache4/v3/pkg/types"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type samplingRuleKey struct{}
+
+// SamplingRule holds the sampling settings of an entry point or a router,
+// overriding the global ones when set.
+type SamplingRule struct {
+	// Rate is the rate between 0.0 and 1.0 of the requests to trace.
+	Rate *float64
+	// HonorParentDecision defines whether the sampling decision propagated by the client is followed.
+	HonorParentDecision *bool
+}
+
+// WithSamplingRule returns a copy of the context holding the sampling rule of the spans started from it.
+func WithSamplingRule(ctx context.Context, rule SamplingRule) context.Context {
+	return context.WithValue(ctx, samplingRuleKey{}, rule)
+}
+
+func samplingRuleFromContext(ctx context.Context) SamplingRule {
+	rule, _ := ctx.Value(samplingRuleKey{}).(SamplingRule)
+	return rule
+}
+
+// Sampler decides which traces are exported.
+// The decision is taken when the local root span of a trace, usually the entry point span, starts,
+// according to the sampling rule held by its context,
+// and is followed by all the local spans of the trace.
+// With tail sampling, the spans of the traces not sampled are recorded anyway,
+// and exported when their local root span ends in error or lasts longer than the latency threshold.
+type Sampler struct {
+	rate                float64
+	honorParentDecision bool
+	tailSampling        *types.TailSampling
+}
+
+// NewSampler creates a new Sampler.
+func NewSampler(rate float64, honorParentDecision bool, tailSampling *types.TailSampling) *Sampler {
+	return &Sampler{
+		rate:                rate,
+		honorParentDecision: honorParentDecision,
+		tailSampling:        tailSampling,
+	}
+}
+
+// ShouldSample returns the sampling decision of a span.
+func (s *Sampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(p.ParentContext)
+
+	// A local span follows the decision taken for its local root.
+	if parent.IsValid() && !parent.IsRemote() {
+		switch {
+		case parent.IsSampled():
+			return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: parent.TraceState()}
+		case s.tailSampling != nil && trace.SpanFromContext(p.ParentContext).IsRecording():
+			return sdktrace.SamplingResult{Decision: sdktrace.RecordOnly, Tracestate: parent.TraceState()}
+		default:
+			return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: parent.TraceState()}
+		}
+	}
+
+	rule := samplingRuleFromContext(p.ParentContext)
+
+	honorParentDecision := s.honorParentDecision
+	if rule.HonorParentDecision != nil {
+		honorParentDecision = *rule.HonorParentDecision
+	}
+
+	rate := s.rate
+	if rule.Rate != nil {
+		rate = *rule.Rate
+	}
+
+	var sampled bool
+	if honorParentDecision && parent.IsValid() {
+		sampled = parent.IsSampled()
+	} else {
+		sampled = sampledByRate(p.TraceID, rate)
+	}
+
+	switch {
+	case sampled:
+		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Tracestate: parent.TraceState()}
+	case s.tailSampling != nil:
+		// The spans are recorded to be exported if the request ends in error or is slow.
+		return sdktrace.SamplingResult{Decision: sdktrace.RecordOnly, Tracestate: parent.TraceState()}
+	default:
+		return sdktrace.SamplingResult{Decision: sdktrace.Drop, Tracestate: parent.TraceState()}
+	}
+}
+
+// Description returns the description of the Sampler.
+func (s *Sampler) Description() string {
+	return fmt.Sprintf("Apache4Sampler{rate:%g,honorParentDecision:%t,tailSampling:%t}", s.rate, s.honorParentDecision, s.tailSampling != nil)
+}
+
+// WrapSpanProcessor returns the span processor deciding which of the ended spans are given to the exporting one.
+func (s *Sampler) WrapSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	if s.tailSampling == nil {
+		return next
+	}
+
+	return newTailSamplingProcessor(next, *s.tailSampling)
+}
+
+// sampledByRate returns whether the trace is sampled for the given rate,
+// consistently with the TraceIDRatioBased sampler of OpenTelemetry.
+func sampledByRate(traceID trace.TraceID, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+
+	upperBound := uint64(rate * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:16])>>1 < upperBound
+}
+
+// tailSamplingProcessor buffers the ended spans of the traces not sampled until their local root span ends,
+// and gives them to the next span processor if the local root span is in error or slow.
+// The spans of the sampled traces are given to the next span processor right away.
+type tailSamplingProcessor struct {
+	next             sdktrace.SpanProcessor
+	errors           bool
+	latencyThreshold time.Duration
+	maxBufferedSpans int
+
+	mu sync.Mutex
+	// roots are the local root spans of the started spans not sampled.
+	roots map[trace.SpanID]trace.SpanID
+	// buffers are the ended spans of the local root spans not ended yet.
+	buffers  map[trace.SpanID][]sdktrace.ReadOnlySpan
+	buffered int
+}
+
+func newTailSamplingProcessor(next sdktrace.SpanProcessor, config types.TailSampling) *tailSamplingProcessor {
+	return &tailSamplingProcessor{
+		next:             next,
+		errors:           config.Errors,
+		latencyThreshold: time.Duration(config.LatencyThreshold),
+		maxBufferedSpans: config.MaxBufferedSpans,
+		roots:            make(map[trace.SpanID]trace.SpanID),
+		buffers:          make(map[trace.SpanID][]sdktrace.ReadOnlySpan),
+	}
+}
+
+func (p *tailSamplingProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+
+	if s.SpanContext().IsSampled() {
+		return
+	}
+
+	spanID := s.SpanContext().SpanID()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !s.Parent().IsValid() || s.Parent().IsRemote() {
+		p.roots[spanID] = spanID
+		p.buffers[spanID] = nil
+		return
+	}
+
+	// The spans whose parent is not tracked, as it ended before them, are dropped.
+	if root, ok := p.roots[s.Parent().SpanID()]; ok {
+		p.roots[spanID] = root
+	}
+}
+
+func (p *tailSamplingProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.next.OnEnd(s)
+		return
+	}
+
+	spanID := s.SpanContext().SpanID()
+
+	p.mu.Lock()
+
+	root, ok := p.roots[spanID]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.roots, spanID)
+
+	if root != spanID {
+		if _, ok := p.buffers[root]; ok {
+			if p.buffered < p.maxBufferedSpans {
+				p.buffers[root] = append(p.buffers[root], s)
+				p.buffered++
+			} else {
+				log.Debug().Msg("Too many buffered spans, dropping span of a trace not sampled")
+			}
+		}
+
+		p.mu.Unlock()
+		return
+	}
+
+	spans := p.buffers[root]
+	delete(p.buffers, root)
+	p.buffered -= len(spans)
+
+	p.mu.Unlock()
+
+	if !p.keep(s) {
+		return
+	}
+
+	for _, span := range spans {
+		p.next.OnEnd(sampledSpan{ReadOnlySpan: span})
+	}
+	p.next.OnEnd(sampledSpan{ReadOnlySpan: s})
+}
+
+// keep returns whether the trace of the given local root span is exported.
+func (p *tailSamplingProcessor) keep(root sdktrace.ReadOnlySpan) bool {
+	if p.errors && root.Status().Code == codes.Error {
+		return true
+	}
+
+	return p.latencyThreshold > 0 && root.EndTime().Sub(root.StartTime()) > p.latencyThreshold
+}
+
+func (p *tailSamplingProcessor) Shutdown(ctx context.Context) error {
+	return p.next.Shutdown(ctx)
+}
+
+func (p *tailSamplingProcessor) ForceFlush(ctx context.Context) error {
+	return p.next.ForceFlush(ctx)
+}
+
+// sampledSpan is a span flagged as sampled, for the exporting span processor to export it.
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	spanContext := s.ReadOnlySpan.SpanContext()
+	return spanContext.WithTraceFlags(spanContext.TraceFlags().WithSampled(true))
+}
@@ -0,0 +1,248 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/apache4/paerser/types"
+	"github.com/apache4/apache4/v3/pkg/types"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSampler_ShouldSample(t *testing.T) {
+	// The upper half of the trace ID is sampled with a rate of 0.5, the lower half is not.
+	sampledTraceID := trace.TraceID{8: 0x10}
+	notSampledTraceID := trace.TraceID{8: 0xf0}
+
+	testCases := []struct {
+		desc                string
+		rate                float64
+		honorParentDecision bool
+		tailSampling        *types.TailSampling
+		rule                SamplingRule
+		traceID             trace.TraceID
+		parent              *trace.SpanContextConfig
+		expected            sdktrace.SamplingDecision
+	}{
+		{
+			desc:     "sampled by the global rate",
+			rate:     0.5,
+			traceID:  sampledTraceID,
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			desc:     "not sampled by the global rate",
+			rate:     0.5,
+			traceID:  notSampledTraceID,
+			expected: sdktrace.Drop,
+		},
+		{
+			desc:     "rate of the rule",
+			rate:     0.5,
+			rule:     SamplingRule{Rate: pointer(1.0)},
+			traceID:  notSampledTraceID,
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			desc:     "zero rate of the rule",
+			rate:     1,
+			rule:     SamplingRule{Rate: pointer(0.0)},
+			traceID:  sampledTraceID,
+			expected: sdktrace.Drop,
+		},
+		{
+			desc:         "recorded for the tail sampling",
+			rate:         0.5,
+			tailSampling: &types.TailSampling{Errors: true},
+			traceID:      notSampledTraceID,
+			expected:     sdktrace.RecordOnly,
+		},
+		{
+			desc:    "remote parent decision ignored",
+			rate:    0,
+			traceID: sampledTraceID,
+			parent: &trace.SpanContextConfig{
+				TraceFlags: trace.FlagsSampled,
+				Remote:     true,
+			},
+			expected: sdktrace.Drop,
+		},
+		{
+			desc:                "remote parent decision honored",
+			rate:                0,
+			honorParentDecision: true,
+			traceID:             sampledTraceID,
+			parent: &trace.SpanContextConfig{
+				TraceFlags: trace.FlagsSampled,
+				Remote:     true,
+			},
+			expected: sdktrace.RecordAndSample,
+		},
+		{
+			desc:                "remote parent decision not honored by the rule",
+			rate:                0,
+			honorParentDecision: true,
+			rule:                SamplingRule{HonorParentDecision: pointer(false)},
+			traceID:             sampledTraceID,
+			parent: &trace.SpanContextConfig{
+				TraceFlags: trace.FlagsSampled,
+				Remote:     true,
+			},
+			expected: sdktrace.Drop,
+		},
+		{
+			desc:    "local parent decision followed",
+			rate:    0,
+			traceID: sampledTraceID,
+			parent: &trace.SpanContextConfig{
+				TraceFlags: trace.FlagsSampled,
+			},
+			expected: sdktrace.RecordAndSample,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := WithSamplingRule(t.Context(), test.rule)
+			if test.parent != nil {
+				config := *test.parent
+				config.TraceID = test.traceID
+				config.SpanID = trace.SpanID{1}
+				ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(config))
+			}
+
+			sampler := NewSampler(test.rate, test.honorParentDecision, test.tailSampling)
+
+			result := sampler.ShouldSample(sdktrace.SamplingParameters{
+				ParentContext: ctx,
+				TraceID:       test.traceID,
+				Name:          "EntryPoint",
+			})
+
+			assert.Equal(t, test.expected, result.Decision)
+		})
+	}
+}
+
+func TestSampler_tailSampling(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		tailSampling types.TailSampling
+		rate         float64
+		status       codes.Code
+		duration     time.Duration
+		expected     int
+	}{
+		{
+			desc:         "sampled request",
+			tailSampling: types.TailSampling{Errors: true, MaxBufferedSpans: 10},
+			rate:         1,
+			expected:     2,
+		},
+		{
+			desc:         "successful request not sampled",
+			tailSampling: types.TailSampling{Errors: true, MaxBufferedSpans: 10},
+			expected:     0,
+		},
+		{
+			desc:         "failed request not sampled",
+			tailSampling: types.TailSampling{Errors: true, MaxBufferedSpans: 10},
+			status:       codes.Error,
+			expected:     2,
+		},
+		{
+			desc:         "failed request not sampled without errors",
+			tailSampling: types.TailSampling{MaxBufferedSpans: 10},
+			status:       codes.Error,
+			expected:     0,
+		},
+		{
+			desc:         "slow request not sampled",
+			tailSampling: types.TailSampling{LatencyThreshold: ptypes.Duration(time.Second), MaxBufferedSpans: 10},
+			duration:     2 * time.Second,
+			expected:     2,
+		},
+		{
+			desc:         "fast request not sampled",
+			tailSampling: types.TailSampling{LatencyThreshold: ptypes.Duration(time.Second), MaxBufferedSpans: 10},
+			duration:     time.Millisecond,
+			expected:     0,
+		},
+		{
+			desc:         "failed request with too many buffered spans",
+			tailSampling: types.TailSampling{Errors: true},
+			status:       codes.Error,
+			expected:     1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			exporter := tracetest.NewInMemoryExporter()
+
+			sampler := NewSampler(test.rate, false, &test.tailSampling)
+			processor, ok := sampler.WrapSpanProcessor(sdktrace.NewSimpleSpanProcessor(exporter)).(*tailSamplingProcessor)
+			require.True(t, ok)
+
+			tracerProvider := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(sampler),
+				sdktrace.WithSpanProcessor(processor),
+			)
+			tracer := tracerProvider.Tracer("test")
+
+			start := time.Now()
+			ctx, root := tracer.Start(t.Context(), "EntryPoint", trace.WithTimestamp(start))
+
+			_, child := tracer.Start(ctx, "Service")
+			child.End()
+
+			root.SetStatus(test.status, "")
+			root.End(trace.WithTimestamp(start.Add(test.duration)))
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, test.expected)
+
+			for _, span := range spans {
+				assert.True(t, span.SpanContext.IsSampled())
+			}
+
+			assert.Empty(t, processor.roots)
+			assert.Empty(t, processor.buffers)
+		})
+	}
+}
+
+func TestSampler_tailSamplingRelease(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	processor := newTailSamplingProcessor(sdktrace.NewSimpleSpanProcessor(exporter), types.TailSampling{Errors: true, MaxBufferedSpans: 10})
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(NewSampler(0, false, &types.TailSampling{})),
+		sdktrace.WithSpanProcessor(processor),
+	)
+	tracer := tracerProvider.Tracer("test")
+
+	for range 3 {
+		ctx, root := tracer.Start(context.Background(), "EntryPoint")
+		_, child := tracer.Start(ctx, "Service")
+		child.End()
+		root.End()
+	}
+
+	assert.Empty(t, exporter.GetSpans())
+	assert.Empty(t, processor.roots)
+	assert.Empty(t, processor.buffers)
+	assert.Zero(t, processor.buffered)
+}
+
+func pointer[T any](v T) *T { return &v }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/apache4/apache4/v3/pkg/config/static"
+	"github.com/apache4/apache4/v3/pkg/middlewares/requestid"
 	"github.com/apache4/apache4/v3/pkg/types"
 	"go.opentelemetry.io/contrib/propagators/autoprop"
 	"go.opentelemetry.io/otel"
@@ -25,7 +26,7 @@ import (
 
 // Backend is an abstraction for tracking backend (OpenTelemetry, ...).
 type Backend interface {
-	Setup(ctx context.Context, serviceName string, sampleRate float64, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error)
+	Setup(ctx context.Context, serviceName string, sampler types.TracingSampler, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error)
 }
 
 // NewTracing Creates a Tracing.
@@ -44,7 +45,9 @@ func NewTracing(ctx context.Context, conf *static.Tracing) (*Tracer, io.Closer,
 
 	otel.SetTextMapPropagator(autoprop.NewTextMapPropagator())
 
-	tr, closer, err := backend.Setup(ctx, conf.ServiceName, conf.SampleRate, conf.ResourceAttributes)
+	sampler := NewSampler(conf.SampleRate, conf.HonorParentDecision, conf.TailSampling)
+
+	tr, closer, err := backend.Setup(ctx, conf.ServiceName, sampler, conf.ResourceAttributes)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -185,6 +188,10 @@ func (t *Tracer) CaptureClientRequest(span trace.Span, r *http.Request) {
 		span.SetAttributes(semconv.ServerPort(intPort))
 	}
 
+	if requestID := requestid.GetRequestID(r.Context()); requestID != "" {
+		span.SetAttributes(attribute.String("http.request.id", requestID))
+	}
+
 	for _, header := range t.capturedRequestHeaders {
 		// User-agent is already part of the semantic convention as a recommended attribute.
 		if strings.EqualFold(header, "User-Agent") {
@@ -228,6 +235,10 @@ func (t *Tracer) CaptureServerRequest(span trace.Span, r *http.Request) {
 		span.SetAttributes(semconv.NetworkPeerPort(intPort))
 	}
 
+	if requestID := requestid.GetRequestID(r.Context()); requestID != "" {
+		span.SetAttributes(attribute.String("http.request.id", requestID))
+	}
+
 	for _, header := range t.capturedRequestHeaders {
 		// User-agent is already part of the semantic convention as a recommended attribute.
 		if strings.EqualFold(header, "User-Agent") {
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	ptypes "github.com/apache4/paerser/types"
 	"github.com/apache4/apache4/v3/pkg/version"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -39,6 +40,28 @@ func (v TracingVerbosity) Allows(verbosity TracingVerbosity) bool {
 	}
 }
 
+// TailSampling holds the configuration of the tail sampling,
+// which exports the traces of the failed and slow requests whatever the sample rates.
+type TailSampling struct {
+	Errors           bool            `description:"Exports the traces of the requests ending with a server error (5xx)." json:"errors,omitempty" toml:"errors,omitempty" yaml:"errors,omitempty" export:"true"`
+	LatencyThreshold ptypes.Duration `description:"Exports the traces of the requests lasting longer than this duration (0 to disable)." json:"latencyThreshold,omitempty" toml:"latencyThreshold,omitempty" yaml:"latencyThreshold,omitempty" export:"true"`
+	MaxBufferedSpans int             `description:"Maximum number of spans kept in memory while waiting for the end of their request." json:"maxBufferedSpans,omitempty" toml:"maxBufferedSpans,omitempty" yaml:"maxBufferedSpans,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (t *TailSampling) SetDefaults() {
+	t.Errors = true
+	t.MaxBufferedSpans = 10000
+}
+
+// TracingSampler decides which spans are recorded and exported.
+type TracingSampler interface {
+	sdktrace.Sampler
+
+	// WrapSpanProcessor returns the span processor deciding which of the ended spans are given to the exporting one.
+	WrapSpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor
+}
+
 // OTelTracing provides configuration settings for the open-telemetry tracer.
 type OTelTracing struct {
 	GRPC *OTelGRPC `description:"gRPC configuration for the OpenTelemetry collector." json:"grpc,omitempty" toml:"grpc,omitempty" yaml:"grpc,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
@@ -52,7 +75,7 @@ func (c *OTelTracing) SetDefaults() {
 }
 
 // Setup sets up the tracer.
-func (c *OTelTracing) Setup(ctx context.Context, serviceName string, sampleRate float64, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error) {
+func (c *OTelTracing) Setup(ctx context.Context, serviceName string, sampler TracingSampler, resourceAttributes map[string]string) (trace.Tracer, io.Closer, error) {
 	var (
 		err      error
 		exporter *otlptrace.Exporter
@@ -94,11 +117,12 @@ func (c *OTelTracing) Setup(ctx context.Context, serviceName string, sampleRate
 
 	// Register the trace exporter with a TracerProvider, using a batch
 	// span processor to aggregate spans before export.
+	// The sampler decides which of the ended spans reach the batch span processor.
 	bsp := sdktrace.NewBatchSpanProcessor(exporter)
 	tracerProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(sampleRate)),
+		sdktrace.WithSampler(sampler),
 		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(bsp),
+		sdktrace.WithSpanProcessor(sampler.WrapSpanProcessor(bsp)),
 	)
 
 	otel.SetTracerProvider(tracerProvider)
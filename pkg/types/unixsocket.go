@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// UnixSocketScheme is the scheme of the server URLs and addresses targeting a Unix domain socket,
+// e.g. unix:///var/run/app.sock.
+const UnixSocketScheme = "unix"
+
+// unixSocketHostSuffix is the suffix of the hosts encoding a Unix domain socket path.
+// The .invalid TLD is reserved, and guarantees that such hosts never resolve.
+const unixSocketHostSuffix = ".unix.invalid"
+
+// UnixSocketPath returns the socket path of the given Unix domain socket URL.
+// It returns false if the URL does not target a Unix domain socket.
+func UnixSocketPath(u *url.URL) (string, bool) {
+	if u == nil || !strings.EqualFold(u.Scheme, UnixSocketScheme) || u.Path == "" {
+		return "", false
+	}
+
+	return u.Path, true
+}
+
+// UnixSocketAddressPath returns the socket path of the given Unix domain socket address,
+// e.g. unix:///var/run/app.sock.
+// It returns false if the address does not target a Unix domain socket.
+func UnixSocketAddressPath(address string) (string, bool) {
+	if !strings.HasPrefix(strings.ToLower(address), UnixSocketScheme+":") {
+		return "", false
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", false
+	}
+
+	return UnixSocketPath(u)
+}
+
+// UnixSocketHTTPURL returns the HTTP URL to use to reach the given Unix domain socket.
+// Its host encodes the socket path, for the HTTP clients to keep one connection pool per socket,
+// and for the dialers to retrieve the socket path with UnixSocketFromAddr.
+func UnixSocketHTTPURL(path string) *url.URL {
+	return &url.URL{
+		Scheme: "http",
+		Host:   hex.EncodeToString([]byte(path)) + unixSocketHostSuffix,
+	}
+}
+
+// IsUnixSocketHost reports whether the given host, with an optional port, encodes a Unix domain socket path.
+func IsUnixSocketHost(host string) bool {
+	_, ok := UnixSocketFromAddr(host)
+	return ok
+}
+
+// UnixSocketFromAddr returns the Unix domain socket path encoded in the host of the given address,
+// with an optional port, as built by UnixSocketHTTPURL.
+func UnixSocketFromAddr(addr string) (string, bool) {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+
+	encoded, ok := strings.CutSuffix(host, unixSocketHostSuffix)
+	if !ok {
+		return "", false
+	}
+
+	path, err := hex.DecodeString(encoded)
+	if err != nil || len(path) == 0 {
+		return "", false
+	}
+
+	return string(path), true
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		rawURL       string
+		expectedPath string
+		expectedOK   bool
+	}{
+		{
+			desc:         "unix URL",
+			rawURL:       "unix:///var/run/app.sock",
+			expectedPath: "/var/run/app.sock",
+			expectedOK:   true,
+		},
+		{
+			desc:         "unix URL without authority",
+			rawURL:       "unix:/var/run/app.sock",
+			expectedPath: "/var/run/app.sock",
+			expectedOK:   true,
+		},
+		{
+			desc:         "unix URL in upper case",
+			rawURL:       "UNIX:///var/run/app.sock",
+			expectedPath: "/var/run/app.sock",
+			expectedOK:   true,
+		},
+		{
+			desc:   "unix URL without path",
+			rawURL: "unix://",
+		},
+		{
+			desc:   "http URL",
+			rawURL: "http://127.0.0.1:80",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			u, err := url.Parse(test.rawURL)
+			require.NoError(t, err)
+
+			path, ok := UnixSocketPath(u)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedPath, path)
+
+			path, ok = UnixSocketAddressPath(test.rawURL)
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedPath, path)
+		})
+	}
+}
+
+func TestUnixSocketAddressPath_TCPAddress(t *testing.T) {
+	_, ok := UnixSocketAddressPath("127.0.0.1:8080")
+	assert.False(t, ok)
+
+	_, ok = UnixSocketAddressPath("unix.example.com:8080")
+	assert.False(t, ok)
+}
+
+func TestUnixSocketHTTPURL(t *testing.T) {
+	u := UnixSocketHTTPURL("/var/run/app.sock")
+	assert.Equal(t, "http", u.Scheme)
+	assert.True(t, IsUnixSocketHost(u.Host))
+
+	path, ok := UnixSocketFromAddr(u.Host)
+	require.True(t, ok)
+	assert.Equal(t, "/var/run/app.sock", path)
+
+	path, ok = UnixSocketFromAddr(u.Host + ":80")
+	require.True(t, ok)
+	assert.Equal(t, "/var/run/app.sock", path)
+
+	assert.False(t, IsUnixSocketHost("127.0.0.1:80"))
+	assert.False(t, IsUnixSocketHost("zz.unix.invalid:80"))
+	assert.False(t, IsUnixSocketHost(".unix.invalid"))
+}
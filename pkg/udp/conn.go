@@ -7,7 +7,10 @@ import (
 	"io"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // maxDatagramSize is the maximum size of a UDP datagram.
@@ -222,6 +225,12 @@ type Conn struct {
 	timeout  time.Duration // for timeouts
 	doneOnce sync.Once
 	doneCh   chan struct{}
+
+	muTracing     sync.Mutex
+	tracingCtx    context.Context // holds the span of the session when it is traced
+	span          trace.Span
+	bytesReceived atomic.Int64
+	bytesSent     atomic.Int64
 }
 
 // readLoop waits for data to come from the listener's readLoop.
@@ -276,6 +285,7 @@ func (c *Conn) Read(p []byte) (int, error) {
 	select {
 	case c.readCh <- p:
 		n := <-c.sizeCh
+		c.bytesReceived.Add(int64(n))
 		c.muActivity.Lock()
 		c.lastActivity = time.Now()
 		c.muActivity.Unlock()
@@ -294,12 +304,16 @@ func (c *Conn) Write(p []byte) (n int, err error) {
 	c.lastActivity = time.Now()
 	c.muActivity.Unlock()
 
-	return c.listener.pConn.WriteTo(p, c.rAddr)
+	n, err = c.listener.pConn.WriteTo(p, c.rAddr)
+	c.bytesSent.Add(int64(n))
+
+	return n, err
 }
 
 func (c *Conn) close() {
 	c.doneOnce.Do(func() {
 		close(c.doneCh)
+		c.endSessionSpan()
 	})
 }
 
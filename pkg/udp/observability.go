@@ -0,0 +1,88 @@
+package udp
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the tracer giving the apache4 tracer from the span of a session.
+const tracerName = "github.com/apache4/apache4"
+
+// Observe starts the span of the session, which is ended when the session is closed,
+// recording the bytes transferred.
+func (c *Conn) Observe(tracer trace.Tracer, entryPointName string) {
+	c.muTracing.Lock()
+	defer c.muTracing.Unlock()
+
+	if c.span != nil {
+		return
+	}
+
+	c.tracingCtx, c.span = tracer.Start(context.Background(), "EntryPoint", trace.WithSpanKind(trace.SpanKindServer))
+
+	c.span.SetAttributes(attribute.String("entry_point", entryPointName))
+	c.span.SetAttributes(semconv.NetworkTransportUDP)
+
+	if host, port, err := net.SplitHostPort(c.rAddr.String()); err == nil {
+		intPort, _ := strconv.Atoi(port)
+		c.span.SetAttributes(semconv.ClientAddress(host))
+		c.span.SetAttributes(semconv.ClientPort(intPort))
+		c.span.SetAttributes(semconv.NetworkPeerAddress(host))
+		c.span.SetAttributes(semconv.NetworkPeerPort(intPort))
+	}
+}
+
+// Context returns the context holding the span of the session,
+// or an empty context if the session is not traced.
+func (c *Conn) Context() context.Context {
+	c.muTracing.Lock()
+	defer c.muTracing.Unlock()
+
+	if c.tracingCtx == nil {
+		return context.Background()
+	}
+
+	return c.tracingCtx
+}
+
+func (c *Conn) endSessionSpan() {
+	c.muTracing.Lock()
+	defer c.muTracing.Unlock()
+
+	if c.span == nil {
+		return
+	}
+
+	c.span.SetAttributes(attribute.Int64("apache4.connection.received_bytes", c.bytesReceived.Load()))
+	c.span.SetAttributes(attribute.Int64("apache4.connection.sent_bytes", c.bytesSent.Load()))
+	c.span.End()
+}
+
+// startSpan starts a span from the tracing context of the session, if the session is traced.
+// The returned span is nil otherwise.
+func startSpan(conn *Conn, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx := conn.Context()
+
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return ctx, nil
+	}
+
+	return parent.TracerProvider().Tracer(tracerName).Start(ctx, spanName, opts...)
+}
+
+// endSpan ends the span, setting its status from the given error.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -3,8 +3,11 @@ package udp
 import (
 	"io"
 	"net"
+	"strconv"
 
 	"github.com/rs/zerolog/log"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Proxy is a reverse-proxy implementation of the Handler interface.
@@ -25,7 +28,7 @@ func (p *Proxy) ServeUDP(conn *Conn) {
 	// needed because of e.g. server.trackedConnection
 	defer conn.Close()
 
-	connBackend, err := net.Dial("udp", p.target)
+	connBackend, err := p.dialBackend(conn)
 	if err != nil {
 		log.Error().Err(err).Msg("Error while dialing backend")
 		return
@@ -46,6 +49,25 @@ func (p *Proxy) ServeUDP(conn *Conn) {
 	<-errChan
 }
 
+func (p *Proxy) dialBackend(conn *Conn) (net.Conn, error) {
+	_, span := startSpan(conn, "Dial", trace.WithSpanKind(trace.SpanKindClient))
+	if span == nil {
+		return net.Dial("udp", p.target)
+	}
+
+	span.SetAttributes(semconv.NetworkTransportUDP)
+	if host, port, err := net.SplitHostPort(p.target); err == nil {
+		intPort, _ := strconv.Atoi(port)
+		span.SetAttributes(semconv.ServerAddress(host))
+		span.SetAttributes(semconv.ServerPort(intPort))
+	}
+
+	connBackend, err := net.Dial("udp", p.target)
+	endSpan(span, err)
+
+	return connBackend, err
+}
+
 func connCopy(dst io.WriteCloser, src io.Reader, errCh chan error) {
 	// The buffer is initialized to the maximum UDP datagram size,
 	// to make sure that the whole UDP datagram is read or written atomically (no data is discarded).
@@ -0,0 +1,84 @@
+import { makeRowRender, TlsCertificates as TlsCertificatesPage, TlsCertificatesRender } from './TlsCertificates'
+
+import * as useFetchWithPagination from 'hooks/use-fetch-with-pagination'
+import { useFetchWithPaginationMock } from 'utils/mocks'
+import { renderWithProviders } from 'utils/test'
+
+describe('<TlsCertificatesPage />', () => {
+  it('should render the certificates list', () => {
+    const pages = [
+      {
+        fingerprint: 'a1',
+        store: 'default',
+        source: 'myresolver.acme',
+        resolver: 'myresolver',
+        commonName: 'foo.example.com',
+        sans: ['foo.example.com', 'www.foo.example.com'],
+        issuer: 'CN=R3,O=Let\'s Encrypt,C=US',
+        notAfter: new Date(Date.now() + 5 * 24 * 60 * 60 * 1000).toISOString(),
+        routers: ['foo@docker'],
+        tcpRouters: ['foo-tcp@docker'],
+      },
+      {
+        fingerprint: 'b2',
+        store: 'default',
+        source: 'generated',
+        default: true,
+        commonName: 'APACHE4 DEFAULT CERT',
+        sans: ['8c5f0f0b.apache4.default'],
+        issuer: 'CN=APACHE4 DEFAULT CERT',
+        notAfter: new Date(Date.now() + 365 * 24 * 60 * 60 * 1000).toISOString(),
+        routers: ['bar@file'],
+      },
+      {
+        fingerprint: 'c3',
+        store: 'default',
+        source: 'file',
+        commonName: 'old.example.com',
+        sans: ['old.example.com'],
+        issuer: 'CN=Old CA',
+        notAfter: new Date(Date.now() - 24 * 60 * 60 * 1000).toISOString(),
+      },
+    ].map(makeRowRender())
+    const mock = vi
+      .spyOn(useFetchWithPagination, 'default')
+      .mockImplementation(() => useFetchWithPaginationMock({ pages }))
+
+    const { container, getByTestId } = renderWithProviders(<TlsCertificatesPage />)
+
+    expect(mock).toHaveBeenCalled()
+    expect(getByTestId('TLS Certificates page')).toBeInTheDocument()
+    const tbody = container.querySelectorAll('div[role="table"] > div[role="rowgroup"]')[1]
+    expect(tbody.querySelectorAll('div[role="row"]')).toHaveLength(3)
+
+    expect(tbody.querySelectorAll('div[role="row"]')[0].innerHTML).toContain('foo.example.com')
+    expect(tbody.querySelectorAll('div[role="row"]')[0].innerHTML).toContain('www.foo.example.com')
+    expect(tbody.querySelectorAll('div[role="row"]')[0].innerHTML).toContain('myresolver')
+    expect(tbody.querySelectorAll('div[role="row"]')[0].innerHTML).toContain('2')
+
+    expect(tbody.querySelectorAll('div[role="row"]')[1].innerHTML).toContain('APACHE4 DEFAULT CERT')
+    expect(tbody.querySelectorAll('div[role="row"]')[1].innerHTML).toContain('default (default)')
+    expect(tbody.querySelectorAll('div[role="row"]')[1].innerHTML).toContain('generated')
+
+    expect(tbody.querySelectorAll('div[role="row"]')[2].innerHTML).toContain('old.example.com')
+    expect(tbody.querySelectorAll('div[role="row"]')[2].innerHTML).toContain('Expired')
+  })
+
+  it('should render "No data available" when the API returns empty array', async () => {
+    const { container, getByTestId } = renderWithProviders(
+      <TlsCertificatesRender
+        error={undefined}
+        isEmpty={true}
+        isLoadingMore={false}
+        isReachingEnd={true}
+        loadMore={() => {}}
+        pageCount={1}
+        pages={[]}
+      />,
+    )
+    expect(() => getByTestId('loading')).toThrow('Unable to find an element by: [data-testid="loading"]')
+    const tfoot = container.querySelectorAll('div[role="table"] > div[role="rowgroup"]')[2]
+    expect(tfoot.querySelectorAll('div[role="row"]')).toHaveLength(1)
+    expect(tfoot.querySelectorAll('div[role="row"]')[0].innerHTML).toContain('No data available')
+  })
+})
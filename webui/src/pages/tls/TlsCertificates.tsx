@@ -0,0 +1,139 @@
+import { AriaTable, AriaTbody, AriaTd, AriaTfoot, AriaThead, AriaTr, Badge, Box, Flex, Text } from '@apache4labs/faency'
+import { useMemo } from 'react'
+import useInfiniteScroll from 'react-infinite-scroll-hook'
+import { useSearchParams } from 'react-router-dom'
+
+import { ScrollTopButton } from 'components/ScrollTopButton'
+import { SpinnerLoader } from 'components/SpinnerLoader'
+import { searchParamsToState, TableFilter } from 'components/TableFilter'
+import SortableTh from 'components/tables/SortableTh'
+import Tooltip from 'components/Tooltip'
+import TooltipText from 'components/TooltipText'
+import useFetchWithPagination, { pagesResponseInterface, RenderRowType } from 'hooks/use-fetch-with-pagination'
+import { EmptyPlaceholder } from 'layout/EmptyPlaceholder'
+import Page from 'layout/Page'
+
+const DAY = 24 * 60 * 60 * 1000
+
+const ExpirationBadge = ({ notAfter }: { notAfter: string }) => {
+  const remainingDays = Math.floor((new Date(notAfter).getTime() - Date.now()) / DAY)
+
+  if (remainingDays < 0) {
+    return <Badge variant="red">Expired</Badge>
+  }
+
+  return (
+    <Tooltip label={new Date(notAfter).toUTCString()}>
+      <Badge variant={remainingDays < 15 ? 'orange' : 'green'}>{`${remainingDays} days`}</Badge>
+    </Tooltip>
+  )
+}
+
+export const makeRowRender = (): RenderRowType => {
+  const TlsCertificatesRenderRow = (row) => (
+    <AriaTr key={`${row.store}-${row.fingerprint}`}>
+      <AriaTd>
+        <ExpirationBadge notAfter={row.notAfter} />
+      </AriaTd>
+      <AriaTd>
+        <TooltipText text={row.commonName || row.sans?.[0]} />
+      </AriaTd>
+      <AriaTd>
+        <TooltipText text={row.sans?.join(', ')} />
+      </AriaTd>
+      <AriaTd>
+        <TooltipText text={row.issuer} />
+      </AriaTd>
+      <AriaTd>
+        <TooltipText text={row.default ? `${row.store} (default)` : row.store} />
+      </AriaTd>
+      <AriaTd>
+        <TooltipText text={row.resolver || row.source} />
+      </AriaTd>
+      <AriaTd>
+        <Text>{(row.routers?.length || 0) + (row.tcpRouters?.length || 0)}</Text>
+      </AriaTd>
+    </AriaTr>
+  )
+  return TlsCertificatesRenderRow
+}
+
+export const TlsCertificatesRender = ({
+  error,
+  isEmpty,
+  isLoadingMore,
+  isReachingEnd,
+  loadMore,
+  pageCount,
+  pages,
+}: pagesResponseInterface) => {
+  const [infiniteRef] = useInfiniteScroll({
+    loading: isLoadingMore,
+    hasNextPage: !isReachingEnd && !error,
+    onLoadMore: loadMore,
+  })
+
+  return (
+    <>
+      <AriaTable>
+        <AriaThead>
+          <AriaTr>
+            <SortableTh label="Expiration" css={{ width: '100px' }} />
+            <SortableTh label="Common Name" />
+            <SortableTh label="SANs" />
+            <SortableTh label="Issuer" />
+            <SortableTh label="Store" />
+            <SortableTh label="Source" />
+            <SortableTh label="Routers" css={{ width: '75px' }} />
+          </AriaTr>
+        </AriaThead>
+        <AriaTbody>{pages}</AriaTbody>
+        {(isEmpty || !!error) && (
+          <AriaTfoot>
+            <AriaTr>
+              <AriaTd fullColSpan>
+                <EmptyPlaceholder message={error ? 'Failed to fetch data' : 'No data available'} />
+              </AriaTd>
+            </AriaTr>
+          </AriaTfoot>
+        )}
+      </AriaTable>
+      <Flex css={{ height: 60, alignItems: 'center', justifyContent: 'center' }} ref={infiniteRef}>
+        {isLoadingMore ? <SpinnerLoader /> : isReachingEnd && pageCount > 1 && <ScrollTopButton />}
+      </Flex>
+    </>
+  )
+}
+
+export const TlsCertificates = () => {
+  const renderRow = makeRowRender()
+  const [searchParams] = useSearchParams()
+
+  const query = useMemo(() => searchParamsToState(searchParams), [searchParams])
+  const { pages, pageCount, isLoadingMore, isReachingEnd, loadMore, error, isEmpty } = useFetchWithPagination(
+    '/tls/certificates',
+    {
+      listContextKey: JSON.stringify(query),
+      renderRow,
+      renderLoader: () => null,
+      query,
+    },
+  )
+
+  return (
+    <Page title="TLS Certificates">
+      <Box>
+        <TableFilter hideStatusFilter />
+      </Box>
+      <TlsCertificatesRender
+        error={error}
+        isEmpty={isEmpty}
+        isLoadingMore={isLoadingMore}
+        isReachingEnd={isReachingEnd}
+        loadMore={loadMore}
+        pageCount={pageCount}
+        pages={pages}
+      />
+    </Page>
+  )
+}
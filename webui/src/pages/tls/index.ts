@@ -0,0 +1 @@
+export { TlsCertificates } from './TlsCertificates'
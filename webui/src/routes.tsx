@@ -1,5 +1,5 @@
 import { ReactNode } from 'react'
-import { LiaProjectDiagramSolid, LiaServerSolid, LiaCogsSolid, LiaHomeSolid } from 'react-icons/lia'
+import { LiaProjectDiagramSolid, LiaServerSolid, LiaCogsSolid, LiaHomeSolid, LiaLockSolid } from 'react-icons/lia'
 
 export type Route = {
   path: string
@@ -91,4 +91,15 @@ export const ROUTES: RouteSections[] = [
       },
     ],
   },
+  {
+    section: 'tls',
+    sectionLabel: 'TLS',
+    items: [
+      {
+        path: '/tls/certificates',
+        label: 'TLS Certificates',
+        icon: <LiaLockSolid color="currentColor" size={20} />,
+      },
+    ],
+  },
 ]